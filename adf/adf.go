@@ -2,6 +2,7 @@ package adf
 
 import (
 	"encoding/json"
+	"io"
 	"slices"
 	"strings"
 )
@@ -15,28 +16,35 @@ const (
 	NodeTypeChild   = NodeType("child")
 	NodeTypeUnknown = NodeType("unknown")
 
-	NodeBlockquote  = NodeType("blockquote")
-	NodeBulletList  = NodeType("bulletList")
-	NodeCodeBlock   = NodeType("codeBlock")
-	NodeHeading     = NodeType("heading")
-	NodeOrderedList = NodeType("orderedList")
-	NodePanel       = NodeType("panel")
-	NodeParagraph   = NodeType("paragraph")
-	NodeTable       = NodeType("table")
-	NodeMedia       = NodeType("media")
-	NodeMediaGroup  = NodeType("mediaGroup")
-	NodeMediaSingle = NodeType("mediaSingle")
-
-	ChildNodeText        = NodeType("text")
-	ChildNodeListItem    = NodeType("listItem")
-	ChildNodeTableRow    = NodeType("tableRow")
-	ChildNodeTableHeader = NodeType("tableHeader")
-	ChildNodeTableCell   = NodeType("tableCell")
+	NodeBlockquote    = NodeType("blockquote")
+	NodeBulletList    = NodeType("bulletList")
+	NodeCodeBlock     = NodeType("codeBlock")
+	NodeHeading       = NodeType("heading")
+	NodeOrderedList   = NodeType("orderedList")
+	NodePanel         = NodeType("panel")
+	NodeExpand        = NodeType("expand")
+	NodeParagraph     = NodeType("paragraph")
+	NodeTable         = NodeType("table")
+	NodeMedia         = NodeType("media")
+	NodeMediaGroup    = NodeType("mediaGroup")
+	NodeMediaSingle   = NodeType("mediaSingle")
+	NodeCaption       = NodeType("caption")
+	NodeDecisionList  = NodeType("decisionList")
+	NodeLayoutSection = NodeType("layoutSection")
+
+	ChildNodeText         = NodeType("text")
+	ChildNodeListItem     = NodeType("listItem")
+	ChildNodeTableRow     = NodeType("tableRow")
+	ChildNodeTableHeader  = NodeType("tableHeader")
+	ChildNodeTableCell    = NodeType("tableCell")
+	ChildNodeDecisionItem = NodeType("decisionItem")
+	ChildNodeLayoutColumn = NodeType("layoutColumn")
 
 	InlineNodeCard      = NodeType("inlineCard")
 	InlineNodeEmoji     = NodeType("emoji")
 	InlineNodeMention   = NodeType("mention")
 	InlineNodeHardBreak = NodeType("hardBreak")
+	InlineNodeStatus    = NodeType("status")
 
 	MarkEm        = NodeType("em")
 	MarkLink      = NodeType("link")
@@ -44,6 +52,7 @@ const (
 	MarkStrike    = NodeType("strike")
 	MarkStrong    = NodeType("strong")
 	MarkUnderline = NodeType("underline")
+	MarkAlignment = NodeType("alignment")
 )
 
 // ADF document structure (primary interface)
@@ -108,6 +117,7 @@ func ParentNodes() []NodeType {
 		NodeHeading,
 		NodeOrderedList,
 		NodePanel,
+		NodeExpand,
 		NodeParagraph,
 		NodeTable,
 		NodeMedia,
@@ -180,12 +190,12 @@ func NewTextNodeWithMarks(text string, marks []*ADFMark) *ADFNode {
 	}
 }
 
-// Create a heading node
+// Create a heading node; the level is clamped to the ADF range
 func NewHeadingNode(level int) *ADFNode {
 	return &ADFNode{
 		Type: "heading",
 		Attrs: map[string]any{
-			"level": level,
+			"level": ClampHeadingLevel(level),
 		},
 		Content: []*ADFNode{},
 	}
@@ -247,6 +257,16 @@ func NewEmphasisMark() *ADFMark {
 	}
 }
 
+// Create an alignment mark for a paragraph or heading; align is "center" or "end"
+func NewAlignmentMark(align string) *ADFMark {
+	return &ADFMark{
+		Type: "alignment",
+		Attrs: map[string]any{
+			"align": align,
+		},
+	}
+}
+
 // Create a mention node
 func NewMentionNode(userID, displayText string) *ADFNode {
 	return &ADFNode{
@@ -258,6 +278,34 @@ func NewMentionNode(userID, displayText string) *ADFNode {
 	}
 }
 
+// Create an inline card (smart link) node
+func NewInlineCardNode(url string) *ADFNode {
+	return &ADFNode{
+		Type: InlineNodeCard,
+		Attrs: map[string]any{
+			"url": url,
+		},
+	}
+}
+
+// Create a hard break node
+func NewHardBreakNode() *ADFNode {
+	return &ADFNode{
+		Type: InlineNodeHardBreak,
+	}
+}
+
+// Create a status lozenge node
+func NewStatusNode(text, color string) *ADFNode {
+	return &ADFNode{
+		Type: InlineNodeStatus,
+		Attrs: map[string]any{
+			"text":  text,
+			"color": color,
+		},
+	}
+}
+
 // Create a code block node
 func NewCodeBlockNode(language string) *ADFNode {
 	attrs := make(map[string]any)
@@ -272,6 +320,41 @@ func NewCodeBlockNode(language string) *ADFNode {
 	}
 }
 
+// Create a media single node wrapping a single media item
+func NewMediaSingleNode(layout string) *ADFNode {
+	return &ADFNode{
+		Type: NodeMediaSingle,
+		Attrs: map[string]any{
+			"layout": layout,
+		},
+		Content: []*ADFNode{},
+	}
+}
+
+// Create an external media node referencing an image by URL
+func NewExternalMediaNode(url, alt string) *ADFNode {
+	attrs := map[string]any{
+		"type": "external",
+		"url":  url,
+	}
+	if alt != "" {
+		attrs["alt"] = alt
+	}
+
+	return &ADFNode{
+		Type:  NodeMedia,
+		Attrs: attrs,
+	}
+}
+
+// Create a caption node, the optional second child of a media single
+func NewCaptionNode() *ADFNode {
+	return &ADFNode{
+		Type:    NodeCaption,
+		Content: []*ADFNode{},
+	}
+}
+
 // Create a bullet list node
 func NewBulletListNode() *ADFNode {
 	return &ADFNode{
@@ -302,6 +385,14 @@ func NewListItemNode() *ADFNode {
 	}
 }
 
+// Create a blockquote node
+func NewBlockquoteNode() *ADFNode {
+	return &ADFNode{
+		Type:    NodeBlockquote,
+		Content: []*ADFNode{},
+	}
+}
+
 // Create a panel node
 func NewPanelNode(panelType string) *ADFNode {
 	return &ADFNode{
@@ -313,6 +404,17 @@ func NewPanelNode(panelType string) *ADFNode {
 	}
 }
 
+// NewExpandNode creates a collapsible section with the given title
+func NewExpandNode(title string) *ADFNode {
+	return &ADFNode{
+		Type: "expand",
+		Attrs: map[string]any{
+			"title": title,
+		},
+		Content: []*ADFNode{},
+	}
+}
+
 // NewTableNode creates a new ADF table node
 func NewTableNode() *ADFNode {
 	return &ADFNode{
@@ -351,7 +453,16 @@ func NewTableCellNode() *ADFNode {
 	}
 }
 
-// Convert to JSON
+// Convert to JSON. Attrs are written sorted by key, so equal documents give
+// the same bytes, fit for snapshots and diffs.
 func (doc *ADFDocument) ToJSON() ([]byte, error) {
 	return json.MarshalIndent(doc, "", "  ")
 }
+
+// WriteJSON writes the JSON of ToJSON to w, followed by a newline, without
+// handing the whole of it back to the caller.
+func (doc *ADFDocument) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
@@ -0,0 +1,78 @@
+package adf
+
+// MediaAttributes represents the attributes of a media node in ADF
+type MediaAttributes struct {
+	ID         string `json:"id"`
+	Type       string `json:"type"`
+	Collection string `json:"collection"`
+	Alt        string `json:"alt,omitempty"`
+	URL        string `json:"url,omitempty"`
+	Width      int    `json:"width,omitempty"`
+	Height     int    `json:"height,omitempty"`
+}
+
+// MediaAttrs returns the attributes of a media node; ok is false for other
+// nodes.
+func (n *ADFNode) MediaAttrs() (attrs MediaAttributes, ok bool) {
+	if n == nil || n.Type != NodeMedia {
+		return MediaAttributes{}, false
+	}
+	return ParseMediaAttributes(n.Attrs), true
+}
+
+// CardURL returns the URL of an inline card node; ok is false for other
+// nodes and cards without a URL.
+func (n *ADFNode) CardURL() (url string, ok bool) {
+	if n == nil || n.Type != InlineNodeCard {
+		return "", false
+	}
+	url, ok = n.Attrs["url"].(string)
+	return url, ok && url != ""
+}
+
+// ParseMediaAttributes reads media attributes from an attribute map.
+// Attributes of the wrong type are left zero.
+func ParseMediaAttributes(attrs map[string]any) MediaAttributes {
+	var m MediaAttributes
+	m.ID, _ = attrs["id"].(string)
+	m.Type, _ = attrs["type"].(string)
+	m.Collection, _ = attrs["collection"].(string)
+	m.Alt, _ = attrs["alt"].(string)
+	m.URL, _ = attrs["url"].(string)
+	m.Width, _ = IntAttr(attrs["width"])
+	m.Height, _ = IntAttr(attrs["height"])
+	return m
+}
+
+// MinHeadingLevel and MaxHeadingLevel bound the levels of ADF headings.
+const MinHeadingLevel, MaxHeadingLevel = 1, 6
+
+// HeadingLevel returns the level attribute of a heading, a float64 in decoded
+// JSON or an int, clamped to the ADF range. A missing or non-numeric level is
+// MinHeadingLevel.
+func HeadingLevel(attrs map[string]any) int {
+	level, ok := IntAttr(attrs["level"])
+	if !ok {
+		return MinHeadingLevel
+	}
+	return ClampHeadingLevel(level)
+}
+
+// ClampHeadingLevel returns level clamped to the ADF range.
+func ClampHeadingLevel(level int) int {
+	return min(max(level, MinHeadingLevel), MaxHeadingLevel)
+}
+
+// IntAttr reads a numeric attribute, which is a float64 in decoded JSON and
+// usually an int in documents built in Go.
+func IntAttr(v any) (int, bool) {
+	switch v := v.(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case float64:
+		return int(v), true
+	}
+	return 0, false
+}
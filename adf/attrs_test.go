@@ -0,0 +1,75 @@
+package adf
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMediaAttrs(t *testing.T) {
+	expected := MediaAttributes{ID: "file-1", Type: "file", Collection: "c", Width: 640, Height: 480}
+
+	built := &ADFNode{Type: NodeMedia, Attrs: map[string]any{
+		"id": "file-1", "type": "file", "collection": "c", "width": 640, "height": 480,
+	}}
+	attrs, ok := built.MediaAttrs()
+	require.True(t, ok)
+	assert.Equal(t, expected, attrs)
+
+	var decoded ADFNode
+	data, err := json.Marshal(built)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	attrs, ok = decoded.MediaAttrs()
+	require.True(t, ok)
+	assert.Equal(t, expected, attrs)
+
+	attrs, ok = NewExternalMediaNode("https://example.com/i.png", "alt").MediaAttrs()
+	require.True(t, ok)
+	assert.Equal(t, MediaAttributes{Type: "external", URL: "https://example.com/i.png", Alt: "alt"}, attrs)
+
+	_, ok = NewParagraphNode().MediaAttrs()
+	assert.False(t, ok)
+	_, ok = (&ADFNode{Type: NodeMedia, Attrs: map[string]any{"id": 1}}).MediaAttrs()
+	assert.True(t, ok, "attrs of the wrong type are left zero")
+}
+
+func TestCardURL(t *testing.T) {
+	url, ok := (&ADFNode{Type: InlineNodeCard, Attrs: map[string]any{"url": "https://example.com"}}).CardURL()
+	assert.True(t, ok)
+	assert.Equal(t, "https://example.com", url)
+
+	_, ok = (&ADFNode{Type: InlineNodeCard, Attrs: map[string]any{"data": "{}"}}).CardURL()
+	assert.False(t, ok)
+	_, ok = (&ADFNode{Type: NodeMedia, Attrs: map[string]any{"url": "https://example.com"}}).CardURL()
+	assert.False(t, ok)
+}
+
+func TestHeadingLevel(t *testing.T) {
+	tests := []struct {
+		name     string
+		level    any
+		expected int
+	}{
+		{"int", 3, 3},
+		{"decoded JSON", 3.0, 3},
+		{"below the range", 0, 1},
+		{"above the range", 7, 6},
+		{"not a number", "2", 1},
+		{"missing", nil, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attrs := map[string]any{}
+			if tt.level != nil {
+				attrs["level"] = tt.level
+			}
+			assert.Equal(t, tt.expected, HeadingLevel(attrs))
+		})
+	}
+
+	assert.Equal(t, 6, HeadingLevel(NewHeadingNode(9).Attrs), "constructed headings are clamped")
+}
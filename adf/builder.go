@@ -0,0 +1,284 @@
+package adf
+
+// Inline is an inline node for the builder: text, a mention, an emoji, ...
+type Inline struct{ node *ADFNode }
+
+// Block is a block node for the builder: a paragraph, a list, a table, ...
+type Block struct{ node *ADFNode }
+
+// ListItem is an item of a list built with BulletList, OrderedList or
+// DecisionList.
+type ListItem struct{ node *ADFNode }
+
+// TableRow is a row of a table built with Table.
+type TableRow struct{ node *ADFNode }
+
+// TableCell is a cell of a TableRow.
+type TableCell struct{ node *ADFNode }
+
+// Node returns the built node.
+func (i Inline) Node() *ADFNode { return i.node }
+
+// Node returns the built node.
+func (b Block) Node() *ADFNode { return b.node }
+
+// Text creates plain text.
+func Text(text string) Inline { return Inline{NewTextNode(text)} }
+
+// Styled creates text with the given marks.
+func Styled(text string, marks ...*ADFMark) Inline {
+	return Inline{NewTextNodeWithMarks(text, marks)}
+}
+
+// Bold creates strong text.
+func Bold(text string) Inline { return Styled(text, NewStrongMark()) }
+
+// Italic creates emphasized text.
+func Italic(text string) Inline { return Styled(text, NewEmphasisMark()) }
+
+// Underlined creates underlined text.
+func Underlined(text string) Inline { return Styled(text, NewUnderlineMark()) }
+
+// Strike creates struck-through text.
+func Strike(text string) Inline { return Styled(text, NewStrikethroughMark()) }
+
+// Code creates inline code.
+func Code(text string) Inline { return Styled(text, NewCodeMark()) }
+
+// Link creates text linking to href.
+func Link(text, href string) Inline { return Styled(text, NewLinkMark(href)) }
+
+// Mention creates a mention of the user with the given account ID.
+func Mention(accountID, displayText string) Inline {
+	return Inline{NewMentionNode(accountID, displayText)}
+}
+
+// Emoji creates an emoji from a known shortcode such as ":thumbsup:"; an
+// unknown shortcode is kept as text.
+func Emoji(shortName string) Inline {
+	text, ok := EmojiText(shortName)
+	if !ok {
+		return Text(shortName)
+	}
+	return Inline{NewEmojiNode(shortName, text)}
+}
+
+// Card creates an inline card (smart link) to url.
+func Card(url string) Inline { return Inline{NewInlineCardNode(url)} }
+
+// Status creates a status lozenge.
+func Status(text, color string) Inline { return Inline{NewStatusNode(text, color)} }
+
+// HardBreak creates a line break.
+func HardBreak() Inline { return Inline{NewHardBreakNode()} }
+
+// Paragraph creates a paragraph of the given content.
+func Paragraph(content ...Inline) Block {
+	return Block{withInlines(NewParagraphNode(), content)}
+}
+
+// Heading creates a heading of the given level and content.
+func Heading(level int, content ...Inline) Block {
+	return Block{withInlines(NewHeadingNode(level), content)}
+}
+
+// CodeBlock creates a code block; language may be empty.
+func CodeBlock(language, code string) Block {
+	codeBlock := NewCodeBlockNode(language)
+	if code != "" {
+		codeBlock.Content = append(codeBlock.Content, NewTextNode(code))
+	}
+	return Block{codeBlock}
+}
+
+// Blockquote creates a quote of the given blocks.
+func Blockquote(content ...Block) Block {
+	return Block{withBlocks(NewBlockquoteNode(), content)}
+}
+
+// Panel creates a panel such as "info" or "warning" of the given blocks.
+func Panel(panelType string, content ...Block) Block {
+	return Block{withBlocks(NewPanelNode(panelType), content)}
+}
+
+// Expand creates a collapsible section of the given blocks.
+func Expand(title string, content ...Block) Block {
+	return Block{withBlocks(NewExpandNode(title), content)}
+}
+
+// BulletList creates a bullet list.
+func BulletList(items ...ListItem) Block {
+	list := NewBulletListNode()
+	for _, item := range items {
+		list.Content = append(list.Content, item.node)
+	}
+	return Block{list}
+}
+
+// OrderedList creates an ordered list numbered from start.
+func OrderedList(start int, items ...ListItem) Block {
+	list := NewOrderedListNode(start)
+	for _, item := range items {
+		list.Content = append(list.Content, item.node)
+	}
+	return Block{list}
+}
+
+// Item creates a list item holding a paragraph of the given content.
+func Item(content ...Inline) ListItem {
+	return ItemBlocks(Paragraph(content...))
+}
+
+// ItemBlocks creates a list item of the given blocks, e.g. a paragraph
+// followed by a nested list.
+func ItemBlocks(content ...Block) ListItem {
+	return ListItem{withBlocks(NewListItemNode(), content)}
+}
+
+// DecisionList creates a decision list of items created with Decision.
+func DecisionList(items ...ListItem) Block {
+	list := NewDecisionListNode()
+	for _, item := range items {
+		list.Content = append(list.Content, item.node)
+	}
+	return Block{list}
+}
+
+// Decision creates a decided decision item of the given content.
+func Decision(content ...Inline) ListItem {
+	return ListItem{withInlines(NewDecisionItemNode(DecisionDecided), content)}
+}
+
+// Table creates a table of the given rows.
+func Table(rows ...TableRow) Block {
+	table := NewTableNode()
+	for _, row := range rows {
+		table.Content = append(table.Content, row.node)
+	}
+	return Block{table}
+}
+
+// Row creates a table row of the given cells.
+func Row(cells ...TableCell) TableRow {
+	row := NewTableRowNode()
+	for _, cell := range cells {
+		row.Content = append(row.Content, cell.node)
+	}
+	return TableRow{row}
+}
+
+// Cell creates a table cell holding a paragraph of the given content.
+func Cell(content ...Inline) TableCell {
+	return CellBlocks(Paragraph(content...))
+}
+
+// CellBlocks creates a table cell of the given blocks.
+func CellBlocks(content ...Block) TableCell {
+	return TableCell{withBlocks(NewTableCellNode(), content)}
+}
+
+// HeaderCell creates a table header cell holding a paragraph of the given
+// content.
+func HeaderCell(content ...Inline) TableCell {
+	return TableCell{withBlocks(NewTableHeaderNode(), []Block{Paragraph(content...)})}
+}
+
+func withInlines(n *ADFNode, content []Inline) *ADFNode {
+	for _, i := range content {
+		n.Content = append(n.Content, i.node)
+	}
+	return n
+}
+
+func withBlocks(n *ADFNode, content []Block) *ADFNode {
+	for _, b := range content {
+		n.Content = append(n.Content, b.node)
+	}
+	return n
+}
+
+// DocBuilder builds a document block by block:
+//
+//	doc := adf.NewDocBuilder().
+//		Heading(1, "Title").
+//		Paragraph(adf.Text("hi "), adf.Bold("there")).
+//		BulletList(adf.Item(adf.Text("one")), adf.Item(adf.Text("two"))).
+//		Build()
+type DocBuilder struct {
+	doc *ADFDocument
+}
+
+// NewDocBuilder starts an empty document.
+func NewDocBuilder() *DocBuilder {
+	return &DocBuilder{doc: NewADFDocument()}
+}
+
+// Append adds blocks, including ones built elsewhere with the package
+// functions such as Panel or Table.
+func (b *DocBuilder) Append(blocks ...Block) *DocBuilder {
+	for _, block := range blocks {
+		b.doc.Content = append(b.doc.Content, block.node)
+	}
+	return b
+}
+
+// AppendNodes adds nodes built without the builder, e.g. by a translator.
+func (b *DocBuilder) AppendNodes(nodes ...*ADFNode) *DocBuilder {
+	b.doc.Content = append(b.doc.Content, nodes...)
+	return b
+}
+
+// Heading adds a heading with plain text.
+func (b *DocBuilder) Heading(level int, text string) *DocBuilder {
+	return b.Append(Heading(level, Text(text)))
+}
+
+// Paragraph adds a paragraph.
+func (b *DocBuilder) Paragraph(content ...Inline) *DocBuilder {
+	return b.Append(Paragraph(content...))
+}
+
+// BulletList adds a bullet list.
+func (b *DocBuilder) BulletList(items ...ListItem) *DocBuilder {
+	return b.Append(BulletList(items...))
+}
+
+// OrderedList adds an ordered list numbered from start.
+func (b *DocBuilder) OrderedList(start int, items ...ListItem) *DocBuilder {
+	return b.Append(OrderedList(start, items...))
+}
+
+// DecisionList adds a decision list.
+func (b *DocBuilder) DecisionList(items ...ListItem) *DocBuilder {
+	return b.Append(DecisionList(items...))
+}
+
+// CodeBlock adds a code block.
+func (b *DocBuilder) CodeBlock(language, code string) *DocBuilder {
+	return b.Append(CodeBlock(language, code))
+}
+
+// Blockquote adds a quote.
+func (b *DocBuilder) Blockquote(content ...Block) *DocBuilder {
+	return b.Append(Blockquote(content...))
+}
+
+// Panel adds a panel.
+func (b *DocBuilder) Panel(panelType string, content ...Block) *DocBuilder {
+	return b.Append(Panel(panelType, content...))
+}
+
+// Expand adds a collapsible section.
+func (b *DocBuilder) Expand(title string, content ...Block) *DocBuilder {
+	return b.Append(Expand(title, content...))
+}
+
+// Table adds a table.
+func (b *DocBuilder) Table(rows ...TableRow) *DocBuilder {
+	return b.Append(Table(rows...))
+}
+
+// Build returns the document. The builder must not be used afterwards.
+func (b *DocBuilder) Build() *ADFDocument {
+	return b.doc
+}
@@ -0,0 +1,87 @@
+package adf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocBuilder(t *testing.T) {
+	doc := NewDocBuilder().
+		Heading(1, "Title").
+		Paragraph(Text("hi "), Bold("there"), Text(" "), Mention("abc-123", "@alice")).
+		BulletList(Item(Text("one")), ItemBlocks(Paragraph(Text("two")), OrderedList(3, Item(Code("x"))))).
+		Table(Row(HeaderCell(Text("Name"))), Row(Cell(Link("site", "https://example.com")))).
+		Panel("info", Paragraph(Emoji(":bulb:"), Text(" tip"))).
+		Build()
+
+	heading := NewHeadingNode(1)
+	heading.Content = append(heading.Content, NewTextNode("Title"))
+
+	paragraph := NewParagraphNode()
+	paragraph.Content = append(paragraph.Content,
+		NewTextNode("hi "),
+		NewTextNodeWithMarks("there", []*ADFMark{NewStrongMark()}),
+		NewTextNode(" "),
+		NewMentionNode("abc-123", "@alice"),
+	)
+
+	nested := NewOrderedListNode(3)
+	nested.Content = append(nested.Content, listItemOf(NewTextNodeWithMarks("x", []*ADFMark{NewCodeMark()})))
+	second := listItemOf(NewTextNode("two"))
+	second.Content = append(second.Content, nested)
+	list := NewBulletListNode()
+	list.Content = append(list.Content, listItemOf(NewTextNode("one")), second)
+
+	header := NewTableHeaderNode()
+	header.Content = append(header.Content, paragraphWith(NewTextNode("Name")))
+	cell := NewTableCellNode()
+	cell.Content = append(cell.Content, paragraphWith(NewTextNodeWithMarks("site", []*ADFMark{NewLinkMark("https://example.com")})))
+	headerRow, row := NewTableRowNode(), NewTableRowNode()
+	headerRow.Content = append(headerRow.Content, header)
+	row.Content = append(row.Content, cell)
+	table := NewTableNode()
+	table.Content = append(table.Content, headerRow, row)
+
+	panel := NewPanelNode("info")
+	panel.Content = append(panel.Content, paragraphWith(NewEmojiNode(":bulb:", "💡"), NewTextNode(" tip")))
+
+	expected := NewADFDocument()
+	expected.Content = append(expected.Content, heading, paragraph, list, table, panel)
+
+	require.True(t, EqualDocuments(expected, doc))
+	assert.NoError(t, Validate(doc))
+}
+
+func TestDocBuilderMixedNodes(t *testing.T) {
+	existing := NewParagraphNode()
+	existing.Content = append(existing.Content, NewTextNode("from a translator"))
+
+	doc := NewDocBuilder().
+		AppendNodes(existing).
+		Append(Blockquote(Paragraph(Text("quoted")))).
+		CodeBlock("go", "fmt.Println()").
+		Build()
+
+	require.Len(t, doc.Content, 3)
+	assert.Same(t, existing, doc.Content[0])
+	assert.Equal(t, "from a translator\nquoted\nfmt.Println()", PlainText(doc))
+	assert.Equal(t, "go", doc.Content[2].Attrs["language"])
+
+	// Unknown shortcodes stay text
+	assert.Equal(t, ChildNodeText, Emoji(":nope:").Node().Type)
+	assert.Equal(t, InlineNodeEmoji, Emoji(":tada:").Node().Type)
+}
+
+func paragraphWith(content ...*ADFNode) *ADFNode {
+	paragraph := NewParagraphNode()
+	paragraph.Content = append(paragraph.Content, content...)
+	return paragraph
+}
+
+func listItemOf(content ...*ADFNode) *ADFNode {
+	item := NewListItemNode()
+	item.Content = append(item.Content, paragraphWith(content...))
+	return item
+}
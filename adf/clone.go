@@ -0,0 +1,212 @@
+package adf
+
+import "reflect"
+
+// Clone returns a deep copy of the node, including its attrs and marks. A
+// node reachable several times in the tree is copied each time.
+func (n *ADFNode) Clone() *ADFNode {
+	if n == nil {
+		return nil
+	}
+
+	c := &ADFNode{
+		Type:  n.Type,
+		Text:  n.Text,
+		Attrs: cloneAttrs(n.Attrs),
+	}
+	if n.Content != nil {
+		c.Content = make([]*ADFNode, len(n.Content))
+		for i, child := range n.Content {
+			c.Content[i] = child.Clone()
+		}
+	}
+	if n.Marks != nil {
+		c.Marks = make([]*ADFMark, len(n.Marks))
+		for i, m := range n.Marks {
+			c.Marks[i] = m.Clone()
+		}
+	}
+	return c
+}
+
+// Clone returns a deep copy of the mark.
+func (m *ADFMark) Clone() *ADFMark {
+	if m == nil {
+		return nil
+	}
+	return &ADFMark{Type: m.Type, Attrs: cloneAttrs(m.Attrs)}
+}
+
+// Clone returns a deep copy of the document.
+func (doc *ADFDocument) Clone() *ADFDocument {
+	if doc == nil {
+		return nil
+	}
+
+	c := &ADFDocument{Version: doc.Version, Type: doc.Type}
+	if doc.Content != nil {
+		c.Content = make([]*ADFNode, len(doc.Content))
+		for i, n := range doc.Content {
+			c.Content[i] = n.Clone()
+		}
+	}
+	return c
+}
+
+// cloneAttrs deep-copies an attr map along with the maps and slices in it.
+func cloneAttrs(attrs map[string]any) map[string]any {
+	if attrs == nil {
+		return nil
+	}
+	c := make(map[string]any, len(attrs))
+	for k, v := range attrs {
+		c[k] = cloneValue(v)
+	}
+	return c
+}
+
+func cloneValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		return cloneAttrs(v)
+	case []any:
+		c := make([]any, len(v))
+		for i, e := range v {
+			c[i] = cloneValue(e)
+		}
+		return c
+	}
+	return v
+}
+
+// Equal reports whether two nodes have the same type, text, attrs, marks and
+// content. Numeric attrs are compared by value, so that an int attr of a
+// built node equals the float64 it becomes in a JSON roundtrip. Nil and empty
+// attrs, marks and content are equal.
+func Equal(a, b *ADFNode) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Type != b.Type || a.Text != b.Text || !equalAttrs(a.Attrs, b.Attrs) {
+		return false
+	}
+
+	return equalMarkLists(a.Marks, b.Marks) && equalNodes(a.Content, b.Content)
+}
+
+// EqualDocuments is Equal for documents.
+func EqualDocuments(a, b *ADFDocument) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Version == b.Version && a.Type == b.Type && equalNodes(a.Content, b.Content)
+}
+
+// MergeText joins neighbouring text nodes with equal marks throughout doc,
+// such as the "a" and "." a translation may produce for "a.". Documents that
+// only differ in how their text is split are equal after MergeText.
+func MergeText(doc *ADFDocument) {
+	Walk(doc, func(n *ADFNode, _ int) bool {
+		var merged []*ADFNode
+		for _, child := range n.Content {
+			if last := len(merged) - 1; last >= 0 && child != nil && child.Type == ChildNodeText &&
+				merged[last] != nil && merged[last].Type == ChildNodeText && equalMarkLists(merged[last].Marks, child.Marks) {
+				merged[last].Text += child.Text
+				continue
+			}
+			merged = append(merged, child)
+		}
+		n.Content = merged
+		return true
+	})
+}
+
+func equalNodes(a, b []*ADFNode) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func equalMarkLists(a, b []*ADFMark) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !equalMarks(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func equalMarks(a, b *ADFMark) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Type == b.Type && equalAttrs(a.Attrs, b.Attrs)
+}
+
+func equalAttrs(a, b map[string]any) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, av := range a {
+		bv, ok := b[k]
+		if !ok || !equalValues(av, bv) {
+			return false
+		}
+	}
+	return true
+}
+
+func equalValues(a, b any) bool {
+	if af, ok := number(a); ok {
+		bf, ok := number(b)
+		return ok && af == bf
+	}
+
+	switch a := a.(type) {
+	case map[string]any:
+		b, ok := b.(map[string]any)
+		return ok && equalAttrs(a, b)
+	case []any:
+		b, ok := b.([]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !equalValues(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	case nil:
+		return b == nil
+	}
+
+	// Remaining values are strings, booleans and the like
+	return reflect.DeepEqual(a, b)
+}
+
+// number returns a numeric value as a float64.
+func number(v any) (float64, bool) {
+	switch v := v.(type) {
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
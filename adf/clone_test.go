@@ -0,0 +1,103 @@
+package adf
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleDocument() *ADFDocument {
+	heading := NewHeadingNode(2)
+	heading.Content = append(heading.Content, NewTextNodeWithMarks("Title", []*ADFMark{NewLinkMark("https://example.com")}))
+
+	media := &ADFNode{Type: NodeMedia, Attrs: map[string]any{"id": "file-1", "width": 640, "extra": map[string]any{"tags": []any{"a", 1}}}}
+	single := NewMediaSingleNode("center")
+	single.Content = append(single.Content, media)
+
+	return &ADFDocument{Version: 1, Type: "doc", Content: []*ADFNode{heading, single, NewParagraphNode()}}
+}
+
+func TestClone(t *testing.T) {
+	doc := sampleDocument()
+	clone := doc.Clone()
+
+	require.True(t, EqualDocuments(doc, clone))
+
+	// Nothing is shared with the original
+	clone.Content[0].Content[0].Text = "Changed"
+	clone.Content[0].Content[0].Marks[0].Attrs["href"] = "https://changed.example.com"
+	clone.Content[1].Content[0].Attrs["extra"].(map[string]any)["tags"].([]any)[0] = "changed"
+	clone.Content[1].Content = append(clone.Content[1].Content, NewParagraphNode())
+
+	assert.Equal(t, "Title", doc.Content[0].Content[0].Text)
+	assert.Equal(t, "https://example.com", doc.Content[0].Content[0].Marks[0].Attrs["href"])
+	assert.Equal(t, "a", doc.Content[1].Content[0].Attrs["extra"].(map[string]any)["tags"].([]any)[0])
+	assert.Len(t, doc.Content[1].Content, 1)
+	assert.False(t, EqualDocuments(doc, clone))
+
+	var nilNode *ADFNode
+	var nilDoc *ADFDocument
+	assert.Nil(t, nilNode.Clone())
+	assert.Nil(t, nilDoc.Clone())
+	assert.Nil(t, (&ADFNode{Type: NodeParagraph}).Clone().Attrs)
+}
+
+func TestEqual(t *testing.T) {
+	doc := sampleDocument()
+
+	// A JSON roundtrip turns int attrs into float64 ones
+	data, err := json.Marshal(doc)
+	require.NoError(t, err)
+	var decoded ADFDocument
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.True(t, EqualDocuments(doc, &decoded))
+	assert.True(t, Equal(doc.Content[1], decoded.Content[1]))
+
+	tests := []struct {
+		name  string
+		a, b  *ADFNode
+		equal bool
+	}{
+		{"nil nodes", nil, nil, true},
+		{"nil and node", nil, NewParagraphNode(), false},
+		{"nil and empty attrs", &ADFNode{Type: NodeParagraph}, &ADFNode{Type: NodeParagraph, Attrs: map[string]any{}}, true},
+		{"nil and empty content", &ADFNode{Type: NodeParagraph}, &ADFNode{Type: NodeParagraph, Content: []*ADFNode{}}, true},
+		{"different text", NewTextNode("a"), NewTextNode("b"), false},
+		{"different marks", NewTextNodeWithMarks("a", []*ADFMark{NewStrongMark()}), NewTextNodeWithMarks("a", []*ADFMark{NewEmphasisMark()}), false},
+		{"missing mark", NewTextNodeWithMarks("a", []*ADFMark{NewStrongMark()}), NewTextNode("a"), false},
+		{"int and float", NewHeadingNode(2), &ADFNode{Type: NodeHeading, Attrs: map[string]any{"level": 2.0}}, true},
+		{"different numbers", NewHeadingNode(2), NewHeadingNode(3), false},
+		{"number and string", NewHeadingNode(2), &ADFNode{Type: NodeHeading, Attrs: map[string]any{"level": "2"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.equal, Equal(tt.a, tt.b))
+			assert.Equal(t, tt.equal, Equal(tt.b, tt.a))
+		})
+	}
+}
+
+func TestMergeText(t *testing.T) {
+	link := []*ADFMark{NewLinkMark("https://example.com")}
+
+	paragraph := NewParagraphNode()
+	paragraph.Content = []*ADFNode{
+		NewTextNode("in it"), NewTextNode("."), NewHardBreakNode(), NewTextNode("see "),
+		NewTextNodeWithMarks("here", link), NewTextNodeWithMarks("!", []*ADFMark{NewLinkMark("https://example.com")}),
+		NewTextNode(" or "), NewTextNodeWithMarks("there", []*ADFMark{NewLinkMark("https://example.org")}),
+	}
+	doc := &ADFDocument{Version: 1, Type: "doc", Content: []*ADFNode{paragraph}}
+
+	MergeText(doc)
+
+	want := NewParagraphNode()
+	want.Content = []*ADFNode{
+		NewTextNode("in it."), NewHardBreakNode(), NewTextNode("see "),
+		NewTextNodeWithMarks("here!", link), NewTextNode(" or "),
+		NewTextNodeWithMarks("there", []*ADFMark{NewLinkMark("https://example.org")}),
+	}
+	assert.True(t, Equal(want, doc.Content[0]))
+}
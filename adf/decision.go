@@ -0,0 +1,44 @@
+package adf
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+// DecisionDecided is the state of a decision item that has been made, the
+// only state Jira's editor gives decisions.
+const DecisionDecided = "DECIDED"
+
+// NewLocalID returns a random version 4 UUID, the form of the localId attr
+// Jira's editor gives task and decision nodes.
+func NewLocalID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// NewDecisionListNode creates a decision list with a new localId.
+func NewDecisionListNode() *ADFNode {
+	return &ADFNode{
+		Type: NodeDecisionList,
+		Attrs: map[string]any{
+			"localId": NewLocalID(),
+		},
+		Content: []*ADFNode{},
+	}
+}
+
+// NewDecisionItemNode creates a decision item in the given state with a new
+// localId. Its content is inline.
+func NewDecisionItemNode(state string) *ADFNode {
+	return &ADFNode{
+		Type: ChildNodeDecisionItem,
+		Attrs: map[string]any{
+			"localId": NewLocalID(),
+			"state":   state,
+		},
+		Content: []*ADFNode{},
+	}
+}
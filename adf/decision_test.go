@@ -0,0 +1,33 @@
+package adf
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewLocalID(t *testing.T) {
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+	id := NewLocalID()
+	assert.Regexp(t, uuid, id)
+	assert.NotEqual(t, id, NewLocalID())
+}
+
+func TestDecisionNodes(t *testing.T) {
+	doc := NewDocBuilder().
+		DecisionList(Decision(Text("Ship on "), Bold("Friday")), Decision(Text("Use Go"))).
+		Build()
+
+	list := doc.Content[0]
+	assert.Equal(t, NodeDecisionList, list.Type)
+	assert.NotEmpty(t, list.Attrs["localId"])
+	assert.Len(t, list.Content, 2)
+	for _, item := range list.Content {
+		assert.Equal(t, ChildNodeDecisionItem, item.Type)
+		assert.Equal(t, DecisionDecided, item.Attrs["state"])
+		assert.NotEqual(t, list.Attrs["localId"], item.Attrs["localId"])
+	}
+	assert.Equal(t, "Ship on Friday\nUse Go", PlainText(doc))
+}
@@ -0,0 +1,371 @@
+package adf
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// RenderDiffMarkdown renders the changes from before to after as markdown for
+// review. Blocks are aligned by content; text that changed is compared word by
+// word, with deletions struck through (~~old~~) and insertions in bold
+// (**new**). Blocks added or removed as a whole, such as a table row or a
+// panel, are rendered as labeled blockquote callouts. Unchanged content is
+// rendered as is. Nil nodes are skipped and cycles are not followed, as with
+// Walk.
+func RenderDiffMarkdown(before, after *ADFDocument) string {
+	var beforeContent, afterContent []*ADFNode
+	if before != nil {
+		beforeContent = diffableContent(before.Content, make(map[*ADFNode]bool))
+	}
+	if after != nil {
+		afterContent = diffableContent(after.Content, make(map[*ADFNode]bool))
+	}
+
+	blocks := diffBlocks(beforeContent, afterContent)
+	if len(blocks) == 0 {
+		return ""
+	}
+	return strings.Join(blocks, "\n\n") + "\n"
+}
+
+// diffableContent copies nodes for diffing, leaving out nil nodes and marks
+// and the nodes that close a cycle, so that the copy can be compared and
+// rendered without checking for either.
+func diffableContent(nodes []*ADFNode, ancestors map[*ADFNode]bool) []*ADFNode {
+	var content []*ADFNode
+	for _, n := range nodes {
+		if n == nil || ancestors[n] {
+			continue
+		}
+
+		c := *n
+		c.Marks = slices.DeleteFunc(slices.Clone(n.Marks), func(m *ADFMark) bool { return m == nil })
+		ancestors[n] = true
+		c.Content = diffableContent(n.Content, ancestors)
+		delete(ancestors, n)
+		content = append(content, &c)
+	}
+	return content
+}
+
+// nodePair is a pair of aligned nodes. One side is nil for an added or
+// removed node.
+type nodePair struct {
+	before, after *ADFNode
+}
+
+// alignNodes aligns two node lists on their longest common subsequence of
+// equal nodes. Between equal nodes, removed and added nodes of the same type
+// are paired as modified, the rest are left unpaired.
+func alignNodes(before, after []*ADFNode) []nodePair {
+	n, m := len(before), len(after)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if Equal(before[i], after[j]) {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var pairs []nodePair
+	var removed, added []*ADFNode
+	flush := func() {
+		j := 0
+		for _, r := range removed {
+			k := j
+			for k < len(added) && added[k].Type != r.Type {
+				k++
+			}
+			if k == len(added) {
+				pairs = append(pairs, nodePair{before: r})
+				continue
+			}
+			for ; j < k; j++ {
+				pairs = append(pairs, nodePair{after: added[j]})
+			}
+			pairs = append(pairs, nodePair{before: r, after: added[k]})
+			j = k + 1
+		}
+		for ; j < len(added); j++ {
+			pairs = append(pairs, nodePair{after: added[j]})
+		}
+		removed, added = nil, nil
+	}
+
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && Equal(before[i], after[j]):
+			flush()
+			pairs = append(pairs, nodePair{before[i], after[j]})
+			i++
+			j++
+		case j == m || (i < n && lcs[i+1][j] >= lcs[i][j+1]):
+			removed = append(removed, before[i])
+			i++
+		default:
+			added = append(added, after[j])
+			j++
+		}
+	}
+	flush()
+
+	return pairs
+}
+
+// diffBlocks renders aligned block nodes, one string per block.
+func diffBlocks(before, after []*ADFNode) []string {
+	var blocks []string
+	for _, p := range alignNodes(before, after) {
+		if s := diffBlock(p); s != "" {
+			blocks = append(blocks, s)
+		}
+	}
+	return blocks
+}
+
+// diffBlock renders a pair of aligned block nodes.
+func diffBlock(p nodePair) string {
+	switch {
+	case p.before == nil:
+		return callout("Added", p.after)
+	case p.after == nil:
+		return callout("Removed", p.before)
+	case p.before.Type != p.after.Type:
+		return callout("Removed", p.before) + "\n\n" + callout("Added", p.after)
+	}
+
+	switch p.after.Type {
+	case NodeParagraph:
+		return diffInline(p.before, p.after)
+	case NodeHeading:
+		return strings.Repeat("#", HeadingLevel(p.after.Attrs)) + " " + diffInline(p.before, p.after)
+	case NodeBulletList, NodeOrderedList:
+		return diffList(p.before, p.after)
+	case NodeBlockquote:
+		return quote(strings.Join(diffBlocks(p.before.Content, p.after.Content), "\n\n"))
+	case NodePanel:
+		return strings.Join(diffBlocks(p.before.Content, p.after.Content), "\n\n")
+	case NodeTable:
+		return diffTable(p.before, p.after)
+	case NodeCodeBlock:
+		if Equal(p.before, p.after) {
+			language, _ := p.after.Attrs["language"].(string)
+			return "```" + language + "\n" + nodeText(p.after) + "\n```"
+		}
+	}
+
+	if Equal(p.before, p.after) {
+		return nodeText(p.after)
+	}
+	return callout("Removed", p.before) + "\n\n" + callout("Added", p.after)
+}
+
+// diffList renders a pair of lists item by item.
+func diffList(before, after *ADFNode) string {
+	var items []string
+	for i, p := range alignNodes(before.Content, after.Content) {
+		marker := "- "
+		if after.Type == NodeOrderedList {
+			marker = fmt.Sprintf("%d. ", i+1)
+		}
+
+		var content string
+		if p.before == nil || p.after == nil {
+			content = diffBlock(p)
+		} else {
+			content = strings.Join(diffBlocks(p.before.Content, p.after.Content), "\n")
+		}
+
+		items = append(items, marker+strings.ReplaceAll(content, "\n", "\n    "))
+	}
+	return strings.Join(items, "\n")
+}
+
+// diffTable renders a pair of tables. Rows present on both sides are compared
+// cell by cell; added and removed rows follow the table as callouts.
+func diffTable(before, after *ADFNode) string {
+	var rows, callouts []string
+	for _, p := range alignNodes(before.Content, after.Content) {
+		if p.before == nil || p.after == nil {
+			callouts = append(callouts, diffBlock(p))
+			continue
+		}
+
+		var cells []string
+		for i := range max(len(p.before.Content), len(p.after.Content)) {
+			var beforeCell, afterCell *ADFNode
+			if i < len(p.before.Content) {
+				beforeCell = p.before.Content[i]
+			}
+			if i < len(p.after.Content) {
+				afterCell = p.after.Content[i]
+			}
+
+			if beforeCell != nil && afterCell != nil && Equal(beforeCell, afterCell) {
+				var parts []string
+				for _, paragraph := range afterCell.Content {
+					parts = append(parts, renderInline(paragraph.Content))
+				}
+				cells = append(cells, strings.Join(parts, " "))
+			} else {
+				cells = append(cells, diffWords(nodeText(beforeCell), nodeText(afterCell)))
+			}
+		}
+
+		rows = append(rows, "| "+strings.Join(cells, " | ")+" |")
+		if len(rows) == 1 {
+			rows = append(rows, "|"+strings.Repeat("---|", len(cells)))
+		}
+	}
+
+	blocks := callouts
+	if len(rows) > 0 {
+		blocks = append([]string{strings.Join(rows, "\n")}, callouts...)
+	}
+	return strings.Join(blocks, "\n\n")
+}
+
+// diffInline renders the inline content of two blocks, word by word if it changed.
+func diffInline(before, after *ADFNode) string {
+	if equalNodes(before.Content, after.Content) {
+		return renderInline(after.Content)
+	}
+	return diffWords(nodeText(before), nodeText(after))
+}
+
+// diffWords renders the word-level difference between two texts.
+func diffWords(before, after string) string {
+	a, b := diffTokens.FindAllString(before, -1), diffTokens.FindAllString(after, -1)
+
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var out, deleted, inserted strings.Builder
+	flush := func() {
+		out.WriteString(wrapDelimited(deleted.String(), "~~"))
+		out.WriteString(wrapDelimited(inserted.String(), "**"))
+		deleted.Reset()
+		inserted.Reset()
+	}
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			flush()
+			out.WriteString(a[i])
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			deleted.WriteString(a[i])
+			i++
+		default:
+			inserted.WriteString(b[j])
+			j++
+		}
+	}
+	flush()
+
+	return out.String()
+}
+
+// diffTokens splits text into words and the whitespace between them.
+var diffTokens = regexp.MustCompile(`\s+|\S+`)
+
+// wrapDelimited wraps s in delim, keeping surrounding whitespace outside so
+// the delimiters stay valid markdown.
+func wrapDelimited(s, delim string) string {
+	trimmed := strings.TrimSpace(s)
+	if trimmed == "" {
+		return s
+	}
+	start := strings.Index(s, trimmed)
+	return s[:start] + delim + trimmed + delim + s[start+len(trimmed):]
+}
+
+// callout renders a node added or removed as a whole as a labeled blockquote.
+func callout(verb string, n *ADFNode) string {
+	label := verb + " " + nodeLabel(n.Type)
+	if text := strings.Join(strings.Fields(nodeText(n)), " "); text != "" {
+		return "> **" + label + ":** " + text
+	}
+	return "> **" + label + "**"
+}
+
+// nodeLabel spells a node type out in words: "tableRow" becomes "table row".
+func nodeLabel(t NodeType) string {
+	var b strings.Builder
+	for _, r := range string(t) {
+		if unicode.IsUpper(r) {
+			b.WriteRune(' ')
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// quote prefixes every line with a blockquote marker.
+func quote(s string) string {
+	return "> " + strings.ReplaceAll(s, "\n", "\n> ")
+}
+
+// renderInline renders inline nodes as markdown, keeping their marks.
+func renderInline(nodes []*ADFNode) string {
+	var b strings.Builder
+	for _, n := range nodes {
+		if n.Type != ChildNodeText {
+			text, _ := inlineText(n)
+			b.WriteString(text)
+			continue
+		}
+
+		text := n.Text
+		for _, m := range n.Marks {
+			switch m.Type {
+			case MarkCode:
+				text = "`" + text + "`"
+			case MarkStrong:
+				text = "**" + text + "**"
+			case MarkEm:
+				text = "_" + text + "_"
+			case MarkStrike:
+				text = "~~" + text + "~~"
+			case MarkLink:
+				href, _ := m.Attrs["href"].(string)
+				text = "[" + text + "](" + href + ")"
+			}
+		}
+		b.WriteString(text)
+	}
+	return b.String()
+}
+
+// nodeText returns the text of a node as PlainText renders it.
+func nodeText(n *ADFNode) string {
+	if n == nil {
+		return ""
+	}
+	return PlainText(&ADFDocument{Content: []*ADFNode{n}})
+}
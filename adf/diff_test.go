@@ -0,0 +1,114 @@
+package adf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func paragraphOf(text string) *ADFNode {
+	p := NewParagraphNode()
+	p.Content = append(p.Content, NewTextNode(text))
+	return p
+}
+
+func bulletListOf(items ...string) *ADFNode {
+	list := NewBulletListNode()
+	for _, text := range items {
+		item := NewListItemNode()
+		item.Content = append(item.Content, paragraphOf(text))
+		list.Content = append(list.Content, item)
+	}
+	return list
+}
+
+func documentOf(nodes ...*ADFNode) *ADFDocument {
+	doc := NewADFDocument()
+	doc.Content = nodes
+	return doc
+}
+
+func TestRenderDiffMarkdownWordEditInListItem(t *testing.T) {
+	before := documentOf(paragraphOf("Shopping:"), bulletListOf("Buy milk today", "Call mom"))
+	after := documentOf(paragraphOf("Shopping:"), bulletListOf("Buy bread today", "Call mom"))
+
+	assert.Equal(t, "Shopping:\n\n- Buy ~~milk~~**bread** today\n- Call mom\n", RenderDiffMarkdown(before, after))
+}
+
+func TestRenderDiffMarkdownDeletedParagraph(t *testing.T) {
+	before := documentOf(paragraphOf("Keep this."), paragraphOf("Drop this one."), paragraphOf("Keep that."))
+	after := documentOf(paragraphOf("Keep this."), paragraphOf("Keep that."))
+
+	assert.Equal(t, "Keep this.\n\n> **Removed paragraph:** Drop this one.\n\nKeep that.\n", RenderDiffMarkdown(before, after))
+}
+
+func TestRenderDiffMarkdownStructuralChanges(t *testing.T) {
+	row := func(cells ...string) *ADFNode {
+		r := NewTableRowNode()
+		for _, text := range cells {
+			cell := NewTableCellNode()
+			cell.Content = append(cell.Content, paragraphOf(text))
+			r.Content = append(r.Content, cell)
+		}
+		return r
+	}
+	table := func(rows ...*ADFNode) *ADFNode {
+		t := NewTableNode()
+		t.Content = rows
+		return t
+	}
+	panel := NewPanelNode("warning")
+	panel.Content = append(panel.Content, paragraphOf("Careful"))
+
+	before := documentOf(table(row("a", "b")), panel)
+	after := documentOf(table(row("a", "c"), row("d", "e")))
+
+	assert.Equal(t,
+		"| a | ~~b~~**c** |\n|---|---|\n\n> **Added table row:** d e\n\n> **Removed panel:** Careful\n",
+		RenderDiffMarkdown(before, after))
+}
+
+func TestRenderDiffMarkdownUnchanged(t *testing.T) {
+	strong := NewTextNodeWithMarks("bold", []*ADFMark{NewStrongMark()})
+	p := NewParagraphNode()
+	p.Content = append(p.Content, NewTextNode("Some "), strong)
+
+	doc := documentOf(p)
+	assert.Equal(t, "Some **bold**\n", RenderDiffMarkdown(doc, doc))
+	assert.Equal(t, "", RenderDiffMarkdown(nil, nil))
+}
+
+func TestRenderDiffMarkdownMalformed(t *testing.T) {
+	t.Run("nil nodes", func(t *testing.T) {
+		list := bulletListOf("one")
+		list.Content = append(list.Content, nil)
+		p := paragraphOf("text")
+		p.Content = append(p.Content, nil)
+		p.Content[0].Marks = []*ADFMark{nil}
+
+		before := documentOf(nil, paragraphOf("text"), bulletListOf("one"))
+		after := documentOf(p, list, nil)
+		assert.Equal(t, "text\n\n- one\n", RenderDiffMarkdown(before, after))
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		quote := NewBlockquoteNode()
+		quote.Content = append(quote.Content, paragraphOf("looped"), quote)
+
+		out := RenderDiffMarkdown(documentOf(paragraphOf("before")), documentOf(quote))
+		assert.Equal(t, "> **Removed paragraph:** before\n\n> **Added blockquote:** looped\n", out)
+	})
+}
+
+func TestRenderDiffMarkdownNumericAttrs(t *testing.T) {
+	heading := func(level any) *ADFNode {
+		h := paragraphOf("Title")
+		h.Type = NodeHeading
+		h.Attrs = map[string]any{"level": level}
+		return h
+	}
+
+	// Attrs decoded from JSON are float64 where built ones are int
+	out := RenderDiffMarkdown(documentOf(heading(2), paragraphOf("a")), documentOf(heading(2.0), paragraphOf("b")))
+	assert.Equal(t, "## Title\n\n~~a~~**b**\n", out)
+}
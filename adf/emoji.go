@@ -0,0 +1,97 @@
+package adf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// emojiShortcodes maps the shortcodes known to both translators to their
+// unicode text.
+var emojiShortcodes = map[string]string{
+	":smile:":                    "😄",
+	":smiley:":                   "😃",
+	":grinning:":                 "😀",
+	":laughing:":                 "😆",
+	":joy:":                      "😂",
+	":slight_smile:":             "🙂",
+	":wink:":                     "😉",
+	":blush:":                    "😊",
+	":heart_eyes:":               "😍",
+	":thinking:":                 "🤔",
+	":neutral_face:":             "😐",
+	":confused:":                 "😕",
+	":worried:":                  "😟",
+	":cry:":                      "😢",
+	":sob:":                      "😭",
+	":angry:":                    "😠",
+	":scream:":                   "😱",
+	":sweat_smile:":              "😅",
+	":sunglasses:":               "😎",
+	":thumbsup:":                 "👍",
+	":thumbsdown:":               "👎",
+	":clap:":                     "👏",
+	":wave:":                     "👋",
+	":pray:":                     "🙏",
+	":muscle:":                   "💪",
+	":ok_hand:":                  "👌",
+	":raised_hands:":             "🙌",
+	":eyes:":                     "👀",
+	":heart:":                    "❤️",
+	":broken_heart:":             "💔",
+	":fire:":                     "🔥",
+	":star:":                     "⭐",
+	":sparkles:":                 "✨",
+	":tada:":                     "🎉",
+	":rocket:":                   "🚀",
+	":bulb:":                     "💡",
+	":warning:":                  "⚠️",
+	":no_entry:":                 "⛔",
+	":x:":                        "❌",
+	":white_check_mark:":         "✅",
+	":heavy_check_mark:":         "✔️",
+	":question:":                 "❓",
+	":exclamation:":              "❗",
+	":bug:":                      "🐛",
+	":lock:":                     "🔒",
+	":key:":                      "🔑",
+	":memo:":                     "📝",
+	":calendar:":                 "📅",
+	":hourglass:":                "⌛",
+	":construction:":             "🚧",
+	":zap:":                      "⚡",
+	":hammer:":                   "🔨",
+	":wrench:":                   "🔧",
+	":gear:":                     "⚙️",
+	":link:":                     "🔗",
+	":pushpin:":                  "📌",
+	":mag:":                      "🔍",
+	":chart_with_upwards_trend:": "📈",
+	":coffee:":                   "☕",
+	":100:":                      "💯",
+}
+
+// EmojiText returns the unicode text of a known emoji shortcode such as
+// ":thumbsup:".
+func EmojiText(shortName string) (string, bool) {
+	text, ok := emojiShortcodes[shortName]
+	return text, ok
+}
+
+// Create an emoji node; id is derived from the code points of text
+func NewEmojiNode(shortName, text string) *ADFNode {
+	var codePoints []string
+	for _, r := range text {
+		if r != 0xfe0f { // variation selectors are not part of the id
+			codePoints = append(codePoints, fmt.Sprintf("%x", r))
+		}
+	}
+
+	return &ADFNode{
+		Type: InlineNodeEmoji,
+		Attrs: map[string]any{
+			"shortName": shortName,
+			"id":        strings.Join(codePoints, "-"),
+			"text":      text,
+		},
+	}
+}
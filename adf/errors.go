@@ -0,0 +1,33 @@
+package adf
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// InternalError is a panic raised inside a translator, recovered at its
+// public entry point so that a malformed document can't take the caller down.
+type InternalError struct {
+	Value any    // the value passed to panic
+	Stack []byte // the stack of the panicking goroutine
+}
+
+func (e *InternalError) Error() string {
+	return fmt.Sprintf("adf: internal error: %v", e.Value)
+}
+
+// Unwrap returns the panic value if it is an error.
+func (e *InternalError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// CatchPanic recovers a panic into *errp as an *InternalError. It must be
+// deferred directly:
+//
+//	defer adf.CatchPanic(&err)
+func CatchPanic(errp *error) {
+	if r := recover(); r != nil {
+		*errp = &InternalError{Value: r, Stack: debug.Stack()}
+	}
+}
@@ -0,0 +1,48 @@
+package adf
+
+import (
+	"maps"
+	"slices"
+)
+
+// ShiftHeading returns the heading n with its level shifted by offset, clamped
+// to MinHeadingLevel. A level past MaxHeadingLevel, which ADF has no heading
+// for, makes a paragraph of the heading's content in bold instead. n is left
+// alone: the result is a copy sharing what it doesn't change.
+func ShiftHeading(n *ADFNode, offset int) *ADFNode {
+	level := HeadingLevel(n.Attrs) + offset
+
+	shifted := *n
+	if level <= MaxHeadingLevel {
+		shifted.Attrs = maps.Clone(n.Attrs)
+		if shifted.Attrs == nil {
+			shifted.Attrs = make(map[string]any)
+		}
+		shifted.Attrs["level"] = max(level, MinHeadingLevel)
+		return &shifted
+	}
+
+	// Block marks such as an alignment carry over, but the attributes of a
+	// heading don't apply to a paragraph
+	shifted.Type = NodeParagraph
+	shifted.Attrs = nil
+	shifted.Content = make([]*ADFNode, len(n.Content))
+	for i, child := range n.Content {
+		shifted.Content[i] = embolden(child)
+	}
+	return &shifted
+}
+
+// embolden returns a text node with a strong mark added, unless it already
+// has one or has a code mark, which takes no other formatting. Other nodes are
+// returned as they are.
+func embolden(n *ADFNode) *ADFNode {
+	if n == nil || n.Type != ChildNodeText || slices.ContainsFunc(n.Marks, func(m *ADFMark) bool {
+		return m != nil && (m.Type == MarkStrong || m.Type == MarkCode)
+	}) {
+		return n
+	}
+	bold := *n
+	bold.Marks = append([]*ADFMark{NewStrongMark()}, n.Marks...)
+	return &bold
+}
@@ -0,0 +1,42 @@
+package adf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShiftHeading(t *testing.T) {
+	heading := NewHeadingNode(2)
+	heading.Content = append(heading.Content, NewTextNode("Title"))
+
+	shifted := ShiftHeading(heading, 3)
+	assert.Equal(t, NodeHeading, shifted.Type)
+	assert.Equal(t, 5, shifted.Attrs["level"])
+	assert.Same(t, heading.Content[0], shifted.Content[0])
+	assert.Equal(t, 2, heading.Attrs["level"], "the heading is left alone")
+
+	assert.Equal(t, MinHeadingLevel, ShiftHeading(heading, -4).Attrs["level"])
+	assert.Equal(t, 2, ShiftHeading(heading, 0).Attrs["level"])
+}
+
+func TestShiftHeadingPastMaxLevel(t *testing.T) {
+	heading := NewHeadingNode(6)
+	heading.Content = append(heading.Content,
+		NewTextNode("Plain "),
+		NewTextNodeWithMarks("linked", []*ADFMark{NewLinkMark("https://example.com")}),
+		NewTextNodeWithMarks(" bold ", []*ADFMark{NewStrongMark()}),
+		NewTextNodeWithMarks("code", []*ADFMark{NewCodeMark()}),
+		NewEmojiNode(":smile:", "😄"),
+	)
+
+	shifted := ShiftHeading(heading, 1)
+	assert.Equal(t, NodeParagraph, shifted.Type)
+	assert.Nil(t, shifted.Attrs)
+	assert.Equal(t, []*ADFMark{NewStrongMark()}, shifted.Content[0].Marks)
+	assert.Equal(t, []*ADFMark{NewStrongMark(), NewLinkMark("https://example.com")}, shifted.Content[1].Marks)
+	assert.Same(t, heading.Content[2], shifted.Content[2])
+	assert.Same(t, heading.Content[3], shifted.Content[3])
+	assert.Same(t, heading.Content[4], shifted.Content[4])
+	assert.Empty(t, heading.Content[0].Marks, "the heading is left alone")
+}
@@ -0,0 +1,230 @@
+package adf
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+)
+
+// intAttrs are the numeric attributes normalized to ints when decoding.
+var intAttrs = []string{"level", "order", "width", "height", "colspan", "rowspan"}
+
+// schemaNodeTypes are the node types of the ADF schema, including the ones
+// the translators don't render such as extensions.
+var schemaNodeTypes = map[NodeType]bool{
+	"doc":                  true,
+	NodeBlockquote:         true,
+	NodeBulletList:         true,
+	NodeCodeBlock:          true,
+	NodeHeading:            true,
+	NodeOrderedList:        true,
+	NodePanel:              true,
+	NodeExpand:             true,
+	"nestedExpand":         true,
+	NodeParagraph:          true,
+	NodeTable:              true,
+	NodeMedia:              true,
+	NodeMediaGroup:         true,
+	NodeMediaSingle:        true,
+	"mediaInline":          true,
+	NodeCaption:            true,
+	NodeDecisionList:       true,
+	NodeLayoutSection:      true,
+	"rule":                 true,
+	"taskList":             true,
+	"blockCard":            true,
+	"embedCard":            true,
+	"extension":            true,
+	"bodiedExtension":      true,
+	"multiBodiedExtension": true,
+	"extensionFrame":       true,
+
+	ChildNodeText:         true,
+	ChildNodeListItem:     true,
+	ChildNodeTableRow:     true,
+	ChildNodeTableHeader:  true,
+	ChildNodeTableCell:    true,
+	ChildNodeDecisionItem: true,
+	ChildNodeLayoutColumn: true,
+	"taskItem":            true,
+
+	InlineNodeCard:      true,
+	InlineNodeEmoji:     true,
+	InlineNodeMention:   true,
+	InlineNodeHardBreak: true,
+	InlineNodeStatus:    true,
+	"date":              true,
+	"placeholder":       true,
+	"inlineExtension":   true,
+}
+
+// schemaMarkTypes are the mark types of the ADF schema.
+var schemaMarkTypes = map[NodeType]bool{
+	MarkEm:            true,
+	MarkLink:          true,
+	MarkCode:          true,
+	MarkStrike:        true,
+	MarkStrong:        true,
+	MarkUnderline:     true,
+	MarkAlignment:     true,
+	"subsup":          true,
+	"textColor":       true,
+	"backgroundColor": true,
+	"indentation":     true,
+	"breakout":        true,
+	"annotation":      true,
+	"border":          true,
+	"dataConsumer":    true,
+	"fragment":        true,
+}
+
+// DecodeOption changes what FromJSON accepts.
+type DecodeOption func(*decoder)
+
+// decoder is the configuration of FromJSON.
+type decoder struct {
+	allowUnknownTypes     bool
+	disallowUnknownFields bool
+}
+
+// AllowUnknownTypes makes FromJSON accept node and mark types that aren't
+// part of the ADF schema, such as ones newer than this package, for callers
+// that handle them like adf2md's unknown node policy.
+func AllowUnknownTypes() DecodeOption {
+	return func(d *decoder) {
+		d.allowUnknownTypes = true
+	}
+}
+
+// DisallowUnknownFields makes FromJSON reject documents with top-level
+// fields other than version, type and content. It is off by default as Jira
+// responses may grow fields without a new document version.
+func DisallowUnknownFields() DecodeOption {
+	return func(d *decoder) {
+		d.disallowUnknownFields = true
+	}
+}
+
+// FromJSON decodes a document such as the description field of a Jira API
+// response. Unlike a plain json.Unmarshal it rejects documents whose type
+// isn't "doc", whose version isn't 1 or that hold node or mark types missing
+// from the ADF schema, such as typos. Numeric attributes such as level, order
+// and width are decoded as ints.
+func FromJSON(data []byte, opts ...DecodeOption) (*ADFDocument, error) {
+	var d decoder
+	for _, opt := range opts {
+		opt(&d)
+	}
+
+	if d.disallowUnknownFields {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return nil, fmt.Errorf("adf: %w", err)
+		}
+
+		var unknown []string
+		for name := range fields {
+			if name != "version" && name != "type" && name != "content" {
+				unknown = append(unknown, name)
+			}
+		}
+		if len(unknown) > 0 {
+			sort.Strings(unknown)
+			return nil, fmt.Errorf("adf: unknown document fields %q", unknown)
+		}
+	}
+
+	doc, err := FromJSONLenient(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if doc.Type != "doc" {
+		return nil, fmt.Errorf("adf: document type is %q, want \"doc\"", doc.Type)
+	}
+	if doc.Version != 1 {
+		return nil, fmt.Errorf("adf: unsupported document version %d", doc.Version)
+	}
+	if !d.allowUnknownTypes {
+		if err := checkTypes(doc.Content, []int{}); err != nil {
+			return nil, err
+		}
+	}
+
+	return doc, nil
+}
+
+// checkTypes returns an error for the first node or mark of nodes, in
+// document order, whose type isn't in the ADF schema. path leads to nodes.
+func checkTypes(nodes []*ADFNode, path []int) error {
+	for i, n := range nodes {
+		if n == nil {
+			continue
+		}
+
+		nodePath := append(path[:len(path):len(path)], i)
+		if !schemaNodeTypes[n.Type] {
+			return fmt.Errorf("adf: %s: unknown node type %q", FormatPath(nodePath), n.Type)
+		}
+		for _, m := range n.Marks {
+			if m != nil && !schemaMarkTypes[m.Type] {
+				return fmt.Errorf("adf: %s: unknown mark type %q", FormatPath(nodePath), m.Type)
+			}
+		}
+		if err := checkTypes(n.Content, nodePath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FromJSONLenient decodes a document without checking its type, version,
+// fields or node types, but with numeric attributes normalized like FromJSON.
+func FromJSONLenient(data []byte) (*ADFDocument, error) {
+	var doc ADFDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("adf: %w", err)
+	}
+
+	normalizeNumbers(&doc)
+	return &doc, nil
+}
+
+// NodeFromJSON decodes a single node, with numeric attributes normalized like
+// FromJSON. It rejects JSON that isn't an object with a node type.
+func NodeFromJSON(data []byte) (*ADFNode, error) {
+	var n ADFNode
+	if err := json.Unmarshal(data, &n); err != nil {
+		return nil, fmt.Errorf("adf: %w", err)
+	}
+	if n.Type == "" {
+		return nil, errors.New("adf: node has no type")
+	}
+
+	normalizeNumbers(&ADFDocument{Content: []*ADFNode{&n}})
+	return &n, nil
+}
+
+// normalizeNumbers normalizes the numeric attributes of the nodes and marks
+// of doc.
+func normalizeNumbers(doc *ADFDocument) {
+	WalkMarks(doc, func(n *ADFNode, _ int) bool {
+		normalizeIntAttrs(n.Attrs)
+		return true
+	}, func(m *ADFMark, _ *ADFNode) {
+		normalizeIntAttrs(m.Attrs)
+	})
+}
+
+// normalizeIntAttrs turns whole float64 values of intAttrs into ints.
+func normalizeIntAttrs(attrs map[string]any) {
+	for name, v := range attrs {
+		f, ok := v.(float64)
+		if ok && slices.Contains(intAttrs, name) && f == math.Trunc(f) && math.Abs(f) <= math.MaxInt32 {
+			attrs[name] = int(f)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package adf
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromJSON(t *testing.T) {
+	data := []byte(`{"version":1,"type":"doc","content":[
+		{"type":"heading","attrs":{"level":2},"content":[{"type":"text","text":"Title"}]},
+		{"type":"orderedList","attrs":{"order":3},"content":[]},
+		{"type":"mediaSingle","content":[{"type":"media","attrs":{"id":"f","type":"file","width":640.5,"height":480}}]}
+	]}`)
+
+	doc, err := FromJSON(data)
+	require.NoError(t, err)
+	assert.Equal(t, 2, doc.Content[0].Attrs["level"])
+	assert.Equal(t, 3, doc.Content[1].Attrs["order"])
+	assert.Equal(t, 640.5, doc.Content[2].Content[0].Attrs["width"], "fractional values are kept")
+	assert.Equal(t, 480, doc.Content[2].Content[0].Attrs["height"])
+
+	expected := NewDocBuilder().Heading(2, "Title").OrderedList(3).Build()
+	assert.True(t, Equal(expected.Content[0], doc.Content[0]))
+}
+
+func TestFromJSONErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  string
+		error string
+	}{
+		{"malformed", `{"type":"doc"`, "adf: unexpected end of JSON input"},
+		{"wrong type", `{"version":1,"type":"paragraph","content":[]}`, `adf: document type is "paragraph", want "doc"`},
+		{"wrong version", `{"version":2,"type":"doc","content":[]}`, "adf: unsupported document version 2"},
+		{"unknown node type", `{"version":1,"type":"doc","content":[{"type":"bulletList","content":[{"type":"listItem","content":[{"type":"paragrahp"}]}]}]}`, `adf: /content/0/content/0/content/0: unknown node type "paragrahp"`},
+		{"unknown mark type", `{"version":1,"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"a","marks":[{"type":"strong"},{"type":"bold"}]}]}]}`, `adf: /content/0/content/0: unknown mark type "bold"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := FromJSON([]byte(tt.data))
+			assert.EqualError(t, err, tt.error)
+		})
+	}
+
+	// Unknown top-level fields are only rejected on request
+	fields := []byte(`{"version":1,"type":"doc","content":[],"typo":1,"extra":true}`)
+	_, err := FromJSON(fields)
+	assert.NoError(t, err)
+	_, err = FromJSON(fields, DisallowUnknownFields())
+	assert.EqualError(t, err, `adf: unknown document fields ["extra" "typo"]`)
+
+	_, err = FromJSON([]byte(`[]`))
+	assert.Error(t, err)
+	_, err = FromJSON([]byte(`[]`), DisallowUnknownFields())
+	assert.Error(t, err)
+
+	// The lenient variant accepts what it can decode
+	doc, err := FromJSONLenient([]byte(`{"version":2,"type":"doc","content":[{"type":"heading","attrs":{"level":1}}],"typo":1}`))
+	require.NoError(t, err)
+	assert.Equal(t, 1, doc.Content[0].Attrs["level"])
+}
+
+func TestFromJSONSchemaTypes(t *testing.T) {
+	data := []byte(`{"version":1,"type":"doc","content":[
+		{"type":"extension","attrs":{"extensionKey":"toc"}},
+		{"type":"taskList","content":[{"type":"taskItem","content":[
+			{"type":"date","attrs":{"timestamp":"0"}},
+			{"type":"text","text":"x","marks":[{"type":"textColor","attrs":{"color":"#ff0000"}}]}
+		]}]},
+		null,
+		{"type":"rule"}
+	]}`)
+	_, err := FromJSON(data)
+	assert.NoError(t, err, "types the translators don't render are still part of the schema")
+
+	doc, err := FromJSON([]byte(`{"version":1,"type":"doc","content":[{"type":"futureNode","marks":[{"type":"futureMark"}]}]}`), AllowUnknownTypes())
+	require.NoError(t, err)
+	assert.Equal(t, NodeType("futureNode"), doc.Content[0].Type)
+}
+
+func TestNodeFromJSON(t *testing.T) {
+	n, err := NodeFromJSON([]byte(`{"type":"extension","attrs":{"extensionKey":"toc","width":640}}`))
+	require.NoError(t, err)
+	assert.Equal(t, NodeType("extension"), n.Type)
+	assert.Equal(t, 640, n.Attrs["width"])
+
+	_, err = NodeFromJSON([]byte(`{"attrs":{}}`))
+	assert.EqualError(t, err, "adf: node has no type")
+	_, err = NodeFromJSON([]byte(`{"type":`))
+	assert.EqualError(t, err, "adf: unexpected end of JSON input")
+}
+
+func TestToJSONSortsAttrs(t *testing.T) {
+	panel := NewPanelNode("info")
+	panel.Attrs["panelColor"] = "#ff0000"
+	panel.Attrs["panelIcon"] = ":star:"
+	panel.Attrs["localId"] = "p1"
+	doc := &ADFDocument{Version: 1, Type: "doc", Content: []*ADFNode{panel}}
+
+	out, err := doc.ToJSON()
+	require.NoError(t, err)
+
+	s := string(out)
+	assert.Less(t, strings.Index(s, `"localId"`), strings.Index(s, `"panelColor"`))
+	assert.Less(t, strings.Index(s, `"panelColor"`), strings.Index(s, `"panelIcon"`))
+	assert.Less(t, strings.Index(s, `"panelIcon"`), strings.Index(s, `"panelType"`))
+}
+
+func TestWriteJSON(t *testing.T) {
+	doc := NewDocBuilder().Heading(2, "Title").Paragraph(Text("<b> & text")).Build()
+
+	expected, err := doc.ToJSON()
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, doc.WriteJSON(&buf))
+	assert.Equal(t, string(expected)+"\n", buf.String())
+}
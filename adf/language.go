@@ -0,0 +1,27 @@
+package adf
+
+import "slices"
+
+// codeLanguages lists the language identifiers Jira highlights code blocks
+// in. A code block of another language is shown as plain text, labeled with
+// the unknown language.
+var codeLanguages = []string{
+	"abap", "actionscript", "ada", "applescript", "arduino", "autoit",
+	"bash", "c", "c++", "clojure", "coffeescript", "cpp", "csharp", "css",
+	"cuda", "d", "dart", "delphi", "diff", "docker", "elixir", "erlang",
+	"fortran", "foxpro", "go", "graphql", "groovy", "haskell", "haxe", "html",
+	"java", "javascript", "json", "julia", "kotlin", "latex", "livescript",
+	"lua", "makefile", "markdown", "mathematica", "matlab", "nginx",
+	"objective-c", "objective-j", "objectpascal", "ocaml", "octave", "perl",
+	"php", "powershell", "prolog", "protobuf", "puppet", "python", "qml", "r",
+	"racket", "restructuredtext", "ruby", "rust", "sass", "scala", "scheme",
+	"shell", "smalltalk", "sql", "standardml", "swift", "tcl", "tex", "text",
+	"toml", "typescript", "vala", "vbnet", "verilog", "vhdl", "xml", "xquery",
+	"yaml",
+}
+
+// IsCodeLanguage reports whether Jira highlights code blocks of language.
+func IsCodeLanguage(language string) bool {
+	_, found := slices.BinarySearch(codeLanguages, language)
+	return found
+}
@@ -0,0 +1,19 @@
+package adf
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsCodeLanguage(t *testing.T) {
+	assert.True(t, slices.IsSorted(codeLanguages), "codeLanguages must stay sorted for the binary search")
+
+	for _, language := range []string{"go", "javascript", "bash", "yaml", "c++", "objective-c"} {
+		assert.True(t, IsCodeLanguage(language), language)
+	}
+	assert.False(t, IsCodeLanguage("golang"))
+	assert.False(t, IsCodeLanguage("Go"))
+	assert.False(t, IsCodeLanguage(""))
+}
@@ -0,0 +1,48 @@
+package adf
+
+import "slices"
+
+// MediaRef identifies an attachment referenced by a media node.
+type MediaRef struct {
+	ID         string
+	Collection string
+}
+
+// FindMedia returns the attachments referenced by the media nodes of doc, in
+// mediaSingle, mediaGroup or on their own, and separately the URLs of its
+// external media. Both are deduplicated and in document order.
+func FindMedia(doc *ADFDocument) (attachments []MediaRef, external []string) {
+	if doc == nil {
+		return nil, nil
+	}
+
+	seen := make(map[*ADFNode]bool)
+	var find func(nodes []*ADFNode)
+	find = func(nodes []*ADFNode) {
+		for _, n := range nodes {
+			if n == nil || seen[n] {
+				continue
+			}
+			seen[n] = true
+
+			if attrs, ok := n.MediaAttrs(); ok {
+				switch {
+				case attrs.ID != "":
+					ref := MediaRef{ID: attrs.ID, Collection: attrs.Collection}
+					if !slices.Contains(attachments, ref) {
+						attachments = append(attachments, ref)
+					}
+				case attrs.Type == "external" && attrs.URL != "":
+					if !slices.Contains(external, attrs.URL) {
+						external = append(external, attrs.URL)
+					}
+				}
+			}
+
+			find(n.Content)
+		}
+	}
+	find(doc.Content)
+
+	return attachments, external
+}
@@ -0,0 +1,33 @@
+package adf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindMedia(t *testing.T) {
+	file := func(id, collection string) *ADFNode {
+		return &ADFNode{Type: NodeMedia, Attrs: map[string]any{"id": id, "type": "file", "collection": collection}}
+	}
+
+	single := NewMediaSingleNode("center")
+	single.Content = append(single.Content, file("file-1", "c"))
+	group := &ADFNode{Type: NodeMediaGroup, Content: []*ADFNode{file("file-2", "c"), file("file-1", "c"), NewExternalMediaNode("https://example.com/a.png", "")}}
+	external := NewMediaSingleNode("center")
+	external.Content = append(external.Content, NewExternalMediaNode("https://example.com/b.png", "b"))
+	item := NewListItemNode()
+	item.Content = append(item.Content, file("file-3", "other"))
+	list := NewBulletListNode()
+	list.Content = append(list.Content, item)
+
+	doc := &ADFDocument{Type: "doc", Content: []*ADFNode{single, group, external, list, single}}
+
+	attachments, urls := FindMedia(doc)
+	assert.Equal(t, []MediaRef{{"file-1", "c"}, {"file-2", "c"}, {"file-3", "other"}}, attachments)
+	assert.Equal(t, []string{"https://example.com/a.png", "https://example.com/b.png"}, urls)
+
+	attachments, urls = FindMedia(&ADFDocument{Type: "doc"})
+	assert.Empty(t, attachments)
+	assert.Empty(t, urls)
+}
@@ -0,0 +1,42 @@
+package adf
+
+// Collector receives conversion metrics. It is deliberately tiny so callers
+// can adapt it to Prometheus, OpenTelemetry or anything else.
+type Collector interface {
+	Observe(name string, value float64, labels map[string]string)
+}
+
+// Metric names reported by the translators.
+const (
+	// MetricDocumentsConverted is observed with 1 for every converted document.
+	MetricDocumentsConverted = "md2adf_documents_converted"
+	// MetricConversionDuration is the conversion wall time in seconds.
+	MetricConversionDuration = "md2adf_conversion_duration_seconds"
+	// MetricInputBytes is the size of the markdown input in bytes.
+	MetricInputBytes = "md2adf_input_size_bytes"
+	// MetricInputNodes is the number of nodes in the ADF input.
+	MetricInputNodes = "md2adf_input_size_nodes"
+	// MetricNodesProduced is the number of ADF nodes produced, per node type.
+	MetricNodesProduced = "md2adf_nodes_produced"
+	// MetricWarnings is observed with 1 for every emitted warning, per warning code.
+	MetricWarnings = "md2adf_warnings"
+	// MetricConversionErrors is observed with 1 for every conversion that
+	// failed. Failed conversions count as converted documents too.
+	MetricConversionErrors = "md2adf_conversion_errors"
+)
+
+// Metric label names and conversion directions.
+const (
+	LabelDirection = "direction"
+	LabelNodeType  = "type"
+	LabelCode      = "code"
+
+	DirectionMarkdownToADF = "md2adf"
+	DirectionADFToMarkdown = "adf2md"
+)
+
+// NopCollector discards all observations.
+type NopCollector struct{}
+
+// Observe implements Collector.
+func (NopCollector) Observe(string, float64, map[string]string) {}
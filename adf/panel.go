@@ -0,0 +1,26 @@
+package adf
+
+import "slices"
+
+// Panel types Jira accepts as the panelType attr of a panel.
+const (
+	PanelInfo    = "info"
+	PanelNote    = "note"
+	PanelWarning = "warning"
+	PanelError   = "error"
+	PanelSuccess = "success"
+	// PanelCustom is a panel styled by its PanelAttrs instead of its type.
+	PanelCustom = "custom"
+)
+
+// PanelAttrs are the attrs of a panel besides panelType that style a custom
+// panel: its emoji icon and background color.
+var PanelAttrs = []string{"panelIcon", "panelIconId", "panelIconText", "panelColor"}
+
+// panelTypes lists the known panel types.
+var panelTypes = []string{PanelInfo, PanelNote, PanelWarning, PanelError, PanelSuccess, PanelCustom}
+
+// IsPanelType reports whether Jira accepts t as a panel type.
+func IsPanelType(t string) bool {
+	return slices.Contains(panelTypes, t)
+}
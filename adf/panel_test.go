@@ -0,0 +1,16 @@
+package adf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsPanelType(t *testing.T) {
+	for _, panelType := range []string{PanelInfo, PanelNote, PanelWarning, PanelError, PanelSuccess, PanelCustom} {
+		assert.True(t, IsPanelType(panelType), panelType)
+	}
+	assert.False(t, IsPanelType("tip"))
+	assert.False(t, IsPanelType("Info"))
+	assert.False(t, IsPanelType(""))
+}
@@ -0,0 +1,150 @@
+package adf
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// PlainText renders doc as its text alone, for indexing and previews. Blocks,
+// list items and table rows go on lines of their own, table cells are
+// separated by tabs, mentions show their display names and code blocks are
+// kept verbatim. No markup is added.
+func PlainText(doc *ADFDocument) string {
+	if doc == nil {
+		return ""
+	}
+	p := textRenderer{ancestors: make(map[*ADFNode]bool)}
+	return p.blocks(doc.Content)
+}
+
+// textRenderer renders nodes as plain text, stopping at cycles.
+type textRenderer struct {
+	ancestors map[*ADFNode]bool
+}
+
+// blocks renders block nodes one after another, each on lines of its own.
+func (p textRenderer) blocks(nodes []*ADFNode) string {
+	var lines []string
+	for _, n := range nodes {
+		if text := p.block(n); text != "" {
+			lines = append(lines, text)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// block renders a block node.
+func (p textRenderer) block(n *ADFNode) string {
+	if n == nil || p.ancestors[n] {
+		return ""
+	}
+	p.ancestors[n] = true
+	defer delete(p.ancestors, n)
+
+	switch n.Type {
+	case NodeParagraph, NodeHeading, NodeCaption:
+		return p.inline(n.Content)
+	case NodeCodeBlock:
+		var code strings.Builder
+		for _, child := range n.Content {
+			if child != nil {
+				code.WriteString(child.Text)
+			}
+		}
+		return strings.TrimRight(code.String(), "\n")
+	case NodeExpand:
+		title, _ := n.Attrs["title"].(string)
+		if title == "" {
+			return p.blocks(n.Content)
+		}
+		return p.blocks(append([]*ADFNode{NewTextNode(title)}, n.Content...))
+	case ChildNodeTableRow:
+		cells := make([]string, 0, len(n.Content))
+		for _, cell := range n.Content {
+			cells = append(cells, strings.Join(strings.Fields(p.block(cell)), " "))
+		}
+		return strings.Join(cells, "\t")
+	case ChildNodeDecisionItem:
+		// Decisions hold inline content, though some hold paragraphs
+		if len(n.Content) > 0 && n.Content[0] != nil && n.Content[0].Type == NodeParagraph {
+			return p.blocks(n.Content)
+		}
+		return p.inline(n.Content)
+	case ChildNodeText:
+		// Text directly among blocks, like an expand title
+		return n.Text
+	case NodeMedia:
+		alt, _ := n.Attrs["alt"].(string)
+		return alt
+	}
+
+	if GetADFNodeType(n.Type) == NodeTypeChild && n.Type != ChildNodeListItem &&
+		n.Type != ChildNodeTableCell && n.Type != ChildNodeTableHeader {
+		return p.inline([]*ADFNode{n})
+	}
+
+	// Containers: lists, list items, quotes, panels, tables, cells, media
+	return p.blocks(n.Content)
+}
+
+// inline renders inline nodes, separating atoms like mentions from the text
+// around them by a space where the text has none.
+func (p textRenderer) inline(nodes []*ADFNode) string {
+	var b strings.Builder
+	prevAtom := false
+	for _, n := range nodes {
+		if n == nil {
+			continue
+		}
+
+		text, atom := inlineText(n)
+		if text == "" {
+			continue
+		}
+		if (atom || prevAtom) && b.Len() > 0 && !endsWithSpace(b.String()) && !startsWithSpace(text) {
+			b.WriteString(" ")
+		}
+		b.WriteString(text)
+		prevAtom = atom
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// inlineText returns the text of an inline node and whether it is an atom,
+// a node with no text content of its own.
+func inlineText(n *ADFNode) (string, bool) {
+	attr := func(key string) string {
+		v, _ := n.Attrs[key].(string)
+		return v
+	}
+
+	switch n.Type {
+	case ChildNodeText:
+		return n.Text, false
+	case InlineNodeHardBreak:
+		return "\n", false
+	case InlineNodeMention:
+		return strings.TrimPrefix(attr("text"), "@"), true
+	case InlineNodeEmoji:
+		if text := attr("text"); text != "" {
+			return text, true
+		}
+		return attr("shortName"), true
+	case InlineNodeStatus:
+		return attr("text"), true
+	case InlineNodeCard:
+		return attr("url"), true
+	}
+	return "", false
+}
+
+func endsWithSpace(s string) bool {
+	r, _ := utf8.DecodeLastRuneInString(s)
+	return unicode.IsSpace(r)
+}
+
+func startsWithSpace(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return unicode.IsSpace(r)
+}
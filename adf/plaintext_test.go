@@ -0,0 +1,72 @@
+package adf
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlainText(t *testing.T) {
+	paragraph := NewParagraphNode()
+	paragraph.Content = append(paragraph.Content,
+		NewTextNode("Hi "), NewMentionNode("id", "@Jane"), NewTextNodeWithMarks("bold", []*ADFMark{NewStrongMark()}),
+		NewTextNode(" and "), NewTextNodeWithMarks("code", []*ADFMark{NewCodeMark()}), NewHardBreakNode(),
+		NewTextNode("next line"), NewEmojiNode(":rocket:", "🚀"), NewStatusNode("DONE", "green"))
+
+	heading := NewHeadingNode(2)
+	heading.Content = append(heading.Content, NewTextNode("Title"))
+
+	code := NewCodeBlockNode("go")
+	code.Content = append(code.Content, NewTextNode("if x {\n\treturn\n}\n"))
+
+	item := func(text string, nested ...*ADFNode) *ADFNode {
+		li := NewListItemNode()
+		p := NewParagraphNode()
+		p.Content = append(p.Content, NewTextNode(text))
+		li.Content = append(append(li.Content, p), nested...)
+		return li
+	}
+	inner := NewBulletListNode()
+	inner.Content = append(inner.Content, item("nested"))
+	list := NewOrderedListNode(1)
+	list.Content = append(list.Content, item("first", inner), item("second"))
+
+	cell := func(text string) *ADFNode {
+		c := NewTableCellNode()
+		p := NewParagraphNode()
+		p.Content = append(p.Content, NewTextNode(text))
+		c.Content = append(c.Content, p)
+		return c
+	}
+	row := NewTableRowNode()
+	row.Content = append(row.Content, cell("a"), cell("b c"))
+	table := NewTableNode()
+	table.Content = append(table.Content, row, row)
+
+	doc := &ADFDocument{Type: "doc", Content: []*ADFNode{heading, paragraph, code, list, table}}
+
+	expected := "Title\n" +
+		"Hi Jane bold and code\nnext line 🚀 DONE\n" +
+		"if x {\n\treturn\n}\n" +
+		"first\nnested\nsecond\n" +
+		"a\tb c\na\tb c"
+	assert.Equal(t, expected, PlainText(doc))
+	assert.Equal(t, "", PlainText(nil))
+}
+
+func TestPlainTextFixture(t *testing.T) {
+	data, err := os.ReadFile("../adf2md/testdata/md.json")
+	require.NoError(t, err)
+	var doc ADFDocument
+	require.NoError(t, json.Unmarshal(data, &doc))
+
+	text := PlainText(&doc)
+	assert.NotEmpty(t, text)
+	for _, markup := range []string{"**", "](", "{panel", "| "} {
+		assert.False(t, strings.Contains(text, markup), "unexpected %q in plain text", markup)
+	}
+}
@@ -0,0 +1,37 @@
+package adf
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// Position is a location in a markdown source.
+type Position struct {
+	Line   int // 1-based
+	Column int // 1-based, in characters
+	Offset int // 0-based, in bytes
+}
+
+// String formats the position as "line:column".
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// PositionAt returns the position of the byte offset in content. Offsets past
+// the end of content are clamped to it.
+func PositionAt(content []byte, offset int) Position {
+	offset = max(0, min(offset, len(content)))
+
+	pos := Position{Line: 1, Column: 1, Offset: offset}
+	for i := 0; i < offset; {
+		r, size := utf8.DecodeRune(content[i:])
+		if r == '\n' {
+			pos.Line++
+			pos.Column = 1
+		} else {
+			pos.Column++
+		}
+		i += size
+	}
+	return pos
+}
@@ -0,0 +1,31 @@
+package adf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPositionAt(t *testing.T) {
+	content := []byte("first\nsecond läne\n\nlast")
+
+	tests := []struct {
+		offset   int
+		expected string
+	}{
+		{0, "1:1"},
+		{4, "1:5"},
+		{5, "1:6"},
+		{6, "2:1"},
+		{16, "2:10"}, // after the two-byte "ä"
+		{19, "3:1"},
+		{20, "4:1"},
+		{100, "4:5"},
+		{-1, "1:1"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, PositionAt(content, tt.offset).String(), "offset %d", tt.offset)
+	}
+	assert.Equal(t, Position{Line: 4, Column: 5, Offset: 24}, PositionAt(content, 100))
+}
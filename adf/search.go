@@ -0,0 +1,98 @@
+package adf
+
+import "strings"
+
+// SearchOption changes which text the search and replace methods of
+// ADFDocument look at.
+type SearchOption func(*search)
+
+// search is the configuration of a text search.
+type search struct {
+	skipCodeBlocks bool
+}
+
+// SkipCodeBlocks leaves the text of code blocks out of a search or replace,
+// so that code samples are kept as written.
+func SkipCodeBlocks() SearchOption {
+	return func(s *search) {
+		s.skipCodeBlocks = true
+	}
+}
+
+// TextMatch is a text node found by FindText.
+type TextMatch struct {
+	Node *ADFNode
+	// Path are the indexes into the Content slices leading to Node from
+	// the document, as formatted by FormatPath.
+	Path []int
+}
+
+// FindText returns the text nodes of doc containing substr, in document
+// order, with their paths.
+func (doc *ADFDocument) FindText(substr string, opts ...SearchOption) []TextMatch {
+	var matches []TextMatch
+	doc.eachText(opts, func(n *ADFNode, path []int) bool {
+		if strings.Contains(n.Text, substr) {
+			matches = append(matches, TextMatch{Node: n, Path: append([]int(nil), path...)})
+		}
+		return true
+	})
+	return matches
+}
+
+// Contains reports whether the text of a text node of doc contains substr.
+// Text split over several nodes, such as by a change of marks, isn't found.
+func (doc *ADFDocument) Contains(substr string, opts ...SearchOption) bool {
+	found := false
+	doc.eachText(opts, func(n *ADFNode, _ []int) bool {
+		found = strings.Contains(n.Text, substr)
+		return !found
+	})
+	return found
+}
+
+// ReplaceAll replaces all occurrences of old in the text nodes of doc with
+// new. Attributes, such as link URLs and mention IDs, are left alone.
+func (doc *ADFDocument) ReplaceAll(old, new string, opts ...SearchOption) {
+	doc.eachText(opts, func(n *ADFNode, _ []int) bool {
+		n.Text = strings.ReplaceAll(n.Text, old, new)
+		return true
+	})
+}
+
+// eachText calls fn for every text node of doc the options search, in
+// document order, with its path, until fn returns false. A cycle is not
+// followed.
+func (doc *ADFDocument) eachText(opts []SearchOption, fn func(n *ADFNode, path []int) bool) {
+	if doc == nil {
+		return
+	}
+	var s search
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	ancestors := make(map[*ADFNode]bool)
+	var path []int
+	var walk func(nodes []*ADFNode) bool
+	walk = func(nodes []*ADFNode) bool {
+		for i, n := range nodes {
+			if n == nil || ancestors[n] || s.skipCodeBlocks && n.Type == NodeCodeBlock {
+				continue
+			}
+			path = append(path, i)
+			if n.Type == ChildNodeText && !fn(n, path) {
+				return false
+			}
+			ancestors[n] = true
+			more := walk(n.Content)
+			delete(ancestors, n)
+			path = path[:len(path)-1]
+			if !more {
+				return false
+			}
+		}
+		return true
+	}
+	walk(doc.Content)
+}
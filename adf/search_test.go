@@ -0,0 +1,68 @@
+package adf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// searchDocument is a paragraph mentioning an internal host, linked and
+// plain, and a code block using it.
+func searchDocument() *ADFDocument {
+	link := NewTextNodeWithMarks("wiki.internal.example", []*ADFMark{NewLinkMark("https://wiki.internal.example/page")})
+	paragraph := NewParagraphNode()
+	paragraph.Content = append(paragraph.Content, NewTextNode("See "), link, NewTextNode(" or ask on chat.internal.example"))
+	code := NewCodeBlockNode("sh")
+	code.Content = append(code.Content, NewTextNode("curl https://api.internal.example"))
+	return &ADFDocument{Type: "doc", Content: []*ADFNode{paragraph, nil, code}}
+}
+
+func TestFindText(t *testing.T) {
+	doc := searchDocument()
+
+	matches := doc.FindText(".internal.example")
+	if assert.Len(t, matches, 3) {
+		assert.Equal(t, "wiki.internal.example", matches[0].Node.Text)
+		assert.Equal(t, []int{0, 1}, matches[0].Path)
+		assert.Equal(t, []int{0, 2}, matches[1].Path)
+		assert.Equal(t, "/content/2/content/0", FormatPath(matches[2].Path))
+	}
+
+	assert.Len(t, doc.FindText(".internal.example", SkipCodeBlocks()), 2)
+	assert.Empty(t, doc.FindText("missing"))
+	assert.Empty(t, (*ADFDocument)(nil).FindText("x"))
+}
+
+func TestContains(t *testing.T) {
+	doc := searchDocument()
+
+	assert.True(t, doc.Contains("chat.internal"))
+	assert.True(t, doc.Contains("api.internal"))
+	assert.False(t, doc.Contains("api.internal", SkipCodeBlocks()))
+	// Text is matched node by node
+	assert.False(t, doc.Contains("See wiki"))
+}
+
+func TestDocumentReplaceAll(t *testing.T) {
+	doc := searchDocument()
+
+	doc.ReplaceAll(".internal.example", ".example.com", SkipCodeBlocks())
+
+	paragraph := doc.Content[0]
+	assert.Equal(t, "wiki.example.com", paragraph.Content[1].Text)
+	assert.Equal(t, " or ask on chat.example.com", paragraph.Content[2].Text)
+	// Attributes are left alone
+	assert.Equal(t, "https://wiki.internal.example/page", paragraph.Content[1].Marks[0].Attrs["href"])
+	assert.Equal(t, "curl https://api.internal.example", doc.Content[2].Content[0].Text)
+
+	doc.ReplaceAll(".internal.example", ".example.com")
+	assert.Equal(t, "curl https://api.example.com", doc.Content[2].Content[0].Text)
+}
+
+func TestSearchCycle(t *testing.T) {
+	paragraph := NewParagraphNode()
+	paragraph.Content = append(paragraph.Content, NewTextNode("text"), paragraph)
+	doc := &ADFDocument{Type: "doc", Content: []*ADFNode{paragraph}}
+
+	assert.Len(t, doc.FindText("text"), 1)
+}
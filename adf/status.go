@@ -0,0 +1,22 @@
+package adf
+
+import "slices"
+
+// Status colors Jira accepts as the color attr of a status lozenge.
+const (
+	StatusNeutral = "neutral"
+	StatusPurple  = "purple"
+	StatusBlue    = "blue"
+	StatusRed     = "red"
+	StatusYellow  = "yellow"
+	StatusGreen   = "green"
+)
+
+// statusColors lists the known status colors.
+var statusColors = []string{StatusNeutral, StatusPurple, StatusBlue, StatusRed, StatusYellow, StatusGreen}
+
+// IsStatusColor reports whether Jira accepts c as the color of a status
+// lozenge.
+func IsStatusColor(c string) bool {
+	return slices.Contains(statusColors, c)
+}
@@ -0,0 +1,16 @@
+package adf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsStatusColor(t *testing.T) {
+	for _, color := range []string{StatusNeutral, StatusPurple, StatusBlue, StatusRed, StatusYellow, StatusGreen} {
+		assert.True(t, IsStatusColor(color), color)
+	}
+	assert.False(t, IsStatusColor("orange"))
+	assert.False(t, IsStatusColor("Green"))
+	assert.False(t, IsStatusColor(""))
+}
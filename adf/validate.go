@@ -0,0 +1,123 @@
+package adf
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrCycle reports a node that is contained in its own subtree.
+	ErrCycle = errors.New("node is its own ancestor")
+	// ErrAliasedNode reports a node referenced from more than one place.
+	// Translators handle it, but mutating one occurrence changes every other.
+	ErrAliasedNode = errors.New("node is referenced more than once")
+)
+
+// PathError is a structural problem found at a node path.
+type PathError struct {
+	Path  []int // indexes into Content slices, starting at the document
+	First []int // where the repeated node was reached first
+	Err   error
+}
+
+func (e *PathError) Error() string {
+	return fmt.Sprintf("adf: %s: %v (first reached at %s)", FormatPath(e.Path), e.Err, FormatPath(e.First))
+}
+
+func (e *PathError) Unwrap() error {
+	return e.Err
+}
+
+// FormatPath formats a node path as a JSON pointer into the document,
+// e.g. "/content/1/content/0".
+func FormatPath(path []int) string {
+	if len(path) == 0 {
+		return "/"
+	}
+
+	var b strings.Builder
+	for _, i := range path {
+		b.WriteString("/content/")
+		b.WriteString(strconv.Itoa(i))
+	}
+	return b.String()
+}
+
+// Validate checks the node structure of doc. Every cycle is reported as a
+// *PathError wrapping ErrCycle and every aliased node as one wrapping
+// ErrAliasedNode; use errors.Is to tell them apart, as aliasing alone is safe
+// to translate.
+func Validate(doc *ADFDocument) error {
+	if doc == nil {
+		return nil
+	}
+	return ValidateNode(&ADFNode{Type: NodeType(doc.Type), Content: doc.Content})
+}
+
+// ValidateNode is Validate for the subtree of node, such as the root node of
+// a document decoded without its version. Paths start at node.
+func ValidateNode(node *ADFNode) error {
+	var errs []error
+	walkStructure(node, func(err *PathError) bool {
+		errs = append(errs, err)
+		return true
+	})
+	return errors.Join(errs...)
+}
+
+// FindCycle returns the first cycle in doc as a *PathError, or nil.
+func FindCycle(doc *ADFNode) error {
+	var found error
+	walkStructure(doc, func(err *PathError) bool {
+		if errors.Is(err, ErrCycle) {
+			found = err
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+// walkStructure walks doc depth first, reporting repeated nodes until report
+// returns false. Repeated nodes are not descended into again.
+func walkStructure(doc *ADFNode, report func(*PathError) bool) {
+	if doc == nil {
+		return
+	}
+
+	root := []int{}
+	seen := map[*ADFNode][]int{doc: root}
+	ancestors := map[*ADFNode]bool{doc: true}
+
+	var walk func(n *ADFNode, path []int) bool
+	walk = func(n *ADFNode, path []int) bool {
+		for i, child := range n.Content {
+			if child == nil {
+				continue
+			}
+
+			childPath := append(path[:len(path):len(path)], i)
+			if first, ok := seen[child]; ok {
+				err := &PathError{Path: childPath, First: first, Err: ErrAliasedNode}
+				if ancestors[child] {
+					err.Err = ErrCycle
+				}
+				if !report(err) {
+					return false
+				}
+				continue
+			}
+
+			seen[child] = childPath
+			ancestors[child] = true
+			if !walk(child, childPath) {
+				return false
+			}
+			delete(ancestors, child)
+		}
+		return true
+	}
+	walk(doc, root)
+}
@@ -0,0 +1,73 @@
+package adf
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateSharedSubtree(t *testing.T) {
+	shared := NewParagraphNode()
+	shared.Content = append(shared.Content, NewTextNode("shared"))
+
+	doc := &ADFNode{Type: "doc", Content: []*ADFNode{shared, NewParagraphNode(), shared}}
+
+	err := ValidateNode(doc)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrAliasedNode)
+	assert.NotErrorIs(t, err, ErrCycle)
+	assert.NoError(t, FindCycle(doc))
+
+	var pathErr *PathError
+	require.ErrorAs(t, err, &pathErr)
+	assert.Equal(t, []int{2}, pathErr.Path)
+	assert.Equal(t, []int{0}, pathErr.First)
+	assert.Equal(t, "adf: /content/2: node is referenced more than once (first reached at /content/0)", err.Error())
+}
+
+func TestValidateCycle(t *testing.T) {
+	item := NewListItemNode()
+	list := NewBulletListNode()
+	list.Content = append(list.Content, item)
+	item.Content = append(item.Content, NewParagraphNode(), list)
+
+	doc := &ADFNode{Type: "doc", Content: []*ADFNode{list}}
+
+	err := ValidateNode(doc)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrCycle)
+
+	cycle := FindCycle(doc)
+	var pathErr *PathError
+	require.True(t, errors.As(cycle, &pathErr))
+	assert.Equal(t, []int{0, 0, 1}, pathErr.Path)
+	assert.Equal(t, []int{0}, pathErr.First)
+}
+
+func TestValidateWellFormed(t *testing.T) {
+	paragraph := NewParagraphNode()
+	paragraph.Content = append(paragraph.Content, NewTextNode("a"), NewTextNode("b"))
+
+	assert.NoError(t, ValidateNode(&ADFNode{Type: "doc", Content: []*ADFNode{paragraph}}))
+	assert.NoError(t, ValidateNode(nil))
+	assert.NoError(t, Validate(nil))
+}
+
+func TestValidateDocument(t *testing.T) {
+	shared := NewParagraphNode()
+	doc := NewADFDocument()
+	doc.Content = append(doc.Content, NewParagraphNode(), shared, shared)
+
+	err := Validate(doc)
+	assert.ErrorIs(t, err, ErrAliasedNode)
+
+	var pathErr *PathError
+	require.ErrorAs(t, err, &pathErr)
+	assert.Equal(t, []int{2}, pathErr.Path)
+	assert.Equal(t, []int{1}, pathErr.First)
+
+	doc.Content = doc.Content[:2]
+	assert.NoError(t, Validate(doc))
+}
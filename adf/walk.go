@@ -0,0 +1,55 @@
+package adf
+
+// Walk calls fn for every node of doc in document order, parents before
+// their children, with the depth of the node: 0 for the top-level blocks.
+// When fn returns false the children of the node are skipped. A node
+// reachable several times is visited each time, but a cycle is not followed.
+func Walk(doc *ADFDocument, fn func(n *ADFNode, depth int) bool) {
+	WalkMarks(doc, fn, nil)
+}
+
+// WalkMarks is Walk that also calls markFn for every mark of a visited node,
+// after fn is called for the node and before its children are visited.
+func WalkMarks(doc *ADFDocument, fn func(n *ADFNode, depth int) bool, markFn func(m *ADFMark, n *ADFNode)) {
+	if doc == nil {
+		return
+	}
+
+	ancestors := make(map[*ADFNode]bool)
+	var walk func(nodes []*ADFNode, depth int)
+	walk = func(nodes []*ADFNode, depth int) {
+		for _, n := range nodes {
+			if n == nil || ancestors[n] {
+				continue
+			}
+
+			descend := fn(n, depth)
+			if markFn != nil {
+				for _, m := range n.Marks {
+					if m != nil {
+						markFn(m, n)
+					}
+				}
+			}
+
+			if descend {
+				ancestors[n] = true
+				walk(n.Content, depth+1)
+				delete(ancestors, n)
+			}
+		}
+	}
+	walk(doc.Content, 0)
+}
+
+// FindAll returns the nodes of doc of the given type in document order.
+func FindAll(doc *ADFDocument, nodeType NodeType) []*ADFNode {
+	var found []*ADFNode
+	Walk(doc, func(n *ADFNode, _ int) bool {
+		if n.Type == nodeType {
+			found = append(found, n)
+		}
+		return true
+	})
+	return found
+}
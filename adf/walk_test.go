@@ -0,0 +1,62 @@
+package adf
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWalk(t *testing.T) {
+	bold := NewTextNodeWithMarks("bold", []*ADFMark{NewStrongMark(), NewEmphasisMark()})
+	paragraph := NewParagraphNode()
+	paragraph.Content = append(paragraph.Content, NewTextNode("plain "), bold)
+	item := NewListItemNode()
+	item.Content = append(item.Content, paragraph)
+	list := NewBulletListNode()
+	list.Content = append(list.Content, item)
+	heading := NewHeadingNode(1)
+
+	doc := &ADFDocument{Type: "doc", Content: []*ADFNode{heading, list, nil}}
+
+	var visited []string
+	var marks []string
+	WalkMarks(doc, func(n *ADFNode, depth int) bool {
+		visited = append(visited, fmt.Sprintf("%s@%d", n.Type, depth))
+		return true
+	}, func(m *ADFMark, n *ADFNode) {
+		marks = append(marks, fmt.Sprintf("%s:%s", m.Type, n.Text))
+	})
+	assert.Equal(t, []string{"heading@0", "bulletList@0", "listItem@1", "paragraph@2", "text@3", "text@3"}, visited)
+	assert.Equal(t, []string{"strong:bold", "em:bold"}, marks)
+
+	// Returning false skips the children
+	visited = nil
+	Walk(doc, func(n *ADFNode, depth int) bool {
+		visited = append(visited, string(n.Type))
+		return n.Type != NodeBulletList
+	})
+	assert.Equal(t, []string{"heading", "bulletList"}, visited)
+
+	assert.Equal(t, []*ADFNode{paragraph}, FindAll(doc, NodeParagraph))
+	assert.Len(t, FindAll(doc, ChildNodeText), 2)
+	assert.Empty(t, FindAll(doc, NodePanel))
+}
+
+func TestWalkCycle(t *testing.T) {
+	item := NewListItemNode()
+	list := NewBulletListNode()
+	list.Content = append(list.Content, item)
+	item.Content = append(item.Content, NewParagraphNode(), list)
+
+	// An aliased node is visited each time, the cycle back to list is not followed
+	shared := NewParagraphNode()
+	doc := &ADFDocument{Type: "doc", Content: []*ADFNode{list, shared, shared}}
+
+	var visited []NodeType
+	Walk(doc, func(n *ADFNode, _ int) bool {
+		visited = append(visited, n.Type)
+		return true
+	})
+	assert.Equal(t, []NodeType{NodeBulletList, ChildNodeListItem, NodeParagraph, NodeParagraph, NodeParagraph}, visited)
+}
@@ -1,11 +1,20 @@
 package adf2md
 
 import (
-	"encoding/json"
+	"bufio"
+	"errors"
 	"fmt"
 	"github.com/jorres/md2adf-translator/adf"
+	"io"
 	"log"
+	"maps"
+	"reflect"
+	"regexp"
+	"slices"
 	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
 // TagOpener is a tag opener.
@@ -24,6 +33,21 @@ type TagOpenerCloser interface {
 	TagCloser
 }
 
+// ContentWrapper is an optional interface of a TagOpenerCloser that needs to
+// post-process the rendered children of some nodes as a whole, for example to
+// indent every continuation line of a list item.
+type ContentWrapper interface {
+	WrapsContent(n Connector) bool
+	WrapContent(n Connector, content string) string
+}
+
+// NodeFilter is an optional interface of a TagOpenerCloser whose format can't
+// represent every node and mark. When Accept returns an error for a node or
+// mark, the translation stops: the result is empty and Err reports the error.
+type NodeFilter interface {
+	Accept(n Connector) error
+}
+
 // Connector is a connector interface.
 type Connector interface {
 	GetType() adf.NodeType
@@ -31,14 +55,7 @@ type Connector interface {
 }
 
 // MediaAttributes represents the attributes of a media node in ADF
-type MediaAttributes struct {
-	ID         string `json:"id"`
-	Type       string `json:"type"`
-	Collection string `json:"collection"`
-	Alt        string `json:"alt,omitempty"`
-	Width      int    `json:"width,omitempty"`
-	Height     int    `json:"height,omitempty"`
-}
+type MediaAttributes = adf.MediaAttributes
 
 // InlineCardAttributes represents the attributes of an inline card node in ADF
 type InlineCardAttributes struct {
@@ -50,29 +67,250 @@ type InlineCardAttributes struct {
 type Translator struct {
 	doc               *adf.ADFNode
 	tsl               TagOpenerCloser
-	buf               *strings.Builder
+	buf               io.StringWriter // where emit writes
 	mediaMapping      map[string]*adf.ADFNode
 	inlineCardMapping map[string]*adf.ADFNode
+	mediaInfo         map[string]preservedInfo
+	inlineCardInfo    map[string]preservedInfo
+	fetchedAt         time.Time // when the document being translated was fetched
+	metrics           adf.Collector
+	recoverPanics     bool
+	visited           int // nodes visited by the current Translate call
+
+	normalizeURL    func(string) string
+	linkRewriter    func(href string) string
+	headingOffset   int
+	nameAttachments bool
+	attachmentNames map[string]string // media id -> filename
+	// attachmentIDs maps the filenames attachments were rendered by to their
+	// media ids.
+	attachmentIDs map[string]string
+	mediaKeys     map[string]string // media id -> filename in the current Translate call
+
+	unknownPolicy UnknownNodePolicy
+	unknownTypes  []adf.NodeType // met by the current Translate call
+	lossless      bool
+	warnings      []Warning // of the current Translate call
+
+	// ancestors holds the nodes being visited, to stop at cycles.
+	ancestors map[*adf.ADFNode]bool
+	stack     []*adf.ADFNode // the nodes being visited, outermost first
+	err       error
+	halted    bool // a node was refused by a NodeFilter, or a panic recovered
+	// marks are the marks open around the text being rendered, outermost
+	// first. They stay open over the next text node that carries them too.
+	marks    []*adf.ADFMark
+	lastText string // the text rendered last
+}
+
+// maxVisitDepth bounds nesting as a backstop against runaway structures.
+const maxVisitDepth = 1000
+
+// TranslatorOption is a functional option for Translator.
+type TranslatorOption func(*Translator)
+
+// WithMetrics reports conversion metrics to the given collector.
+func WithMetrics(collector adf.Collector) TranslatorOption {
+	return func(a *Translator) {
+		a.metrics = collector
+	}
+}
+
+// WithPanicRecovery sets whether a panic during translation is recovered and
+// reported by Err as an *adf.InternalError instead of crashing the caller. It
+// is on by default.
+func WithPanicRecovery(recover bool) TranslatorOption {
+	return func(a *Translator) {
+		a.recoverPanics = recover
+	}
+}
+
+// WithHeadingOffset shifts the level of every heading by offset as it is
+// rendered, such as -1 to render a section of a page as a document of its own.
+// Levels are clamped to 1, and a heading shifted past level 6 is rendered as a
+// paragraph in bold.
+func WithHeadingOffset(offset int) TranslatorOption {
+	return func(a *Translator) {
+		a.headingOffset = offset
+	}
 }
 
 // NewTranslator constructs an ADF translator.
-func NewTranslator(tr TagOpenerCloser) *Translator {
-	return &Translator{
+func NewTranslator(tr TagOpenerCloser, opts ...TranslatorOption) *Translator {
+	a := &Translator{
 		doc:               nil,
 		tsl:               tr,
 		buf:               nil,
 		mediaMapping:      make(map[string]*adf.ADFNode),
 		inlineCardMapping: make(map[string]*adf.ADFNode),
+		mediaInfo:         make(map[string]preservedInfo),
+		inlineCardInfo:    make(map[string]preservedInfo),
+		recoverPanics:     true,
+		attachmentIDs:     make(map[string]string),
+		normalizeURL:      NormalizeURL,
+	}
+
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	return a
+}
+
+// Translate translates ADF to a new format. Unusual attributes, such as a
+// heading level given as a string, are rendered as well as they can be. The
+// error is the one Err reports: the output then leaves out what couldn't be
+// translated. If translation panics, the panic is recovered unless turned
+// off with WithPanicRecovery: the result is empty and the error is a
+// *NodeError wrapping an *adf.InternalError.
+func (a *Translator) Translate(doc *adf.ADFNode) (string, error) {
+	var out strings.Builder
+	a.translate(doc, &out)
+	if a.halted {
+		return "", a.err
 	}
+	return out.String(), a.err
 }
 
-// Translate translates ADF to a new format.
-func (a *Translator) Translate(doc *adf.ADFNode) string {
+// TranslateTo is Translate writing the output to w as it is rendered, rather
+// than holding all of it in memory. Unlike with Translate, the output
+// written before an error that stops the translation is left in w. An error
+// writing to w is returned as is.
+func (a *Translator) TranslateTo(w io.Writer, doc *adf.ADFNode) error {
+	out := bufio.NewWriter(w)
+	a.translate(doc, out)
+	if err := out.Flush(); err != nil {
+		return err
+	}
+	return a.err
+}
+
+// translate translates doc to out, leaving the error Err reports in a.err. A
+// recovered panic halts the translation.
+func (a *Translator) translate(doc *adf.ADFNode, out io.StringWriter) {
+	// Deferred first, to observe the error of a recovered panic
+	if a.metrics != nil {
+		started := time.Now()
+		defer func() { a.observeConversion(time.Since(started)) }()
+	}
+
+	if a.recoverPanics {
+		defer func() {
+			var internal *adf.InternalError
+			if errors.As(a.err, &internal) {
+				a.err = a.nodeError(a.err)
+				a.halted = true
+			}
+		}()
+		defer adf.CatchPanic(&a.err)
+	}
+
 	a.doc = doc
-	a.buf = new(strings.Builder)
+	a.fetchedAt = time.Now()
+	a.buf = out
+	a.visited = 0
+	a.ancestors = map[*adf.ADFNode]bool{doc: true}
+	a.stack = a.stack[:0]
+	a.marks = a.marks[:0]
+	a.lastText = ""
+	a.err = nil
+	a.halted = false
+	a.unknownTypes = nil
+	a.warnings = nil
+
+	a.mediaKeys = nil
+	if a.nameAttachments {
+		a.mediaKeys = a.attachmentKeys()
+		for id, key := range a.mediaKeys {
+			a.attachmentIDs[key] = id
+		}
+	}
+	if k, ok := a.tsl.(AttachmentKeyer); ok {
+		k.SetAttachmentKeys(a.mediaKeys)
+	}
 
 	a.walk()
-	return a.buf.String()
+}
+
+// observeConversion reports the metrics of a finished translation, failed or
+// not.
+func (a *Translator) observeConversion(took time.Duration) {
+	direction := map[string]string{adf.LabelDirection: adf.DirectionADFToMarkdown}
+	a.metrics.Observe(adf.MetricDocumentsConverted, 1, direction)
+	a.metrics.Observe(adf.MetricConversionDuration, took.Seconds(), direction)
+	a.metrics.Observe(adf.MetricInputNodes, float64(a.visited), direction)
+	if a.err != nil {
+		a.metrics.Observe(adf.MetricConversionErrors, 1, direction)
+	}
+}
+
+// MustTranslate is Translate for documents known to translate cleanly; it
+// panics on error.
+func (a *Translator) MustTranslate(doc *adf.ADFNode) string {
+	out, err := a.Translate(doc)
+	if err != nil {
+		panic(err)
+	}
+	return out
+}
+
+// NodeError is an error translating a node, located by its path.
+type NodeError struct {
+	Path []int // indexes into Content slices, starting at the document
+	Err  error
+}
+
+func (e *NodeError) Error() string {
+	return fmt.Sprintf("adf2md: %s: %v", adf.FormatPath(e.Path), e.Err)
+}
+
+func (e *NodeError) Unwrap() error {
+	return e.Err
+}
+
+// nodeError locates err at the node being visited.
+func (a *Translator) nodeError(err error) error {
+	return &NodeError{Path: a.path(), Err: err}
+}
+
+// path returns the path of the node being visited.
+func (a *Translator) path() []int {
+	path := make([]int, 0, len(a.stack))
+	parent := a.doc
+	for _, n := range a.stack {
+		i := slices.Index(parent.Content, n)
+		if i < 0 {
+			break // a copy made by mergeAdjacentText, located by its parent
+		}
+		path = append(path, i)
+		parent = n
+	}
+	return path
+}
+
+// Err returns the error that cut the last Translate call short, such as a
+// cycle in the document, a recovered panic or a node refused by a NodeFilter.
+// It is the error Translate returned. Cyclic subtrees are left out of the
+// output.
+func (a *Translator) Err() error {
+	return a.err
+}
+
+// TranslateDocument is Translate for an ADF document.
+func (a *Translator) TranslateDocument(doc *adf.ADFDocument) (string, error) {
+	if doc == nil {
+		return "", nil
+	}
+	return a.Translate(&adf.ADFNode{Type: adf.NodeType(doc.Type), Content: doc.Content})
+}
+
+// TranslateDocumentTo is TranslateDocument writing the output to w, as
+// TranslateTo does.
+func (a *Translator) TranslateDocumentTo(w io.Writer, doc *adf.ADFDocument) error {
+	if doc == nil {
+		return nil
+	}
+	return a.TranslateTo(w, &adf.ADFNode{Type: adf.NodeType(doc.Type), Content: doc.Content})
 }
 
 // GetMediaMapping returns the mapping of media IDs to their ADF nodes.
@@ -80,7 +318,8 @@ func (a *Translator) GetMediaMapping() map[string]*adf.ADFNode {
 	return a.mediaMapping
 }
 
-// GetInlineCardMapping returns the mapping of inline card URLs to their ADF nodes.
+// GetInlineCardMapping returns the mapping of normalized inline card URLs to
+// their ADF nodes, see InlineCard.
 func (a *Translator) GetInlineCardMapping() map[string]*adf.ADFNode {
 	return a.inlineCardMapping
 }
@@ -90,116 +329,381 @@ func (a *Translator) walk() {
 		return
 	}
 	for _, parent := range a.doc.Content {
-		a.visit(parent, a.doc, 0)
+		if parent != nil {
+			a.visit(parent, a.doc, 0)
+		}
 	}
 }
 
 func (a *Translator) CheckSupport(n *adf.ADFNode) map[adf.NodeType]bool {
 	forbidden := make(map[adf.NodeType]bool)
 
-	if n == nil {
-		return forbidden
-	}
-
-	if n.Type == adf.NodeBlockquote {
-		forbidden[n.Type] = true
-	}
-
-	for _, child := range n.Content {
-		for k, _ := range a.CheckSupport(child) {
-			forbidden[k] = true
+	adf.Walk(&adf.ADFDocument{Content: []*adf.ADFNode{n}}, func(n *adf.ADFNode, _ int) bool {
+		if n.Type == adf.NodeBlockquote {
+			forbidden[n.Type] = true
 		}
-	}
+		return true
+	})
 
 	return forbidden
 }
 
 func (a *Translator) visit(n *adf.ADFNode, parent *adf.ADFNode, depth int) {
-	if n.Type == adf.NodeMediaGroup || n.Type == adf.NodeMediaSingle {
-		// We currently don't distinguish between group \ single, just preserve them
-		// fully and resend them back to jira on update
-		var firstChildMediaAttrs MediaAttributes
-		firstChildNode := n.Content[0]
-		jsonBytes, err := json.Marshal(firstChildNode.Attrs)
-		if err != nil {
-			panic("NodeMedia node is supposed to have children")
+	if a.halted {
+		return
+	}
+	if a.ancestors[n] || depth > maxVisitDepth {
+		if a.err == nil {
+			a.err = adf.FindCycle(a.doc)
+			if a.err == nil {
+				a.err = a.nodeError(fmt.Errorf("document is nested deeper than %d levels", maxVisitDepth))
+			}
 		}
+		return
+	}
+	a.ancestors[n] = true
+	defer delete(a.ancestors, n)
+
+	// The stack is popped without defer, so that it still locates a panic
+	// when the panic is recovered
+	a.stack = append(a.stack, n)
+	if !a.accept(n) {
+		a.stack = a.stack[:len(a.stack)-1]
+		return
+	}
 
-		_ = json.Unmarshal(jsonBytes, &firstChildMediaAttrs)
-		if firstChildMediaAttrs.ID != "" {
-			a.mediaMapping[firstChildMediaAttrs.ID] = n
-		}
+	a.visited++
+
+	// Marks are only kept open from text to text
+	if n.Type != adf.ChildNodeText {
+		a.emit(a.closeMarks(0))
 	}
 
-	if n.Type == adf.InlineNodeCard {
-		var attrs InlineCardAttributes
-		jsonBytes, _ := json.Marshal(n.Attrs)
-		_ = json.Unmarshal(jsonBytes, &attrs)
-		if attrs.URL != "" {
-			a.inlineCardMapping[attrs.URL] = n
+	if a.visitRaw(n, parent) {
+		a.stack = a.stack[:len(a.stack)-1]
+		return
+	}
+	if n.Type == adf.NodeLayoutSection {
+		a.warn(WarningLayoutDiscarded, "layout of %d columns discarded, the columns are rendered one below the other", len(n.Content))
+	}
+	if !knownTypes[n.Type] && !a.visitUnknown(n, parent) {
+		a.stack = a.stack[:len(a.stack)-1]
+		return
+	}
+
+	if n.Type == adf.NodeMediaGroup || n.Type == adf.NodeMediaSingle {
+		// The whole container is preserved under the key of each media in it
+		// and resent to jira on update; md2adf regroups the media of a group
+		for _, child := range n.Content {
+			mediaAttrs, _ := child.MediaAttrs()
+			if mediaAttrs.ID != "" {
+				preserve(a.mediaMapping, a.mediaInfo, mediaAttrs.ID, PreservedNode{Node: n, FetchedAt: a.fetchedAt})
+				if key, ok := a.mediaKeys[mediaAttrs.ID]; ok {
+					preserve(a.mediaMapping, a.mediaInfo, key, PreservedNode{Node: n, FetchedAt: a.fetchedAt})
+				}
+			} else if mediaAttrs.Type == "external" && mediaAttrs.URL != "" {
+				// External images have no ID, they are referenced by URL from markdown
+				preserve(a.mediaMapping, a.mediaInfo, mediaAttrs.URL, PreservedNode{Node: n, FetchedAt: a.fetchedAt})
+			}
 		}
 	}
 
-	a.buf.WriteString(a.tsl.Open(n, depth))
+	if url, ok := n.CardURL(); ok {
+		preserve(a.inlineCardMapping, a.inlineCardInfo, a.cardKey(url), PreservedNode{Node: n, FetchedAt: a.fetchedAt})
+	}
 
-	for _, child := range n.Content {
-		a.visit(child, n, depth+1)
+	// Cards are preserved under the URL they have, but rendered rewritten
+	n = a.rewriteLinks(n)
+	if n.Type == adf.NodeHeading && a.headingOffset != 0 {
+		n = adf.ShiftHeading(n, a.headingOffset)
+	}
+
+	a.emit(a.tsl.Open(n, depth))
+
+	if w, ok := a.tsl.(ContentWrapper); ok && w.WrapsContent(n) {
+		outer, content := a.buf, new(strings.Builder)
+		a.buf = content
+		a.visitChildren(n, depth)
+		outer.WriteString(w.WrapContent(n, content.String()))
+		a.buf = outer
+	} else {
+		a.visitChildren(n, depth)
 	}
 
 	if adf.GetADFNodeType(n.Type) == adf.NodeTypeChild {
 		var tag strings.Builder
 
-		opened := make([]*adf.ADFMark, 0, len(n.Marks))
 		if n.Type == adf.ChildNodeText {
-			for _, m := range n.Marks {
-				opened = append(opened, m)
+			marks := nestedMarks(n.Marks)
+			kept := a.keptMarks(marks, n.Text)
+			tag.WriteString(a.closeMarks(kept))
+			for _, m := range marks[kept:] {
+				if !a.accept(m) {
+					a.stack = a.stack[:len(a.stack)-1]
+					return
+				}
+				a.marks = append(a.marks, m)
 				tag.WriteString(a.tsl.Open(m, depth))
 			}
 		}
 
-		textContent := sanitize(n.Text)
+		code := parent.Type == adf.NodeCodeBlock || slices.ContainsFunc(n.Marks, isCodeMark)
+		if r, ok := a.tsl.(TextRenderer); ok {
+			tag.WriteString(r.RenderText(n.Text, code))
+		} else if parent.Type == adf.NodeCodeBlock {
+			// Code is taken verbatim on reparse, so nothing needs escaping;
+			// the line break closing the fence adds to its own
+			tag.WriteString(n.Text)
+		} else if code {
+			tag.WriteString(strings.TrimRight(n.Text, "\n"))
+		} else {
+			tag.WriteString(escapeMarkdown(sanitize(n.Text)))
+		}
+		a.lastText = n.Text
+
+		a.emit(tag.String())
+	}
+
+	a.emit(a.tsl.Close(n))
+	a.stack = a.stack[:len(a.stack)-1]
+}
+
+// visitChildren visits the content of n, closing the marks left open by its
+// last text node.
+func (a *Translator) visitChildren(n *adf.ADFNode, depth int) {
+	if len(n.Content) == 0 {
+		return
+	}
+	for _, child := range mergeAdjacentText(n.Content) {
+		a.visit(child, n, depth+1)
+	}
+	a.emit(a.closeMarks(0))
+}
+
+// keptMarks returns how many of the open marks stay open around text marked
+// with marks, sorted by nestedMarks: those it starts with. Code spans are
+// never continued, their delimiter depends on the text. Nor are marks around
+// emphasis delimiters with no whitespace on either side, which may not
+// delimit there, as in "**a_b_**".
+func (a *Translator) keptMarks(marks []*adf.ADFMark, text string) int {
+	kept := 0
+	for kept < len(a.marks) && kept < len(marks) && !isCodeMark(marks[kept]) &&
+		a.marks[kept].Type == marks[kept].Type && reflect.DeepEqual(a.marks[kept].Attrs, marks[kept].Attrs) {
+		kept++
+	}
+
+	toggled := slices.ContainsFunc(a.marks[kept:], isEmphasisMark) || slices.ContainsFunc(marks[kept:], isEmphasisMark)
+	before, _ := utf8.DecodeLastRuneInString(a.lastText)
+	after, _ := utf8.DecodeRuneInString(text)
+	if toggled && !unicode.IsSpace(before) && !unicode.IsSpace(after) {
+		return 0
+	}
+	return kept
+}
+
+// isEmphasisMark reports whether m is rendered by emphasis delimiters.
+func isEmphasisMark(m *adf.ADFMark) bool {
+	switch m.Type {
+	case adf.MarkStrong, adf.MarkEm, adf.MarkStrike:
+		return true
+	}
+	return false
+}
+
+// closeMarks closes the open marks but the outermost kept ones, innermost
+// first, and returns the tags to emit.
+func (a *Translator) closeMarks(kept int) string {
+	var tags strings.Builder
+	for len(a.marks) > kept {
+		last := len(a.marks) - 1
+		tags.WriteString(a.tsl.Close(a.marks[last]))
+		a.marks = a.marks[:last]
+	}
+	return tags.String()
+}
+
+// accept reports whether the output format can represent n, halting the
+// translation if it can't.
+func (a *Translator) accept(n Connector) bool {
+	f, ok := a.tsl.(NodeFilter)
+	if !ok {
+		return true
+	}
+	if err := f.Accept(n); err != nil {
+		a.err = a.nodeError(err)
+		a.halted = true
+		return false
+	}
+	return true
+}
+
+// emit writes translated output, into the content of the innermost node a
+// ContentWrapper wraps if any. Markdown table cells are such nodes, as tables
+// are rendered as a whole once closed.
+func (a *Translator) emit(s string) {
+	a.buf.WriteString(s)
+}
+
+// mergeAdjacentText joins consecutive text nodes carrying the same marks, so
+// they render as one span instead of two touching delimiter runs like
+// "**a****b**". The original nodes are left untouched.
+func mergeAdjacentText(nodes []*adf.ADFNode) []*adf.ADFNode {
+	merged := make([]*adf.ADFNode, 0, len(nodes))
+	for _, n := range nodes {
+		if n == nil {
+			continue
+		}
+		last := len(merged) - 1
+		if last >= 0 && n.Type == adf.ChildNodeText && merged[last].Type == adf.ChildNodeText &&
+			len(n.Marks) > 0 && sameMarks(merged[last].Marks, n.Marks) {
+			joined := *merged[last]
+			joined.Text += n.Text
+			merged[last] = &joined
+			continue
+		}
+		merged = append(merged, n)
+	}
+	return merged
+}
+
+// markNesting is the order marks are nested in when rendered, outermost
+// first. Link text may hold any formatting, and code goes innermost because
+// nothing inside backticks is parsed. Underline is raw HTML whose content is
+// not read back as markdown, so it sits inside the delimited marks; code
+// inside underline is lost either way.
+var markNesting = []adf.NodeType{
+	adf.MarkLink, adf.MarkStrong, adf.MarkEm, adf.MarkStrike, adf.MarkUnderline, adf.MarkCode,
+}
 
-		// If we're inside a table cell, accumulate content in the translator
-		var mdTranslator *MarkdownTranslator
-		if mt, ok := a.tsl.(*MarkdownTranslator); ok {
-			mdTranslator = mt
-		} else if jmt, ok := a.tsl.(*JiraMarkdownTranslator); ok {
-			mdTranslator = jmt.MarkdownTranslator
+// nestedMarks returns marks sorted into markNesting order. Unknown marks keep
+// their relative order after the known ones.
+func nestedMarks(marks []*adf.ADFMark) []*adf.ADFMark {
+	rank := func(m *adf.ADFMark) int {
+		if i := slices.Index(markNesting, m.Type); i >= 0 {
+			return i
 		}
+		return len(markNesting)
+	}
 
-		if mdTranslator != nil && mdTranslator.isInTableCell() {
-			// Add opening marks
-			for _, m := range opened {
-				mdTranslator.addCellContent(a.tsl.Open(m, depth))
-			}
-			mdTranslator.addCellContent(textContent)
-			// Add closing marks
-			for i := len(opened) - 1; i >= 0; i-- {
-				m := opened[i]
-				mdTranslator.addCellContent(a.tsl.Close(m))
+	sorted := slices.Clone(marks)
+	slices.SortStableFunc(sorted, func(a, b *adf.ADFMark) int {
+		return rank(a) - rank(b)
+	})
+	return sorted
+}
+
+// sameMarks reports whether two mark lists hold the same marks in any order.
+func sameMarks(a, b []*adf.ADFMark) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, m := range a {
+		found := false
+		for _, o := range b {
+			if m.Type == o.Type && reflect.DeepEqual(m.Attrs, o.Attrs) {
+				found = true
+				break
 			}
-			return
 		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// underlineTag matches the tags the markdown grammar reads as underline.
+var underlineTag = regexp.MustCompile(`(?i)<(/?u)>`)
 
-		tag.WriteString(textContent)
+// sanitize keeps text from being misread as underline: the tags get
+// look-alike brackets.
+func sanitize(s string) string {
+	s = strings.TrimRight(s, "\n")
+	return underlineTag.ReplaceAllString(s, "❬$1❭")
+}
 
-		// Close tags in reverse order.
-		for i := len(opened) - 1; i >= 0; i-- {
-			m := opened[i]
-			tag.WriteString(a.tsl.Close(m))
+// macroStart matches the opening of a brace macro such as "{panel" or "{/expand}".
+var macroStart = regexp.MustCompile(`^\{/?[a-zA-Z]+[:}|]`)
+
+// breakTag matches a <br> tag, which in a table cell reads back as a
+// paragraph break.
+var breakTag = regexp.MustCompile(`^(?i)<br\s*/?>`)
+
+// escapeMarkdown backslash-escapes the characters of plain text that would
+// otherwise be read back as markup. Characters that are only significant in
+// some positions, like an intraword underscore or a "#" mid-line, are kept as
+// they are. The start of s and every line in it are taken to start a line of
+// output, where block markers like "#", "- " and "1. " are escaped.
+func escapeMarkdown(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		escape := false
+		switch c {
+		case '*', '`', '[', '~':
+			escape = true
+		case '\\':
+			escape = i+1 < len(s) && isASCIIPunct(s[i+1])
+		case '_':
+			escape = i == 0 || i+1 == len(s) || !isWordByte(s[i-1]) || !isWordByte(s[i+1])
+		case '#', '>':
+			escape = atLineStart(s, i)
+		case '-', '+':
+			// A bullet list marker, or for "-" a thematic break or setext
+			// heading underline
+			escape = atLineStart(s, i) && (i+1 == len(s) || isMarkerGap(s[i+1]) || c == '-' && s[i+1] == '-')
+		case '.', ')':
+			escape = endsListNumber(s, i)
+		case '<':
+			// "<>" starting a list item would read back as a decision
+			escape = i == 0 && strings.HasPrefix(s, decisionMarker) || breakTag.MatchString(s[i:])
+		case '{':
+			escape = macroStart.MatchString(s[i:])
 		}
+		if escape {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// atLineStart reports whether s[i] starts a line of s, past any indentation.
+func atLineStart(s string, i int) bool {
+	for i > 0 && (s[i-1] == ' ' || s[i-1] == '\t') {
+		i--
+	}
+	return i == 0 || s[i-1] == '\n'
+}
 
-		a.buf.WriteString(tag.String())
+// endsListNumber reports whether the "." or ")" at s[i] ends the number of an
+// ordered list marker, as in "1. " at the start of a line.
+func endsListNumber(s string, i int) bool {
+	start := i
+	for start > 0 && s[start-1] >= '0' && s[start-1] <= '9' {
+		start--
+	}
+	if start == i || i-start > 9 || !atLineStart(s, start) {
+		return false
 	}
+	return i+1 == len(s) || isMarkerGap(s[i+1])
+}
 
-	a.buf.WriteString(a.tsl.Close(n))
+// isMarkerGap reports whether c may follow a list marker.
+func isMarkerGap(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n'
 }
 
-func sanitize(s string) string {
-	s = strings.TrimRight(s, "\n")
-	s = strings.ReplaceAll(s, "<", "❬")
-	s = strings.ReplaceAll(s, ">", "❭")
-	return s
+func isASCIIPunct(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
+
+func isWordByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
+
+func isCodeMark(m *adf.ADFMark) bool {
+	return m != nil && m.Type == adf.MarkCode
 }
 
 type nodeTypeHook map[adf.NodeType]func(Connector) string
@@ -209,45 +713,126 @@ type UserEmailResolver func(userID string) string
 
 // MarkdownTranslator is a markdown translator.
 type MarkdownTranslator struct {
-	table struct {
-		rows        int
-		cols        int
-		ccol        int // current column count
-		sep         bool
-		content     [][]string // store table content for width calculation
-		widths      []int      // column widths
-		inTable     bool       // whether we're currently inside a table
-		inTableCell bool       // whether we're currently inside a table cell/header
-	}
-	list struct {
-		ol, ul  map[int]bool
-		depthO  int
-		depthU  int
-		counter map[int]int // each level starts with same numeric counter at the moment.
+	table tableState
+	// outerTables holds the tables a table nested in a cell of is in,
+	// innermost last, while it is rendered.
+	outerTables []tableState
+	list        struct {
+		open    []listLevel // lists currently open, innermost last
+		blocks  []int       // blocks rendered so far in each open list item
+		indents []string    // indentation of the content column of each open list item
+		ended   bool        // whether a list at the top level was the last thing rendered
+	}
+	// context holds the lists and table cells the blocks being rendered
+	// are in, innermost last. Blocks outside any are at the top level.
+	context []blockContext
+	marks   struct {
+		last    string   // delimiters written in a row, reset once anything else is output
+		opening bool     // whether the last of them opened a mark
+		open    []string // delimiters of the currently open marks, innermost last
+		code    string   // opening delimiter of a code span around the text being rendered
 	}
 	openHooks  nodeTypeHook
 	closeHooks nodeTypeHook
+	// wrapHooks post-process the rendered content of nodes, instead of
+	// WrapContent.
+	wrapHooks map[adf.NodeType]func(n Connector, content string) string
 
 	emailResolver UserEmailResolver
+	underline     UnderlineStyle
+	mediaLayout   string // layout of the mediaSingle being rendered
+	layoutColumns int    // columns rendered of the layoutSection being rendered
+	// mentionRun is set while the output since the last mention would be read
+	// back as part of it, the grammar taking in everything up to whitespace.
+	mentionRun bool
+	// attachmentKeys maps media ids to the keys attachments are rendered by.
+	attachmentKeys map[string]string
+}
+
+// tableState is a table being rendered.
+type tableState struct {
+	rows    int
+	cols    int
+	ccol    int // current column count
+	sep     bool
+	content [][]string // store table content for width calculation
+	widths  []int      // column widths
+	aligns  []string   // column alignments, "" for the default
+	inTable bool       // whether we're currently inside a table
+	ended   bool       // whether a table was the last thing rendered
+}
+
+// listLevel is an open list; ordered lists count their items.
+type listLevel struct {
+	ordered  bool
+	decision bool // a decision list, whose items are single lines
+	counter  int  // number of the last item rendered
 }
 
+// blockContext is a container paragraphs are laid out by: a list, whose
+// paragraphs are single lines, a table cell, whose blocks are kept on the
+// row, or a quote, whose blocks are separated by quoted blank lines.
+type blockContext struct {
+	kind   blockContextKind
+	blocks int          // blocks and list items opened in a cell or quote so far
+	open   int          // blocks of a cell currently open, nested ones included
+	last   adf.NodeType // the block of a quote opened last
+}
+
+// blockContextKind is the kind of a blockContext.
+type blockContextKind int
+
+const (
+	contextTopLevel blockContextKind = iota
+	contextList
+	contextCell
+	contextQuote
+)
+
+// markDelimiters lists the delimiter variants of each delimited mark, preferred
+// first. A variant is switched when the preferred one would touch the previous
+// delimiter and form an ambiguous run, e.g. "**a**__b__" instead of "**a****b**".
+var markDelimiters = map[adf.NodeType][]string{
+	adf.MarkStrong: {"**", "__"},
+	adf.MarkEm:     {"_", "*"},
+	adf.MarkStrike: {"~"},
+	adf.MarkCode:   {"`"},
+}
+
+// decisionMarker follows the bullet of a decision item, as in "- <> text".
+const decisionMarker = "<>"
+
+// captionStart and captionEnd enclose the caption of a media single.
+const captionStart, captionEnd = "{caption}", "{/caption}"
+
+// layoutSeparator is the thematic break between the flattened columns of a
+// layout section.
+const layoutSeparator = "***\n\n"
+
+// hardBreak is a backslash line break, which unlike a blank line keeps the
+// paragraph in one piece when read back.
+const hardBreak = "\\\n"
+
+// cellParagraphSeparator separates the blocks of a table cell, which can't
+// span lines. md2adf splits cells at it back into paragraphs.
+//
+// Blocks other than paragraphs are kept on the row at a loss: the items of
+// bullet lists, marked by cellBullet, read back as one flat list whatever
+// their nesting, ordered list items as paragraphs numbered in their text,
+// code blocks as code spans with neither language nor line breaks, headings
+// and quotes as paragraphs with their markers in the text, and tables nested
+// in the cell as a paragraph of markdown for each of their rows.
+const cellParagraphSeparator = "<br>"
+
+// cellBullet marks the items of a bullet list in a table cell.
+const cellBullet = "• "
+
 // MarkdownTranslatorOption is a functional option for MarkdownTranslator.
 type MarkdownTranslatorOption func(*MarkdownTranslator)
 
 // NewMarkdownTranslator constructs markdown translator.
 func NewMarkdownTranslator(opts ...MarkdownTranslatorOption) *MarkdownTranslator {
-	tr := MarkdownTranslator{
-		list: struct {
-			ol, ul  map[int]bool
-			depthO  int
-			depthU  int
-			counter map[int]int
-		}{
-			ol:      make(map[int]bool),
-			ul:      make(map[int]bool),
-			counter: make(map[int]int),
-		},
-	}
+	tr := MarkdownTranslator{}
 
 	for _, opt := range opts {
 		opt(&tr)
@@ -270,6 +855,14 @@ func WithMarkdownCloseHooks(hooks nodeTypeHook) MarkdownTranslatorOption {
 	}
 }
 
+// WithMarkdownWrapHooks sets hooks post-processing the rendered content of
+// nodes of a markdown translator, as ContentWrapper does.
+func WithMarkdownWrapHooks(hooks map[adf.NodeType]func(n Connector, content string) string) MarkdownTranslatorOption {
+	return func(tr *MarkdownTranslator) {
+		tr.wrapHooks = hooks
+	}
+}
+
 // WithUserEmailResolver sets a user email resolver function
 func WithUserEmailResolver(resolver UserEmailResolver) MarkdownTranslatorOption {
 	return func(tr *MarkdownTranslator) {
@@ -277,6 +870,46 @@ func WithUserEmailResolver(resolver UserEmailResolver) MarkdownTranslatorOption
 	}
 }
 
+// UnderlineStyle is how a MarkdownTranslator renders underlined text.
+type UnderlineStyle string
+
+const (
+	// UnderlineTag wraps the text in <u> tags, which md2adf reads back. It is
+	// the default.
+	UnderlineTag UnderlineStyle = "u-tag"
+	// UnderlineDrop renders the text alone, for viewers showing tags literally.
+	UnderlineDrop UnderlineStyle = "drop"
+	// UnderlineEmphasis renders the text emphasized, as _text_.
+	UnderlineEmphasis UnderlineStyle = "emphasis"
+)
+
+// WithUnderlineStyle sets how underlined text is rendered.
+func WithUnderlineStyle(style UnderlineStyle) MarkdownTranslatorOption {
+	return func(tr *MarkdownTranslator) {
+		tr.underline = style
+	}
+}
+
+// underlineAs returns the type a mark is rendered as under the underline
+// style, "" if it isn't rendered at all.
+func (tr *MarkdownTranslator) underlineAs(nt adf.NodeType) adf.NodeType {
+	if nt != adf.MarkUnderline {
+		return nt
+	}
+	switch tr.underline {
+	case UnderlineDrop:
+		return ""
+	case UnderlineEmphasis:
+		return adf.MarkEm
+	}
+	return nt
+}
+
+// SetAttachmentKeys implements AttachmentKeyer.
+func (tr *MarkdownTranslator) SetAttachmentKeys(keys map[string]string) {
+	tr.attachmentKeys = keys
+}
+
 // Open implements TagOpener interface.
 //
 // renderTable renders the complete table with proper formatting
@@ -289,6 +922,16 @@ func (tr *MarkdownTranslator) renderTable() string {
 
 	var result strings.Builder
 
+	// A pipe in cell content would split the cell on reparse, and a line
+	// break would end the row
+	for _, row := range tr.table.content {
+		for colIdx, cell := range row {
+			cell = strings.ReplaceAll(cell, hardBreak, " ")
+			cell = strings.TrimSpace(strings.ReplaceAll(cell, "\n", " "))
+			row[colIdx] = strings.ReplaceAll(cell, "|", `\|`)
+		}
+	}
+
 	// Calculate column widths
 	tr.calculateColumnWidths()
 
@@ -297,8 +940,8 @@ func (tr *MarkdownTranslator) renderTable() string {
 		result.WriteString("|")
 		for colIdx, cell := range row {
 			width := tr.table.widths[colIdx]
-			padded := fmt.Sprintf(" %-*s ", width, cell)
-			result.WriteString(padded)
+			padding := strings.Repeat(" ", max(width-displayWidth(cell), 0))
+			result.WriteString(" " + cell + padding + " ")
 			result.WriteString("|")
 		}
 		result.WriteString("\n")
@@ -309,6 +952,14 @@ func (tr *MarkdownTranslator) renderTable() string {
 			for colIdx := range row {
 				width := tr.table.widths[colIdx]
 				separator := strings.Repeat("-", width+2) // +2 for spaces around content
+				if colIdx < len(tr.table.aligns) {
+					switch tr.table.aligns[colIdx] {
+					case "center":
+						separator = ":" + strings.Repeat("-", width) + ":"
+					case "end":
+						separator = strings.Repeat("-", width+1) + ":"
+					}
+				}
 				result.WriteString(separator)
 				result.WriteString("|")
 			}
@@ -337,8 +988,8 @@ func (tr *MarkdownTranslator) calculateColumnWidths() {
 	// Find maximum width for each column
 	for _, row := range tr.table.content {
 		for colIdx, cell := range row {
-			if len(cell) > tr.table.widths[colIdx] {
-				tr.table.widths[colIdx] = len(cell)
+			if w := displayWidth(cell); w > tr.table.widths[colIdx] {
+				tr.table.widths[colIdx] = w
 			}
 		}
 	}
@@ -359,7 +1010,7 @@ func (tr *MarkdownTranslator) addCellContent(content string) {
 
 	currentRow := &tr.table.content[tr.table.rows-1]
 	// Use cols for headers and ccol for regular cells
-	currentCol := tr.table.cols - 1
+	currentCol := max(tr.table.cols-1, 0)
 	if tr.table.ccol > 0 {
 		currentCol = tr.table.ccol - 1
 	}
@@ -373,28 +1024,194 @@ func (tr *MarkdownTranslator) addCellContent(content string) {
 	(*currentRow)[currentCol] += content
 }
 
-// isInTableCell returns true if we're currently inside a table cell
+// cellCodeSpan renders a code block in a table cell as a code span. A cell
+// can't span lines, so the lines of the code are joined by spaces.
+func cellCodeSpan(code string) string {
+	code = strings.ReplaceAll(strings.TrimRight(code, "\n"), "\n", " ")
+	if code == "" {
+		return ""
+	}
+	delim := codeSpanDelimiter(code)
+	return delim + code + reverse(delim)
+}
+
+// alignColumn records the alignment of a table column from the alignment mark
+// of its cell's first block, unless an earlier cell already set it.
+func (tr *MarkdownTranslator) alignColumn(cell Connector, col int) {
+	node, ok := cell.(*adf.ADFNode)
+	if !ok || len(node.Content) == 0 || node.Content[0] == nil {
+		return
+	}
+
+	for _, m := range node.Content[0].Marks {
+		if m.Type != adf.MarkAlignment {
+			continue
+		}
+		align, _ := m.Attrs["align"].(string)
+		for len(tr.table.aligns) <= col {
+			tr.table.aligns = append(tr.table.aligns, "")
+		}
+		if tr.table.aligns[col] == "" {
+			tr.table.aligns[col] = align
+		}
+	}
+}
+
+// isInTableCell returns true if we're currently inside a table cell, even
+// within a list in it
 func (tr *MarkdownTranslator) isInTableCell() bool {
-	return tr.table.inTableCell
+	return slices.ContainsFunc(tr.context, func(c blockContext) bool { return c.kind == contextCell })
+}
+
+// innermostContext returns the kind of the innermost open block context.
+func (tr *MarkdownTranslator) innermostContext() blockContextKind {
+	if n := len(tr.context); n > 0 {
+		return tr.context[n-1].kind
+	}
+	return contextTopLevel
+}
+
+// pushContext opens a block context of the given kind.
+func (tr *MarkdownTranslator) pushContext(kind blockContextKind) {
+	tr.context = append(tr.context, blockContext{kind: kind})
+}
+
+// popContext closes the innermost block context.
+func (tr *MarkdownTranslator) popContext() {
+	if n := len(tr.context); n > 0 {
+		tr.context = tr.context[:n-1]
+	}
+}
+
+// cellContext returns the innermost open table cell context, nil outside
+// table cells.
+func (tr *MarkdownTranslator) cellContext() *blockContext {
+	for i := len(tr.context) - 1; i >= 0; i-- {
+		if tr.context[i].kind == contextCell {
+			return &tr.context[i]
+		}
+	}
+	return nil
+}
+
+// separateQuoteBlock returns the separator to write before a node opened in
+// a quote: a blank line after the previous block, which quoteLines quotes, as
+// the quote content is otherwise read back as a lazy continuation of it. A
+// paragraph or a quote ends with a blank line already.
+func (tr *MarkdownTranslator) separateQuoteBlock(nt adf.NodeType) string {
+	switch {
+	case tr.innermostContext() != contextQuote:
+		return ""
+	case !isListItemBlock(nt) && nt != adf.NodeBulletList && nt != adf.NodeOrderedList && nt != adf.NodeDecisionList:
+		return ""
+	}
+	quote := &tr.context[len(tr.context)-1]
+	separator := ""
+	if quote.blocks > 0 && quote.last != adf.NodeParagraph && quote.last != adf.NodeBlockquote {
+		separator = "\n"
+	}
+	quote.blocks++
+	quote.last = nt
+	return separator
+}
+
+// separateCellBlock returns the separator to write before a node opened in
+// a table cell: the blocks of the cell and the items of its lists each go on
+// a line of their own, the first one aside.
+func (tr *MarkdownTranslator) separateCellBlock(nt adf.NodeType) string {
+	cell := tr.cellContext()
+	switch {
+	case cell == nil:
+		return ""
+	case nt == adf.ChildNodeListItem || nt == adf.ChildNodeDecisionItem:
+	case isListItemBlock(nt) && cell == &tr.context[len(tr.context)-1]:
+		// Blocks nested in a block of the cell, as in a quote, stay with it
+		cell.open++
+		if cell.open > 1 {
+			return ""
+		}
+	default:
+		return ""
+	}
+
+	cell.blocks++
+	if cell.blocks > 1 {
+		return cellParagraphSeparator
+	}
+	return ""
+}
+
+// closeCellBlock closes a block separateCellBlock opened.
+func (tr *MarkdownTranslator) closeCellBlock(nt adf.NodeType) {
+	if cell := tr.cellContext(); cell != nil && isListItemBlock(nt) && cell == &tr.context[len(tr.context)-1] {
+		cell.open--
+	}
 }
 
 func (tr *MarkdownTranslator) Open(n Connector, _ int) string {
 	var tag strings.Builder
 
-	nt, attrs := n.GetType(), n.GetAttributes()
+	nt, attrs := tr.underlineAs(n.GetType()), n.GetAttributes()
+	if nt == "" {
+		return ""
+	}
+
+	if tr.mentionRun && tr.separatesMention(n) {
+		tag.WriteString(" ")
+	}
+
+	// A paragraph right below a table would read back as one of its rows
+	if tr.table.ended && nt == adf.NodeParagraph {
+		tag.WriteString("\n")
+	}
+	tr.table.ended = false
+	// A block right below a list would be read back as part of its last
+	// item; tables start with a blank line already
+	if tr.list.ended && isListItemBlock(nt) && nt != adf.NodeTable {
+		tag.WriteString("\n")
+	}
+	tr.list.ended = false
+
+	// Text is opened before its marks, so a code span can fit its delimiter
+	if node, ok := n.(*adf.ADFNode); ok && nt == adf.ChildNodeText {
+		tr.marks.code = codeSpanDelimiter(node.Text)
+	}
+
+	// Blocks after the first one in a list item are separated by a blank line
+	if n := len(tr.list.blocks); n > 0 && isListItemBlock(nt) && !tr.inDecision() && tr.innermostContext() == contextList {
+		if tr.list.blocks[n-1] > 0 {
+			tag.WriteString("\n")
+		}
+		tr.list.blocks[n-1]++
+	}
+	tag.WriteString(tr.separateQuoteBlock(nt))
+	tag.WriteString(tr.separateCellBlock(nt))
 
-	if hook, ok := tr.openHooks[nt]; ok {
+	hook, hooked := tr.openHooks[nt]
+	if hooked {
 		tag.WriteString(hook(n))
 	} else {
 		switch nt {
+		case adf.NodeHeading:
+			// Headings without a usable level are still headings
+			a, _ := attrs.(map[string]any)
+			tag.WriteString(strings.Repeat("#", adf.HeadingLevel(a)) + " ")
 		case adf.NodeBlockquote:
-			tag.WriteString("> ")
+			// Quotes in a cell are quoted as a whole by WrapContent
+			if !tr.isInTableCell() {
+				tr.pushContext(contextQuote)
+			}
 		case adf.NodeCodeBlock:
-			tag.WriteString("```")
+			if tr.isInTableCell() {
+				// A code span is made of the code by WrapContent
+				tr.marks.last = ""
+				return tag.String()
+			}
+			tag.WriteString(codeFence(n))
 
 			nl := true
 			if attrs != nil {
-				a := attrs.(map[string]interface{})
+				a, _ := attrs.(map[string]interface{})
 				for k := range a {
 					if k == "language" {
 						nl = false
@@ -408,41 +1225,71 @@ func (tr *MarkdownTranslator) Open(n Connector, _ int) string {
 		case adf.NodePanel:
 			tag.WriteString("---\n")
 		case adf.NodeTable:
-			tag.WriteString("\n")
+			if tr.table.inTable {
+				// ADF has no nested tables, but they do come up
+				tr.outerTables = append(tr.outerTables, tr.table)
+				tr.table = tableState{}
+			} else {
+				tag.WriteString("\n")
+			}
 			tr.table.inTable = true
 		case adf.NodeMedia:
-			mediaID := tr.extractMediaID(attrs)
-			if mediaID != "" {
-				tag.WriteString(fmt.Sprintf("\n{attachment:%s}", mediaID))
+			mediaAttrs := tr.extractMediaAttrs(attrs)
+			if key, ok := tr.attachmentKeys[mediaAttrs.ID]; ok && mediaAttrs.ID != "" {
+				tag.WriteString(fmt.Sprintf("\n{attachment:%s%s}", key, attachmentParams(mediaAttrs, tr.mediaLayout)))
+			} else if mediaAttrs.ID != "" {
+				tag.WriteString(fmt.Sprintf("\n{attachment:%s%s}", mediaAttrs.ID, attachmentParams(mediaAttrs, tr.mediaLayout)))
+			} else if mediaAttrs.Type == "external" && mediaAttrs.URL != "" {
+				tag.WriteString(fmt.Sprintf("\n![%s](%s)", mediaAttrs.Alt, linkTarget(map[string]any{"href": mediaAttrs.URL})))
 			} else {
 				tag.WriteString("\n[attachment]")
 			}
+		case adf.NodeMediaSingle:
+			a, _ := attrs.(map[string]any)
+			tr.mediaLayout, _ = a["layout"].(string)
+		case adf.NodeCaption:
+			// On the line below the media, which md2adf attaches it to
+			tag.WriteString("\n" + captionStart)
+		case adf.NodeLayoutSection:
+			tr.layoutColumns = 0
+		case adf.ChildNodeLayoutColumn:
+			// The columns are flattened, one below the other
+			if tr.layoutColumns > 0 {
+				tag.WriteString(layoutSeparator)
+			}
+			tr.layoutColumns++
 		case adf.NodeBulletList:
-			tr.list.depthU++
-			tr.list.ul[tr.list.depthU] = true
+			tr.list.open = append(tr.list.open, listLevel{})
+			tr.pushContext(contextList)
 		case adf.NodeOrderedList:
-			tr.list.depthO++
-			tr.list.ol[tr.list.depthO] = true
+			tr.list.open = append(tr.list.open, listLevel{ordered: true, counter: listOrder(attrs) - 1})
+			tr.pushContext(contextList)
+		case adf.NodeDecisionList:
+			tr.list.open = append(tr.list.open, listLevel{decision: true})
+			tr.pushContext(contextList)
+		case adf.ChildNodeDecisionItem:
+			tag.WriteString("- " + decisionMarker + " ")
 		case adf.ChildNodeListItem:
-			if tr.list.ol[tr.list.depthO] {
-				for i := 0; i < tr.list.depthO-1; i++ {
-					tag.WriteString("    ")
-				}
-				tr.list.counter[tr.list.depthO]++
-				tag.WriteString(fmt.Sprintf("%d. ", tr.list.counter[tr.list.depthO]))
-			} else {
-				for i := 0; i < tr.list.depthU-1; i++ {
-					tag.WriteString("    ")
-				}
-				tag.WriteString("- ")
+			// Nested items are indented by WrapContent of their parent item
+			marker := "- "
+			if n := len(tr.list.open); n > 0 && tr.list.open[n-1].ordered {
+				tr.list.open[n-1].counter++
+				marker = fmt.Sprintf("%d. ", tr.list.open[n-1].counter)
+			} else if tr.isInTableCell() {
+				marker = cellBullet
 			}
+			tag.WriteString(marker)
+			tr.list.blocks = append(tr.list.blocks, 0)
+			tr.list.indents = append(tr.list.indents, strings.Repeat(" ", utf8.RuneCountInString(marker)))
 		case adf.ChildNodeTableHeader:
 			tr.table.cols++
-			tr.table.inTableCell = true
+			tr.pushContext(contextCell)
+			tr.alignColumn(n, tr.table.cols-1)
 			// Don't output anything, content will be captured later
 		case adf.ChildNodeTableCell:
 			tr.table.ccol++
-			tr.table.inTableCell = true
+			tr.pushContext(contextCell)
+			tr.alignColumn(n, tr.table.ccol-1)
 			// Don't output anything, content will be captured later
 		case adf.ChildNodeTableRow:
 			tr.table.rows++
@@ -455,11 +1302,20 @@ func (tr *MarkdownTranslator) Open(n Connector, _ int) string {
 			}
 			tr.table.ccol = 0
 		case adf.InlineNodeHardBreak:
-			tag.WriteString("\n\n")
+			tag.WriteString(hardBreak)
 		case adf.InlineNodeMention:
-			tag.WriteString(" @")
+			tag.WriteString("@")
 			tag.WriteString(tr.setOpenTagAttributesForMention(attrs))
+			tr.marks.last = ""
 			return tag.String() // Return early to avoid double processing
+		case adf.InlineNodeEmoji:
+			tag.WriteString(emojiText(attrs))
+			tr.marks.last = ""
+			return tag.String() // The text attr is rendered by emojiText
+		case adf.InlineNodeStatus:
+			tag.WriteString(statusTag(attrs))
+			tr.marks.last = ""
+			return tag.String() // The text attr is part of the tag
 		case adf.InlineNodeCard:
 			cardURL := tr.extractCardURL(attrs)
 			if cardURL != "" {
@@ -469,99 +1325,411 @@ func (tr *MarkdownTranslator) Open(n Connector, _ int) string {
 			}
 		case adf.MarkUnderline:
 			tag.WriteString("<u>")
-		case adf.MarkStrong:
-			tag.WriteString("**")
-		case adf.MarkEm:
-			tag.WriteString("_")
-		case adf.MarkCode:
-			tag.WriteString("`")
-		case adf.MarkStrike:
-			tag.WriteString("-")
+		case adf.MarkStrong, adf.MarkEm, adf.MarkCode, adf.MarkStrike:
+			tag.WriteString(tr.openDelimiter(nt))
 		case adf.MarkLink:
 			tag.WriteString("[")
 		}
 	}
 
-	tag.WriteString(tr.setOpenTagAttributes(attrs))
+	// A hook renders the whole tag, attributes included
+	if !hooked {
+		tag.WriteString(tr.setOpenTagAttributes(attrs))
+	}
+
+	if _, delimited := markDelimiters[nt]; !delimited && nt != adf.ChildNodeText {
+		tr.marks.last = ""
+	}
 
 	return tag.String()
 }
 
+// inDecision reports whether the innermost open list is a decision list.
+func (tr *MarkdownTranslator) inDecision() bool {
+	n := len(tr.list.open)
+	return n > 0 && tr.list.open[n-1].decision
+}
+
+// attachmentParams renders the parameters of an {attachment:id|...}
+// reference: the size of the media when set and the layout of its
+// mediaSingle unless it is the default, "center".
+func attachmentParams(media MediaAttributes, layout string) string {
+	var params strings.Builder
+	if media.Width > 0 {
+		fmt.Fprintf(&params, "|width=%d", media.Width)
+	}
+	if media.Height > 0 {
+		fmt.Fprintf(&params, "|height=%d", media.Height)
+	}
+	if layout != "" && layout != "center" {
+		params.WriteString("|layout=" + layout)
+	}
+	return params.String()
+}
+
+// closeList pops the innermost open list.
+func (tr *MarkdownTranslator) closeList() {
+	if n := len(tr.list.open); n > 0 {
+		tr.list.open = tr.list.open[:n-1]
+	}
+	tr.popContext()
+}
+
+// listOrder returns the number an ordered list starts at, 1 unless its
+// order attr says otherwise.
+func listOrder(a any) int {
+	attrs, _ := a.(map[string]any)
+	if order, ok := adf.IntAttr(attrs["order"]); ok {
+		return order
+	}
+	return 1
+}
+
+// isListItemBlock reports whether a node is a block that needs separating
+// from a preceding sibling inside a list item. Nested lists stay tight.
+func isListItemBlock(nt adf.NodeType) bool {
+	switch nt {
+	case adf.NodeParagraph, adf.NodeCodeBlock, adf.NodeBlockquote, adf.NodeHeading,
+		adf.NodePanel, adf.NodeExpand, adf.NodeTable, adf.NodeMediaSingle, adf.NodeMediaGroup:
+		return true
+	}
+	return false
+}
+
+// WrapsContent implements ContentWrapper: list item content is indented as a
+// whole, decisions are kept to one line, quote content is quoted line by line, paragraphs drop trailing hard
+// breaks, and expands are fenced. Table cell content is kept for the table
+// and code blocks in it are made code spans.
+func (tr *MarkdownTranslator) WrapsContent(n Connector) bool {
+	if _, ok := tr.wrapHooks[n.GetType()]; ok {
+		return true
+	}
+	switch n.GetType() {
+	case adf.ChildNodeListItem, adf.ChildNodeDecisionItem, adf.NodeBlockquote, adf.NodeParagraph, adf.NodeExpand,
+		adf.ChildNodeTableCell, adf.ChildNodeTableHeader:
+		return true
+	case adf.NodeCodeBlock:
+		return tr.isInTableCell()
+	}
+	return false
+}
+
+// WrapContent implements ContentWrapper. Continuation lines of a list item
+// (following paragraphs, code fences, nested lists) are indented to the
+// content column of its marker, as more would be read back as part of the
+// text or as indented code.
+// A hard break ending a paragraph or decision would read back as a literal
+// backslash, so it is removed. Quote content is quoted by quoteLines and expand content is
+// fenced by expandFence.
+func (tr *MarkdownTranslator) WrapContent(n Connector, content string) string {
+	if hook, ok := tr.wrapHooks[n.GetType()]; ok {
+		return hook(n, content)
+	}
+	switch n.GetType() {
+	case adf.ChildNodeTableCell, adf.ChildNodeTableHeader:
+		// Tables are rendered as a whole once closed
+		tr.addCellContent(content)
+		return ""
+	case adf.NodeCodeBlock:
+		return cellCodeSpan(content)
+	}
+	if n.GetType() == adf.NodeExpand {
+		return expandFence(n, content)
+	}
+	if n.GetType() == adf.NodeBlockquote {
+		if tr.isInTableCell() {
+			// Quoted as a whole, a cell being a single line
+			return "> " + strings.ReplaceAll(strings.TrimRight(content, "\n"), "\n", " ")
+		}
+		return quoteLines(content)
+	}
+	if n.GetType() == adf.NodeParagraph {
+		for strings.HasSuffix(content, hardBreak) {
+			content = strings.TrimSuffix(content, hardBreak)
+		}
+		return content
+	}
+	if n.GetType() == adf.ChildNodeDecisionItem {
+		// Its paragraphs close with hard breaks, which must not end it. The
+		// lines after a hard break continue the decision unindented, as
+		// indentation would be read back as part of the text.
+		for strings.HasSuffix(content, hardBreak) {
+			content = strings.TrimSuffix(content, hardBreak)
+		}
+		return content + "\n"
+	}
+
+	indent := "    "
+	if n := len(tr.list.indents); n > 0 {
+		indent = tr.list.indents[n-1]
+	}
+	lines := strings.Split(content, "\n")
+	for i := 1; i < len(lines); i++ {
+		if lines[i] != "" {
+			lines[i] = indent + lines[i]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// quoteLines prefixes every line of quote content with "> ", and blank lines
+// between its blocks with ">", so that lists, code fences and following
+// paragraphs stay inside the quote when read back.
+func quoteLines(content string) string {
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	for i, line := range lines {
+		if line == "" {
+			lines[i] = ">"
+		} else {
+			lines[i] = "> " + line
+		}
+	}
+	return strings.Join(lines, "\n") + "\n\n"
+}
+
+// codeFence returns the fence of a code block: three backticks, or more
+// when the code has a run of backticks as long, so that it isn't closed early.
+func codeFence(n Connector) string {
+	longest := 0
+	if node, ok := n.(*adf.ADFNode); ok {
+		for _, child := range node.Content {
+			if child != nil {
+				longest = max(longest, longestBacktickRun(child.Text))
+			}
+		}
+	}
+	return strings.Repeat("`", max(longest+1, 3))
+}
+
+// longestBacktickRun returns the length of the longest run of backticks in s.
+func longestBacktickRun(s string) int {
+	longest, run := 0, 0
+	for _, c := range s {
+		if c == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return longest
+}
+
+// expandFence fences expand content with "{expand:title=...}" and
+// "{/expand}" markers. Each marker is set apart by blank lines so it reads
+// back as a paragraph of its own rather than continuing a list or table.
+func expandFence(n Connector, content string) string {
+	var fence strings.Builder
+
+	fence.WriteString("{expand")
+	if attrs, ok := n.GetAttributes().(map[string]any); ok {
+		if title, _ := attrs["title"].(string); title != "" {
+			fence.WriteString(":title=" + sanitize(strings.ReplaceAll(title, "\n", " ")))
+		}
+	}
+	fence.WriteString("}\n\n")
+
+	if content = strings.Trim(content, "\n"); content != "" {
+		fence.WriteString(content + "\n\n")
+	}
+	fence.WriteString("{/expand}\n\n")
+
+	return fence.String()
+}
+
+// openDelimiter picks the delimiter variant for a mark that doesn't collide
+// with the delimiters written right before it, and remembers it for closing.
+// When every variant collides, one continuing the last delimiter is used if
+// that opened a mark, and one differing from it if it closed one:
+// "**a**___b___" and "_**a**_*b*" read back as intended, "**a**__*b*__" and
+// "_**a**__b_" don't.
+func (tr *MarkdownTranslator) openDelimiter(nt adf.NodeType) string {
+	variants := markDelimiters[nt]
+	delim := variants[0]
+	if nt == adf.MarkCode && tr.marks.code != "" {
+		delim = tr.marks.code
+	} else if run := tr.marks.last; run != "" {
+		delim = ""
+		for _, v := range variants {
+			if !strings.Contains(run, v[:1]) {
+				delim = v
+				break
+			}
+		}
+		for _, v := range variants {
+			if delim == "" && (v[0] == run[len(run)-1]) == tr.marks.opening {
+				delim = v
+			}
+		}
+		if delim == "" {
+			delim = variants[0]
+		}
+	}
+
+	tr.marks.open = append(tr.marks.open, delim)
+	tr.marks.last += delim
+	tr.marks.opening = true
+	return delim
+}
+
+// codeSpanDelimiter returns the opening delimiter of a code span of text:
+// a backtick run longer than any in the text, padded with a space when the
+// text would otherwise merge with it or lose a space on reparse.
+func codeSpanDelimiter(text string) string {
+	delim := strings.Repeat("`", longestBacktickRun(text)+1)
+	if strings.HasPrefix(text, "`") || strings.HasSuffix(text, "`") ||
+		strings.HasPrefix(text, " ") && strings.HasSuffix(text, " ") && strings.TrimSpace(text) != "" {
+		delim += " "
+	}
+	return delim
+}
+
+// reverse returns s with its bytes in reverse order.
+func reverse(s string) string {
+	b := []byte(s)
+	slices.Reverse(b)
+	return string(b)
+}
+
+// closeDelimiter returns the delimiter the innermost open mark was opened with.
+func (tr *MarkdownTranslator) closeDelimiter(nt adf.NodeType) string {
+	delim := markDelimiters[nt][0]
+	if n := len(tr.marks.open); n > 0 {
+		// The padding of a code span delimiter goes inside the span
+		delim = reverse(tr.marks.open[n-1])
+		tr.marks.open = tr.marks.open[:n-1]
+	}
+
+	tr.marks.last += delim
+	tr.marks.opening = false
+	return delim
+}
+
 // Close implements TagCloser interface.
 //
 //nolint:gocyclo
 func (tr *MarkdownTranslator) Close(n Connector) string {
 	var tag strings.Builder
 
-	nt := n.GetType()
+	nt := tr.underlineAs(n.GetType())
+	if nt == "" {
+		return ""
+	}
+
+	// Only text continues the run of a mention
+	if nt != adf.ChildNodeText {
+		tr.mentionRun = false
+	}
+	// Nor is a list the last thing rendered once what it is in closes
+	tr.list.ended = false
+	tr.closeCellBlock(nt)
 
 	if hook, ok := tr.closeHooks[nt]; ok {
 		tag.WriteString(hook(n))
 	} else {
 		switch nt {
 		case adf.NodeBlockquote:
-			tag.WriteString("\n")
+			if !tr.isInTableCell() {
+				tr.popContext()
+				tag.WriteString("\n")
+			}
 		case adf.NodeCodeBlock:
-			tag.WriteString("\n```\n")
+			if !tr.isInTableCell() {
+				tag.WriteString("\n" + codeFence(n) + "\n")
+			}
 		case adf.NodePanel:
 			tag.WriteString("---\n")
 		case adf.NodeHeading:
-			tag.WriteString("\n")
-		case adf.NodeBulletList:
-			tr.list.ul[tr.list.depthU] = false
-			tr.list.depthU--
-		case adf.NodeOrderedList:
-			tr.list.ol[tr.list.depthO] = false
-			tr.list.depthO--
+			if !tr.isInTableCell() {
+				tag.WriteString("\n")
+			}
+		case adf.NodeCaption:
+			tag.WriteString(captionEnd)
+		case adf.NodeMediaSingle, adf.NodeMediaGroup:
+			tr.mediaLayout = ""
+			tag.WriteString("\n\n")
+		case adf.NodeBulletList, adf.NodeOrderedList:
+			tr.closeList()
+			tr.list.ended = tr.innermostContext() == contextTopLevel
+		case adf.NodeDecisionList:
+			tr.closeList()
+			// Unlike list items, a paragraph right below would continue
+			// the last decision
+			if len(tr.list.open) == 0 {
+				tag.WriteString("\n")
+			}
 		case adf.NodeParagraph:
-			if tr.list.ul[tr.list.depthU] || tr.list.ol[tr.list.depthO] {
+			switch {
+			case tr.isInTableCell():
+				// Blocks of a cell are separated as the next one opens,
+				// paragraphs nested in them by a line break the table
+				// renders as a space
+				if tr.cellContext().open > 0 {
+					tag.WriteString("\n")
+				}
+			case tr.inDecision():
+				// Paragraphs of a decision are joined into one line
+				tag.WriteString(hardBreak)
+			case tr.innermostContext() == contextList:
 				tag.WriteString("\n")
-			} else if tr.table.rows == 0 {
-				tag.WriteString("\n\n")
+			default:
+				if node, ok := n.(*adf.ADFNode); ok && len(node.Content) == 0 {
+					// An empty paragraph is an extra blank line, which
+					// md2adf.WithEmptyParagraphs reads back as one
+					tag.WriteString("\n")
+				} else {
+					tag.WriteString("\n\n")
+				}
 			}
 		case adf.NodeTable:
 			// Render the complete table with proper formatting
-			tag.WriteString(tr.renderTable())
-			// Reset table state
-			tr.table.rows = 0
-			tr.table.cols = 0
-			tr.table.sep = false
-			tr.table.content = nil
-			tr.table.widths = nil
-			tr.table.inTable = false
-			tr.table.inTableCell = false
-		case adf.ChildNodeTableHeader:
-			tr.table.inTableCell = false
-		case adf.ChildNodeTableCell:
-			tr.table.inTableCell = false
+			table := tr.renderTable()
+			if n := len(tr.outerTables); n > 0 {
+				// A nested table goes in the cell of the outer one, its
+				// rows being the lines of the cell
+				tag.WriteString(strings.ReplaceAll(strings.TrimSuffix(table, "\n"), "\n", cellParagraphSeparator))
+				tr.table = tr.outerTables[n-1]
+				tr.outerTables = tr.outerTables[:n-1]
+			} else {
+				tag.WriteString(table)
+				tr.table = tableState{ended: true}
+			}
+		case adf.ChildNodeListItem:
+			if n := len(tr.list.blocks); n > 0 {
+				tr.list.blocks = tr.list.blocks[:n-1]
+				tr.list.indents = tr.list.indents[:n-1]
+			}
+		case adf.ChildNodeTableHeader, adf.ChildNodeTableCell:
+			tr.popContext()
 		case adf.ChildNodeTableRow:
 			// Table rows are handled in renderTable()
 		case adf.InlineNodeMention:
-			tag.WriteString(" ")
-		case adf.InlineNodeEmoji:
-			tag.WriteString(" ")
+			tr.mentionRun = true
 		case adf.MarkUnderline:
 			tag.WriteString("</u>")
-		case adf.MarkStrong:
-			tag.WriteString("**")
-		case adf.MarkEm:
-			tag.WriteString("_")
-		case adf.MarkCode:
-			tag.WriteString("`")
-		case adf.MarkStrike:
-			tag.WriteString("-")
+		case adf.MarkStrong, adf.MarkEm, adf.MarkCode, adf.MarkStrike:
+			tag.WriteString(tr.closeDelimiter(nt))
 		case adf.MarkLink:
-			tag.WriteString("]")
+			attrs, _ := n.GetAttributes().(map[string]any)
+			tag.WriteString("](" + linkTarget(attrs) + ")")
 		}
 	}
 
-	tag.WriteString(tr.setCloseTagAttributes(n.GetAttributes()))
+	if _, delimited := markDelimiters[nt]; !delimited && nt != adf.ChildNodeText {
+		tr.marks.last = ""
+	}
+	// Text separates whatever delimiters surround it; the marks of text are
+	// closed after it
+	if node, ok := n.(*adf.ADFNode); ok && nt == adf.ChildNodeText && node.Text != "" {
+		tr.marks.last = ""
+	}
 
 	return tag.String()
 }
 
 func (tr *MarkdownTranslator) setOpenTagAttributes(a interface{}) string {
-	if a == nil {
+	attrs, _ := a.(map[string]interface{})
+	if len(attrs) == 0 {
 		return ""
 	}
 
@@ -570,48 +1738,76 @@ func (tr *MarkdownTranslator) setOpenTagAttributes(a interface{}) string {
 		nl  bool
 	)
 
-	attrs := a.(map[string]interface{})
-	for k, v := range attrs {
-		if tr.isValidAttr(k) {
-			switch k {
-			case "language":
-				tag.WriteString(fmt.Sprintf("%s", v))
-				nl = true
-			case "level":
-				for i := 0; i < int(v.(float64)); i++ {
-					tag.WriteString("#")
-				}
-				tag.WriteString(" ")
-			case "text":
-				tag.WriteString(fmt.Sprintf("%s", v))
-				nl = false
-			}
+	// Keys are sorted for a stable output
+	for _, k := range slices.Sorted(maps.Keys(attrs)) {
+		if !tr.isValidAttr(k) {
+			continue
 		}
-		if nl {
-			tag.WriteString("\n")
+		switch v := attrs[k]; k {
+		case "language":
+			tag.WriteString(fmt.Sprintf("%s", v))
+			nl = true
+		case "text":
+			tag.WriteString(fmt.Sprintf("%s", v))
+			nl = false
 		}
 	}
+	if nl {
+		tag.WriteString("\n")
+	}
 
 	return tag.String()
 }
 
+// mentionTrailing is the sentence punctuation md2adf leaves out of a mention
+// it follows, as in "@jane@example.com,".
+const mentionTrailing = ".,;:!?)]}'\""
+
+// separatesMention reports whether n, opened in the run of a mention, needs a
+// space before it not to be read back as part of the mention. Whitespace or
+// another mention ends the run; text of mentionTrailing punctuation only
+// leaves it going on.
+func (tr *MarkdownTranslator) separatesMention(n Connector) bool {
+	node, _ := n.(*adf.ADFNode)
+	switch {
+	case n.GetType() == adf.InlineNodeMention:
+		tr.mentionRun = false
+		return false
+	case n.GetType() != adf.ChildNodeText || node == nil || len(node.Marks) > 0:
+		// Anything else starts with a delimiter or syntax of its own
+		tr.mentionRun = false
+		return true
+	}
+
+	for _, r := range escapeMarkdown(sanitize(node.Text)) {
+		switch {
+		case unicode.IsSpace(r) || r == '@':
+			tr.mentionRun = false
+			return false
+		case !strings.ContainsRune(mentionTrailing, r):
+			tr.mentionRun = false
+			return true
+		}
+	}
+	return false
+}
+
 func (tr *MarkdownTranslator) setOpenTagAttributesForMention(a interface{}) string {
 	if a == nil {
 		return ""
 	}
 
-	attrs := a.(map[string]interface{})
+	attrs, _ := a.(map[string]interface{})
 
 	// For mentions, we want to render as @email instead of @displayName
-	if userID, ok := attrs["id"]; ok {
-		if email := tr.resolveUserEmail(userID.(string)); email != "" {
+	if userID, ok := attrs["id"].(string); ok {
+		if email := tr.resolveUserEmail(userID); email != "" {
 			return email
 		}
 	}
 
 	// Fallback to display name if email resolution fails
-	if text, ok := attrs["text"]; ok {
-		textStr := text.(string)
+	if textStr, ok := attrs["text"].(string); ok {
 		if tr.emailResolver != nil {
 			log.Printf("DEBUG: Using fallback text: %s", textStr)
 		}
@@ -625,6 +1821,40 @@ func (tr *MarkdownTranslator) setOpenTagAttributesForMention(a interface{}) stri
 	return ""
 }
 
+// emojiText renders an emoji as its shortcode when md2adf knows it, so it
+// reads back as an emoji node, and as its unicode text otherwise.
+func emojiText(a any) string {
+	attrs, _ := a.(map[string]any)
+	shortName, _ := attrs["shortName"].(string)
+	text, _ := attrs["text"].(string)
+
+	if _, known := adf.EmojiText(shortName); known || text == "" {
+		return shortName
+	}
+	return text
+}
+
+// statusTag renders a status lozenge as "{status:color=green|localId=...}TEXT{/status}".
+func statusTag(a any) string {
+	attrs, _ := a.(map[string]any)
+	text, _ := attrs["text"].(string)
+
+	var params []string
+	for _, k := range []string{"color", "localId"} {
+		if v, ok := attrs[k].(string); ok && v != "" {
+			params = append(params, k+"="+v)
+		}
+	}
+
+	var tag strings.Builder
+	tag.WriteString("{status")
+	if len(params) > 0 {
+		tag.WriteString(":" + strings.Join(params, "|"))
+	}
+	tag.WriteString("}" + sanitize(text) + "{/status}")
+	return tag.String()
+}
+
 // resolveUserEmail attempts to resolve a user ID to email
 func (tr *MarkdownTranslator) resolveUserEmail(userID string) string {
 	if tr.emailResolver != nil {
@@ -633,23 +1863,22 @@ func (tr *MarkdownTranslator) resolveUserEmail(userID string) string {
 	return ""
 }
 
-func (*MarkdownTranslator) setCloseTagAttributes(a interface{}) string {
-	if a == nil {
-		return ""
+// linkTarget renders the destination of a link mark and its title, if any.
+// A destination with spaces or unbalanced parentheses is wrapped in angle
+// brackets.
+func linkTarget(attrs map[string]any) string {
+	href, _ := attrs["href"].(string)
+	if strings.ContainsAny(href, " <>") || strings.Count(href, "(") != strings.Count(href, ")") {
+		href = "<" + strings.NewReplacer("<", `\<`, ">", `\>`).Replace(href) + ">"
 	}
-
-	var tag strings.Builder
-
-	attrs := a.(map[string]interface{})
-	if h, ok := attrs["href"]; ok {
-		tag.WriteString(fmt.Sprintf("(%s) ", h))
+	if title, _ := attrs["title"].(string); title != "" {
+		href += ` "` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(title) + `"`
 	}
-
-	return tag.String()
+	return href
 }
 
 func (*MarkdownTranslator) isValidAttr(attr string) bool {
-	known := []string{"language", "level", "text"}
+	known := []string{"language", "text"}
 	for _, k := range known {
 		if k == attr {
 			return true
@@ -658,52 +1887,19 @@ func (*MarkdownTranslator) isValidAttr(attr string) bool {
 	return false
 }
 
-// extractMediaID extracts the media ID from attributes
-func (*MarkdownTranslator) extractMediaID(attrs interface{}) string {
-	if attrs == nil {
-		return ""
-	}
-
-	jsonBytes, err := json.Marshal(attrs)
-	if err != nil {
-		return ""
-	}
-
-	var mediaAttrs MediaAttributes
-	if err := json.Unmarshal(jsonBytes, &mediaAttrs); err != nil {
-		return ""
-	}
-
-	return mediaAttrs.ID
+// extractMediaAttrs extracts the media attributes (ID, or URL for external media)
+func (*MarkdownTranslator) extractMediaAttrs(attrs interface{}) MediaAttributes {
+	a, _ := attrs.(map[string]any)
+	return adf.ParseMediaAttributes(a)
 }
 
 // extractCardURL extracts the inline card URL from attributes
 func (*MarkdownTranslator) extractCardURL(attrs interface{}) string {
-	if attrs == nil {
-		return ""
-	}
-
-	jsonBytes, err := json.Marshal(attrs)
-	if err != nil {
-		return ""
-	}
-
-	var inlineCardAttrs InlineCardAttributes
-	if err := json.Unmarshal(jsonBytes, &inlineCardAttrs); err != nil {
-		return ""
-	}
-
-	return inlineCardAttrs.URL
+	a, _ := attrs.(map[string]any)
+	url, _ := a["url"].(string)
+	return url
 }
 
-const (
-	panelTypeInfo    = "info"
-	panelTypeNote    = "note"
-	panelTypeError   = "error"
-	panelTypeSuccess = "success"
-	panelTypeWarning = "warning"
-)
-
 // JiraMarkdownTranslator is a jira markdown translator.
 type JiraMarkdownTranslator struct {
 	*MarkdownTranslator
@@ -742,29 +1938,39 @@ func (tr *JiraMarkdownTranslator) Close(n Connector) string {
 }
 
 func nodePanelOpenHook(n Connector) string {
-	attrs := n.GetAttributes()
+	attrs, _ := n.GetAttributes().(map[string]any)
+
+	// The type goes first, the other attrs follow sorted by key so that the
+	// output is stable
+	var params []string
+	if panelType, ok := attrs["panelType"]; ok {
+		params = append(params, fmt.Sprintf("type=%v", panelType))
+	}
+	for _, k := range slices.Sorted(maps.Keys(attrs)) {
+		if k != "panelType" {
+			params = append(params, fmt.Sprintf("%s=%v", k, attrs[k]))
+		}
+	}
 
 	var tag strings.Builder
 
 	tag.WriteString("\n{panel")
-	if attrs != nil {
-		a := attrs.(map[string]any)
-		if len(a) > 0 {
-			tag.WriteString(":")
-		}
-		for k, v := range a {
-			if k == "panelType" {
-				tag.WriteString(fmt.Sprintf("type=%s", v.(string)))
-			} else {
-				tag.WriteString(fmt.Sprintf("|%s=%s", k, v))
-			}
-		}
+	if len(params) > 0 {
+		tag.WriteString(":" + strings.Join(params, "|"))
 	}
 	tag.WriteString("}\n")
 
 	return tag.String()
 }
 
-func nodePanelCloseHook(Connector) string {
+func nodePanelCloseHook(n Connector) string {
+	// A closing fence or table row right above the end marker would swallow it
+	// on reparse, so it is set apart by a blank line
+	if node, ok := n.(*adf.ADFNode); ok && len(node.Content) > 0 && node.Content[len(node.Content)-1] != nil {
+		switch node.Content[len(node.Content)-1].Type {
+		case adf.NodeCodeBlock, adf.NodeTable:
+			return "\n{/panel}\n"
+		}
+	}
 	return "{/panel}\n"
 }
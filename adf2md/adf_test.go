@@ -2,12 +2,15 @@ package adf2md
 
 import (
 	"encoding/json"
+	"errors"
 	"github.com/jorres/md2adf-translator/adf"
+	"io"
 	"os"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestADF(t *testing.T) {
@@ -22,11 +25,8 @@ func TestADF(t *testing.T) {
 
 	expected := `# H1
 ## H2
-1. Some text
-
-2. Some more text
-
-
+1\. Some text\
+2\. Some more text
 
 > Blockquote text
 
@@ -39,7 +39,7 @@ Implement epic browser
 Panel paragraph
 
 ---
- @Person A 
+@Person A
 
 ---
 **Strong** Paragraph 1
@@ -55,23 +55,23 @@ _Italic Text_
 
 ` + "`" + `Prefix: Inline Code Block` + "`" + `
 
--Prefix: Strikethrough text-
+~Prefix: Strikethrough text~
 
-[Link](https://ankit.pl) 
+[Link](https://ankit.pl)
 
 - Prefix: Unordered list item 1
-    - Next
-        - Another
-            - New level
+  - Next
+    - Another
+      - New level
 - Unordered list item 2
 - Unordered list item 3
 1. Ordered list item 1
 2. Ordered list item 2
 3. Ordered list item 3
-    1. nested
-        1. second level
-            1. third level
-                1. fourth level
+   1. nested
+      1. second level
+         1. third level
+            1. fourth level
 
 | **Table Header 1**   | **Table Header 2**   | **Table Header 3**   |
 |----------------------|----------------------|----------------------|
@@ -87,6 +87,7 @@ import (
 func main() {
     fmt.Println("Hello, World!")
 }
+
 ` + "```" + `
 
 | **Table Header 1**   | **Table Header 2**   | **Table Header 3**   | **Table Header 4**   | **Table Header 5**   |
@@ -96,7 +97,7 @@ func main() {
 | Table row 1 column 2 | Table row 2 column 3 | Table row 3 column 3 | Table row 4 column 3 | Table row 5 column 3 |
 `
 
-	assert.Equal(t, expected, tr.Translate(&adf))
+	assert.Equal(t, expected, tr.MustTranslate(&adf))
 }
 
 func TestADFReplaceAll(t *testing.T) {
@@ -115,3 +116,1059 @@ func TestADFReplaceAll(t *testing.T) {
 	assert.False(t, strings.Contains(string(dump), "Prefix:"))
 	assert.True(t, strings.Contains(string(dump), "Replaced:"))
 }
+
+func TestExternalMediaRendering(t *testing.T) {
+	mediaSingle := adf.NewMediaSingleNode("center")
+	mediaSingle.Content = append(mediaSingle.Content, adf.NewExternalMediaNode("https://example.com/pic.png", "A picture"))
+
+	tr := NewTranslator(NewMarkdownTranslator())
+	result := tr.MustTranslate(&adf.ADFNode{Type: "doc", Content: []*adf.ADFNode{mediaSingle}})
+
+	assert.Equal(t, "\n![A picture](https://example.com/pic.png)\n\n", result)
+	assert.Same(t, mediaSingle, tr.GetMediaMapping()["https://example.com/pic.png"])
+}
+
+func TestMediaGroupMapping(t *testing.T) {
+	group := &adf.ADFNode{Type: adf.NodeMediaGroup}
+	for _, id := range []string{"file-1", "file-2", "file-3"} {
+		group.Content = append(group.Content, &adf.ADFNode{Type: adf.NodeMedia, Attrs: map[string]any{"id": id, "type": "file"}})
+	}
+	empty := &adf.ADFNode{Type: adf.NodeMediaGroup}
+
+	tr := NewTranslator(NewMarkdownTranslator())
+	result := tr.MustTranslate(&adf.ADFNode{Type: "doc", Content: []*adf.ADFNode{group, empty}})
+
+	assert.Contains(t, result, "{attachment:file-1}\n{attachment:file-2}\n{attachment:file-3}")
+	assert.Len(t, tr.GetMediaMapping(), 3)
+	for _, id := range []string{"file-1", "file-2", "file-3"} {
+		assert.Same(t, group, tr.GetMediaMapping()[id], id)
+	}
+}
+
+func TestAdjacentMarkRendering(t *testing.T) {
+	strong := func() *adf.ADFMark { return adf.NewStrongMark() }
+	em := func() *adf.ADFMark { return adf.NewEmphasisMark() }
+
+	tests := []struct {
+		name     string
+		nodes    []*adf.ADFNode
+		expected string
+	}{
+		{
+			name: "identical marks are merged",
+			nodes: []*adf.ADFNode{
+				adf.NewTextNodeWithMarks("a", []*adf.ADFMark{strong()}),
+				adf.NewTextNodeWithMarks("b", []*adf.ADFMark{strong()}),
+			},
+			expected: "**ab**\n\n",
+		},
+		{
+			name: "different marks keep their default delimiters",
+			nodes: []*adf.ADFNode{
+				adf.NewTextNodeWithMarks("bold", []*adf.ADFMark{strong()}),
+				adf.NewTextNodeWithMarks("italic", []*adf.ADFMark{em()}),
+			},
+			expected: "**bold**_italic_\n\n",
+		},
+		{
+			name: "touching strong switches variant",
+			nodes: []*adf.ADFNode{
+				adf.NewTextNodeWithMarks("a", []*adf.ADFMark{strong()}),
+				adf.NewTextNodeWithMarks("b", []*adf.ADFMark{strong(), em()}),
+			},
+			expected: "**a**___b___\n\n",
+		},
+		{
+			name: "touching emphasis switches variant",
+			nodes: []*adf.ADFNode{
+				adf.NewTextNodeWithMarks("a", []*adf.ADFMark{em()}),
+				adf.NewTextNodeWithMarks("b", []*adf.ADFMark{adf.NewStrikethroughMark(), em()}),
+			},
+			expected: "_a_*~b~*\n\n",
+		},
+		{
+			name: "plain text between spans resets the variant",
+			nodes: []*adf.ADFNode{
+				adf.NewTextNodeWithMarks("a", []*adf.ADFMark{strong()}),
+				adf.NewTextNode(" and "),
+				adf.NewTextNodeWithMarks("b", []*adf.ADFMark{strong(), em()}),
+			},
+			expected: "**a** and **_b_**\n\n",
+		},
+		{
+			name: "shared outer marks stay open",
+			nodes: []*adf.ADFNode{
+				adf.NewTextNodeWithMarks("bold ", []*adf.ADFMark{strong()}),
+				adf.NewTextNodeWithMarks("code", []*adf.ADFMark{adf.NewCodeMark(), strong()}),
+				adf.NewTextNodeWithMarks(" inside", []*adf.ADFMark{strong()}),
+			},
+			expected: "**bold `code` inside**\n\n",
+		},
+		{
+			name: "a link stays open over formatted text",
+			nodes: []*adf.ADFNode{
+				adf.NewTextNodeWithMarks("bold", []*adf.ADFMark{adf.NewLinkMark("https://example.com"), strong()}),
+				adf.NewTextNodeWithMarks(" link", []*adf.ADFMark{adf.NewLinkMark("https://example.com")}),
+				adf.NewTextNodeWithMarks(" other", []*adf.ADFMark{adf.NewLinkMark("https://example.org")}),
+			},
+			expected: "[**bold** link](https://example.com)[ other](https://example.org)\n\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paragraph := adf.NewParagraphNode()
+			paragraph.Content = tt.nodes
+
+			tr := NewTranslator(NewMarkdownTranslator())
+			result := tr.MustTranslate(&adf.ADFNode{Type: "doc", Content: []*adf.ADFNode{paragraph}})
+
+			assert.Equal(t, tt.expected, result)
+			assert.Len(t, paragraph.Content, len(tt.nodes), "source nodes must not be modified")
+		})
+	}
+}
+
+func TestListItemContinuationBlocks(t *testing.T) {
+	paragraph := func(text string) *adf.ADFNode {
+		p := adf.NewParagraphNode()
+		p.Content = append(p.Content, adf.NewTextNode(text))
+		return p
+	}
+
+	code := adf.NewCodeBlockNode("bash")
+	code.Content = append(code.Content, adf.NewTextNode("make build\nmake test"))
+
+	first := adf.NewListItemNode()
+	first.Content = append(first.Content, paragraph("First paragraph"), paragraph("Second paragraph"))
+	second := adf.NewListItemNode()
+	second.Content = append(second.Content, paragraph("Run:"), code)
+
+	list := adf.NewOrderedListNode(1)
+	list.Content = append(list.Content, first, second)
+
+	tr := NewTranslator(NewMarkdownTranslator())
+	result := tr.MustTranslate(&adf.ADFNode{Type: "doc", Content: []*adf.ADFNode{list}})
+
+	expected := "1. First paragraph\n" +
+		"\n" +
+		"   Second paragraph\n" +
+		"2. Run:\n" +
+		"\n" +
+		"   ```bash\n" +
+		"   make build\n" +
+		"   make test\n" +
+		"   ```\n"
+	assert.Equal(t, expected, result)
+}
+
+func TestSharedSubtreeRendering(t *testing.T) {
+	shared := adf.NewParagraphNode()
+	shared.Content = append(shared.Content, adf.NewTextNode("again"))
+
+	tr := NewTranslator(NewMarkdownTranslator())
+	out := tr.MustTranslate(&adf.ADFNode{Type: "doc", Content: []*adf.ADFNode{shared, shared}})
+
+	assert.Equal(t, 2, strings.Count(out, "again"))
+	assert.NoError(t, tr.Err())
+}
+
+func TestCyclicDocument(t *testing.T) {
+	item := adf.NewListItemNode()
+	list := adf.NewBulletListNode()
+	list.Content = append(list.Content, item)
+	paragraph := adf.NewParagraphNode()
+	paragraph.Content = append(paragraph.Content, adf.NewTextNode("loop"))
+	item.Content = append(item.Content, paragraph, list)
+
+	tr := NewTranslator(NewMarkdownTranslator())
+	out, err := tr.Translate(&adf.ADFNode{Type: "doc", Content: []*adf.ADFNode{list}})
+
+	assert.Equal(t, 1, strings.Count(out, "loop"))
+	assert.ErrorIs(t, err, adf.ErrCycle)
+	assert.Contains(t, err.Error(), "/content/0/content/0/content/1")
+	assert.Equal(t, err, tr.Err())
+
+	// The error is cleared by the next call
+	tr.Translate(&adf.ADFNode{Type: "doc", Content: []*adf.ADFNode{paragraph}})
+	assert.NoError(t, tr.Err())
+}
+
+func TestDeeplyNestedDocument(t *testing.T) {
+	root := adf.NewBlockquoteNode()
+	n := root
+	for range maxVisitDepth + 10 {
+		child := adf.NewBlockquoteNode()
+		n.Content = append(n.Content, child)
+		n = child
+	}
+
+	tr := NewTranslator(NewMarkdownTranslator())
+	tr.Translate(&adf.ADFNode{Type: "doc", Content: []*adf.ADFNode{root}})
+
+	assert.Error(t, tr.Err())
+	assert.NotErrorIs(t, tr.Err(), adf.ErrCycle)
+}
+
+func TestHardBreakRendering(t *testing.T) {
+	paragraph := adf.NewParagraphNode()
+	paragraph.Content = append(paragraph.Content,
+		adf.NewTextNode("one"),
+		adf.NewHardBreakNode(),
+		adf.NewTextNode("two"),
+		adf.NewHardBreakNode(),
+	)
+
+	tr := NewTranslator(NewMarkdownTranslator())
+	assert.Equal(t, "one\\\ntwo\n\n", tr.MustTranslate(&adf.ADFNode{Type: "doc", Content: []*adf.ADFNode{paragraph}}))
+}
+
+func TestPanelEndingInCodeBlock(t *testing.T) {
+	codeBlock := adf.NewCodeBlockNode("")
+	codeBlock.Content = append(codeBlock.Content, adf.NewTextNode("stack trace"))
+	panel := adf.NewPanelNode("error")
+	panel.Content = append(panel.Content, codeBlock)
+
+	tr := NewTranslator(NewJiraMarkdownTranslator())
+	out := tr.MustTranslate(&adf.ADFNode{Type: "doc", Content: []*adf.ADFNode{panel}})
+
+	assert.Equal(t, "\n{panel:type=error}\n```\nstack trace\n```\n\n{/panel}\n", out)
+}
+
+func TestPanelAttributeOrder(t *testing.T) {
+	panel := adf.NewPanelNode("info")
+	panel.Attrs["title"] = "Note"
+	panel.Attrs["borderColor"] = "red"
+	panel.Content = append(panel.Content, adf.NewParagraphNode())
+
+	// Map iteration order varies between runs, the output must not
+	for range 20 {
+		out := NewTranslator(NewJiraMarkdownTranslator()).MustTranslate(&adf.ADFNode{Type: "doc", Content: []*adf.ADFNode{panel}})
+		assert.True(t, strings.HasPrefix(out, "\n{panel:type=info|borderColor=red|title=Note}\n"), out)
+	}
+}
+
+func TestBlockquoteNestedBlocks(t *testing.T) {
+	doc := adf.NewDocBuilder().
+		Blockquote(
+			adf.Paragraph(adf.Text("First")),
+			adf.BulletList(adf.Item(adf.Text("a")), adf.Item(adf.Text("b"))),
+			adf.CodeBlock("go", "x := 1"),
+			adf.Paragraph(adf.Text("Last")),
+		).
+		Paragraph(adf.Text("After")).
+		Build()
+
+	out, err := NewTranslator(NewMarkdownTranslator()).TranslateDocument(doc)
+	assert.NoError(t, err)
+	assert.Equal(t, "> First\n>\n> - a\n> - b\n>\n> ```go\n> x := 1\n> ```\n>\n> Last\n\n\nAfter\n\n", out)
+}
+
+func TestEmptyParagraphs(t *testing.T) {
+	doc := adf.NewDocBuilder().
+		Paragraph(adf.Text("First")).
+		Paragraph().
+		Paragraph().
+		Paragraph(adf.Text("Second")).
+		Build()
+
+	out, err := NewTranslator(NewMarkdownTranslator()).TranslateDocument(doc)
+	assert.NoError(t, err)
+	assert.Equal(t, "First\n\n\n\nSecond\n\n", out)
+}
+
+func TestCodeBlockLanguagePassedThrough(t *testing.T) {
+	doc := adf.NewDocBuilder().
+		CodeBlock("golang", "x := 1").
+		CodeBlock("brainfuck", "+.").
+		Build()
+
+	out, err := NewTranslator(NewMarkdownTranslator()).TranslateDocument(doc)
+	assert.NoError(t, err)
+	assert.Equal(t, "```golang\nx := 1\n```\n```brainfuck\n+.\n```\n", out)
+}
+
+func TestCodeBlockFenceLength(t *testing.T) {
+	doc := adf.NewDocBuilder().
+		CodeBlock("markdown", "```go\nx\n```").
+		CodeBlock("", "a ````` b").
+		Build()
+
+	out, err := NewTranslator(NewMarkdownTranslator()).TranslateDocument(doc)
+	assert.NoError(t, err)
+	assert.Equal(t, "````markdown\n```go\nx\n```\n````\n``````\na ````` b\n``````\n", out)
+}
+
+func TestInlineCodeDelimiters(t *testing.T) {
+	doc := adf.NewDocBuilder().
+		Paragraph(adf.Code("ls `pwd`"), adf.Text(" and "), adf.Code("a``b")).
+		Paragraph(adf.Code(" padded "), adf.Text(" "), adf.Code("plain")).
+		Build()
+
+	out, err := NewTranslator(NewMarkdownTranslator()).TranslateDocument(doc)
+	assert.NoError(t, err)
+	assert.Equal(t, "`` ls `pwd` `` and ```a``b```\n\n`  padded  ` `plain`\n\n", out)
+}
+
+func TestLinkRendering(t *testing.T) {
+	titled := adf.NewLinkMark("https://x.y")
+	titled.Attrs["title"] = `The "docs"`
+	strong := adf.NewStrongMark()
+	strong.Attrs = map[string]any{"href": "https://not.a.link"}
+
+	doc := adf.NewDocBuilder().
+		Paragraph(adf.Text("see "), adf.Link("docs", "https://x.y"), adf.Text(".")).
+		Paragraph(adf.Styled("docs", titled), adf.Text(", "), adf.Link("spaced", "https://x.y/a b")).
+		Paragraph(adf.Link("paren", "https://x.y/a(b"), adf.Text(" "), adf.Styled("bold", strong)).
+		Build()
+
+	out, err := NewTranslator(NewMarkdownTranslator()).TranslateDocument(doc)
+	assert.NoError(t, err)
+	assert.Equal(t, "see [docs](https://x.y).\n\n"+
+		`[docs](https://x.y "The \"docs\""), [spaced](<https://x.y/a b>)`+"\n\n"+
+		"[paren](<https://x.y/a(b>) **bold**\n\n", out)
+}
+
+func TestUnderlineStyle(t *testing.T) {
+	doc := adf.NewDocBuilder().
+		Paragraph(adf.Text("a "), adf.Underlined("b"), adf.Text(" "), adf.Styled("c", adf.NewEmphasisMark(), adf.NewUnderlineMark())).
+		Build()
+
+	tests := []struct {
+		style    UnderlineStyle
+		expected string
+	}{
+		{"", "a <u>b</u> _<u>c</u>_\n\n"},
+		{UnderlineTag, "a <u>b</u> _<u>c</u>_\n\n"},
+		{UnderlineDrop, "a b _c_\n\n"},
+		{UnderlineEmphasis, "a _b_ _*c*_\n\n"},
+	}
+	for _, tt := range tests {
+		out, err := NewTranslator(NewJiraMarkdownTranslator(WithUnderlineStyle(tt.style))).TranslateDocument(doc)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.expected, out, tt.style)
+	}
+}
+
+func TestTableCellPipeEscaping(t *testing.T) {
+	cell := func(nt adf.NodeType, text string) *adf.ADFNode {
+		paragraph := adf.NewParagraphNode()
+		paragraph.Content = append(paragraph.Content, adf.NewTextNode(text))
+		return &adf.ADFNode{Type: nt, Content: []*adf.ADFNode{paragraph}}
+	}
+	header := adf.NewTableRowNode()
+	header.Content = append(header.Content, cell(adf.ChildNodeTableHeader, "Pattern"))
+	row := adf.NewTableRowNode()
+	row.Content = append(row.Content, cell(adf.ChildNodeTableCell, "a|b"))
+	table := adf.NewTableNode()
+	table.Content = append(table.Content, header, row)
+
+	tr := NewTranslator(NewMarkdownTranslator())
+	out := tr.MustTranslate(&adf.ADFNode{Type: "doc", Content: []*adf.ADFNode{table}})
+
+	assert.Contains(t, out, `| a\|b    |`)
+}
+
+func TestTableDisplayWidth(t *testing.T) {
+	cell := func(nt adf.NodeType, text string) *adf.ADFNode {
+		paragraph := adf.NewParagraphNode()
+		paragraph.Content = append(paragraph.Content, adf.NewTextNode(text))
+		return &adf.ADFNode{Type: nt, Content: []*adf.ADFNode{paragraph}}
+	}
+	row := func(nt adf.NodeType, texts ...string) *adf.ADFNode {
+		r := adf.NewTableRowNode()
+		for _, text := range texts {
+			r.Content = append(r.Content, cell(nt, text))
+		}
+		return r
+	}
+
+	tests := []struct {
+		name  string
+		cells []string
+		width int // display width of the widest cell in the second column
+	}{
+		{"cyrillic", []string{"Привет", "мир"}, 6},
+		{"cjk", []string{"日本語", "中文"}, 6},
+		{"emoji", []string{"🚀 launch", "✅"}, 9},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := adf.NewTableNode()
+			table.Content = append(table.Content,
+				row(adf.ChildNodeTableHeader, "Language", "Sample"),
+				row(adf.ChildNodeTableCell, "ok", tt.cells[0]),
+				row(adf.ChildNodeTableCell, "ok", tt.cells[1]),
+			)
+
+			tr := NewTranslator(NewMarkdownTranslator())
+			out := tr.MustTranslate(&adf.ADFNode{Type: "doc", Content: []*adf.ADFNode{table}})
+
+			lines := strings.Split(strings.TrimSpace(out), "\n")
+			assert.Len(t, lines, 4)
+			assert.Equal(t, "|----------|"+strings.Repeat("-", tt.width+2)+"|", lines[1])
+			for _, line := range lines {
+				assert.Equal(t, displayWidth(lines[1]), displayWidth(line), "misaligned row %q", line)
+			}
+		})
+	}
+}
+
+func TestDisplayWidth(t *testing.T) {
+	assert.Equal(t, 5, displayWidth("hello"))
+	assert.Equal(t, 6, displayWidth("Привет"))
+	assert.Equal(t, 6, displayWidth("日本語"))
+	assert.Equal(t, 2, displayWidth("🚀"))
+	assert.Equal(t, 2, displayWidth("👩\u200d")) // ZWJ takes no column
+	assert.Equal(t, 1, displayWidth("é"))
+}
+
+func TestMarkNestingOrder(t *testing.T) {
+	tests := []struct {
+		name     string
+		marks    []*adf.ADFMark
+		expected string
+	}{
+		{"code inside strong", []*adf.ADFMark{adf.NewCodeMark(), adf.NewStrongMark()}, "**`x`**"},
+		{"link outermost", []*adf.ADFMark{adf.NewEmphasisMark(), adf.NewLinkMark("https://example.com"), adf.NewStrongMark()}, "[**_x_**](https://example.com)"},
+		{"underline inside strike", []*adf.ADFMark{adf.NewUnderlineMark(), adf.NewEmphasisMark(), adf.NewStrikethroughMark()}, "_~<u>x</u>~_"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first := tt.marks[0]
+			paragraph := adf.NewParagraphNode()
+			paragraph.Content = append(paragraph.Content, adf.NewTextNodeWithMarks("x", tt.marks))
+
+			tr := NewTranslator(NewMarkdownTranslator())
+			out := tr.MustTranslate(&adf.ADFNode{Type: "doc", Content: []*adf.ADFNode{paragraph}})
+
+			assert.Equal(t, tt.expected, strings.TrimSpace(out))
+			assert.Same(t, first, tt.marks[0], "the node's own marks are not reordered")
+		})
+	}
+}
+
+func TestTableColumnAlignment(t *testing.T) {
+	cell := func(nt adf.NodeType, text, align string) *adf.ADFNode {
+		paragraph := adf.NewParagraphNode()
+		paragraph.Content = append(paragraph.Content, adf.NewTextNode(text))
+		if align != "" {
+			paragraph.Marks = append(paragraph.Marks, adf.NewAlignmentMark(align))
+		}
+		return &adf.ADFNode{Type: nt, Content: []*adf.ADFNode{paragraph}}
+	}
+
+	header := adf.NewTableRowNode()
+	header.Content = append(header.Content,
+		cell(adf.ChildNodeTableHeader, "Name", ""),
+		cell(adf.ChildNodeTableHeader, "Status", "center"),
+		cell(adf.ChildNodeTableHeader, "Count", "end"),
+	)
+	row := adf.NewTableRowNode()
+	row.Content = append(row.Content,
+		cell(adf.ChildNodeTableCell, "a", ""),
+		cell(adf.ChildNodeTableCell, "ok", "center"),
+		cell(adf.ChildNodeTableCell, "7", "end"),
+	)
+	table := adf.NewTableNode()
+	table.Content = append(table.Content, header, row)
+
+	tr := NewTranslator(NewMarkdownTranslator())
+	out := tr.MustTranslate(&adf.ADFNode{Type: "doc", Content: []*adf.ADFNode{table}})
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	assert.Len(t, lines, 3)
+	assert.Equal(t, "|-------|:------:|------:|", lines[1])
+	assert.NotContains(t, out, "alignment")
+}
+
+func TestExpandRendering(t *testing.T) {
+	list := adf.NewBulletListNode()
+	item := adf.NewListItemNode()
+	paragraph := adf.NewParagraphNode()
+	paragraph.Content = append(paragraph.Content, adf.NewTextNode("item"))
+	item.Content = append(item.Content, paragraph)
+	list.Content = append(list.Content, item)
+
+	intro := adf.NewParagraphNode()
+	intro.Content = append(intro.Content, adf.NewTextNode("Hidden text"))
+
+	expand := adf.NewExpandNode("More details")
+	expand.Content = append(expand.Content, intro, list)
+
+	tr := NewTranslator(NewMarkdownTranslator())
+	out := tr.MustTranslate(&adf.ADFNode{Type: "doc", Content: []*adf.ADFNode{expand}})
+	assert.Equal(t, "{expand:title=More details}\n\nHidden text\n\n- item\n\n{/expand}\n\n", out)
+
+	empty := NewTranslator(NewJiraMarkdownTranslator()).MustTranslate(&adf.ADFNode{Type: "doc", Content: []*adf.ADFNode{adf.NewExpandNode("")}})
+	assert.Equal(t, "{expand}\n\n{/expand}\n\n", empty)
+}
+
+func TestStatusRendering(t *testing.T) {
+	status := adf.NewStatusNode("IN PROGRESS", "blue")
+	status.Attrs["localId"] = "abc"
+	paragraph := adf.NewParagraphNode()
+	paragraph.Content = append(paragraph.Content, adf.NewTextNode("State: "), status, adf.NewStatusNode("NEW", ""))
+
+	tr := NewTranslator(NewMarkdownTranslator())
+	out := tr.MustTranslate(&adf.ADFNode{Type: "doc", Content: []*adf.ADFNode{paragraph}})
+	assert.Equal(t, "State: {status:color=blue|localId=abc}IN PROGRESS{/status}{status}NEW{/status}\n\n", out)
+}
+
+func TestInlineNodesInTableCell(t *testing.T) {
+	paragraph := adf.NewParagraphNode()
+	paragraph.Content = append(paragraph.Content,
+		adf.NewTextNode("See"),
+		adf.NewHardBreakNode(),
+		adf.NewStatusNode("DONE", "green"),
+	)
+	row := adf.NewTableRowNode()
+	row.Content = append(row.Content, &adf.ADFNode{Type: adf.ChildNodeTableHeader, Content: []*adf.ADFNode{paragraph}})
+	table := adf.NewTableNode()
+	table.Content = append(table.Content, row)
+
+	tr := NewTranslator(NewMarkdownTranslator())
+	out := tr.MustTranslate(&adf.ADFNode{Type: "doc", Content: []*adf.ADFNode{table}})
+	assert.Equal(t, "\n| See {status:color=green}DONE{/status} |\n|---------------------------------------|\n", out)
+}
+
+func TestTableCellParagraphs(t *testing.T) {
+	table := func(paragraphs ...string) *adf.ADFNode {
+		cell := &adf.ADFNode{Type: adf.ChildNodeTableCell}
+		for _, text := range paragraphs {
+			paragraph := adf.NewParagraphNode()
+			if text != "" {
+				paragraph.Content = append(paragraph.Content, adf.NewTextNode(text))
+			}
+			cell.Content = append(cell.Content, paragraph)
+		}
+		row := adf.NewTableRowNode()
+		row.Content = append(row.Content, cell)
+		table := adf.NewTableNode()
+		table.Content = append(table.Content, row)
+		return table
+	}
+	list := func(text string, blocks ...*adf.ADFNode) *adf.ADFNode {
+		paragraph := adf.NewParagraphNode()
+		paragraph.Content = append(paragraph.Content, adf.NewTextNode(text))
+		item := &adf.ADFNode{Type: adf.ChildNodeListItem, Content: append([]*adf.ADFNode{paragraph}, blocks...)}
+		return &adf.ADFNode{Type: adf.NodeBulletList, Content: []*adf.ADFNode{item}}
+	}
+
+	tests := []struct {
+		name     string
+		content  []*adf.ADFNode
+		expected string
+	}{
+		{
+			name:     "two paragraphs",
+			content:  []*adf.ADFNode{table("First", "Second")},
+			expected: "\n| First<br>Second |\n|-----------------|\n",
+		},
+		{
+			name:     "empty paragraph between",
+			content:  []*adf.ADFNode{table("a", "", "b")},
+			expected: "\n| a<br><br>b |\n|------------|\n",
+		},
+		{
+			name:     "after a list",
+			content:  []*adf.ADFNode{list("Item"), table("a", "b")},
+			expected: "- Item\n\n| a<br>b |\n|--------|\n",
+		},
+		{
+			name:     "in a list item",
+			content:  []*adf.ADFNode{list("Item", table("a", "b"))},
+			expected: "- Item\n\n\n  | a<br>b |\n  |--------|\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := NewTranslator(NewMarkdownTranslator())
+			out := tr.MustTranslate(&adf.ADFNode{Type: "doc", Content: tt.content})
+			assert.Equal(t, tt.expected, out)
+		})
+	}
+}
+
+func TestTableCellBlocks(t *testing.T) {
+	paragraph := func(text string) *adf.ADFNode {
+		paragraph := adf.NewParagraphNode()
+		paragraph.Content = append(paragraph.Content, adf.NewTextNode(text))
+		return paragraph
+	}
+	list := func(list *adf.ADFNode, items ...*adf.ADFNode) *adf.ADFNode {
+		for _, item := range items {
+			listItem := adf.NewListItemNode()
+			listItem.Content = append(listItem.Content, item)
+			list.Content = append(list.Content, listItem)
+		}
+		return list
+	}
+	code := adf.NewCodeBlockNode("go")
+	code.Content = append(code.Content, adf.NewTextNode("x := `a`\ny := 2\n"))
+	heading := adf.NewHeadingNode(2)
+	heading.Content = append(heading.Content, adf.NewTextNode("Title"))
+	quote := adf.NewBlockquoteNode()
+	quote.Content = append(quote.Content, paragraph("Quoted"), paragraph("twice"))
+
+	nested := list(adf.NewBulletListNode(), paragraph("two"))
+	nested.Content[0].Content = append(nested.Content[0].Content, list(adf.NewOrderedListNode(1), paragraph("sub")))
+
+	tests := []struct {
+		name     string
+		content  []*adf.ADFNode
+		expected string
+	}{
+		{
+			name:     "bullet list",
+			content:  []*adf.ADFNode{paragraph("Steps:"), list(adf.NewBulletListNode(), paragraph("one"), paragraph("two"))},
+			expected: "Steps:<br>• one<br>• two",
+		},
+		{
+			name:     "nested lists",
+			content:  []*adf.ADFNode{list(adf.NewBulletListNode(), paragraph("one")), nested},
+			expected: "• one<br>• two<br>1. sub",
+		},
+		{
+			name:     "code block",
+			content:  []*adf.ADFNode{code, paragraph("after")},
+			expected: "``x := `a` y := 2``<br>after",
+		},
+		{
+			name:     "heading and quote",
+			content:  []*adf.ADFNode{heading, quote},
+			expected: "## Title<br>> Quoted twice",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			row := adf.NewTableRowNode()
+			row.Content = append(row.Content, &adf.ADFNode{Type: adf.ChildNodeTableCell, Content: tt.content})
+			table := adf.NewTableNode()
+			table.Content = append(table.Content, row)
+
+			tr := NewTranslator(NewMarkdownTranslator())
+			out := tr.MustTranslate(&adf.ADFNode{Type: "doc", Content: []*adf.ADFNode{table, paragraph("Below")}})
+
+			lines := strings.Split(out, "\n")
+			assert.Equal(t, "| "+tt.expected+" |", lines[1])
+			assert.Equal(t, "Below", lines[4])
+		})
+	}
+}
+
+func TestNestedTable(t *testing.T) {
+	cell := func(nt adf.NodeType, blocks ...*adf.ADFNode) *adf.ADFNode {
+		return &adf.ADFNode{Type: nt, Content: blocks}
+	}
+	paragraph := func(text string) *adf.ADFNode {
+		paragraph := adf.NewParagraphNode()
+		paragraph.Content = append(paragraph.Content, adf.NewTextNode(text))
+		return paragraph
+	}
+	table := func(rows ...[]*adf.ADFNode) *adf.ADFNode {
+		table := adf.NewTableNode()
+		for _, cells := range rows {
+			row := adf.NewTableRowNode()
+			row.Content = cells
+			table.Content = append(table.Content, row)
+		}
+		return table
+	}
+
+	inner := table(
+		[]*adf.ADFNode{cell(adf.ChildNodeTableHeader, paragraph("k")), cell(adf.ChildNodeTableHeader, paragraph("v"))},
+		[]*adf.ADFNode{cell(adf.ChildNodeTableCell, paragraph("a")), cell(adf.ChildNodeTableCell, paragraph("1"))},
+	)
+	outer := table(
+		[]*adf.ADFNode{cell(adf.ChildNodeTableHeader, paragraph("Name")), cell(adf.ChildNodeTableHeader, paragraph("Values"))},
+		[]*adf.ADFNode{cell(adf.ChildNodeTableCell, paragraph("x")), cell(adf.ChildNodeTableCell, paragraph("See:"), inner)},
+		[]*adf.ADFNode{cell(adf.ChildNodeTableCell, paragraph("y")), cell(adf.ChildNodeTableCell, paragraph("none"))},
+	)
+
+	tr := NewTranslator(NewMarkdownTranslator())
+	out := tr.MustTranslate(&adf.ADFNode{Type: "doc", Content: []*adf.ADFNode{outer, paragraph("After")}})
+
+	expected := "\n" +
+		"| Name  | Values                                                                       |\n" +
+		"|-------|------------------------------------------------------------------------------|\n" +
+		"| x     | See:<br>\\| k     \\| v     \\|<br>\\|-------\\|-------\\|<br>\\| a     \\| 1     \\| |\n" +
+		"| y     | none                                                                         |\n" +
+		"\nAfter\n\n"
+	assert.Equal(t, expected, out)
+}
+
+func TestEmojiRendering(t *testing.T) {
+	tests := []struct {
+		name     string
+		emoji    *adf.ADFNode
+		expected string
+	}{
+		{"known shortcode", adf.NewEmojiNode(":thumbsup:", "👍"), "Nice :thumbsup:!"},
+		{"unknown shortcode with text", adf.NewEmojiNode(":party_parrot:", "🦜"), "Nice 🦜!"},
+		{"shortcode only", &adf.ADFNode{Type: adf.InlineNodeEmoji, Attrs: map[string]any{"shortName": ":custom:"}}, "Nice :custom:!"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paragraph := adf.NewParagraphNode()
+			paragraph.Content = append(paragraph.Content, adf.NewTextNode("Nice "), tt.emoji, adf.NewTextNode("!"))
+
+			tr := NewTranslator(NewMarkdownTranslator())
+			out := tr.MustTranslate(&adf.ADFNode{Type: "doc", Content: []*adf.ADFNode{paragraph}})
+			assert.Equal(t, tt.expected+"\n\n", out)
+		})
+	}
+}
+
+func TestSanitizeLeavesCodeAlone(t *testing.T) {
+	code := adf.NewCodeBlockNode("go")
+	code.Content = append(code.Content, adf.NewTextNode("func Map[T any](s []T) <-chan T {}"))
+
+	inline := adf.NewParagraphNode()
+	inline.Content = append(inline.Content,
+		adf.NewTextNode("Run "),
+		adf.NewTextNodeWithMarks("cat < in > out", []*adf.ADFMark{adf.NewCodeMark()}),
+	)
+
+	text := adf.NewParagraphNode()
+	text.Content = append(text.Content, adf.NewTextNode("a <b> c <u>not underline</U> d"))
+
+	quoteLike := adf.NewParagraphNode()
+	quoteLike.Content = append(quoteLike.Content, adf.NewTextNode("> not a quote"))
+
+	tr := NewTranslator(NewMarkdownTranslator())
+	out := tr.MustTranslate(&adf.ADFNode{Type: "doc", Content: []*adf.ADFNode{code, inline, text, quoteLike}})
+
+	assert.Contains(t, out, "func Map[T any](s []T) <-chan T {}")
+	assert.Contains(t, out, "Run `cat < in > out`")
+	assert.Contains(t, out, "a <b> c ❬u❭not underline❬/U❭ d")
+	assert.Contains(t, out, "\\> not a quote")
+}
+
+func TestMarkdownEscaping(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{"use *args and **kwargs", `use \*args and \*\*kwargs`},
+		{"snake_case stays readable", "snake_case stays readable"},
+		{"_private and trailing_", `\_private and trailing\_`},
+		{"# not a heading, but #hashtag", `\# not a heading, but #hashtag`},
+		{"see [docs] and `tick`", "see \\[docs] and \\`tick\\`"},
+		{"{panel:type=info} but { \"json\": 1 }", `\{panel:type=info} but { "json": 1 }`},
+		{`C:\dir\*`, `C:\dir\\\*`},
+		{"~/.bashrc", `\~/.bashrc`},
+		{"a<br>b, a<BR/>b but <b>", `a\<br>b, a\<BR/>b but <b>`},
+		{"1. Some text, 2. not", `1\. Some text, 2. not`},
+		{"3) and 2024", `3\) and 2024`},
+		{"- not a list, but a-b and - c", `\- not a list, but a-b and - c`},
+		{"+ not a list", `\+ not a list`},
+		{"---", `\---`},
+		{"line one\n# not heading\n> not quoted\n  10. not numbered", "line one\n\\# not heading\n\\> not quoted\n  10\\. not numbered"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			paragraph := adf.NewParagraphNode()
+			paragraph.Content = append(paragraph.Content, adf.NewTextNode(tt.text))
+
+			tr := NewTranslator(NewMarkdownTranslator())
+			out := tr.MustTranslate(&adf.ADFNode{Type: "doc", Content: []*adf.ADFNode{paragraph}})
+			assert.Equal(t, tt.expected+"\n\n", out)
+		})
+	}
+}
+
+func TestOrderedListNumbering(t *testing.T) {
+	list := func(order int, items ...string) *adf.ADFNode {
+		l := adf.NewOrderedListNode(order)
+		for _, text := range items {
+			paragraph := adf.NewParagraphNode()
+			paragraph.Content = append(paragraph.Content, adf.NewTextNode(text))
+			item := adf.NewListItemNode()
+			item.Content = append(item.Content, paragraph)
+			l.Content = append(l.Content, item)
+		}
+		return l
+	}
+	separator := adf.NewParagraphNode()
+	separator.Content = append(separator.Content, adf.NewTextNode("between"))
+
+	t.Run("sibling lists restart", func(t *testing.T) {
+		doc := &adf.ADFNode{Type: "doc", Content: []*adf.ADFNode{list(1, "a", "b", "c"), separator, list(1, "d", "e")}}
+		out := NewTranslator(NewMarkdownTranslator()).MustTranslate(doc)
+		assert.Contains(t, out, "1. a\n2. b\n3. c\n")
+		assert.Contains(t, out, "1. d\n2. e\n")
+		assert.NotContains(t, out, "4.")
+	})
+
+	t.Run("explicit start", func(t *testing.T) {
+		doc := &adf.ADFNode{Type: "doc", Content: []*adf.ADFNode{list(5, "fifth", "sixth")}}
+		out := NewTranslator(NewMarkdownTranslator()).MustTranslate(doc)
+		assert.Contains(t, out, "5. fifth\n6. sixth\n")
+	})
+
+	t.Run("start from decoded JSON", func(t *testing.T) {
+		var doc adf.ADFNode
+		err := json.Unmarshal([]byte(`{"type":"doc","content":[{"type":"orderedList","attrs":{"order":3},"content":[
+			{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"third"}]}]}]}]}`), &doc)
+		assert.NoError(t, err)
+		out := NewTranslator(NewMarkdownTranslator()).MustTranslate(&doc)
+		assert.Contains(t, out, "3. third\n")
+	})
+}
+
+func TestMixedNestedLists(t *testing.T) {
+	item := func(text string, nested ...*adf.ADFNode) *adf.ADFNode {
+		paragraph := adf.NewParagraphNode()
+		paragraph.Content = append(paragraph.Content, adf.NewTextNode(text))
+		li := adf.NewListItemNode()
+		li.Content = append(append(li.Content, paragraph), nested...)
+		return li
+	}
+	list := func(l *adf.ADFNode, items ...*adf.ADFNode) *adf.ADFNode {
+		l.Content = append(l.Content, items...)
+		return l
+	}
+
+	doc := list(adf.NewOrderedListNode(1),
+		item("one", list(adf.NewBulletListNode(),
+			item("bullet", list(adf.NewOrderedListNode(1), item("inner a"), item("inner b"))),
+			item("second bullet"),
+		)),
+		item("two"),
+	)
+
+	out := NewTranslator(NewMarkdownTranslator()).MustTranslate(&adf.ADFNode{Type: "doc", Content: []*adf.ADFNode{doc}})
+	assert.Equal(t, "1. one\n   - bullet\n     1. inner a\n     2. inner b\n   - second bullet\n2. two\n", out)
+}
+
+func TestBlocksAfterList(t *testing.T) {
+	doc := adf.NewDocBuilder().
+		BulletList(adf.Item(adf.Text("item"))).
+		Paragraph(adf.Text("para")).
+		OrderedList(1, adf.Item(adf.Text("step"))).
+		Heading(2, "Title").
+		Build()
+
+	out, err := NewTranslator(NewMarkdownTranslator()).TranslateDocument(doc)
+	assert.NoError(t, err)
+	assert.Equal(t, "- item\n\npara\n\n1. step\n\n## Title\n", out)
+}
+
+func TestDecisionList(t *testing.T) {
+	// Decisions saved by some editors hold paragraphs instead of inline content
+	paragraphs := adf.NewDecisionItemNode(adf.DecisionDecided)
+	paragraphs.Content = append(paragraphs.Content,
+		adf.Paragraph(adf.Text("First")).Node(),
+		adf.Paragraph(adf.Text("Second"), adf.HardBreak()).Node())
+
+	doc := adf.NewDocBuilder().
+		DecisionList(adf.Decision(adf.Text("Ship on "), adf.Bold("Friday")), adf.Decision(adf.Text("Use"), adf.HardBreak(), adf.Text("Go"))).
+		Paragraph(adf.Text("Between")).
+		AppendNodes(&adf.ADFNode{Type: adf.NodeDecisionList, Content: []*adf.ADFNode{paragraphs}}).
+		BulletList(adf.Item(adf.Text("<> not a decision"))).
+		Build()
+
+	out, err := NewTranslator(NewMarkdownTranslator()).TranslateDocument(doc)
+	assert.NoError(t, err)
+	assert.Equal(t, "- <> Ship on **Friday**\n- <> Use\\\nGo\n\nBetween\n\n- <> First\\\nSecond\n\n- \\<> not a decision\n", out)
+}
+
+func TestMediaCaption(t *testing.T) {
+	mediaSingle := adf.NewMediaSingleNode("center")
+	caption := adf.NewCaptionNode()
+	caption.Content = append(caption.Content, adf.NewTextNode("Figure "), adf.NewTextNodeWithMarks("1", []*adf.ADFMark{adf.NewEmphasisMark()}))
+	mediaSingle.Content = append(mediaSingle.Content,
+		&adf.ADFNode{Type: adf.NodeMedia, Attrs: map[string]any{"id": "file-1", "type": "file"}}, caption)
+
+	doc := adf.NewDocBuilder().AppendNodes(mediaSingle).Paragraph(adf.Text("After")).Build()
+
+	out, err := NewTranslator(NewMarkdownTranslator()).TranslateDocument(doc)
+	assert.NoError(t, err)
+	assert.Equal(t, "\n{attachment:file-1}\n{caption}Figure _1_{/caption}\n\nAfter\n\n", out)
+}
+
+func TestLayoutSection(t *testing.T) {
+	column := func(blocks ...adf.Block) *adf.ADFNode {
+		n := &adf.ADFNode{Type: adf.ChildNodeLayoutColumn, Attrs: map[string]any{"width": 50}}
+		for _, b := range blocks {
+			n.Content = append(n.Content, b.Node())
+		}
+		return n
+	}
+	layout := &adf.ADFNode{Type: adf.NodeLayoutSection, Content: []*adf.ADFNode{
+		column(adf.BulletList(adf.Item(adf.Text("Left")))),
+		column(adf.Paragraph(adf.Text("Right"))),
+	}}
+	doc := adf.NewDocBuilder().
+		Paragraph(adf.Text("Intro")).
+		AppendNodes(layout).
+		Paragraph(adf.Text("After")).
+		Build()
+
+	translator := NewTranslator(NewMarkdownTranslator())
+	out, err := translator.TranslateDocument(doc)
+	assert.NoError(t, err)
+	assert.Equal(t, "Intro\n\n- Left\n***\n\nRight\n\nAfter\n\n", out)
+	assert.Equal(t, []Warning{{
+		Code:    WarningLayoutDiscarded,
+		Path:    []int{1},
+		Message: "layout of 2 columns discarded, the columns are rendered one below the other",
+	}}, translator.Warnings())
+	assert.Empty(t, translator.UnknownTypes())
+
+	out, err = NewTranslator(NewHTMLTranslator()).TranslateDocument(doc)
+	assert.NoError(t, err)
+	assert.Contains(t, out, "</ul>\n<hr>\n<p>Right</p>")
+}
+
+func TestHeadingLevels(t *testing.T) {
+	heading := func(attrs map[string]any) *adf.ADFNode {
+		return &adf.ADFNode{Type: adf.NodeHeading, Attrs: attrs, Content: []*adf.ADFNode{adf.NewTextNode("Title")}}
+	}
+
+	tests := []struct {
+		name     string
+		attrs    map[string]any
+		expected string
+	}{
+		{"int", map[string]any{"level": 2}, "## Title\n"},
+		{"decoded JSON", map[string]any{"level": 3.0}, "### Title\n"},
+		{"above the range", map[string]any{"level": 9.0}, "###### Title\n"},
+		{"below the range", map[string]any{"level": 0}, "# Title\n"},
+		{"missing", nil, "# Title\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := adf.NewDocBuilder().AppendNodes(heading(tt.attrs)).Build()
+			out, err := NewTranslator(NewMarkdownTranslator()).TranslateDocument(doc)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, out)
+		})
+	}
+}
+
+func TestHeadingOffset(t *testing.T) {
+	doc := adf.NewDocBuilder().
+		Heading(1, "Title").
+		AppendNodes(adf.Panel(adf.PanelNote,
+			adf.Heading(2, adf.Text("Panel heading")),
+			adf.Heading(5, adf.Text("Panel detail")),
+		).Node()).
+		Build()
+
+	out, err := NewTranslator(NewMarkdownTranslator(), WithHeadingOffset(2)).TranslateDocument(doc)
+	require.NoError(t, err)
+	assert.Equal(t, "### Title\n---\n#### Panel heading\n**Panel detail**\n\n---\n", out)
+	assert.Equal(t, 5, doc.Content[1].Content[1].Attrs["level"], "the document is left alone")
+
+	out, err = NewTranslator(NewMarkdownTranslator(), WithHeadingOffset(-1)).TranslateDocument(doc)
+	require.NoError(t, err)
+	assert.Contains(t, out, "# Title\n")
+	assert.Contains(t, out, "# Panel heading\n")
+	assert.Contains(t, out, "#### Panel detail\n")
+}
+
+func TestMentionSeparation(t *testing.T) {
+	mention := func() *adf.ADFNode {
+		return &adf.ADFNode{Type: adf.InlineNodeMention, Attrs: map[string]any{"id": "jane-id", "text": "@Jane"}}
+	}
+	resolver := WithUserEmailResolver(func(string) string { return "jane@example.com" })
+
+	tests := []struct {
+		name     string
+		content  []*adf.ADFNode
+		expected string
+	}{
+		{"text after a space", []*adf.ADFNode{adf.NewTextNode("Hi "), mention(), adf.NewTextNode(" there")}, "Hi @jane@example.com there"},
+		{"punctuation", []*adf.ADFNode{mention(), adf.NewTextNode(", "), adf.NewTextNode("hi")}, "@jane@example.com, hi"},
+		{"a word right after", []*adf.ADFNode{mention(), adf.NewTextNode("s")}, "@jane@example.com s"},
+		{"a word after punctuation", []*adf.ADFNode{mention(), adf.NewTextNode("."), adf.NewTextNode("x")}, "@jane@example.com. x"},
+		{"formatted text", []*adf.ADFNode{mention(), adf.NewTextNodeWithMarks("bold", []*adf.ADFMark{adf.NewStrongMark()})}, "@jane@example.com **bold**"},
+		{"two mentions", []*adf.ADFNode{mention(), mention()}, "@jane@example.com@jane@example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paragraph := adf.NewParagraphNode()
+			paragraph.Content = tt.content
+			doc := adf.NewDocBuilder().AppendNodes(paragraph).Build()
+
+			out, err := NewTranslator(NewMarkdownTranslator(resolver)).TranslateDocument(doc)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected+"\n\n", out)
+		})
+	}
+}
+
+func TestTranslateTo(t *testing.T) {
+	doc := loadFixture(t)
+
+	expected, err := NewTranslator(NewMarkdownTranslator()).Translate(doc)
+	require.NoError(t, err)
+
+	var out strings.Builder
+	require.NoError(t, NewTranslator(NewMarkdownTranslator()).TranslateTo(&out, doc))
+	assert.Equal(t, expected, out.String())
+
+	// Write errors are returned
+	failing := errors.New("disk full")
+	err = NewTranslator(NewMarkdownTranslator()).TranslateTo(errWriter{failing}, doc)
+	assert.ErrorIs(t, err, failing)
+
+	// What was rendered before the translation halted is kept
+	panel := adf.NewPanelNode("info")
+	panel.Content = append(panel.Content, adf.NewParagraphNode())
+	halting := &adf.ADFNode{Type: "doc", Content: []*adf.ADFNode{
+		{Type: adf.NodeParagraph, Content: []*adf.ADFNode{adf.NewTextNode("Before")}},
+		panel,
+	}}
+	out.Reset()
+	err = NewTranslator(NewWikiMarkupTranslator()).TranslateTo(&out, halting)
+	var unsupported *UnsupportedNodeError
+	assert.ErrorAs(t, err, &unsupported)
+	assert.Equal(t, "Before\n\n", out.String())
+}
+
+// errWriter fails every write with err.
+type errWriter struct{ err error }
+
+func (w errWriter) Write([]byte) (int, error) { return 0, w.err }
+
+func BenchmarkTranslateLargeDocument(b *testing.B) {
+	// About a megabyte of markdown
+	fixture := loadFixture(b)
+	doc := &adf.ADFNode{Type: "doc"}
+	for size := 0; size < 1<<20; size += len(NewTranslator(NewMarkdownTranslator()).MustTranslate(fixture)) {
+		doc.Content = append(doc.Content, fixture.Content...)
+	}
+
+	b.Run("string", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			NewTranslator(NewMarkdownTranslator()).Translate(doc)
+		}
+	})
+	b.Run("writer", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			NewTranslator(NewMarkdownTranslator()).TranslateTo(io.Discard, doc)
+		}
+	})
+}
+
+func TestConvert(t *testing.T) {
+	markdown, err := Convert([]byte(`{"type":"doc","version":1,"content":[{"type":"paragraph","content":[{"type":"text","text":"Hello ","marks":[]},{"type":"text","text":"world","marks":[{"type":"strong"}]}]}]}`))
+	require.NoError(t, err)
+	assert.Equal(t, "Hello **world**\n\n", markdown)
+
+	_, err = Convert([]byte(`{"type":`))
+	assert.Error(t, err)
+}
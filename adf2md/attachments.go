@@ -0,0 +1,80 @@
+package adf2md
+
+import (
+	"strings"
+
+	"github.com/jorres/md2adf-translator/adf"
+)
+
+// AttachmentKeyer is an optional interface of a TagOpenerCloser that renders
+// attachment references by a key other than the media id. The Translator sets
+// the keys, by media id, before each translation.
+type AttachmentKeyer interface {
+	SetAttachmentKeys(keys map[string]string)
+}
+
+// WithAttachmentNames renders attachments by filename, {attachment:diagram.png},
+// instead of by media id. names maps media ids to filenames; media missing
+// from it are named by their alt attr. A filename shared by several
+// attachments of a document, or one that can't be written in the reference,
+// falls back to the id. Both forms resolve back to the media node.
+func WithAttachmentNames(names map[string]string) TranslatorOption {
+	return func(a *Translator) {
+		a.nameAttachments = true
+		a.attachmentNames = names
+	}
+}
+
+// AttachmentID returns the media id an attachment reference key stands for,
+// which is the key itself unless it is a filename.
+func (a *Translator) AttachmentID(key string) string {
+	if id, ok := a.attachmentIDs[key]; ok {
+		return id
+	}
+	return key
+}
+
+// attachmentKeys names the media of the document being translated, mapping
+// their ids to the filenames they are referenced by. Media keep their id when
+// not named.
+func (a *Translator) attachmentKeys() map[string]string {
+	names := make(map[string]string)  // id -> name
+	owners := make(map[string]string) // name -> id, "" once shared by several ids
+
+	seen := make(map[*adf.ADFNode]bool)
+	var collect func(n *adf.ADFNode)
+	collect = func(n *adf.ADFNode) {
+		if n == nil || seen[n] {
+			return
+		}
+		seen[n] = true
+
+		if attrs, ok := n.MediaAttrs(); ok && attrs.ID != "" {
+			name, ok := a.attachmentNames[attrs.ID]
+			if !ok {
+				name = attrs.Alt
+			}
+			if name != "" && !strings.ContainsAny(name, "{}\n\t ") {
+				if owner, seen := owners[name]; seen && owner != attrs.ID {
+					owners[name] = ""
+				} else {
+					owners[name] = attrs.ID
+				}
+				names[attrs.ID] = name
+			}
+		}
+
+		for _, child := range n.Content {
+			collect(child)
+		}
+	}
+	collect(a.doc)
+
+	keys := make(map[string]string)
+	for id, name := range names {
+		if owners[name] == id {
+			keys[id] = name
+		}
+	}
+	return keys
+}
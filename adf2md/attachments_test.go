@@ -0,0 +1,52 @@
+package adf2md
+
+import (
+	"testing"
+
+	"github.com/jorres/md2adf-translator/adf"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttachmentNames(t *testing.T) {
+	media := func(id, alt string) *adf.ADFNode {
+		attrs := map[string]any{"id": id, "type": "file", "collection": "c"}
+		if alt != "" {
+			attrs["alt"] = alt
+		}
+		single := adf.NewMediaSingleNode("center")
+		single.Content = append(single.Content, &adf.ADFNode{Type: adf.NodeMedia, Attrs: attrs})
+		return single
+	}
+	doc := &adf.ADFNode{Type: "doc", Content: []*adf.ADFNode{
+		media("id-1", "diagram.png"),
+		media("id-2", ""),
+		media("id-3", "shot.png"),
+		media("id-4", "shot.png"),
+		media("id-5", "has space.png"),
+	}}
+
+	tr := NewTranslator(NewJiraMarkdownTranslator(), WithAttachmentNames(map[string]string{"id-2": "notes.txt"}))
+	result := tr.MustTranslate(doc)
+
+	for _, ref := range []string{"{attachment:diagram.png}", "{attachment:notes.txt}", "{attachment:id-3}", "{attachment:id-4}", "{attachment:id-5}"} {
+		assert.Contains(t, result, ref)
+	}
+
+	assert.Same(t, doc.Content[0], tr.GetMediaMapping()["diagram.png"])
+	assert.Same(t, doc.Content[0], tr.GetMediaMapping()["id-1"])
+	assert.Same(t, doc.Content[1], tr.GetMediaMapping()["notes.txt"])
+	assert.NotContains(t, tr.GetMediaMapping(), "shot.png")
+
+	assert.Equal(t, "id-1", tr.AttachmentID("diagram.png"))
+	assert.Equal(t, "id-1", tr.AttachmentID("id-1"))
+	assert.Equal(t, map[string]string{"diagram.png": "id-1", "notes.txt": "id-2"}, tr.ExportMappings().AttachmentNames)
+
+	imported := NewTranslator(NewJiraMarkdownTranslator())
+	imported.ImportMappings(tr.ExportMappings())
+	assert.Equal(t, "id-2", imported.AttachmentID("notes.txt"))
+
+	// Without the option attachments keep their ids
+	plain := NewTranslator(NewJiraMarkdownTranslator()).MustTranslate(doc)
+	assert.Contains(t, plain, "{attachment:id-1}")
+	assert.NotContains(t, plain, "diagram.png")
+}
@@ -0,0 +1,15 @@
+package adf2md
+
+import (
+	"github.com/jorres/md2adf-translator/adf"
+)
+
+// Convert renders the ADF document in adfJSON as markdown with a
+// MarkdownTranslator and the default options. It is safe for concurrent use.
+func Convert(adfJSON []byte) (string, error) {
+	doc, err := adf.FromJSON(adfJSON)
+	if err != nil {
+		return "", err
+	}
+	return NewTranslator(NewMarkdownTranslator()).TranslateDocument(doc)
+}
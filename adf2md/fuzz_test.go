@@ -0,0 +1,172 @@
+package adf2md
+
+import (
+	"encoding/json"
+	"math/rand/v2"
+	"os"
+	"testing"
+
+	"github.com/jorres/md2adf-translator/adf"
+	"github.com/stretchr/testify/assert"
+)
+
+var fuzzNodeTypes = []adf.NodeType{
+	adf.NodeBlockquote, adf.NodeBulletList, adf.NodeCodeBlock, adf.NodeHeading,
+	adf.NodeOrderedList, adf.NodePanel, adf.NodeParagraph, adf.NodeTable,
+	adf.NodeMedia, adf.NodeMediaGroup, adf.NodeMediaSingle, adf.NodeExpand, adf.NodeCaption,
+	adf.NodeDecisionList, adf.NodeLayoutSection,
+	adf.ChildNodeText, adf.ChildNodeListItem, adf.ChildNodeTableRow,
+	adf.ChildNodeTableHeader, adf.ChildNodeTableCell, adf.ChildNodeDecisionItem, adf.ChildNodeLayoutColumn,
+	adf.InlineNodeCard, adf.InlineNodeEmoji, adf.InlineNodeMention, adf.InlineNodeHardBreak, adf.InlineNodeStatus,
+	"rule", "unknownNode",
+}
+
+var fuzzMarkTypes = []adf.NodeType{
+	adf.MarkEm, adf.MarkLink, adf.MarkCode, adf.MarkStrike, adf.MarkStrong, adf.MarkUnderline, adf.MarkAlignment,
+}
+
+// fuzzAttrValues holds attribute values of every shape, including the wrong ones.
+var fuzzAttrValues = []any{nil, "", "x", "@name", 0, 2, 3.0, -1.0, true, []any{1}, map[string]any{"k": "v"}}
+
+var fuzzAttrKeys = []string{
+	"level", "language", "text", "id", "url", "href", "type", "collection", "panelType", "shortName",
+	"order", "title", "color", "localId", "width", "height", "colspan", "rowspan", "layout", "state",
+	"alt", "align",
+}
+
+// randomNode builds a node of random shape that need not be valid ADF.
+func randomNode(r *rand.Rand, depth int) *adf.ADFNode {
+	if r.IntN(20) == 0 {
+		return nil
+	}
+
+	n := &adf.ADFNode{Type: fuzzNodeTypes[r.IntN(len(fuzzNodeTypes))]}
+	if r.IntN(2) == 0 {
+		n.Text = []string{"", "text", "a|b", "<u>", "\n\n", "**", "日本語"}[r.IntN(7)]
+	}
+	if r.IntN(2) == 0 {
+		n.Attrs = map[string]any{}
+		for range r.IntN(3) {
+			n.Attrs[fuzzAttrKeys[r.IntN(len(fuzzAttrKeys))]] = fuzzAttrValues[r.IntN(len(fuzzAttrValues))]
+		}
+	}
+	for range r.IntN(3) {
+		m := &adf.ADFMark{Type: fuzzMarkTypes[r.IntN(len(fuzzMarkTypes))]}
+		if r.IntN(2) == 0 {
+			key := []string{"href", "align"}[r.IntN(2)]
+			m.Attrs = map[string]any{key: fuzzAttrValues[r.IntN(len(fuzzAttrValues))]}
+		}
+		n.Marks = append(n.Marks, m)
+	}
+	if depth > 0 {
+		for range r.IntN(4) {
+			n.Content = append(n.Content, randomNode(r, depth-1))
+		}
+	}
+	return n
+}
+
+func TestNoPanicOnFixtures(t *testing.T) {
+	data, err := os.ReadFile("./testdata/md.json")
+	assert.NoError(t, err)
+
+	var doc adf.ADFNode
+	assert.NoError(t, json.Unmarshal(data, &doc))
+
+	// The whole fixture, and every block of it as a document of its own
+	tr := NewTranslator(NewMarkdownTranslator(), WithPanicRecovery(false))
+	tr.Translate(&doc)
+	assert.NoError(t, tr.Err())
+
+	for _, block := range doc.Content {
+		tr := NewTranslator(NewJiraMarkdownTranslator(), WithPanicRecovery(false))
+		tr.Translate(&adf.ADFNode{Type: "doc", Content: []*adf.ADFNode{block}})
+		assert.NoError(t, tr.Err())
+	}
+}
+
+func TestNoPanicOnRandomDocuments(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	for range 10000 {
+		doc := &adf.ADFNode{Type: "doc"}
+		for range r.IntN(4) + 1 {
+			doc.Content = append(doc.Content, randomNode(r, 4))
+		}
+
+		for _, tsl := range []TagOpenerCloser{NewJiraMarkdownTranslator(), NewHTMLTranslator(), NewWikiMarkupTranslator()} {
+			tr := NewTranslator(tsl, WithPanicRecovery(false))
+			func() {
+				defer func() {
+					if v := recover(); v != nil {
+						dump, _ := json.Marshal(doc)
+						t.Fatalf("Translate with %T panicked with %v on %s", tsl, v, dump)
+					}
+				}()
+				tr.Translate(doc)
+			}()
+		}
+	}
+}
+
+func FuzzTranslate(f *testing.F) {
+	data, err := os.ReadFile("./testdata/md.json")
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(data)
+	f.Add([]byte(`{"type":"doc","content":[{"type":"mediaGroup"}]}`))
+	f.Add([]byte(`{"type":"doc","content":[{"type":"heading","attrs":{"level":"1"}}]}`))
+	f.Add([]byte(`{"type":"doc","content":[{"type":"table","content":[{"type":"tableRow"},{"type":"tableCell"}]}]}`))
+	f.Add([]byte(`{"type":"doc","content":[{"type":"layoutSection","content":[{"type":"layoutColumn","attrs":{"width":50},"content":[{"type":"expand","attrs":{"title":7}},{"type":"decisionList","content":[{"type":"decisionItem","attrs":{"state":null}}]}]}]},{"type":"rule"}]}`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var doc adf.ADFNode
+		if json.Unmarshal(data, &doc) != nil {
+			return
+		}
+		tr := NewTranslator(NewJiraMarkdownTranslator(), WithPanicRecovery(false))
+		tr.Translate(&doc)
+	})
+}
+
+// panickingTranslator fails on every tag, standing in for a translator bug.
+type panickingTranslator struct{}
+
+func (panickingTranslator) Open(Connector, int) string { panic("open") }
+func (panickingTranslator) Close(Connector) string     { panic("close") }
+
+func TestPanicRecovery(t *testing.T) {
+	doc := &adf.ADFDocument{Type: "doc", Content: []*adf.ADFNode{adf.NewParagraphNode()}}
+
+	out, err := NewTranslator(panickingTranslator{}).TranslateDocument(doc)
+	assert.Empty(t, out)
+
+	var internal *adf.InternalError
+	if assert.ErrorAs(t, err, &internal) {
+		assert.Equal(t, "open", internal.Value)
+		assert.NotEmpty(t, internal.Stack)
+	}
+	var nodeErr *NodeError
+	if assert.ErrorAs(t, err, &nodeErr) {
+		assert.Equal(t, []int{0}, nodeErr.Path)
+	}
+
+	assert.Panics(t, func() {
+		NewTranslator(panickingTranslator{}, WithPanicRecovery(false)).TranslateDocument(doc)
+	})
+}
+
+func TestUnusualAttributeTypes(t *testing.T) {
+	var doc adf.ADFNode
+	data := `{"type":"doc","content":[
+		{"type":"heading","attrs":{"level":"2"},"content":[{"type":"text","text":"Title"}]},
+		{"type":"orderedList","attrs":{"order":"3"},"content":[{"type":"listItem","content":[{"type":"paragraph","content":[{"type":"text","text":"item"}]}]}]},
+		{"type":"paragraph","content":[{"type":"mention","attrs":{"id":42,"text":null}},{"type":"text","text":"x","marks":[{"type":"link","attrs":{"href":7}}]}]}
+	]}`
+	assert.NoError(t, json.Unmarshal([]byte(data), &doc))
+
+	out, err := NewTranslator(NewMarkdownTranslator(), WithPanicRecovery(false)).Translate(&doc)
+	assert.NoError(t, err)
+	assert.Contains(t, out, "# Title")
+	assert.Contains(t, out, "1. item")
+}
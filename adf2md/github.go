@@ -0,0 +1,101 @@
+package adf2md
+
+import (
+	"strings"
+
+	"github.com/jorres/md2adf-translator/adf"
+)
+
+// GitHubMarkdownTranslator renders GitHub-flavored markdown, e.g. to mirror
+// Jira issues into GitHub discussions. It has none of the Jira-specific
+// syntax md2adf reads back: strikethrough is ~~text~~, panels are quotes
+// headed by their type in bold, underline is dropped, mentions are plain
+// @localpart text and attachments are image placeholders.
+type GitHubMarkdownTranslator struct {
+	*MarkdownTranslator
+}
+
+// NewGitHubMarkdownTranslator constructs a GitHub markdown translator.
+func NewGitHubMarkdownTranslator(opts ...MarkdownTranslatorOption) *GitHubMarkdownTranslator {
+	tr := &GitHubMarkdownTranslator{}
+
+	strike := func(Connector) string { return "~~" }
+	none := func(Connector) string { return "" }
+
+	openHooks := nodeTypeHook{
+		adf.MarkStrike:        strike,
+		adf.NodePanel:         none,
+		adf.InlineNodeMention: tr.mention,
+		adf.NodeMedia:         tr.media,
+	}
+
+	closeHooks := nodeTypeHook{
+		adf.MarkStrike:        strike,
+		adf.NodePanel:         none,
+		adf.InlineNodeMention: none,
+	}
+
+	wrapHooks := map[adf.NodeType]func(Connector, string) string{
+		adf.NodePanel: githubPanel,
+	}
+
+	// Combine built-in hooks with any additional options
+	allOpts := []MarkdownTranslatorOption{
+		WithMarkdownOpenHooks(openHooks),
+		WithMarkdownCloseHooks(closeHooks),
+		WithMarkdownWrapHooks(wrapHooks),
+		WithUnderlineStyle(UnderlineDrop),
+	}
+	allOpts = append(allOpts, opts...)
+
+	tr.MarkdownTranslator = NewMarkdownTranslator(allOpts...)
+	return tr
+}
+
+// mention renders a mention as @ and the local part of the user's email,
+// or their display name when the email is unknown.
+func (tr *GitHubMarkdownTranslator) mention(n Connector) string {
+	attrs, _ := n.GetAttributes().(map[string]any)
+
+	if id, ok := attrs["id"].(string); ok {
+		if email := tr.resolveUserEmail(id); email != "" {
+			localPart, _, _ := strings.Cut(email, "@")
+			return "@" + localPart
+		}
+	}
+	text, _ := attrs["text"].(string)
+	return "@" + strings.TrimPrefix(text, "@")
+}
+
+// media renders an external image as an image and an attachment as an image
+// placeholder named after the attachment.
+func (tr *GitHubMarkdownTranslator) media(n Connector) string {
+	attrs, _ := n.GetAttributes().(map[string]any)
+	media := adf.ParseMediaAttributes(attrs)
+
+	if media.Type == "external" && media.URL != "" {
+		return "\n![" + media.Alt + "](" + linkTarget(map[string]any{"href": media.URL}) + ")"
+	}
+
+	name := tr.attachmentKeys[media.ID]
+	if name == "" {
+		name = media.Alt
+	}
+	if name == "" {
+		name = media.ID
+	}
+	return "\n![" + name + "](#)"
+}
+
+// githubPanel renders panel content as a quote whose first line is the panel
+// type in bold, e.g. "> **Warning**".
+func githubPanel(n Connector, content string) string {
+	attrs, _ := n.GetAttributes().(map[string]any)
+	panelType, _ := attrs["panelType"].(string)
+	if panelType == "" || panelType == adf.PanelCustom {
+		panelType = adf.PanelInfo
+	}
+
+	title := "**" + strings.ToUpper(panelType[:1]) + panelType[1:] + "**"
+	return quoteLines(title + "\n\n" + content)
+}
@@ -0,0 +1,45 @@
+package adf2md
+
+import (
+	"testing"
+
+	"github.com/jorres/md2adf-translator/adf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitHubMarkdownTranslator(t *testing.T) {
+	attachment := adf.NewMediaSingleNode("center")
+	attachment.Content = append(attachment.Content, &adf.ADFNode{
+		Type:  adf.NodeMedia,
+		Attrs: map[string]any{"id": "f00", "type": "file", "collection": "c"},
+	})
+	image := adf.NewMediaSingleNode("center")
+	image.Content = append(image.Content, adf.NewExternalMediaNode("https://x.y/a.png", "diagram"))
+
+	doc := adf.NewDocBuilder().
+		Paragraph(adf.Text("Hi "), adf.Mention("abc-123", "@Jane"), adf.Text(" and "), adf.Mention("def-456", "@Joe"),
+			adf.Text(", "), adf.Strike("not"), adf.Text(" "), adf.Underlined("this")).
+		Panel("warning", adf.Paragraph(adf.Text("Careful")), adf.BulletList(adf.Item(adf.Text("a")))).
+		Table(
+			adf.Row(adf.HeaderCell(adf.Text("Name"))),
+			adf.Row(adf.Cell(adf.Strike("gone"))),
+		).
+		AppendNodes(attachment, image).
+		Build()
+
+	emails := map[string]string{"abc-123": "jane.doe@example.com"}
+	tr := NewGitHubMarkdownTranslator(WithUserEmailResolver(func(id string) string { return emails[id] }))
+
+	out, err := NewTranslator(tr).TranslateDocument(doc)
+	require.NoError(t, err)
+
+	expected := "Hi @jane.doe and @Joe, ~~not~~ this\n\n" +
+		"> **Warning**\n>\n> Careful\n>\n> - a\n\n" +
+		"\n| Name     |\n|----------|\n| ~~gone~~ |\n" +
+		"\n![f00](#)\n\n" +
+		"\n![diagram](https://x.y/a.png)\n\n"
+	assert.Equal(t, expected, out)
+	assert.NotContains(t, out, "{panel")
+	assert.NotContains(t, out, "{attachment")
+}
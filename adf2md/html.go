@@ -0,0 +1,243 @@
+package adf2md
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/jorres/md2adf-translator/adf"
+)
+
+// TextRenderer is an optional interface of a TagOpenerCloser that renders the
+// text of text nodes itself, instead of the text being escaped for markdown.
+// code is true for the text of code blocks and code marks.
+type TextRenderer interface {
+	RenderText(text string, code bool) string
+}
+
+// HTMLTranslator renders ADF as HTML, e.g. for previews.
+type HTMLTranslator struct {
+	// sections holds the table section ("thead" or "tbody") open in each
+	// open table, "" before the first row; nested tables are innermost last.
+	sections []string
+	// layoutColumns counts the columns of the layout section being rendered,
+	// which are flattened like in markdown.
+	layoutColumns int
+}
+
+// NewHTMLTranslator constructs an HTML translator.
+func NewHTMLTranslator() *HTMLTranslator {
+	return &HTMLTranslator{}
+}
+
+// RenderText implements TextRenderer: text is HTML-escaped.
+func (*HTMLTranslator) RenderText(text string, _ bool) string {
+	return html.EscapeString(text)
+}
+
+// Open implements TagOpener interface.
+//
+//nolint:gocyclo
+func (tr *HTMLTranslator) Open(n Connector, _ int) string {
+	attrs, _ := n.GetAttributes().(map[string]any)
+
+	switch n.GetType() {
+	case adf.NodeParagraph:
+		return "<p>"
+	case adf.NodeHeading:
+		return fmt.Sprintf("<h%d>", adf.HeadingLevel(attrs))
+	case adf.NodeBlockquote:
+		return "<blockquote>"
+	case adf.NodeBulletList:
+		return "<ul>"
+	case adf.NodeOrderedList:
+		if order := listOrder(attrs); order != 1 {
+			return fmt.Sprintf(`<ol start="%d">`, order)
+		}
+		return "<ol>"
+	case adf.ChildNodeListItem:
+		return "<li>"
+	case adf.NodeDecisionList:
+		return `<ul class="decisions">`
+	case adf.ChildNodeDecisionItem:
+		return `<li class="decision">`
+	case adf.NodeCodeBlock:
+		if language, _ := attrs["language"].(string); language != "" {
+			return fmt.Sprintf(`<pre><code class="language-%s">`, html.EscapeString(language))
+		}
+		return "<pre><code>"
+	case adf.NodePanel:
+		panelType, _ := attrs["panelType"].(string)
+		if panelType == "" {
+			panelType = adf.PanelInfo
+		}
+		return fmt.Sprintf(`<div class="panel panel-%s">`, html.EscapeString(panelType))
+	case adf.NodeExpand:
+		title, _ := attrs["title"].(string)
+		return "<details><summary>" + html.EscapeString(title) + "</summary>"
+	case adf.NodeTable:
+		tr.sections = append(tr.sections, "")
+		return "<table>"
+	case adf.ChildNodeTableRow:
+		return tr.openRow(n) + "<tr>"
+	case adf.ChildNodeTableHeader:
+		return "<th" + spanAttrs(attrs) + ">"
+	case adf.ChildNodeTableCell:
+		return "<td" + spanAttrs(attrs) + ">"
+	case adf.NodeMediaSingle, adf.NodeMediaGroup:
+		return `<div class="media">`
+	case adf.NodeCaption:
+		return `<p class="caption">`
+	case adf.NodeLayoutSection:
+		tr.layoutColumns = 0
+	case adf.ChildNodeLayoutColumn:
+		tr.layoutColumns++
+		if tr.layoutColumns > 1 {
+			return "<hr>\n"
+		}
+	case adf.NodeMedia:
+		media := adf.ParseMediaAttributes(attrs)
+		if media.Type == "external" && media.URL != "" {
+			return fmt.Sprintf(`<img src="%s" alt="%s">`, html.EscapeString(media.URL), html.EscapeString(media.Alt))
+		}
+		return fmt.Sprintf(`<span class="attachment" data-media-id="%s">%s</span>`,
+			html.EscapeString(media.ID), html.EscapeString(media.Alt))
+	case adf.InlineNodeHardBreak:
+		return "<br>"
+	case adf.InlineNodeMention:
+		id, _ := attrs["id"].(string)
+		text, _ := attrs["text"].(string)
+		if !strings.HasPrefix(text, "@") {
+			text = "@" + text
+		}
+		return fmt.Sprintf(`<span class="mention" data-account-id="%s">%s</span>`, html.EscapeString(id), html.EscapeString(text))
+	case adf.InlineNodeEmoji:
+		text, _ := attrs["text"].(string)
+		if text == "" {
+			text, _ = attrs["shortName"].(string)
+		}
+		return html.EscapeString(text)
+	case adf.InlineNodeStatus:
+		text, _ := attrs["text"].(string)
+		color, _ := attrs["color"].(string)
+		return fmt.Sprintf(`<span class="status status-%s">%s</span>`, html.EscapeString(color), html.EscapeString(text))
+	case adf.InlineNodeCard:
+		url, _ := attrs["url"].(string)
+		return fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(url), html.EscapeString(url))
+	case adf.MarkStrong:
+		return "<strong>"
+	case adf.MarkEm:
+		return "<em>"
+	case adf.MarkCode:
+		return "<code>"
+	case adf.MarkStrike:
+		return "<s>"
+	case adf.MarkUnderline:
+		return "<u>"
+	case adf.MarkLink:
+		href, _ := attrs["href"].(string)
+		return fmt.Sprintf(`<a href="%s">`, html.EscapeString(href))
+	}
+	return ""
+}
+
+// Close implements TagCloser interface.
+//
+//nolint:gocyclo
+func (tr *HTMLTranslator) Close(n Connector) string {
+	attrs, _ := n.GetAttributes().(map[string]any)
+
+	switch n.GetType() {
+	case adf.NodeParagraph, adf.NodeCaption:
+		return "</p>\n"
+	case adf.NodeHeading:
+		return fmt.Sprintf("</h%d>\n", adf.HeadingLevel(attrs))
+	case adf.NodeBlockquote:
+		return "</blockquote>\n"
+	case adf.NodeBulletList, adf.NodeDecisionList:
+		return "</ul>\n"
+	case adf.NodeOrderedList:
+		return "</ol>\n"
+	case adf.ChildNodeListItem, adf.ChildNodeDecisionItem:
+		return "</li>\n"
+	case adf.NodeCodeBlock:
+		return "</code></pre>\n"
+	case adf.NodePanel, adf.NodeMediaSingle, adf.NodeMediaGroup:
+		return "</div>\n"
+	case adf.NodeExpand:
+		return "</details>\n"
+	case adf.NodeTable:
+		var tag string
+		if n := len(tr.sections); n > 0 {
+			if section := tr.sections[n-1]; section != "" {
+				tag = "</" + section + ">"
+			}
+			tr.sections = tr.sections[:n-1]
+		}
+		return tag + "</table>\n"
+	case adf.ChildNodeTableRow:
+		return "</tr>\n"
+	case adf.ChildNodeTableHeader:
+		return "</th>"
+	case adf.ChildNodeTableCell:
+		return "</td>"
+	case adf.MarkStrong:
+		return "</strong>"
+	case adf.MarkEm:
+		return "</em>"
+	case adf.MarkCode:
+		return "</code>"
+	case adf.MarkStrike:
+		return "</s>"
+	case adf.MarkUnderline:
+		return "</u>"
+	case adf.MarkLink:
+		return "</a>"
+	}
+	return ""
+}
+
+// openRow switches the table to the section of row n, returning the tags to
+// do so. Leading rows of header cells only go to the thead.
+func (tr *HTMLTranslator) openRow(n Connector) string {
+	last := len(tr.sections) - 1
+	if last < 0 {
+		return ""
+	}
+
+	section := "tbody"
+	if node, ok := n.(*adf.ADFNode); ok && tr.sections[last] != "tbody" && isHeaderRow(node) {
+		section = "thead"
+	}
+	if section == tr.sections[last] {
+		return ""
+	}
+
+	var tag string
+	if tr.sections[last] != "" {
+		tag = "</" + tr.sections[last] + ">"
+	}
+	tr.sections[last] = section
+	return tag + "<" + section + ">\n"
+}
+
+// isHeaderRow reports whether every cell of a table row is a header cell.
+func isHeaderRow(row *adf.ADFNode) bool {
+	for _, cell := range row.Content {
+		if cell == nil || cell.Type != adf.ChildNodeTableHeader {
+			return false
+		}
+	}
+	return len(row.Content) > 0
+}
+
+// spanAttrs renders the colspan and rowspan of a table cell.
+func spanAttrs(attrs map[string]any) string {
+	var tag strings.Builder
+	for _, name := range []string{"colspan", "rowspan"} {
+		if span, ok := adf.IntAttr(attrs[name]); ok && span > 1 {
+			fmt.Fprintf(&tag, ` %s="%d"`, name, span)
+		}
+	}
+	return tag.String()
+}
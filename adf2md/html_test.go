@@ -0,0 +1,58 @@
+package adf2md
+
+import (
+	"testing"
+
+	"github.com/jorres/md2adf-translator/adf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTMLTranslator(t *testing.T) {
+	doc := adf.NewDocBuilder().
+		Heading(2, "Fish & <chips>").
+		Paragraph(adf.Text("Hi "), adf.Mention("abc-123", "@Jane"), adf.Text(", see "),
+			adf.Link("the docs", "https://example.com/?a=1&b=2"), adf.Text(" and "), adf.Strike("not"),
+			adf.Text(" "), adf.Underlined("this"), adf.HardBreak(), adf.Styled("x", adf.NewStrongMark(), adf.NewEmphasisMark())).
+		CodeBlock("go", "if a < b {\n}").
+		Panel("warning", adf.Paragraph(adf.Code("<u>"))).
+		OrderedList(3, adf.Item(adf.Text("three"))).
+		Table(
+			adf.Row(adf.HeaderCell(adf.Text("Name")), adf.HeaderCell(adf.Text("State"))),
+			adf.Row(adf.Cell(adf.Text("a")), adf.Cell(adf.Status("DONE", "green"))),
+		).
+		Build()
+
+	out, err := NewTranslator(NewHTMLTranslator()).TranslateDocument(doc)
+	require.NoError(t, err)
+
+	expected := `<h2>Fish &amp; &lt;chips&gt;</h2>
+<p>Hi <span class="mention" data-account-id="abc-123">@Jane</span>, see <a href="https://example.com/?a=1&amp;b=2">the docs</a> and <s>not</s> <u>this</u><br><strong><em>x</em></strong></p>
+<pre><code class="language-go">if a &lt; b {
+}</code></pre>
+<div class="panel panel-warning"><p><code>&lt;u&gt;</code></p>
+</div>
+<ol start="3"><li><p>three</p>
+</li>
+</ol>
+<table><thead>
+<tr><th><p>Name</p>
+</th><th><p>State</p>
+</th></tr>
+</thead><tbody>
+<tr><td><p>a</p>
+</td><td><p><span class="status status-green">DONE</span></p>
+</td></tr>
+</tbody></table>
+`
+	assert.Equal(t, expected, out)
+}
+
+func TestHTMLTableWithoutHeader(t *testing.T) {
+	row := adf.Row(adf.Cell(adf.Text("a")))
+	doc := adf.NewDocBuilder().Table(row).Build()
+
+	out, err := NewTranslator(NewHTMLTranslator()).TranslateDocument(doc)
+	require.NoError(t, err)
+	assert.Equal(t, "<table><tbody>\n<tr><td><p>a</p>\n</td></tr>\n</tbody></table>\n", out)
+}
@@ -0,0 +1,94 @@
+package adf2md
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/jorres/md2adf-translator/adf"
+)
+
+// RawADFLanguage is the language of the code fences a lossless translation
+// embeds nodes in as ADF JSON. md2adf splices the JSON of such a fence back
+// into the document verbatim.
+const RawADFLanguage = "adf-raw"
+
+// WithLossless makes the translation lossless for the nodes markdown can't
+// represent: blocks of unknown types, layout sections, blocks holding unknown
+// inline nodes and tables a pipe table can't hold, such as ones with merged
+// cells, are embedded as their ADF JSON in RawADFLanguage code fences. It takes
+// precedence over the unknown node policy, and UnknownTypes still reports
+// the types embedded.
+func WithLossless() TranslatorOption {
+	return func(a *Translator) {
+		a.lossless = true
+	}
+}
+
+// visitRaw embeds n in a RawADFLanguage fence if the lossless translation
+// can't render it as markdown. It reports whether n was embedded.
+func (a *Translator) visitRaw(n, parent *adf.ADFNode) bool {
+	if !a.lossless || parent == nil || inlineParents[parent.Type] || !needsRaw(n) {
+		return false
+	}
+
+	adf.Walk(&adf.ADFDocument{Content: []*adf.ADFNode{n}}, func(n *adf.ADFNode, _ int) bool {
+		if !knownTypes[n.Type] && !slices.Contains(a.unknownTypes, n.Type) {
+			a.unknownTypes = append(a.unknownTypes, n.Type)
+		}
+		return true
+	})
+
+	raw, err := json.MarshalIndent(n, "", "  ")
+	if err != nil {
+		return false
+	}
+	fence := strings.Repeat("`", max(longestBacktickRun(string(raw))+1, 3))
+	a.emit(fence + RawADFLanguage + "\n" + string(raw) + "\n" + fence + "\n\n")
+	return true
+}
+
+// needsRaw reports whether a block can't be rendered as markdown without loss.
+func needsRaw(n *adf.ADFNode) bool {
+	switch {
+	case !knownTypes[n.Type], n.Type == adf.NodeLayoutSection:
+		return true
+	case n.Type == adf.NodeTable:
+		return isComplexTable(n)
+	case inlineParents[n.Type]:
+		return slices.ContainsFunc(n.Content, func(child *adf.ADFNode) bool {
+			return child != nil && !knownTypes[child.Type]
+		})
+	}
+	return false
+}
+
+// isComplexTable reports whether a table has more than a pipe table holds:
+// merged cells, cells of anything but a single paragraph, or unknown nodes.
+func isComplexTable(table *adf.ADFNode) bool {
+	for _, row := range table.Content {
+		if row == nil {
+			continue
+		}
+		for _, cell := range row.Content {
+			if cell == nil {
+				continue
+			}
+			for _, span := range []string{"colspan", "rowspan"} {
+				if n, ok := adf.IntAttr(cell.Attrs[span]); ok && n > 1 {
+					return true
+				}
+			}
+			if len(cell.Content) > 1 || len(cell.Content) == 1 && cell.Content[0] != nil && needsRawCell(cell.Content[0]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// needsRawCell reports whether the block of a table cell can't be rendered
+// in a pipe table cell.
+func needsRawCell(block *adf.ADFNode) bool {
+	return block.Type != adf.NodeParagraph || needsRaw(block)
+}
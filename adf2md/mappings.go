@@ -0,0 +1,110 @@
+package adf2md
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"maps"
+	"time"
+
+	"github.com/jorres/md2adf-translator/adf"
+)
+
+// Mappings holds the nodes a Translator preserved from the documents it
+// translated, so they can be cached along with the generated markdown and
+// imported again when the markdown is converted back.
+type Mappings struct {
+	Media       map[string]PreservedNode `json:"media,omitempty"`
+	InlineCards map[string]PreservedNode `json:"inlineCards,omitempty"`
+	// AttachmentNames maps the filenames attachments were rendered by to
+	// their media ids, see WithAttachmentNames.
+	AttachmentNames map[string]string `json:"attachmentNames,omitempty"`
+}
+
+// PreservedNode is a preserved node with the hash of its content and the time
+// of the fetch it came from.
+type PreservedNode struct {
+	Node      *adf.ADFNode `json:"node"`
+	Hash      string       `json:"hash"`
+	FetchedAt time.Time    `json:"fetchedAt"`
+}
+
+// preservedInfo is what a Translator knows about a preserved node besides the node.
+type preservedInfo struct {
+	hash      string
+	fetchedAt time.Time
+	changed   bool // a newer fetch replaced a node with different content
+}
+
+// nodeHash returns a hash of the JSON encoding of n.
+func nodeHash(n *adf.ADFNode) string {
+	data, _ := json.Marshal(n)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// ExportMappings returns the preserved media and inline card nodes.
+func (a *Translator) ExportMappings() Mappings {
+	export := func(nodes map[string]*adf.ADFNode, info map[string]preservedInfo) map[string]PreservedNode {
+		out := make(map[string]PreservedNode, len(nodes))
+		for key, n := range nodes {
+			out[key] = PreservedNode{Node: n, Hash: info[key].hash, FetchedAt: info[key].fetchedAt}
+		}
+		return out
+	}
+
+	return Mappings{
+		Media:           export(a.mediaMapping, a.mediaInfo),
+		InlineCards:     export(a.inlineCardMapping, a.inlineCardInfo),
+		AttachmentNames: maps.Clone(a.attachmentIDs),
+	}
+}
+
+// ImportMappings merges preserved nodes into the translator. For a key known
+// already, the node from the newer fetch wins; if its content differs, the key
+// is reported by MediaChanged or InlineCardChanged.
+func (a *Translator) ImportMappings(m Mappings) {
+	for key, pn := range m.Media {
+		preserve(a.mediaMapping, a.mediaInfo, key, pn)
+	}
+	for key, pn := range m.InlineCards {
+		preserve(a.inlineCardMapping, a.inlineCardInfo, a.cardKey(key), pn)
+	}
+	maps.Copy(a.attachmentIDs, m.AttachmentNames)
+}
+
+// MediaChanged reports whether the media node preserved under key was
+// replaced by a newer fetch with different content, meaning the resource
+// changed since markdown referencing it was generated.
+func (a *Translator) MediaChanged(key string) bool {
+	return a.mediaInfo[key].changed
+}
+
+// InlineCardChanged is MediaChanged for inline cards, whose keys are URLs
+// normalized like in InlineCard.
+func (a *Translator) InlineCardChanged(key string) bool {
+	return a.inlineCardInfo[a.cardKey(key)].changed
+}
+
+// preserve stores pn under key unless a node from a newer fetch is stored already.
+func preserve(nodes map[string]*adf.ADFNode, info map[string]preservedInfo, key string, pn PreservedNode) {
+	if pn.Hash == "" {
+		pn.Hash = nodeHash(pn.Node)
+	}
+
+	old, known := info[key]
+	if known && old.fetchedAt.After(pn.FetchedAt) {
+		if old.hash != pn.Hash {
+			old.changed = true
+			info[key] = old
+		}
+		return
+	}
+
+	nodes[key] = pn.Node
+	info[key] = preservedInfo{
+		hash:      pn.Hash,
+		fetchedAt: pn.FetchedAt,
+		changed:   known && (old.changed || old.hash != pn.Hash),
+	}
+}
@@ -0,0 +1,74 @@
+package adf2md
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jorres/md2adf-translator/adf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mediaDocument(id, collection string) *adf.ADFNode {
+	media := &adf.ADFNode{Type: adf.NodeMedia, Attrs: map[string]any{
+		"id":         id,
+		"type":       "file",
+		"collection": collection,
+	}}
+	mediaSingle := adf.NewMediaSingleNode("center")
+	mediaSingle.Content = append(mediaSingle.Content, media)
+	return &adf.ADFNode{Type: "doc", Content: []*adf.ADFNode{mediaSingle}}
+}
+
+func TestImportMappingsPrefersNewerFetch(t *testing.T) {
+	first := NewTranslator(NewJiraMarkdownTranslator())
+	first.Translate(mediaDocument("file-1", "old-collection"))
+	cached := first.ExportMappings()
+
+	second := NewTranslator(NewJiraMarkdownTranslator())
+	second.Translate(mediaDocument("file-1", "new-collection"))
+	fresh := second.ExportMappings()
+
+	// The order of imports doesn't matter, the newer fetch wins
+	for _, order := range [][]Mappings{{cached, fresh}, {fresh, cached}} {
+		tr := NewTranslator(NewJiraMarkdownTranslator())
+		for _, m := range order {
+			tr.ImportMappings(m)
+		}
+
+		node := tr.GetMediaMapping()["file-1"]
+		require.NotNil(t, node)
+		assert.Equal(t, "new-collection", node.Content[0].Attrs["collection"])
+		assert.True(t, tr.MediaChanged("file-1"))
+	}
+}
+
+func TestImportMappingsUnchangedContent(t *testing.T) {
+	first := NewTranslator(NewJiraMarkdownTranslator())
+	first.Translate(mediaDocument("file-1", "collection"))
+
+	second := NewTranslator(NewJiraMarkdownTranslator())
+	second.Translate(mediaDocument("file-1", "collection"))
+
+	tr := NewTranslator(NewJiraMarkdownTranslator())
+	tr.ImportMappings(first.ExportMappings())
+	tr.ImportMappings(second.ExportMappings())
+
+	assert.NotNil(t, tr.GetMediaMapping()["file-1"])
+	assert.False(t, tr.MediaChanged("file-1"))
+	assert.False(t, tr.MediaChanged("unknown"))
+}
+
+func TestMappingsJSONRoundtrip(t *testing.T) {
+	tr := NewTranslator(NewJiraMarkdownTranslator())
+	tr.Translate(mediaDocument("file-1", "collection"))
+	exported := tr.ExportMappings()
+
+	data, err := json.Marshal(exported)
+	require.NoError(t, err)
+
+	var imported Mappings
+	require.NoError(t, json.Unmarshal(data, &imported))
+	assert.Equal(t, exported.Media["file-1"].Hash, imported.Media["file-1"].Hash)
+	assert.True(t, exported.Media["file-1"].FetchedAt.Equal(imported.Media["file-1"].FetchedAt))
+}
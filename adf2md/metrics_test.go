@@ -0,0 +1,161 @@
+package adf2md
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/jorres/md2adf-translator/adf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type observation struct {
+	name   string
+	value  float64
+	labels map[string]string
+}
+
+type recordingCollector struct {
+	observations []observation
+}
+
+func (c *recordingCollector) Observe(name string, value float64, labels map[string]string) {
+	c.observations = append(c.observations, observation{name: name, value: value, labels: labels})
+}
+
+func (c *recordingCollector) find(name string) []observation {
+	var found []observation
+	for _, o := range c.observations {
+		if o.name == name {
+			found = append(found, o)
+		}
+	}
+	return found
+}
+
+func loadFixture(tb testing.TB) *adf.ADFNode {
+	data, err := os.ReadFile("./testdata/md.json")
+	require.NoError(tb, err)
+
+	var doc adf.ADFNode
+	require.NoError(tb, json.Unmarshal(data, &doc))
+	return &doc
+}
+
+func TestTranslateMetrics(t *testing.T) {
+	doc := loadFixture(t)
+	collector := &recordingCollector{}
+
+	tr := NewTranslator(NewMarkdownTranslator(), WithMetrics(collector))
+	tr.Translate(doc)
+
+	converted := collector.find(adf.MetricDocumentsConverted)
+	require.Len(t, converted, 1)
+	assert.Equal(t, 1.0, converted[0].value)
+	assert.Equal(t, adf.DirectionADFToMarkdown, converted[0].labels[adf.LabelDirection])
+
+	duration := collector.find(adf.MetricConversionDuration)
+	require.Len(t, duration, 1)
+	assert.GreaterOrEqual(t, duration[0].value, 0.0)
+
+	var countNodes func(nodes []*adf.ADFNode) int
+	countNodes = func(nodes []*adf.ADFNode) int {
+		total := len(nodes)
+		for _, n := range nodes {
+			total += countNodes(n.Content)
+		}
+		return total
+	}
+
+	size := collector.find(adf.MetricInputNodes)
+	require.Len(t, size, 1)
+	assert.Equal(t, float64(countNodes(doc.Content)), size[0].value)
+}
+
+func TestTranslateMetricsWarnings(t *testing.T) {
+	layout := &adf.ADFNode{Type: adf.NodeLayoutSection}
+	for _, text := range []string{"left", "right"} {
+		column := &adf.ADFNode{Type: adf.ChildNodeLayoutColumn, Attrs: map[string]any{"width": 50}}
+		column.Content = append(column.Content, adf.NewParagraphNode())
+		column.Content[0].Content = append(column.Content[0].Content, adf.NewTextNode(text))
+		layout.Content = append(layout.Content, column)
+	}
+	unknown := &adf.ADFNode{Type: "bodiedExtension"}
+	doc := &adf.ADFNode{Type: "doc", Content: []*adf.ADFNode{layout, unknown, unknown.Clone()}}
+
+	collector := &recordingCollector{}
+	tr := NewTranslator(NewMarkdownTranslator(), WithMetrics(collector))
+	_, err := tr.Translate(doc)
+	require.NoError(t, err)
+
+	codes := make(map[string]float64)
+	for _, o := range collector.find(adf.MetricWarnings) {
+		assert.Equal(t, adf.DirectionADFToMarkdown, o.labels[adf.LabelDirection])
+		codes[o.labels[adf.LabelCode]] += o.value
+	}
+	assert.Equal(t, map[string]float64{WarningLayoutDiscarded: 1, WarningUnknownNode: 2}, codes)
+	assert.Len(t, tr.Warnings(), 3)
+}
+
+func TestTranslateMetricsOnError(t *testing.T) {
+	item := adf.NewListItemNode()
+	list := adf.NewBulletListNode()
+	list.Content = append(list.Content, item)
+	item.Content = append(item.Content, list)
+
+	collector := &recordingCollector{}
+	tr := NewTranslator(NewMarkdownTranslator(), WithMetrics(collector))
+	_, err := tr.Translate(&adf.ADFNode{Type: "doc", Content: []*adf.ADFNode{list}})
+	require.ErrorIs(t, err, adf.ErrCycle)
+
+	assert.Len(t, collector.find(adf.MetricDocumentsConverted), 1)
+	assert.Len(t, collector.find(adf.MetricConversionDuration), 1)
+	assert.Len(t, collector.find(adf.MetricConversionErrors), 1)
+
+	collector.observations = nil
+	_, err = tr.Translate(loadFixture(t))
+	require.NoError(t, err)
+	assert.Empty(t, collector.find(adf.MetricConversionErrors))
+}
+
+func BenchmarkTranslateMetrics(b *testing.B) {
+	doc := loadFixture(b)
+
+	benchmarks := []struct {
+		name string
+		opts []TranslatorOption
+	}{
+		{name: "without collector"},
+		{name: "nop collector", opts: []TranslatorOption{WithMetrics(adf.NopCollector{})}},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				NewTranslator(NewMarkdownTranslator(), bm.opts...).Translate(doc)
+			}
+		})
+	}
+}
+
+func BenchmarkTranslateMedia(b *testing.B) {
+	doc := &adf.ADFNode{Type: "doc"}
+	for i := range 100 {
+		group := &adf.ADFNode{Type: adf.NodeMediaGroup}
+		group.Content = append(group.Content, &adf.ADFNode{Type: adf.NodeMedia, Attrs: map[string]any{
+			"id": fmt.Sprintf("file-%d", i), "type": "file", "collection": "c", "width": 640.0, "height": 480.0,
+		}})
+		card := &adf.ADFNode{Type: adf.InlineNodeCard, Attrs: map[string]any{"url": fmt.Sprintf("https://example.com/%d", i)}}
+		paragraph := adf.NewParagraphNode()
+		paragraph.Content = append(paragraph.Content, card)
+		doc.Content = append(doc.Content, group, paragraph)
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		NewTranslator(NewMarkdownTranslator()).Translate(doc)
+	}
+}
@@ -0,0 +1,127 @@
+package adf2md
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jorres/md2adf-translator/adf"
+)
+
+// knownTypes are the node types the translators render.
+var knownTypes = map[adf.NodeType]bool{
+	"doc":                     true,
+	adf.NodeParagraph:         true,
+	adf.NodeHeading:           true,
+	adf.NodeBlockquote:        true,
+	adf.NodeCodeBlock:         true,
+	adf.NodeBulletList:        true,
+	adf.NodeOrderedList:       true,
+	adf.ChildNodeListItem:     true,
+	adf.NodePanel:             true,
+	adf.NodeExpand:            true,
+	adf.NodeTable:             true,
+	adf.ChildNodeTableRow:     true,
+	adf.ChildNodeTableHeader:  true,
+	adf.ChildNodeTableCell:    true,
+	adf.NodeMedia:             true,
+	adf.NodeMediaSingle:       true,
+	adf.NodeMediaGroup:        true,
+	adf.NodeCaption:           true,
+	adf.NodeDecisionList:      true,
+	adf.ChildNodeDecisionItem: true,
+	adf.NodeLayoutSection:     true,
+	adf.ChildNodeLayoutColumn: true,
+	adf.ChildNodeText:         true,
+	adf.InlineNodeHardBreak:   true,
+	adf.InlineNodeMention:     true,
+	adf.InlineNodeEmoji:       true,
+	adf.InlineNodeStatus:      true,
+	adf.InlineNodeCard:        true,
+}
+
+// inlineParents are the node types whose content is inline.
+var inlineParents = map[adf.NodeType]bool{
+	adf.NodeParagraph:         true,
+	adf.NodeHeading:           true,
+	adf.NodeCaption:           true,
+	adf.ChildNodeDecisionItem: true,
+}
+
+// UnknownNodePolicy tells how a node of a type the translator doesn't know,
+// such as an extension or a layout section, is rendered.
+type UnknownNodePolicy int
+
+const (
+	// UnknownNodePlaceholder renders a visible "{unsupported:type}"
+	// placeholder followed by the node's content.
+	UnknownNodePlaceholder UnknownNodePolicy = iota
+	// UnknownNodeSkip leaves the node and its content out.
+	UnknownNodeSkip
+	// UnknownNodeRawJSON renders the node as its ADF JSON, in a code fence or
+	// a code span for inline nodes, so that nothing is lost.
+	UnknownNodeRawJSON
+)
+
+// WithUnknownNodePolicy sets how nodes of unknown types are rendered. The
+// default is UnknownNodePlaceholder. Whatever the policy, UnknownTypes
+// reports the types met and every such node gets a WarningUnknownNode.
+func WithUnknownNodePolicy(policy UnknownNodePolicy) TranslatorOption {
+	return func(a *Translator) {
+		a.unknownPolicy = policy
+	}
+}
+
+// UnknownTypes returns the distinct node types the last Translate call didn't
+// know, in the order they were first met.
+func (a *Translator) UnknownTypes() []adf.NodeType {
+	return slices.Clone(a.unknownTypes)
+}
+
+// visitUnknown renders a node of an unknown type under the unknown node
+// policy. It reports whether the node's content is still to be visited.
+func (a *Translator) visitUnknown(n, parent *adf.ADFNode) bool {
+	if !slices.Contains(a.unknownTypes, n.Type) {
+		a.unknownTypes = append(a.unknownTypes, n.Type)
+	}
+	inline := parent != nil && inlineParents[parent.Type]
+
+	switch a.unknownPolicy {
+	case UnknownNodeSkip:
+		a.warn(WarningUnknownNode, "node of unknown type %s skipped", n.Type)
+		return false
+	case UnknownNodeRawJSON:
+		a.warn(WarningUnknownNode, "node of unknown type %s rendered as its JSON", n.Type)
+		a.emit(rawNode(n, inline))
+		return false
+	}
+
+	a.warn(WarningUnknownNode, "node of unknown type %s rendered as a placeholder", n.Type)
+	placeholder := fmt.Sprintf("{unsupported:%s}", n.Type)
+	if !inline {
+		placeholder += "\n\n"
+	}
+	a.emit(placeholder)
+	return true
+}
+
+// rawNode renders n as its ADF JSON: indented in a code fence, or compact in
+// a code span for an inline node.
+func rawNode(n *adf.ADFNode, inline bool) string {
+	if inline {
+		raw, err := json.Marshal(n)
+		if err != nil {
+			return ""
+		}
+		delim := codeSpanDelimiter(string(raw))
+		return delim + string(raw) + reverse(delim)
+	}
+
+	raw, err := json.MarshalIndent(n, "", "  ")
+	if err != nil {
+		return ""
+	}
+	fence := strings.Repeat("`", max(longestBacktickRun(string(raw))+1, 3))
+	return fence + "json\n" + string(raw) + "\n" + fence + "\n\n"
+}
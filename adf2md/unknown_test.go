@@ -0,0 +1,103 @@
+package adf2md
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jorres/md2adf-translator/adf"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnknownNodePolicy(t *testing.T) {
+	date := &adf.ADFNode{Type: "date", Attrs: map[string]any{"timestamp": "1700000000000"}}
+	extension := &adf.ADFNode{
+		Type:    "bodiedExtension",
+		Attrs:   map[string]any{"extensionKey": "toc"},
+		Content: []*adf.ADFNode{adf.Paragraph(adf.Text("Inside")).Node()},
+	}
+	paragraph := adf.Paragraph(adf.Text("Due ")).Node()
+	paragraph.Content = append(paragraph.Content, date)
+	doc := adf.NewDocBuilder().
+		AppendNodes(paragraph, extension).
+		Paragraph(adf.Text("After")).
+		Build()
+
+	tests := []struct {
+		name     string
+		policy   UnknownNodePolicy
+		expected string
+	}{
+		{
+			name:     "placeholder",
+			policy:   UnknownNodePlaceholder,
+			expected: "Due {unsupported:date}\n\n{unsupported:bodiedExtension}\n\nInside\n\nAfter\n\n",
+		},
+		{
+			name:     "skip",
+			policy:   UnknownNodeSkip,
+			expected: "Due \n\nAfter\n\n",
+		},
+		{
+			name:   "raw JSON",
+			policy: UnknownNodeRawJSON,
+			expected: "Due `{\"type\":\"date\",\"attrs\":{\"timestamp\":\"1700000000000\"}}`\n\n" +
+				"```json\n{\n  \"type\": \"bodiedExtension\",\n  \"content\": [\n    {\n      \"type\": \"paragraph\",\n" +
+				"      \"content\": [\n        {\n          \"type\": \"text\",\n          \"text\": \"Inside\"\n        }\n      ]\n" +
+				"    }\n  ],\n  \"attrs\": {\n    \"extensionKey\": \"toc\"\n  }\n}\n```\n\nAfter\n\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			translator := NewTranslator(NewMarkdownTranslator(), WithUnknownNodePolicy(tt.policy))
+			out, err := translator.TranslateDocument(doc)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, out)
+			assert.Equal(t, []adf.NodeType{"date", "bodiedExtension"}, translator.UnknownTypes())
+		})
+	}
+}
+
+func TestLossless(t *testing.T) {
+	date := &adf.ADFNode{Type: "date", Attrs: map[string]any{"timestamp": "1700000000000"}}
+	paragraph := adf.Paragraph(adf.Text("Due ")).Node()
+	paragraph.Content = append(paragraph.Content, date)
+	extension := &adf.ADFNode{Type: "extension", Attrs: map[string]any{"extensionKey": "toc"}}
+	merged := adf.Table(
+		adf.Row(adf.HeaderCell(adf.Text("A")), adf.HeaderCell(adf.Text("B"))),
+		adf.Row(adf.Cell(adf.Text("Both"))),
+	).Node()
+	merged.Content[1].Content[0].Attrs = map[string]any{"colspan": 2}
+	simple := adf.Table(
+		adf.Row(adf.HeaderCell(adf.Text("A"))),
+		adf.Row(adf.Cell(adf.Text("1"))),
+	).Node()
+
+	tests := []struct {
+		name string
+		node *adf.ADFNode
+		raw  bool
+	}{
+		{name: "unknown block", node: extension, raw: true},
+		{name: "unknown inline node", node: paragraph, raw: true},
+		{name: "merged cells", node: merged, raw: true},
+		{name: "simple table", node: simple, raw: false},
+		{name: "text", node: adf.Paragraph(adf.Text("Plain")).Node(), raw: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := adf.NewDocBuilder().AppendNodes(tt.node).Build()
+			out, err := NewTranslator(NewMarkdownTranslator(), WithLossless()).TranslateDocument(doc)
+			assert.NoError(t, err)
+			if !tt.raw {
+				assert.NotContains(t, out, RawADFLanguage)
+				return
+			}
+
+			raw, err := json.MarshalIndent(tt.node, "", "  ")
+			assert.NoError(t, err)
+			assert.Equal(t, "```adf-raw\n"+string(raw)+"\n```\n\n", out)
+		})
+	}
+}
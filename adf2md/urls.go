@@ -0,0 +1,126 @@
+package adf2md
+
+import (
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/jorres/md2adf-translator/adf"
+)
+
+// trackingParams are query parameters that only record where a link was
+// clicked and don't change the resource it points to.
+var trackingParams = []string{"atlOrigin"}
+
+// WithURLNormalizer sets the function inline card URLs are normalized with
+// before they are preserved and looked up, so that differently written URLs
+// of the same resource match. It defaults to NormalizeURL; nil matches URLs
+// exactly.
+func WithURLNormalizer(normalize func(string) string) TranslatorOption {
+	return func(a *Translator) {
+		a.normalizeURL = normalize
+	}
+}
+
+// WithLinkRewriter sets a function rewriting the href of every link mark and
+// the URL of every inline card as they are rendered, such as to point links
+// at a mirror. The document is left alone, and inline cards are preserved
+// under the URL they have in it.
+func WithLinkRewriter(rewrite func(href string) string) TranslatorOption {
+	return func(a *Translator) {
+		a.linkRewriter = rewrite
+	}
+}
+
+// rewriteLinks returns n with the link rewriter applied to its card URL and
+// link marks: a copy if that changes any, n itself otherwise.
+func (a *Translator) rewriteLinks(n *adf.ADFNode) *adf.ADFNode {
+	if a.linkRewriter == nil {
+		return n
+	}
+
+	rewritten := n
+	if url, ok := n.CardURL(); ok {
+		if to := a.linkRewriter(url); to != url {
+			c := *n
+			c.Attrs = maps.Clone(n.Attrs)
+			c.Attrs["url"] = to
+			rewritten = &c
+		}
+	}
+	var marks []*adf.ADFMark // those of n, copied once a link is rewritten
+	for i, m := range n.Marks {
+		if m == nil || m.Type != adf.MarkLink {
+			continue
+		}
+		href, _ := m.Attrs["href"].(string)
+		to := a.linkRewriter(href)
+		if to == href {
+			continue
+		}
+		if marks == nil {
+			marks = slices.Clone(n.Marks)
+		}
+		link := *m
+		link.Attrs = maps.Clone(m.Attrs)
+		if link.Attrs == nil {
+			link.Attrs = make(map[string]any)
+		}
+		link.Attrs["href"] = to
+		marks[i] = &link
+	}
+	if marks != nil {
+		if rewritten == n {
+			c := *n
+			rewritten = &c
+		}
+		rewritten.Marks = marks
+	}
+	return rewritten
+}
+
+// NormalizeURL lowercases the scheme and host of a URL, strips a trailing
+// slash from its path and drops tracking parameters such as atlOrigin and
+// utm_source. Strings that don't parse as absolute URLs are returned as is.
+func NormalizeURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return raw
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	u.RawPath = strings.TrimSuffix(u.RawPath, "/")
+
+	if u.RawQuery != "" {
+		query := u.Query()
+		for param := range query {
+			if strings.HasPrefix(param, "utm_") {
+				query.Del(param)
+			}
+		}
+		for _, param := range trackingParams {
+			query.Del(param)
+		}
+		u.RawQuery = query.Encode()
+	}
+
+	return u.String()
+}
+
+// InlineCard returns the preserved inline card for a URL, written in any way
+// the URL normalizer considers equivalent.
+func (a *Translator) InlineCard(url string) (*adf.ADFNode, bool) {
+	n, ok := a.inlineCardMapping[a.cardKey(url)]
+	return n, ok
+}
+
+// cardKey returns the key an inline card with the given URL is preserved under.
+func (a *Translator) cardKey(url string) string {
+	if a.normalizeURL == nil {
+		return url
+	}
+	return a.normalizeURL(url)
+}
@@ -0,0 +1,87 @@
+package adf2md
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jorres/md2adf-translator/adf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"https://example.atlassian.net/browse/PROJ-1", "https://example.atlassian.net/browse/PROJ-1"},
+		{"https://Example.Atlassian.NET/browse/PROJ-1/", "https://example.atlassian.net/browse/PROJ-1"},
+		{"HTTPS://example.com/", "https://example.com"},
+		{"https://example.com/wiki?atlOrigin=eyJpIjoi", "https://example.com/wiki"},
+		{"https://example.com/wiki?pageId=42&atlOrigin=x&utm_source=mail", "https://example.com/wiki?pageId=42"},
+		{"https://example.com/CaseSensitive/Path", "https://example.com/CaseSensitive/Path"},
+		{"not a url", "not a url"},
+		{"/relative/", "/relative/"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, NormalizeURL(tt.input), tt.input)
+	}
+}
+
+func TestInlineCardLookup(t *testing.T) {
+	card := &adf.ADFNode{Type: adf.InlineNodeCard, Attrs: map[string]any{"url": "https://Example.com/browse/PROJ-1/?atlOrigin=abc"}}
+	paragraph := adf.NewParagraphNode()
+	paragraph.Content = append(paragraph.Content, card)
+	doc := &adf.ADFNode{Type: "doc", Content: []*adf.ADFNode{paragraph}}
+
+	tr := NewTranslator(NewJiraMarkdownTranslator())
+	tr.Translate(doc)
+
+	for _, url := range []string{"https://example.com/browse/PROJ-1", "https://EXAMPLE.com/browse/PROJ-1/", card.Attrs["url"].(string)} {
+		found, ok := tr.InlineCard(url)
+		assert.True(t, ok, url)
+		assert.Same(t, card, found, url)
+	}
+	_, ok := tr.InlineCard("https://example.com/browse/PROJ-2")
+	assert.False(t, ok)
+
+	// A custom normalizer replaces the default one
+	exact := NewTranslator(NewJiraMarkdownTranslator(), WithURLNormalizer(nil))
+	exact.Translate(doc)
+	_, ok = exact.InlineCard("https://example.com/browse/PROJ-1")
+	assert.False(t, ok)
+
+	lower := NewTranslator(NewJiraMarkdownTranslator(), WithURLNormalizer(strings.ToLower))
+	lower.Translate(doc)
+	_, ok = lower.InlineCard("HTTPS://EXAMPLE.COM/BROWSE/PROJ-1/?ATLORIGIN=ABC")
+	assert.True(t, ok)
+}
+
+func TestLinkRewriter(t *testing.T) {
+	link := adf.NewTextNodeWithMarks("design", []*adf.ADFMark{adf.NewStrongMark(), adf.NewLinkMark("https://github.com/acme/repo/blob/main/design.md")})
+	other := adf.NewTextNodeWithMarks("site", []*adf.ADFMark{adf.NewLinkMark("https://example.com")})
+	card := &adf.ADFNode{Type: adf.InlineNodeCard, Attrs: map[string]any{"url": "https://acme.atlassian.net/browse/PROJ-1"}}
+	paragraph := adf.NewParagraphNode()
+	paragraph.Content = append(paragraph.Content, link, adf.NewTextNode(" "), other, adf.NewTextNode(" "), card)
+	doc := &adf.ADFDocument{Type: "doc", Content: []*adf.ADFNode{paragraph}}
+
+	tr := NewTranslator(NewMarkdownTranslator(), WithLinkRewriter(func(href string) string {
+		if path, ok := strings.CutPrefix(href, "https://github.com/acme/repo/blob/main/"); ok {
+			return "./" + path
+		}
+		if key, ok := strings.CutPrefix(href, "https://acme.atlassian.net/browse/"); ok {
+			return "https://go.acme/" + key
+		}
+		return href
+	}))
+	markdown, err := tr.TranslateDocument(doc)
+	require.NoError(t, err)
+	assert.Equal(t, "[**design**](./design.md) [site](https://example.com) [link](https://go.acme/PROJ-1)\n\n", markdown)
+
+	// The document is left alone, and the card is preserved under its URL
+	assert.Equal(t, "https://github.com/acme/repo/blob/main/design.md", link.Marks[1].Attrs["href"])
+	assert.Equal(t, "https://acme.atlassian.net/browse/PROJ-1", card.Attrs["url"])
+	_, ok := tr.InlineCard("https://acme.atlassian.net/browse/PROJ-1")
+	assert.True(t, ok)
+}
@@ -0,0 +1,47 @@
+package adf2md
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/jorres/md2adf-translator/adf"
+)
+
+// Warning reports something of the document a translation couldn't render
+// faithfully.
+type Warning struct {
+	Code    string // one of the Warning constants
+	Path    []int  // of the node, as in NodeError
+	Message string
+}
+
+func (w Warning) String() string {
+	return adf.FormatPath(w.Path) + ": " + w.Message
+}
+
+// Warning codes.
+const (
+	// WarningLayoutDiscarded reports a layout section whose columns were
+	// rendered one below the other.
+	WarningLayoutDiscarded = "layout_discarded"
+	// WarningUnknownNode reports a node of an unknown type, rendered under
+	// the unknown node policy.
+	WarningUnknownNode = "unknown_node"
+)
+
+// Warnings returns the warnings of the last Translate call in document order.
+func (a *Translator) Warnings() []Warning {
+	return slices.Clone(a.warnings)
+}
+
+// warn records a warning about the node being visited and reports it to the
+// metrics collector.
+func (a *Translator) warn(code, format string, args ...any) {
+	a.warnings = append(a.warnings, Warning{Code: code, Path: a.path(), Message: fmt.Sprintf(format, args...)})
+	if a.metrics != nil {
+		a.metrics.Observe(adf.MetricWarnings, 1, map[string]string{
+			adf.LabelDirection: adf.DirectionADFToMarkdown,
+			adf.LabelCode:      code,
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package adf2md
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jorres/md2adf-translator/adf"
+)
+
+// UnsupportedNodeError is returned for a node or mark the output format has
+// no equivalent for.
+type UnsupportedNodeError struct {
+	Type adf.NodeType
+}
+
+func (e *UnsupportedNodeError) Error() string {
+	return fmt.Sprintf("adf2md: %s has no wiki markup equivalent", e.Type)
+}
+
+// wikiTypes are the node and mark types WikiMarkupTranslator renders. The
+// types md2adf.CheckSafeForV2 reports as unsafe are not among them.
+var wikiTypes = map[adf.NodeType]bool{
+	"doc":                    true,
+	adf.NodeParagraph:        true,
+	adf.NodeHeading:          true,
+	adf.NodeBlockquote:       true,
+	adf.NodeCodeBlock:        true,
+	adf.NodeBulletList:       true,
+	adf.NodeOrderedList:      true,
+	adf.ChildNodeListItem:    true,
+	adf.NodeTable:            true,
+	adf.ChildNodeTableRow:    true,
+	adf.ChildNodeTableHeader: true,
+	adf.ChildNodeTableCell:   true,
+	adf.ChildNodeText:        true,
+	adf.MarkStrong:           true,
+	adf.MarkEm:               true,
+	adf.MarkCode:             true,
+	adf.MarkStrike:           true,
+	adf.MarkLink:             true,
+	adf.MarkAlignment:        true,
+}
+
+// WikiMarkupTranslator renders ADF as the Jira wiki markup of the v2 REST API.
+// It refuses nodes the markup can't represent, such as panels and mentions,
+// with an *UnsupportedNodeError.
+type WikiMarkupTranslator struct {
+	lists     []string // markers of the open lists, "*" or "#", innermost last
+	inCell    int      // open table cells
+	inCode    bool     // inside a code block, whose text is verbatim
+	cellDelim string   // delimiter of the last cell of the row, "|" or "||"
+}
+
+// NewWikiMarkupTranslator constructs a wiki markup translator.
+func NewWikiMarkupTranslator() *WikiMarkupTranslator {
+	return &WikiMarkupTranslator{}
+}
+
+// Accept implements NodeFilter.
+func (*WikiMarkupTranslator) Accept(n Connector) error {
+	if !wikiTypes[n.GetType()] {
+		return &UnsupportedNodeError{Type: n.GetType()}
+	}
+	return nil
+}
+
+// RenderText implements TextRenderer. Code block text is verbatim; elsewhere
+// the characters of wiki markup are backslash-escaped.
+func (tr *WikiMarkupTranslator) RenderText(text string, _ bool) string {
+	if tr.inCode {
+		return strings.TrimRight(text, "\n")
+	}
+	return escapeWiki(strings.ReplaceAll(text, "\n", " "))
+}
+
+// Open implements TagOpener interface.
+//
+//nolint:gocyclo
+func (tr *WikiMarkupTranslator) Open(n Connector, _ int) string {
+	attrs, _ := n.GetAttributes().(map[string]any)
+
+	switch n.GetType() {
+	case adf.NodeHeading:
+		return fmt.Sprintf("h%d. ", adf.HeadingLevel(attrs))
+	case adf.NodeCodeBlock:
+		tr.inCode = true
+	case adf.NodeBulletList:
+		tr.lists = append(tr.lists, "*")
+	case adf.NodeOrderedList:
+		tr.lists = append(tr.lists, "#")
+	case adf.ChildNodeListItem:
+		return strings.Join(tr.lists, "") + " "
+	case adf.ChildNodeTableHeader:
+		tr.inCell++
+		tr.cellDelim = "||"
+		return "||"
+	case adf.ChildNodeTableCell:
+		tr.inCell++
+		tr.cellDelim = "|"
+		return "|"
+	case adf.MarkStrong:
+		return "*"
+	case adf.MarkEm:
+		return "_"
+	case adf.MarkCode:
+		return "{{"
+	case adf.MarkStrike:
+		return "-"
+	case adf.MarkLink:
+		return "["
+	}
+	return ""
+}
+
+// Close implements TagCloser interface.
+//
+//nolint:gocyclo
+func (tr *WikiMarkupTranslator) Close(n Connector) string {
+	attrs, _ := n.GetAttributes().(map[string]any)
+
+	switch n.GetType() {
+	case adf.NodeParagraph:
+		switch {
+		case tr.inCell > 0:
+			return ""
+		case len(tr.lists) > 0:
+			return "\n"
+		}
+		return "\n\n"
+	case adf.NodeHeading:
+		return "\n\n"
+	case adf.NodeCodeBlock:
+		tr.inCode = false
+	case adf.NodeBulletList, adf.NodeOrderedList:
+		tr.lists = tr.lists[:len(tr.lists)-1]
+		if len(tr.lists) == 0 {
+			return "\n"
+		}
+	case adf.ChildNodeTableHeader, adf.ChildNodeTableCell:
+		tr.inCell--
+	case adf.ChildNodeTableRow:
+		return tr.cellDelim + "\n"
+	case adf.NodeTable:
+		return "\n"
+	case adf.MarkStrong:
+		return "*"
+	case adf.MarkEm:
+		return "_"
+	case adf.MarkCode:
+		return "}}"
+	case adf.MarkStrike:
+		return "-"
+	case adf.MarkLink:
+		href, _ := attrs["href"].(string)
+		return "|" + href + "]"
+	}
+	return ""
+}
+
+// WrapsContent implements ContentWrapper: quotes and code blocks are fenced.
+func (*WikiMarkupTranslator) WrapsContent(n Connector) bool {
+	switch n.GetType() {
+	case adf.NodeBlockquote, adf.NodeCodeBlock:
+		return true
+	}
+	return false
+}
+
+// WrapContent implements ContentWrapper.
+func (*WikiMarkupTranslator) WrapContent(n Connector, content string) string {
+	content = strings.TrimRight(content, "\n")
+	if n.GetType() == adf.NodeBlockquote {
+		return "{quote}\n" + content + "\n{quote}\n\n"
+	}
+
+	macro := "{code}"
+	if attrs, ok := n.GetAttributes().(map[string]any); ok {
+		if language, _ := attrs["language"].(string); language != "" {
+			macro = "{code:" + language + "}"
+		}
+	}
+	return macro + "\n" + content + "\n{code}\n\n"
+}
+
+// escapeWiki backslash-escapes the characters of plain text that wiki markup
+// would read as formatting. A hyphen or plus inside a word, as in
+// "well-known", can't start strikethrough or underline and is kept as it is.
+func escapeWiki(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		escape := strings.IndexByte(`*_{}[]|\!^~`, c) >= 0
+		switch c {
+		case '-', '+':
+			escape = i == 0 || i+1 == len(s) || !isWordByte(s[i-1]) || !isWordByte(s[i+1])
+		case '?':
+			escape = i+1 < len(s) && s[i+1] == '?'
+		}
+		if escape {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
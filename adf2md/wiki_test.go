@@ -0,0 +1,78 @@
+package adf2md
+
+import (
+	"testing"
+
+	"github.com/jorres/md2adf-translator/adf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWikiMarkupTranslator(t *testing.T) {
+	doc := adf.NewDocBuilder().
+		Heading(2, "Release notes").
+		Paragraph(adf.Text("A "), adf.Bold("bold"), adf.Text(", "), adf.Italic("well-known"), adf.Text(" "),
+			adf.Code("x := 1"), adf.Text(" and "), adf.Link("docs", "https://example.com"), adf.Text(" - not *this*")).
+		CodeBlock("go", "if a {\n\tb()\n}").
+		Blockquote(adf.Paragraph(adf.Text("quoted")), adf.Paragraph(adf.Strike("gone"))).
+		BulletList(
+			adf.ItemBlocks(adf.Paragraph(adf.Text("one")), adf.OrderedList(1, adf.Item(adf.Text("first")))),
+			adf.Item(adf.Text("two")),
+		).
+		Table(
+			adf.Row(adf.HeaderCell(adf.Text("Name")), adf.HeaderCell(adf.Text("State"))),
+			adf.Row(adf.Cell(adf.Text("a|b")), adf.Cell(adf.Bold("done"))),
+		).
+		Build()
+
+	out, err := NewTranslator(NewWikiMarkupTranslator()).TranslateDocument(doc)
+	require.NoError(t, err)
+
+	expected := `h2. Release notes
+
+A *bold*, _well-known_ {{x := 1}} and [docs|https://example.com] \- not \*this\*
+
+{code:go}
+if a {
+	b()
+}
+{code}
+
+{quote}
+quoted
+
+-gone-
+{quote}
+
+* one
+*# first
+* two
+
+||Name||State||
+|a\|b|*done*|
+
+`
+	assert.Equal(t, expected, out)
+}
+
+func TestWikiMarkupRefusesUnsupportedNodes(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  *adf.ADFDocument
+		want adf.NodeType
+	}{
+		{"panel", adf.NewDocBuilder().Panel("info", adf.Paragraph(adf.Text("x"))).Build(), adf.NodePanel},
+		{"mention", adf.NewDocBuilder().Paragraph(adf.Text("hi "), adf.Mention("abc", "@Jane")).Build(), adf.InlineNodeMention},
+		{"underline", adf.NewDocBuilder().Paragraph(adf.Underlined("x")).Build(), adf.MarkUnderline},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := NewTranslator(NewWikiMarkupTranslator()).TranslateDocument(tt.doc)
+			var unsupported *UnsupportedNodeError
+			require.ErrorAs(t, err, &unsupported)
+			assert.Equal(t, tt.want, unsupported.Type)
+			assert.Empty(t, out)
+		})
+	}
+}
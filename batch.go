@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jorres/md2adf-translator/adf"
+	"github.com/jorres/md2adf-translator/md2adf"
+)
+
+// convertFiles translates every markdown file of files to ADF, writing each
+// document to <name>.adf.json in outDir or, without outDir, as a line of
+// JSON to stdout. A file that fails is reported and skipped. It prints a
+// summary and returns the exit code: 1 if any file failed.
+func convertFiles(translator *md2adf.Translator, files []string, outDir string) int {
+	if outDir != "" {
+		if err := os.MkdirAll(outDir, 0o755); err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating output directory: %v\n", err)
+			return 1
+		}
+	}
+
+	var failed int
+	for _, file := range files {
+		if err := convertFile(translator, file, outDir); err != nil {
+			fmt.Fprintf(os.Stderr, "Error converting %s: %v\n", file, err)
+			failed++
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "%d converted, %d failed\n", len(files)-failed, failed)
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// convertFile translates one file of convertFiles.
+func convertFile(translator *md2adf.Translator, file, outDir string) error {
+	input, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	doc, err := translator.TranslateToADF(input)
+	if err != nil {
+		return err
+	}
+	warnUnmappedMentions(translator, file, input)
+
+	if outDir == "" {
+		return printJSONLine(doc)
+	}
+
+	name := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file)) + ".adf.json"
+	out, err := os.OpenFile(filepath.Join(outDir, name), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if err := doc.WriteJSON(out); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// printJSONLine prints doc as JSON on a single line.
+func printJSONLine(doc *adf.ADFDocument) error {
+	line, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(line))
+	return nil
+}
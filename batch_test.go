@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jorres/md2adf-translator/adf"
+	"github.com/jorres/md2adf-translator/md2adf"
+)
+
+func TestConvertFilesOutDir(t *testing.T) {
+	dir := t.TempDir()
+	files := []string{
+		writeFile(t, "intro.md", "# Intro\n"),
+		writeFile(t, "notes.txt.md", "Some *notes*\n"),
+	}
+	outDir := filepath.Join(dir, "out", "adf")
+
+	var code int
+	stderr := capture(t, &os.Stderr, func() {
+		code = convertFiles(md2adf.NewTranslator(), files, outDir)
+	})
+	if code != 0 {
+		t.Errorf("Expected exit code 0, got %d", code)
+	}
+	if stderr != "2 converted, 0 failed\n" {
+		t.Errorf("Expected a summary of 2 converted files, got %q", stderr)
+	}
+
+	for name, text := range map[string]string{"intro.adf.json": "Intro", "notes.txt.adf.json": "notes"} {
+		data, err := os.ReadFile(filepath.Join(outDir, name))
+		if err != nil {
+			t.Fatalf("Failed to read the output: %v", err)
+		}
+		var doc adf.ADFDocument
+		if err := json.Unmarshal(data, &doc); err != nil {
+			t.Fatalf("Failed to parse %s: %v", name, err)
+		}
+		if !strings.Contains(adf.PlainText(&doc), text) {
+			t.Errorf("Expected %s to hold %q, got:\n%s", name, text, data)
+		}
+	}
+}
+
+func TestConvertFilesPartialFailure(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing.md")
+	files := []string{
+		writeFile(t, "first.md", "first\n"),
+		missing,
+		writeFile(t, "last.md", "last\n"),
+	}
+
+	var code int
+	var stdout string
+	stderr := capture(t, &os.Stderr, func() {
+		stdout = captureStdout(t, func() {
+			code = convertFiles(md2adf.NewTranslator(), files, "")
+		})
+	})
+	if code != 1 {
+		t.Errorf("Expected exit code 1, got %d", code)
+	}
+	if !strings.Contains(stderr, "Error converting "+missing+": ") || !strings.HasSuffix(stderr, "2 converted, 1 failed\n") {
+		t.Errorf("Expected the missing file to be reported, got:\n%s", stderr)
+	}
+
+	// The files around the failure are still printed, a line each
+	lines := strings.Split(strings.TrimSuffix(stdout, "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines of JSON, got:\n%s", stdout)
+	}
+	for i, text := range []string{"first", "last"} {
+		var doc adf.ADFDocument
+		if err := json.Unmarshal([]byte(lines[i]), &doc); err != nil {
+			t.Fatalf("Failed to parse line %d: %v", i+1, err)
+		}
+		if got := strings.TrimSpace(adf.PlainText(&doc)); got != text {
+			t.Errorf("Expected line %d to hold %q, got %q", i+1, text, got)
+		}
+	}
+}
@@ -1,52 +1,200 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"io"
-	"github.com/jorres/md2adf-translator/md2adf"
 	"os"
+	"slices"
+	"strings"
+
+	"github.com/jorres/md2adf-translator/adf"
+	"github.com/jorres/md2adf-translator/adf2md"
+	"github.com/jorres/md2adf-translator/md2adf"
 )
 
+// exitCheckFailed is the exit code of --check-v2 and --verify-roundtrip when
+// the check fails.
+const exitCheckFailed = 3
+
 func main() {
+	reverse := flag.Bool("reverse", false, "translate an ADF JSON document to markdown")
+	jira := flag.Bool("jira", false, "with --reverse, write Jira panel syntax")
+	github := flag.Bool("github", false, "with --reverse, write GitHub-flavored markdown without Jira syntax")
+	unknownNodes := flag.String("unknown-nodes", "placeholder", "with --reverse, how to write nodes markdown has no syntax for: placeholder, skip or json")
+	lossless := flag.Bool("lossless", false, "with --reverse, embed nodes markdown can't hold without loss as adf-raw JSON blocks that translate back verbatim")
+	checkV2 := flag.Bool("check-v2", false, "only check whether the markdown can be posted through the v2 API: exit 0 if safe, 3 if not")
+	verify := flag.Bool("verify-roundtrip", false, "only check that translating the markdown to ADF and back loses nothing; print a diff and exit 3 if it does")
+	outDir := flag.String("out-dir", "", "write the ADF of each input file to <name>.adf.json in this directory")
+	emptyParagraphs := flag.Bool("empty-paragraphs", false, "keep extra blank lines between paragraphs as empty paragraphs")
+	userMapPath := flag.String("user-map", os.Getenv("MD2ADF_USER_MAP"), "JSON file mapping mention emails to account IDs (default $MD2ADF_USER_MAP)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [file...]\n\nReads markdown (or ADF JSON with --reverse) from files or stdin. The ADF of\nseveral files is written as one JSON document per line unless --out-dir is set.\n\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	batch := flag.NArg() > 1 || *outDir != ""
+	if batch && (*reverse || *checkV2 || *verify) {
+		fmt.Fprintln(os.Stderr, "Error: --reverse, --check-v2 and --verify-roundtrip take a single input")
+		os.Exit(2)
+	}
+	if *jira && *github {
+		fmt.Fprintln(os.Stderr, "Error: --jira and --github are exclusive")
+		os.Exit(2)
+	}
+	unknownPolicy, ok := unknownNodePolicies[*unknownNodes]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Error: unknown --unknown-nodes value %q\n", *unknownNodes)
+		os.Exit(2)
+	}
+
 	var input []byte
 	var err error
 
-	if len(os.Args) > 1 {
-		filename := os.Args[1]
-		input, err = os.ReadFile(filename)
+	if !batch {
+		input, err = readInput()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error reading file %s: %v\n", filename, err)
+			fmt.Fprintf(os.Stderr, "Error %v\n", err)
 			os.Exit(1)
 		}
-	} else {
-		input, err = io.ReadAll(os.Stdin)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error reading from stdin: %v\n", err)
-			os.Exit(1)
+
+		if *reverse {
+			var tagger adf2md.TagOpenerCloser = adf2md.NewMarkdownTranslator()
+			if *jira {
+				tagger = adf2md.NewJiraMarkdownTranslator()
+			} else if *github {
+				tagger = adf2md.NewGitHubMarkdownTranslator()
+			}
+			opts := []adf2md.TranslatorOption{adf2md.WithUnknownNodePolicy(unknownPolicy)}
+			if *lossless {
+				opts = append(opts, adf2md.WithLossless())
+			}
+			translateToMarkdown(input, tagger, opts...)
+			return
 		}
 	}
 
-	// Sample user mapping for testing
-	userMapping := map[string]string{
-		"@jorres@nebius.com": "6acd447c-fd28-4da8-b7cb-5b95d4405540",
+	var userMapping map[string]string
+	if *userMapPath != "" {
+		userMapping, err = loadUserMap(*userMapPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading user map: %v\n", err)
+			os.Exit(1)
+		}
 	}
 
 	translator := md2adf.NewTranslator(
 		md2adf.WithUserEmailMapping(userMapping),
+		md2adf.WithEmptyParagraphs(*emptyParagraphs),
 	)
 
+	if batch {
+		os.Exit(convertFiles(translator, flag.Args(), *outDir))
+	}
+	if *checkV2 {
+		os.Exit(checkSafeForV2(translator, input))
+	}
+	if *verify {
+		os.Exit(verifyRoundtrip(translator, input))
+	}
+
 	adfDoc, err := translator.TranslateToADF(input)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error parsing markdown: %v\n", err)
 		os.Exit(1)
 	}
 
+	warnUnmappedMentions(translator, "", input)
+
 	// Output ADF JSON
-	jsonOutput, err := adfDoc.ToJSON()
+	if err := adfDoc.WriteJSON(os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing JSON: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// readInput reads the single input file or stdin.
+func readInput() ([]byte, error) {
+	if flag.NArg() > 0 {
+		input, err := os.ReadFile(flag.Arg(0))
+		if err != nil {
+			return nil, fmt.Errorf("reading file %s: %w", flag.Arg(0), err)
+		}
+		return input, nil
+	}
+
+	input, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return nil, fmt.Errorf("reading from stdin: %w", err)
+	}
+	return input, nil
+}
+
+// unknownNodePolicies are the values of --unknown-nodes.
+var unknownNodePolicies = map[string]adf2md.UnknownNodePolicy{
+	"placeholder": adf2md.UnknownNodePlaceholder,
+	"skip":        adf2md.UnknownNodeSkip,
+	"json":        adf2md.UnknownNodeRawJSON,
+}
+
+// translateToMarkdown prints the markdown tagger renders of the ADF JSON
+// document in input as it is rendered, and lists on stderr the node types it
+// has no syntax for.
+func translateToMarkdown(input []byte, tagger adf2md.TagOpenerCloser, opts ...adf2md.TranslatorOption) {
+	doc, err := adf.FromJSON(input)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error converting to JSON: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error parsing ADF document: %v\n", err)
 		os.Exit(1)
 	}
 
-	fmt.Println(string(jsonOutput))
+	translator := adf2md.NewTranslator(tagger, opts...)
+	if err := translator.TranslateDocumentTo(os.Stdout, doc); err != nil {
+		fmt.Fprintf(os.Stderr, "Error translating ADF document: %v\n", err)
+		os.Exit(1)
+	}
+
+	for _, w := range translator.Warnings() {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", w)
+	}
+}
+
+// warnUnmappedMentions lists on stderr the mentioned emails of input that have
+// no account ID in the user map. name is the input file, if any.
+func warnUnmappedMentions(translator *md2adf.Translator, name string, input []byte) {
+	mentions, err := translator.ExtractMentions(input)
+	if err != nil {
+		return
+	}
+
+	var missing []string
+	for _, mention := range mentions {
+		if mention.AccountID == "" && !slices.Contains(missing, mention.Email) {
+			missing = append(missing, mention.Email)
+		}
+	}
+	if len(missing) > 0 {
+		if name != "" {
+			name += ": "
+		}
+		fmt.Fprintf(os.Stderr, "Warning: %smentions not found in the user map: %s\n", name, strings.Join(missing, ", "))
+	}
+}
+
+// checkSafeForV2 prints the node types of input the v2 API can't represent,
+// one per line, and returns the exit code of --check-v2.
+func checkSafeForV2(translator *md2adf.Translator, input []byte) int {
+	report, err := translator.AnalyzeV2Safety(string(input))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing markdown: %v\n", err)
+		return 1
+	}
+
+	for _, t := range report.UnsafeTypes() {
+		fmt.Fprintln(os.Stderr, t)
+	}
+	if !report.Safe {
+		return exitCheckFailed
+	}
+	return 0
 }
@@ -0,0 +1,142 @@
+package md2adf
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jorres/md2adf-translator/adf2md"
+)
+
+// loadBenchmarkFixture reads a markdown document of testdata.
+func loadBenchmarkFixture(b *testing.B, name string) []byte {
+	content, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		b.Fatalf("Failed to read fixture: %v", err)
+	}
+	return content
+}
+
+func benchmarkTranslate(b *testing.B, content []byte) {
+	translator := NewTranslator()
+	b.SetBytes(int64(len(content)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := translator.TranslateToADF(content); err != nil {
+			b.Fatalf("Failed to convert markdown: %v", err)
+		}
+	}
+}
+
+func BenchmarkTranslateSmall(b *testing.B) {
+	benchmarkTranslate(b, loadBenchmarkFixture(b, "small.md"))
+}
+
+func BenchmarkTranslateMedium(b *testing.B) {
+	benchmarkTranslate(b, loadBenchmarkFixture(b, "medium.md"))
+}
+
+func BenchmarkTranslateLarge(b *testing.B) {
+	// A long issue description, the medium one over and over
+	medium := loadBenchmarkFixture(b, "medium.md")
+	benchmarkTranslate(b, bytes.Repeat(append(medium, '\n'), 50))
+}
+
+func BenchmarkAdf2Md(b *testing.B) {
+	translator := NewTranslator()
+	doc, err := translator.TranslateToADF(loadBenchmarkFixture(b, "medium.md"))
+	if err != nil {
+		b.Fatalf("Failed to convert markdown: %v", err)
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := translator.TranslateToMarkdown(doc); err != nil {
+			b.Fatalf("Failed to render markdown: %v", err)
+		}
+	}
+}
+
+// BenchmarkTranslateMegabyte translates a document of about 1MB, the size
+// limit of a Jira field, to JSON and back to markdown: once handing every
+// result over whole, once writing the outputs to their writers.
+func BenchmarkTranslateMegabyte(b *testing.B) {
+	medium := append(loadBenchmarkFixture(b, "medium.md"), '\n')
+	markdown := string(bytes.Repeat(medium, 1<<20/len(medium)+1))
+
+	b.Run("Whole", func(b *testing.B) {
+		translator := NewTranslator()
+		defer translator.Close()
+		reverse := adf2md.NewTranslator(adf2md.NewMarkdownTranslator())
+		b.SetBytes(int64(len(markdown)))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			doc, err := translator.TranslateToADF([]byte(markdown))
+			if err != nil {
+				b.Fatalf("Failed to convert markdown: %v", err)
+			}
+			out, err := doc.ToJSON()
+			if err != nil {
+				b.Fatalf("Failed to encode the document: %v", err)
+			}
+			io.Discard.Write(out)
+			rendered, err := reverse.TranslateDocument(doc)
+			if err != nil {
+				b.Fatalf("Failed to render markdown: %v", err)
+			}
+			io.WriteString(io.Discard, rendered)
+		}
+	})
+
+	b.Run("Writers", func(b *testing.B) {
+		translator := NewTranslator()
+		defer translator.Close()
+		reverse := adf2md.NewTranslator(adf2md.NewMarkdownTranslator())
+		b.SetBytes(int64(len(markdown)))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			doc, err := translator.TranslateReader(strings.NewReader(markdown))
+			if err != nil {
+				b.Fatalf("Failed to convert markdown: %v", err)
+			}
+			if err := doc.WriteJSON(io.Discard); err != nil {
+				b.Fatalf("Failed to encode the document: %v", err)
+			}
+			if err := reverse.TranslateDocumentTo(io.Discard, doc); err != nil {
+				b.Fatalf("Failed to render markdown: %v", err)
+			}
+		}
+	})
+}
+
+// BenchmarkTranslateConcurrent compares building a Translator for every
+// document, as a server translating concurrently has to without a pool,
+// with taking one from a TranslatorPool.
+func BenchmarkTranslateConcurrent(b *testing.B) {
+	content := loadBenchmarkFixture(b, "small.md")
+
+	b.Run("NewTranslator", func(b *testing.B) {
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				if _, err := NewTranslator().TranslateToADF(content); err != nil {
+					b.Errorf("Failed to convert markdown: %v", err)
+				}
+			}
+		})
+	})
+
+	b.Run("Pool", func(b *testing.B) {
+		pool := NewTranslatorPool()
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				if _, err := pool.TranslateToADF(content); err != nil {
+					b.Errorf("Failed to convert markdown: %v", err)
+				}
+			}
+		})
+	})
+}
@@ -2,7 +2,9 @@ package md2adf
 
 import (
 	"encoding/json"
+	"fmt"
 	"github.com/jorres/md2adf-translator/adf"
+	"strings"
 	"testing"
 )
 
@@ -379,3 +381,263 @@ func TestValidADFOutput(t *testing.T) {
 		t.Fatalf("Invalid ADF document structure")
 	}
 }
+
+func TestCodeBlockLanguageAliases(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []TranslatorOption
+		info     string
+		expected string
+	}{
+		{"alias", nil, "golang", "go"},
+		{"alias case-insensitive", nil, "YML", "yaml"},
+		{"canonical", nil, "javascript", "javascript"},
+		{"unknown kept", nil, "brainfuck", "brainfuck"},
+		{"custom alias", []TranslatorOption{WithLanguageAliases(map[string]string{"Hs": "haskell"})}, "hs", "haskell"},
+		{"overridden alias", []TranslatorOption{WithLanguageAliases(map[string]string{"sh": "shell"})}, "sh", "shell"},
+		{"validated", []TranslatorOption{WithLanguageValidation()}, "js", "javascript"},
+		{"validated unknown", []TranslatorOption{WithLanguageValidation()}, "brainfuck", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := NewTranslator(tt.opts...).TranslateToADF([]byte("```" + tt.info + "\nx\n```"))
+			if err != nil {
+				t.Fatalf("Failed to translate markdown: %v", err)
+			}
+			if len(doc.Content) != 1 || doc.Content[0].Type != "codeBlock" {
+				jsonBytes, _ := json.MarshalIndent(doc, "", "  ")
+				t.Fatalf("Expected one code block, got:\n%s", string(jsonBytes))
+			}
+
+			language, _ := doc.Content[0].Attrs["language"].(string)
+			if language != tt.expected {
+				t.Errorf("Expected language %q, got %q", tt.expected, language)
+			}
+		})
+	}
+}
+
+func TestUnknownLanguageWarning(t *testing.T) {
+	translator := NewTranslator(WithLanguageValidation())
+	if _, err := translator.TranslateToADF([]byte("Text\n\n```brainfuck\n+.\n```")); err != nil {
+		t.Fatalf("Failed to translate markdown: %v", err)
+	}
+
+	warnings := translator.Warnings()
+	if len(warnings) != 1 || warnings[0].Code != WarningUnknownLanguage {
+		t.Fatalf("Expected one unknown language warning, got %+v", warnings)
+	}
+	if warnings[0].Pos.Line != 3 {
+		t.Errorf("Expected the warning on line 3, got %s", warnings[0].Pos)
+	}
+}
+
+func TestLongCodeFences(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		expected string
+	}{
+		{"fence inside", "````md\n```go\nx\n```\n````\n", "```go\nx\n```"},
+		{"fence inside at end of input", "````md\n```go\nx\n```\n````", "```go\nx\n```"},
+		{"backticks ending the last line", "````\na ```\n````", "a ```"},
+		{"shorter fence is code", "````\n```\n", "```"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := NewTranslator().TranslateToADF([]byte(tt.markdown))
+			if err != nil {
+				t.Fatalf("Failed to translate markdown: %v", err)
+			}
+			if len(doc.Content) != 1 || doc.Content[0].Type != "codeBlock" || len(doc.Content[0].Content) != 1 {
+				jsonBytes, _ := json.MarshalIndent(doc, "", "  ")
+				t.Fatalf("Expected one code block, got:\n%s", string(jsonBytes))
+			}
+			if text := doc.Content[0].Content[0].Text; text != tt.expected {
+				t.Errorf("Expected code %q, got %q", tt.expected, text)
+			}
+		})
+	}
+}
+
+func TestCodeBlockWithFenceRoundtrip(t *testing.T) {
+	translator := NewTranslator()
+	markdown := "````markdown\nExample:\n\n```go\nfmt.Println(\"hi\")\n```\n````\n\nAfter\n"
+
+	first, err := translator.TranslateToADF([]byte(markdown))
+	if err != nil {
+		t.Fatalf("Failed to translate markdown: %v", err)
+	}
+	rendered, err := translator.TranslateToMarkdown(first)
+	if err != nil {
+		t.Fatalf("Failed to render markdown: %v", err)
+	}
+	second, err := translator.TranslateToADF([]byte(rendered))
+	if err != nil {
+		t.Fatalf("Failed to translate %q: %v", rendered, err)
+	}
+
+	if !adf.EqualDocuments(first, second) {
+		jsonBytes, _ := json.MarshalIndent(second, "", "  ")
+		t.Errorf("Expected the code block back from %q, got:\n%s", rendered, string(jsonBytes))
+	}
+}
+
+func TestCodeBlockWhitespaceRoundtrip(t *testing.T) {
+	texts := map[string]string{
+		"no final newline":         "a := 1\nb := 2",
+		"final newline":            "a := 1\n",
+		"blank lines at end":       "a := 1\n\n\n",
+		"blank lines at start":     "\n\na := 1",
+		"trailing spaces and tabs": "a := 1  \nb := 2\t\n",
+		"tab indented":             "\tif x {\n\t\treturn\n\t}",
+		"only a newline":           "\n",
+		"only spaces":              "   ",
+	}
+
+	for name, text := range texts {
+		for _, inList := range []bool{false, true} {
+			codeBlock := adf.NewCodeBlockNode("go")
+			codeBlock.Content = []*adf.ADFNode{adf.NewTextNode(text)}
+			doc := adf.NewADFDocument()
+			doc.Content = []*adf.ADFNode{codeBlock}
+			if inList {
+				item := &adf.ADFNode{Type: adf.ChildNodeListItem, Content: []*adf.ADFNode{adf.NewParagraphNode(), codeBlock}}
+				item.Content[0].Content = []*adf.ADFNode{adf.NewTextNode("Item")}
+				doc.Content = []*adf.ADFNode{{Type: adf.NodeBulletList, Content: []*adf.ADFNode{item}}}
+			}
+
+			t.Run(fmt.Sprintf("%s/list=%v", name, inList), func(t *testing.T) {
+				translator := NewTranslator()
+				current := doc
+				for range 2 {
+					rendered, err := translator.TranslateToMarkdown(current)
+					if err != nil {
+						t.Fatalf("Failed to render markdown: %v", err)
+					}
+					current, err = translator.TranslateToADF([]byte(rendered))
+					if err != nil {
+						t.Fatalf("Failed to translate %q: %v", rendered, err)
+					}
+
+					if got := codeBlockTexts(current); len(got) != 1 || got[0] != text {
+						t.Fatalf("Expected code %q back from %q, got %q", text, rendered, got)
+					}
+				}
+			})
+		}
+	}
+}
+
+// codeBlockTexts returns the text of the code blocks of doc.
+func codeBlockTexts(doc *adf.ADFDocument) []string {
+	var texts []string
+	adf.Walk(doc, func(n *adf.ADFNode, _ int) bool {
+		if n.Type == adf.NodeCodeBlock {
+			var text strings.Builder
+			for _, c := range n.Content {
+				text.WriteString(c.Text)
+			}
+			texts = append(texts, text.String())
+		}
+		return true
+	})
+	return texts
+}
+
+func TestIndentedCodeFences(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		expected string
+	}{
+		{"fence indentation is stripped", "  ```\n  a\n   b\n```", "a\n b"},
+		{"in a list item", "- Item\n\n    ```\n    a\n     b\n    ```", "a\n b"},
+		{"in a quote", "> ```\n>   a\n> ```", "  a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := NewTranslator().TranslateToADF([]byte(tt.markdown))
+			if err != nil {
+				t.Fatalf("Failed to translate markdown: %v", err)
+			}
+			if got := codeBlockTexts(doc); len(got) != 1 || got[0] != tt.expected {
+				jsonBytes, _ := json.MarshalIndent(doc, "", "  ")
+				t.Errorf("Expected code %q, got:\n%s", tt.expected, string(jsonBytes))
+			}
+		})
+	}
+}
+
+func TestTildeCodeFences(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		language string
+		code     string
+	}{
+		{"with language", "~~~python\nprint(1)\n~~~\n", "python", "print(1)"},
+		{"without language", "~~~\nplain\n~~~\n", "", "plain"},
+		{"info string words", "~~~ python title=x\nprint(1)\n~~~\n", "python", "print(1)"},
+		{"backtick fence inside", "~~~md\n```go\nx\n```\n~~~\n", "markdown", "```go\nx\n```"},
+		{"backtick fence inside at end of input", "~~~\n```\nx\n```\n~~~", "", "```\nx\n```"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := NewTranslator().TranslateToADF([]byte(tt.markdown))
+			if err != nil {
+				t.Fatalf("Failed to translate markdown: %v", err)
+			}
+			if len(doc.Content) != 1 || doc.Content[0].Type != "codeBlock" || len(doc.Content[0].Content) != 1 {
+				jsonBytes, _ := json.MarshalIndent(doc, "", "  ")
+				t.Fatalf("Expected one code block, got:\n%s", string(jsonBytes))
+			}
+
+			codeBlock := doc.Content[0]
+			if language, _ := codeBlock.Attrs["language"].(string); language != tt.language {
+				t.Errorf("Expected language %q, got %q", tt.language, language)
+			}
+			if text := codeBlock.Content[0].Text; text != tt.code {
+				t.Errorf("Expected code %q, got %q", tt.code, text)
+			}
+		})
+	}
+}
+
+func TestInlineCodeWithBackticks(t *testing.T) {
+	tests := []struct {
+		markdown string
+		expected string
+	}{
+		{"``ls `pwd` ``", "ls `pwd` "},
+		{"`` `pwd` ``", "`pwd`"},
+		{"``a`b``", "a`b"},
+		{"`  padded  `", " padded "},
+		{"` `", " "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.markdown, func(t *testing.T) {
+			doc, err := NewTranslator().TranslateToADF([]byte(tt.markdown))
+			if err != nil {
+				t.Fatalf("Failed to translate markdown: %v", err)
+			}
+			if len(doc.Content) != 1 || len(doc.Content[0].Content) != 1 {
+				jsonBytes, _ := json.MarshalIndent(doc, "", "  ")
+				t.Fatalf("Expected a single code span, got:\n%s", string(jsonBytes))
+			}
+
+			code := doc.Content[0].Content[0]
+			if code.Text != tt.expected {
+				t.Errorf("Expected code %q, got %q", tt.expected, code.Text)
+			}
+			if len(code.Marks) != 1 || code.Marks[0].Type != "code" {
+				t.Errorf("Expected a code mark, got %+v", code.Marks)
+			}
+		})
+	}
+}
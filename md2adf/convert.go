@@ -0,0 +1,31 @@
+package md2adf
+
+import (
+	"sync"
+
+	"github.com/jorres/md2adf-translator/adf"
+)
+
+// defaultPool holds the Translators of Convert and ConvertDocument, built
+// with the default options on first use. It is never closed; like any pool it
+// keeps at most GOMAXPROCS free Translators and closes the others.
+var defaultPool = sync.OnceValue(func() *TranslatorPool {
+	return NewTranslatorPool()
+})
+
+// ConvertDocument translates markdown to an ADF document with the default
+// options: no user mapping, so mentions keep their text as account IDs, and
+// no attachments known. It is safe for concurrent use.
+func ConvertDocument(markdown string) (*adf.ADFDocument, error) {
+	return defaultPool().TranslateToADF([]byte(markdown))
+}
+
+// Convert translates markdown to the JSON of an ADF document as
+// ConvertDocument does.
+func Convert(markdown string) ([]byte, error) {
+	doc, err := ConvertDocument(markdown)
+	if err != nil {
+		return nil, err
+	}
+	return doc.ToJSON()
+}
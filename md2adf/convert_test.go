@@ -0,0 +1,50 @@
+package md2adf
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	markdown := "# Title\n\nSome **bold** text and @jane@example.com"
+
+	translator := NewTranslator()
+	defer translator.Close()
+	doc, err := translator.TranslateToADF([]byte(markdown))
+	if err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+	expected, _ := doc.ToJSON()
+
+	// More calls at once than the default pool keeps Translators for
+	var wg sync.WaitGroup
+	for range 2 * cap(defaultPool().free) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			out, err := Convert(markdown)
+			if err != nil {
+				t.Errorf("Failed to convert markdown: %v", err)
+				return
+			}
+			if string(out) != string(expected) {
+				t.Errorf("Expected %s, got %s", expected, out)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := len(defaultPool().free); n > cap(defaultPool().free) {
+		t.Errorf("Expected the default pool to keep at most %d Translators, got %d", cap(defaultPool().free), n)
+	}
+
+	converted, err := ConvertDocument(markdown)
+	if err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+	out, _ := json.Marshal(converted)
+	want, _ := json.Marshal(doc)
+	if string(out) != string(want) {
+		t.Errorf("Expected %s, got %s", want, out)
+	}
+}
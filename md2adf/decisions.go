@@ -0,0 +1,87 @@
+package md2adf
+
+import (
+	"strings"
+
+	sitter "github.com/tree-sitter/go-tree-sitter"
+
+	"github.com/jorres/md2adf-translator/adf"
+)
+
+// decisionMarker starts the text of a bullet list item that is a decision,
+// as in "- <> Ship on Friday".
+const decisionMarker = "<>"
+
+// isDecisionList reports whether every item of a bullet list is a decision:
+// paragraphs only, the first starting with the decision marker. A marker
+// written as "\<>" is text.
+func isDecisionList(node *sitter.Node, content []byte) bool {
+	items := 0
+	for i := range int(node.ChildCount()) {
+		item := node.Child(uint(i))
+		if item.Kind() != "list_item" {
+			continue
+		}
+		items++
+
+		var paragraphs []*sitter.Node
+		for j := range int(item.ChildCount()) {
+			child := item.Child(uint(j))
+			switch kind := child.Kind(); {
+			case kind == "paragraph":
+				paragraphs = append(paragraphs, child)
+			case kind == "block_continuation" || strings.HasPrefix(kind, "list_marker_"):
+			default:
+				return false
+			}
+		}
+		if len(paragraphs) == 0 {
+			return false
+		}
+
+		text := string(content[paragraphs[0].StartByte():paragraphs[0].EndByte()])
+		rest, ok := strings.CutPrefix(text, decisionMarker)
+		if !ok || rest != "" && !strings.ContainsAny(rest[:1], " \t\r\n") {
+			return false
+		}
+	}
+	return items > 0
+}
+
+// convertDecisionList converts a bullet list of decisions to a decision list.
+// The list and its items get new localIds.
+func (p *Translator) convertDecisionList(node *sitter.Node, content []byte) *adf.ADFNode {
+	list := adf.NewDecisionListNode()
+	p.track(list, node.StartByte(), node.EndByte())
+
+	for i := range int(node.ChildCount()) {
+		if item := node.Child(uint(i)); item.Kind() == "list_item" {
+			list.Content = append(list.Content, p.convertDecisionItem(item, content))
+		}
+	}
+	return list
+}
+
+// convertDecisionItem converts a list item of a decision list. Decisions hold
+// inline content only, so its paragraphs are joined by hard breaks.
+func (p *Translator) convertDecisionItem(node *sitter.Node, content []byte) *adf.ADFNode {
+	decision := adf.NewDecisionItemNode(adf.DecisionDecided)
+	p.track(decision, node.StartByte(), node.EndByte())
+
+	tempDoc := adf.NewADFDocument()
+	p.processChildren(node, content, tempDoc)
+	for _, block := range tempDoc.Content {
+		if block.Type != adf.NodeParagraph {
+			p.warnAt(WarningDroppedContent, node.StartByte(), node.EndByte(), "%s in a decision dropped", block.Type)
+			continue
+		}
+		if len(decision.Content) > 0 {
+			decision.Content = append(decision.Content, adf.NewHardBreakNode())
+		}
+		decision.Content = append(decision.Content, block.Content...)
+	}
+
+	decision.Content, _ = cutTextPrefix(decision.Content, decisionMarker)
+	trimInlineWhitespace(decision)
+	return decision
+}
@@ -0,0 +1,78 @@
+package md2adf
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jorres/md2adf-translator/adf"
+	"github.com/jorres/md2adf-translator/adf2md"
+)
+
+// withoutLocalIDs removes the generated localIds of decisions, so that
+// documents can be compared.
+func withoutLocalIDs(doc *adf.ADFDocument) *adf.ADFDocument {
+	adf.Walk(doc, func(n *adf.ADFNode, _ int) bool {
+		delete(n.Attrs, "localId")
+		return true
+	})
+	return doc
+}
+
+func TestDecisionList(t *testing.T) {
+	markdown := "- <> Ship on **Friday**\n- <>\n- <> First\\\nSecond\n\n  Third\n\nEscaped:\n\n- \\<> not a decision\n- <>x\n\nMixed:\n\n- plain\n- <> decision\n"
+
+	doc, err := NewTranslator().TranslateToADF([]byte(markdown))
+	if err != nil {
+		t.Fatalf("Failed to translate: %v", err)
+	}
+
+	ids := make(map[any]bool)
+	for _, n := range append(adf.FindAll(doc, adf.NodeDecisionList), adf.FindAll(doc, adf.ChildNodeDecisionItem)...) {
+		id, _ := n.Attrs["localId"].(string)
+		if id == "" || ids[id] {
+			t.Errorf("Expected a new localId for each decision node, got %q", id)
+		}
+		ids[id] = true
+	}
+
+	expected := adf.NewDocBuilder().
+		DecisionList(
+			adf.Decision(adf.Text("Ship on "), adf.Bold("Friday")),
+			adf.Decision(),
+			adf.Decision(adf.Text("First"), adf.HardBreak(), adf.Text("Second"), adf.HardBreak(), adf.Text("Third")),
+		).
+		Paragraph(adf.Text("Escaped:")).
+		BulletList(adf.Item(adf.Text("<> not a decision")), adf.Item(adf.Text("<>x"))).
+		Paragraph(adf.Text("Mixed:")).
+		BulletList(adf.Item(adf.Text("plain")), adf.Item(adf.Text("<> decision"))).
+		Build()
+	adf.MergeText(doc)
+	if !adf.EqualDocuments(withoutLocalIDs(expected), withoutLocalIDs(doc)) {
+		jsonBytes, _ := json.MarshalIndent(doc, "", "  ")
+		t.Errorf("Unexpected decisions, got:\n%s", string(jsonBytes))
+	}
+}
+
+func TestDecisionListRoundtrip(t *testing.T) {
+	decisions := adf.NewDocBuilder().
+		DecisionList(
+			adf.Decision(adf.Text("Ship on "), adf.Bold("Friday")),
+			adf.Decision(adf.Text("Use"), adf.HardBreak(), adf.Text("Go")),
+		).
+		Paragraph(adf.Text("After")).
+		Build()
+
+	markdown, err := adf2md.NewTranslator(adf2md.NewMarkdownTranslator()).TranslateDocument(decisions)
+	if err != nil {
+		t.Fatalf("Failed to render the decisions: %v", err)
+	}
+
+	doc, err := NewTranslator().TranslateToADF([]byte(markdown))
+	if err != nil {
+		t.Fatalf("Failed to translate %q: %v", markdown, err)
+	}
+	if !adf.EqualDocuments(withoutLocalIDs(decisions), withoutLocalIDs(doc)) {
+		jsonBytes, _ := json.MarshalIndent(doc, "", "  ")
+		t.Errorf("Expected the decisions back from %q, got:\n%s", markdown, string(jsonBytes))
+	}
+}
@@ -0,0 +1,74 @@
+package md2adf
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jorres/md2adf-translator/adf"
+	"github.com/jorres/md2adf-translator/adf2md"
+)
+
+func TestEmojiShortcodes(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		expected []*adf.ADFNode
+	}{
+		{
+			name:     "known shortcode",
+			markdown: "Ship it :rocket:",
+			expected: []*adf.ADFNode{adf.NewTextNode("Ship it "), adf.NewEmojiNode(":rocket:", "🚀")},
+		},
+		{
+			name:     "unknown shortcode stays text",
+			markdown: "Ship it :not_an_emoji:",
+			// The text is split where the grammar splits it, at the colons
+			expected: []*adf.ADFNode{adf.NewTextNode("Ship it "), adf.NewTextNode(":"), adf.NewTextNode("not_an_emoji"), adf.NewTextNode(":")},
+		},
+		{
+			name:     "shortcode next to words",
+			markdown: "ok:thumbsup:done",
+			expected: []*adf.ADFNode{adf.NewTextNode("ok"), adf.NewEmojiNode(":thumbsup:", "👍"), adf.NewTextNode("done")},
+		},
+		{
+			name:     "shortcode in code stays code",
+			markdown: "`:rocket:`",
+			expected: []*adf.ADFNode{adf.NewTextNodeWithMarks(":rocket:", []*adf.ADFMark{adf.NewCodeMark()})},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := NewTranslator().TranslateToADF([]byte(tt.markdown))
+			if err != nil {
+				t.Fatalf("Failed to convert markdown: %v", err)
+			}
+			if len(doc.Content) != 1 || doc.Content[0].Type != adf.NodeParagraph {
+				t.Fatalf("Expected a single paragraph, got %+v", doc.Content)
+			}
+
+			actual, _ := json.Marshal(doc.Content[0].Content)
+			expected, _ := json.Marshal(tt.expected)
+			if string(actual) != string(expected) {
+				t.Errorf("Expected %s, got %s", expected, actual)
+			}
+		})
+	}
+}
+
+func TestEmojiRoundtrip(t *testing.T) {
+	paragraph := adf.NewParagraphNode()
+	paragraph.Content = append(paragraph.Content, adf.NewTextNode("Done "), adf.NewEmojiNode(":white_check_mark:", "✅"))
+
+	markdown := adf2md.NewTranslator(adf2md.NewMarkdownTranslator()).MustTranslate(&adf.ADFNode{Type: "doc", Content: []*adf.ADFNode{paragraph}})
+	doc, err := NewTranslator().TranslateToADF([]byte(markdown))
+	if err != nil {
+		t.Fatalf("Failed to convert %q: %v", markdown, err)
+	}
+
+	actual, _ := json.Marshal(doc.Content)
+	expected, _ := json.Marshal([]*adf.ADFNode{paragraph})
+	if string(actual) != string(expected) {
+		t.Errorf("Roundtrip through %q: expected %s, got %s", markdown, expected, actual)
+	}
+}
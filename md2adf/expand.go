@@ -0,0 +1,83 @@
+package md2adf
+
+import (
+	"regexp"
+
+	"github.com/jorres/md2adf-translator/adf"
+)
+
+var (
+	// expandStart matches the paragraph opening an expand, e.g. "{expand:title=Details}".
+	expandStart = regexp.MustCompile(`^\{expand(?::title=(.*))?\}$`)
+	// expandEnd matches the paragraph closing an expand.
+	expandEnd = regexp.MustCompile(`^\{/expand\}$`)
+)
+
+// expandFrame is an expand being filled while its closing marker is looked for.
+type expandFrame struct {
+	expand *adf.ADFNode
+	opener *adf.ADFNode // the paragraph holding the opening marker
+}
+
+// foldExpands rebuilds expand nodes from the blocks between "{expand...}" and
+// "{/expand}" marker paragraphs. The grammar has no expand syntax, so the
+// markers arrive as paragraphs of their own. Expands may nest; a marker
+// without its counterpart is kept as a paragraph.
+func (p *Translator) foldExpands(blocks []*adf.ADFNode) []*adf.ADFNode {
+	var stack []expandFrame
+	out := make([]*adf.ADFNode, 0, len(blocks))
+
+	appendBlock := func(block *adf.ADFNode) {
+		if n := len(stack); n > 0 {
+			stack[n-1].expand.Content = append(stack[n-1].expand.Content, block)
+			return
+		}
+		out = append(out, block)
+	}
+
+	for _, block := range blocks {
+		text, ok := markerText(block)
+		switch {
+		case ok && expandStart.MatchString(text):
+			title := expandStart.FindStringSubmatch(text)[1]
+			stack = append(stack, expandFrame{expand: adf.NewExpandNode(title), opener: block})
+		case ok && expandEnd.MatchString(text) && len(stack) > 0:
+			frame := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if p.ranges != nil {
+				p.ranges[frame.expand] = sourceRange{start: p.ranges[frame.opener].start, end: p.ranges[block].end}
+			}
+			appendBlock(frame.expand)
+		default:
+			appendBlock(block)
+		}
+	}
+
+	// Unclosed expands are left as the text they were written as
+	for len(stack) > 0 {
+		frame := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		appendBlock(frame.opener)
+		for _, block := range frame.expand.Content {
+			appendBlock(block)
+		}
+	}
+
+	return out
+}
+
+// markerText returns the text of a paragraph made of text nodes only.
+func markerText(block *adf.ADFNode) (string, bool) {
+	if block.Type != adf.NodeParagraph || len(block.Content) == 0 {
+		return "", false
+	}
+
+	var text string
+	for _, n := range block.Content {
+		if n.Type != adf.ChildNodeText {
+			return "", false
+		}
+		text += n.Text
+	}
+	return text, true
+}
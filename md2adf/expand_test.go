@@ -0,0 +1,108 @@
+package md2adf
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jorres/md2adf-translator/adf"
+	"github.com/jorres/md2adf-translator/adf2md"
+)
+
+func paragraphOf(text string) *adf.ADFNode {
+	paragraph := adf.NewParagraphNode()
+	paragraph.Content = append(paragraph.Content, adf.NewTextNode(text))
+	return paragraph
+}
+
+// blockShape describes blocks as their types, expands with their content.
+func blockShape(blocks []*adf.ADFNode) []any {
+	var shape []any
+	for _, b := range blocks {
+		if b.Type == adf.NodeExpand {
+			shape = append(shape, map[string]any{b.Attrs["title"].(string): blockShape(b.Content)})
+			continue
+		}
+		if text, ok := markerText(b); ok {
+			shape = append(shape, text)
+			continue
+		}
+		shape = append(shape, string(b.Type))
+	}
+	return shape
+}
+
+func TestFoldExpands(t *testing.T) {
+	tests := []struct {
+		name     string
+		blocks   []*adf.ADFNode
+		expected string
+	}{
+		{
+			name:     "single expand",
+			blocks:   []*adf.ADFNode{paragraphOf("{expand:title=Details}"), paragraphOf("inside"), paragraphOf("{/expand}"), paragraphOf("after")},
+			expected: `[{"Details":["inside"]},"after"]`,
+		},
+		{
+			name:     "untitled expand",
+			blocks:   []*adf.ADFNode{paragraphOf("{expand}"), adf.NewCodeBlockNode(""), paragraphOf("{/expand}")},
+			expected: `[{"":["codeBlock"]}]`,
+		},
+		{
+			name: "nested expands",
+			blocks: []*adf.ADFNode{
+				paragraphOf("{expand:title=Outer}"), paragraphOf("{expand:title=Inner}"), paragraphOf("deep"),
+				paragraphOf("{/expand}"), paragraphOf("shallow"), paragraphOf("{/expand}"),
+			},
+			expected: `[{"Outer":[{"Inner":["deep"]},"shallow"]}]`,
+		},
+		{
+			name:     "unclosed expand stays text",
+			blocks:   []*adf.ADFNode{paragraphOf("{expand:title=Open}"), paragraphOf("text")},
+			expected: `["{expand:title=Open}","text"]`,
+		},
+		{
+			name:     "stray end marker stays text",
+			blocks:   []*adf.ADFNode{paragraphOf("text"), paragraphOf("{/expand}")},
+			expected: `["text","{/expand}"]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, _ := json.Marshal(blockShape((&Translator{}).foldExpands(tt.blocks)))
+			if string(actual) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestExpandRoundtrip(t *testing.T) {
+	markdown := "Before\n\n" +
+		"{expand:title=Release notes}\n\n" +
+		"Some **details**\n\n" +
+		"- first\n- second\n\n" +
+		"```go\nfmt.Println()\n```\n\n" +
+		"{/expand}\n\n" +
+		"After\n"
+
+	doc, err := NewTranslator().TranslateToADF([]byte(markdown))
+	if err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+
+	expected := `["Before",{"Release notes":["Some details","bulletList","codeBlock"]},"After"]`
+	if actual, _ := json.Marshal(blockShape(doc.Content)); string(actual) != expected {
+		jsonBytes, _ := json.MarshalIndent(doc, "", "  ")
+		t.Fatalf("Expected %s, got %s from:\n%s", expected, actual, string(jsonBytes))
+	}
+
+	rendered := adf2md.NewTranslator(adf2md.NewJiraMarkdownTranslator()).MustTranslate(&adf.ADFNode{Type: "doc", Content: doc.Content})
+	again, err := NewTranslator().TranslateToADF([]byte(rendered))
+	if err != nil {
+		t.Fatalf("Failed to convert rendered markdown: %v", err)
+	}
+	if actual, _ := json.Marshal(blockShape(again.Content)); string(actual) != expected {
+		t.Errorf("Roundtrip through %q gave %s", rendered, actual)
+	}
+}
@@ -0,0 +1,112 @@
+package md2adf
+
+import (
+	"encoding/json"
+	"math/rand/v2"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jorres/md2adf-translator/adf"
+	"github.com/jorres/md2adf-translator/adf2md"
+)
+
+// fuzzFragments are pieces of markdown syntax, complete and broken, that
+// random documents are assembled from.
+var fuzzFragments = []string{
+	"text", " ", "\n", "\n\n", "    ", "# ", "###### ", "> ", "- ", "* ", "1. ", "3) ",
+	"**", "__", "*", "_", "~", "~~", "`", "```", "```go\n", "<u>", "</u>", "\\", "\\|",
+	"[", "]", "(", ")", "[a](https://example.com)", "![alt](https://example.com/i.png)",
+	"|", "| a | b |\n", "|---|---|\n", "a | b\n--- | ---\n", ":-:", "{panel:type=info}\n", "{panel}",
+	"{/panel}\n", "{attachment:abc}", "@user@example.com", "<", ">", "&amp;", "日本語", "🚀",
+}
+
+// fixtureMarkdown returns the markdown rendering of the adf2md fixture.
+func fixtureMarkdown(t testing.TB) string {
+	data, err := os.ReadFile("../adf2md/testdata/md.json")
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+
+	var doc adf.ADFNode
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("Failed to decode fixture: %v", err)
+	}
+
+	return adf2md.NewTranslator(adf2md.NewJiraMarkdownTranslator()).MustTranslate(&doc)
+}
+
+func TestNoPanicOnFixtures(t *testing.T) {
+	fixture := fixtureMarkdown(t)
+
+	// The whole fixture, and every prefix of it cut at a line boundary
+	inputs := []string{fixture}
+	for i, c := range fixture {
+		if c == '\n' {
+			inputs = append(inputs, fixture[:i])
+		}
+	}
+
+	for _, input := range inputs {
+		if _, err := NewTranslator(WithPanicRecovery(false)).TranslateToADF([]byte(input)); err != nil {
+			t.Errorf("Failed to convert %q: %v", input, err)
+		}
+	}
+}
+
+func TestNoPanicOnRandomMarkdown(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	tr := NewTranslator(WithPanicRecovery(false))
+
+	for range 10000 {
+		var b strings.Builder
+		for range r.IntN(30) + 1 {
+			b.WriteString(fuzzFragments[r.IntN(len(fuzzFragments))])
+		}
+		input := b.String()
+
+		func() {
+			defer func() {
+				if v := recover(); v != nil {
+					t.Fatalf("TranslateToADF panicked with %v on %q", v, input)
+				}
+			}()
+			_, _ = tr.TranslateToADF([]byte(input))
+		}()
+	}
+}
+
+func FuzzTranslateToADF(f *testing.F) {
+	f.Add([]byte(fixtureMarkdown(f)))
+	for _, fragment := range fuzzFragments {
+		f.Add([]byte(fragment))
+	}
+
+	f.Fuzz(func(t *testing.T, input []byte) {
+		_, _ = NewTranslator(WithPanicRecovery(false)).TranslateToADF(input)
+	})
+}
+
+func TestPanicRecovery(t *testing.T) {
+	failing := WithInlineImagePolicy(func(url, alt string) ImageDecision { panic("policy") })
+	input := []byte("![alt](https://example.com/i.png)")
+
+	doc, err := NewTranslator(failing).TranslateToADF(input)
+	if doc != nil {
+		t.Errorf("Expected no document after a panic, got %+v", doc)
+	}
+	internal, ok := err.(*adf.InternalError)
+	if !ok {
+		t.Fatalf("Expected *adf.InternalError, got %T: %v", err, err)
+	}
+	if internal.Value != "policy" || len(internal.Stack) == 0 {
+		t.Errorf("Unexpected internal error %+v", internal)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected the panic to propagate with recovery off")
+		}
+	}()
+	_, _ = NewTranslator(failing, WithPanicRecovery(false)).TranslateToADF(input)
+}
@@ -0,0 +1,74 @@
+package md2adf
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jorres/md2adf-translator/adf"
+	"github.com/jorres/md2adf-translator/adf2md"
+)
+
+func TestHardLineBreaks(t *testing.T) {
+	translator := NewTranslator()
+
+	tests := []struct {
+		name     string
+		markdown string
+	}{
+		{
+			name:     "backslash at end of line",
+			markdown: "First line\\\nSecond line",
+		},
+		{
+			name:     "two trailing spaces",
+			markdown: "First line  \nSecond line",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := translator.TranslateToADF([]byte(tt.markdown))
+			if err != nil {
+				t.Fatalf("Failed to convert markdown: %v", err)
+			}
+
+			if len(doc.Content) != 1 || doc.Content[0].Type != adf.NodeParagraph {
+				jsonBytes, _ := json.MarshalIndent(doc, "", "  ")
+				t.Fatalf("Expected a single paragraph, got:\n%s", string(jsonBytes))
+			}
+
+			content := doc.Content[0].Content
+			if len(content) != 3 || content[1].Type != adf.InlineNodeHardBreak ||
+				content[0].Text != "First line" || content[2].Text != "Second line" {
+				jsonBytes, _ := json.MarshalIndent(content, "", "  ")
+				t.Errorf("Expected text, hardBreak, text, got:\n%s", string(jsonBytes))
+			}
+		})
+	}
+}
+
+func TestHardLineBreakRoundtrip(t *testing.T) {
+	paragraph := adf.NewParagraphNode()
+	paragraph.Content = append(paragraph.Content,
+		adf.NewTextNode("First line"),
+		adf.NewHardBreakNode(),
+		adf.NewTextNode("Second line"),
+	)
+
+	reverse := adf2md.NewTranslator(adf2md.NewMarkdownTranslator())
+	markdown := reverse.MustTranslate(&adf.ADFNode{Type: "doc", Content: []*adf.ADFNode{paragraph}})
+
+	doc, err := NewTranslator().TranslateToADF([]byte(markdown))
+	if err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+
+	if len(doc.Content) != 1 || doc.Content[0].Type != adf.NodeParagraph {
+		jsonBytes, _ := json.MarshalIndent(doc, "", "  ")
+		t.Fatalf("Expected %q to read back as a single paragraph, got:\n%s", markdown, string(jsonBytes))
+	}
+	if len(doc.Content[0].Content) != 3 || doc.Content[0].Content[1].Type != adf.InlineNodeHardBreak {
+		jsonBytes, _ := json.MarshalIndent(doc, "", "  ")
+		t.Errorf("Expected the hard break to survive the roundtrip of %q, got:\n%s", markdown, string(jsonBytes))
+	}
+}
@@ -0,0 +1,270 @@
+package md2adf
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/jorres/md2adf-translator/adf"
+	"github.com/jorres/md2adf-translator/adf2md"
+)
+
+func TestImageToMediaSingle(t *testing.T) {
+	translator := NewTranslator()
+
+	tests := []struct {
+		name     string
+		markdown string
+		expected func(*adf.ADFDocument) bool
+	}{
+		{
+			name:     "image in its own paragraph",
+			markdown: "![alt text](https://example.com/pic.png)",
+			expected: func(doc *adf.ADFDocument) bool {
+				if len(doc.Content) != 1 || doc.Content[0].Type != adf.NodeMediaSingle {
+					return false
+				}
+				mediaSingle := doc.Content[0]
+				if len(mediaSingle.Content) != 1 || mediaSingle.Content[0].Type != adf.NodeMedia {
+					return false
+				}
+				media := mediaSingle.Content[0]
+				return media.Attrs["type"] == "external" &&
+					media.Attrs["url"] == "https://example.com/pic.png" &&
+					media.Attrs["alt"] == "alt text"
+			},
+		},
+		{
+			name:     "image inside a sentence is hoisted to block level",
+			markdown: "Before ![pic](https://example.com/pic.png) after",
+			expected: func(doc *adf.ADFDocument) bool {
+				if len(doc.Content) != 3 {
+					return false
+				}
+				return doc.Content[0].Type == adf.NodeParagraph &&
+					doc.Content[0].Content[0].Text == "Before " &&
+					doc.Content[1].Type == adf.NodeMediaSingle &&
+					doc.Content[2].Type == adf.NodeParagraph &&
+					doc.Content[2].Content[0].Text == " after"
+			},
+		},
+		{
+			name:     "image in a list item",
+			markdown: "- ![pic](https://example.com/pic.png)",
+			expected: func(doc *adf.ADFDocument) bool {
+				if len(doc.Content) != 1 || doc.Content[0].Type != adf.NodeBulletList {
+					return false
+				}
+				listItem := doc.Content[0].Content[0]
+				return len(listItem.Content) == 1 && listItem.Content[0].Type == adf.NodeMediaSingle
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := translator.TranslateToADF([]byte(tt.markdown))
+			if err != nil {
+				t.Fatalf("Translation failed: %v", err)
+			}
+
+			if !tt.expected(doc) {
+				jsonBytes, _ := json.MarshalIndent(doc, "", "  ")
+				t.Errorf("Test %s failed. Actual structure:\n%s", tt.name, string(jsonBytes))
+			}
+		})
+	}
+}
+
+func TestImageDestination(t *testing.T) {
+	doc, err := NewTranslator().TranslateToADF([]byte("![plan](<https://example.com/floor plan.png>)"))
+	if err != nil {
+		t.Fatalf("Translation failed: %v", err)
+	}
+	if len(doc.Content) != 1 || doc.Content[0].Type != adf.NodeMediaSingle ||
+		doc.Content[0].Content[0].Attrs["url"] != "https://example.com/floor plan.png" {
+		jsonBytes, _ := json.MarshalIndent(doc, "", "  ")
+		t.Fatalf("Expected media for the unwrapped URL, got:\n%s", string(jsonBytes))
+	}
+
+	// adf2md writes URLs with spaces in angle brackets, which must read back
+	original := adf.NewMediaSingleNode("center")
+	original.Content = append(original.Content, adf.NewExternalMediaNode("https://example.com/floor plan.png", "plan"))
+	markdown := adf2md.NewTranslator(adf2md.NewMarkdownTranslator()).MustTranslate(&adf.ADFNode{Type: "doc", Content: []*adf.ADFNode{original}})
+	doc, err = NewTranslator().TranslateToADF([]byte(markdown))
+	if err != nil {
+		t.Fatalf("Translation of %q failed: %v", markdown, err)
+	}
+	want := &adf.ADFDocument{Version: doc.Version, Type: doc.Type, Content: []*adf.ADFNode{original}}
+	if !adf.EqualDocuments(want, doc) {
+		jsonBytes, _ := json.MarshalIndent(doc, "", "  ")
+		t.Errorf("Expected the media back from %q, got:\n%s", markdown, string(jsonBytes))
+	}
+}
+
+func TestImageTitle(t *testing.T) {
+	translator := NewTranslator(WithInlineImagePolicy(func(url, _ string) ImageDecision {
+		if strings.HasSuffix(url, ".svg") {
+			return ImageLink
+		}
+		return ImageMedia
+	}))
+
+	doc, err := translator.TranslateToADF([]byte(`Built ![build](https://ci/badge.svg "Latest build") on main`))
+	if err != nil {
+		t.Fatalf("Translation failed: %v", err)
+	}
+	badge := doc.Content[0].Content[1]
+	if len(badge.Marks) != 1 || badge.Marks[0].Attrs["title"] != "Latest build" {
+		t.Errorf("Expected the badge link to keep the title, got %+v", badge.Marks)
+	}
+	if warnings := translator.Warnings(); len(warnings) != 0 {
+		t.Errorf("Expected no warnings, got %+v", warnings)
+	}
+
+	if _, err := translator.TranslateToADF([]byte(`![chart](https://example.com/chart.png "Sales")`)); err != nil {
+		t.Fatalf("Translation failed: %v", err)
+	}
+	warnings := translator.Warnings()
+	if len(warnings) != 1 || warnings[0].Code != WarningDroppedContent || !strings.Contains(warnings[0].Message, `"Sales"`) {
+		t.Errorf("Expected a warning about the dropped title, got %+v", warnings)
+	}
+}
+
+func TestImageReusesMappedMedia(t *testing.T) {
+	original := adf.NewMediaSingleNode("wide")
+	original.Content = append(original.Content, adf.NewExternalMediaNode("https://example.com/pic.png", "pic"))
+
+	reverse := adf2md.NewTranslator(adf2md.NewJiraMarkdownTranslator())
+	markdown := reverse.MustTranslate(&adf.ADFNode{Type: "doc", Content: []*adf.ADFNode{original}})
+
+	translator := NewTranslator(WithAdf2MdTranslator(reverse))
+	doc, err := translator.TranslateToADF([]byte(markdown))
+	if err != nil {
+		t.Fatalf("Translation failed: %v", err)
+	}
+
+	if len(doc.Content) != 1 || doc.Content[0] != original {
+		jsonBytes, _ := json.MarshalIndent(doc, "", "  ")
+		t.Fatalf("Expected the original mediaSingle node to be reused, got:\n%s", string(jsonBytes))
+	}
+}
+
+func TestInlineImagePolicy(t *testing.T) {
+	badges := func(url, alt string) ImageDecision {
+		switch {
+		case strings.HasSuffix(url, ".svg"):
+			return ImageLink
+		case strings.Contains(url, "tracking"):
+			return ImageDrop
+		default:
+			return ImageMedia
+		}
+	}
+	translator := NewTranslator(WithInlineImagePolicy(badges))
+
+	tests := []struct {
+		name     string
+		markdown string
+		expected func(*adf.ADFDocument) bool
+	}{
+		{
+			name:     "badge in a sentence stays inline as a link",
+			markdown: "Built ![build](https://ci/badge.svg) on main",
+			expected: func(doc *adf.ADFDocument) bool {
+				if len(doc.Content) != 1 || doc.Content[0].Type != adf.NodeParagraph {
+					return false
+				}
+				content := doc.Content[0].Content
+				if len(content) != 3 {
+					return false
+				}
+				badge := content[1]
+				return content[0].Text == "Built " &&
+					badge.Text == "build" &&
+					len(badge.Marks) == 1 && badge.Marks[0].Type == adf.MarkLink &&
+					badge.Marks[0].Attrs["href"] == "https://ci/badge.svg" &&
+					content[2].Text == " on main"
+			},
+		},
+		{
+			name:     "badge in a table cell",
+			markdown: "| Service | Build |\n| --- | --- |\n| api | ![build](https://ci/api.svg) |",
+			expected: func(doc *adf.ADFDocument) bool {
+				if len(doc.Content) != 1 || doc.Content[0].Type != adf.NodeTable {
+					return false
+				}
+				cell := doc.Content[0].Content[1].Content[1]
+				if len(cell.Content) != 1 || cell.Content[0].Type != adf.NodeParagraph {
+					return false
+				}
+				badge := cell.Content[0].Content
+				return len(badge) == 1 && badge[0].Text == "build" &&
+					len(badge[0].Marks) == 1 && badge[0].Marks[0].Type == adf.MarkLink
+			},
+		},
+		{
+			name:     "screenshot in its own paragraph becomes media",
+			markdown: "![screenshot](https://example.com/screenshot.png)",
+			expected: func(doc *adf.ADFDocument) bool {
+				return len(doc.Content) == 1 && doc.Content[0].Type == adf.NodeMediaSingle &&
+					doc.Content[0].Content[0].Attrs["url"] == "https://example.com/screenshot.png"
+			},
+		},
+		{
+			name:     "dropped image leaves the text around it",
+			markdown: "Hello ![](https://example.com/tracking.gif)world",
+			expected: func(doc *adf.ADFDocument) bool {
+				if len(doc.Content) != 1 || doc.Content[0].Type != adf.NodeParagraph {
+					return false
+				}
+				var text strings.Builder
+				for _, n := range doc.Content[0].Content {
+					if n.Type != adf.ChildNodeText {
+						return false
+					}
+					text.WriteString(n.Text)
+				}
+				return text.String() == "Hello world"
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := translator.TranslateToADF([]byte(tt.markdown))
+			if err != nil {
+				t.Fatalf("Translation failed: %v", err)
+			}
+
+			if !tt.expected(doc) {
+				jsonBytes, _ := json.MarshalIndent(doc, "", "  ")
+				t.Errorf("Test %s failed. Actual structure:\n%s", tt.name, string(jsonBytes))
+			}
+		})
+	}
+}
+
+func TestImageCaption(t *testing.T) {
+	markdown := []byte("![chart](https://example.com/chart.png)\n{caption}Sales by _month_{/caption}\n\n{caption}Not below an image{/caption}\n")
+
+	doc, err := NewTranslator().TranslateToADF(markdown)
+	if err != nil {
+		t.Fatalf("Failed to translate: %v", err)
+	}
+
+	mediaSingle := adf.NewMediaSingleNode("center")
+	caption := adf.NewCaptionNode()
+	caption.Content = append(caption.Content, adf.Text("Sales by ").Node(), adf.Italic("month").Node())
+	mediaSingle.Content = append(mediaSingle.Content, adf.NewExternalMediaNode("https://example.com/chart.png", "chart"), caption)
+	expected := adf.NewDocBuilder().
+		AppendNodes(mediaSingle).
+		Paragraph(adf.Text("{caption}Not below an image{/caption}")).
+		Build()
+
+	adf.MergeText(doc)
+	if !adf.EqualDocuments(expected, doc) {
+		jsonBytes, _ := json.MarshalIndent(doc, "", "  ")
+		t.Errorf("Expected the caption in the media single, got:\n%s", string(jsonBytes))
+	}
+}
@@ -0,0 +1,61 @@
+package md2adf
+
+import (
+	"strings"
+
+	"github.com/jorres/md2adf-translator/adf"
+)
+
+// defaultLanguageAliases map common markdown info strings to the language
+// identifiers Jira highlights code blocks in.
+var defaultLanguageAliases = map[string]string{
+	"c#":         "csharp",
+	"cs":         "csharp",
+	"dockerfile": "docker",
+	"golang":     "go",
+	"js":         "javascript",
+	"jsx":        "javascript",
+	"kt":         "kotlin",
+	"md":         "markdown",
+	"objc":       "objective-c",
+	"ps1":        "powershell",
+	"py":         "python",
+	"rb":         "ruby",
+	"rs":         "rust",
+	"sh":         "bash",
+	"ts":         "typescript",
+	"tsx":        "typescript",
+	"yml":        "yaml",
+	"zsh":        "bash",
+}
+
+// WithLanguageAliases adds code block language aliases, overriding the
+// default ones for the same names. Names are matched case-insensitively.
+func WithLanguageAliases(aliases map[string]string) TranslatorOption {
+	return func(tr *Translator) {
+		for name, language := range aliases {
+			tr.languageAliases[strings.ToLower(name)] = language
+		}
+	}
+}
+
+// WithLanguageValidation drops the language of code blocks Jira doesn't
+// highlight, with a warning, instead of sending it to be shown as a label.
+func WithLanguageValidation() TranslatorOption {
+	return func(tr *Translator) {
+		tr.validateLanguages = true
+	}
+}
+
+// codeLanguage returns the language of a code block with the info string
+// language, spanning [start, end) of the markdown.
+func (p *Translator) codeLanguage(language string, start, end uint) string {
+	if alias, ok := p.languageAliases[strings.ToLower(language)]; ok {
+		language = alias
+	}
+	if p.validateLanguages && language != "" && !adf.IsCodeLanguage(language) {
+		p.warnAt(WarningUnknownLanguage, start, end, "code block language %q is not supported by Jira, dropped", language)
+		return ""
+	}
+	return language
+}
@@ -0,0 +1,108 @@
+package md2adf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jorres/md2adf-translator/adf"
+	sitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+// ErrInputTooLarge is returned for markdown longer than WithMaxInputSize
+// allows, or parsing to more syntax nodes than WithMaxNodes allows.
+var ErrInputTooLarge = errors.New("markdown input too large")
+
+// WithMaxInputSize makes translations of markdown longer than size bytes
+// fail with ErrInputTooLarge before it is parsed. Zero, the default, sets no
+// limit.
+func WithMaxInputSize(size int) TranslatorOption {
+	return func(tr *Translator) {
+		tr.maxInputSize = size
+	}
+}
+
+// WithMaxNodes makes translations fail with ErrInputTooLarge when the markdown
+// parses to more than n block-level syntax nodes, such as a table of a great
+// many rows, before they are converted. The inline content of a paragraph,
+// heading or table cell counts as one node. Zero, the default, sets no limit.
+func WithMaxNodes(n int) TranslatorOption {
+	return func(tr *Translator) {
+		tr.maxNodes = n
+	}
+}
+
+// WithTimeout makes a translation taking longer than d fail with
+// context.DeadlineExceeded. The parse itself can't be interrupted, so the
+// deadline is checked once it is done and between the blocks and the marked
+// spans being converted. Zero, the default, sets no timeout.
+func WithTimeout(d time.Duration) TranslatorOption {
+	return func(tr *Translator) {
+		tr.timeout = d
+	}
+}
+
+// TranslateToADFContext is TranslateToADF aborting with the error of ctx once
+// it is done, checked as with WithTimeout.
+func (p *Translator) TranslateToADFContext(ctx context.Context, content []byte) (doc *adf.ADFDocument, err error) {
+	// Deferred first, to observe the error of a recovered panic
+	if p.metrics != nil {
+		started := time.Now()
+		defer func() { p.observeConversion(doc, len(content), time.Since(started), err) }()
+	}
+	if p.recoverPanics {
+		defer adf.CatchPanic(&err)
+	}
+	return p.translateToADF(ctx, content)
+}
+
+// checkInputSize fails with ErrInputTooLarge for content longer than
+// WithMaxInputSize allows.
+func (p *Translator) checkInputSize(content []byte) error {
+	if p.maxInputSize > 0 && len(content) > p.maxInputSize {
+		return fmt.Errorf("%w: %d bytes, at most %d allowed", ErrInputTooLarge, len(content), p.maxInputSize)
+	}
+	return nil
+}
+
+// checkNodeCount fails with ErrInputTooLarge for a tree of more block-level
+// nodes than WithMaxNodes allows.
+func (p *Translator) checkNodeCount(tree *sitter.Tree) error {
+	if p.maxNodes <= 0 {
+		return nil
+	}
+	if n := countBlockNodes(tree.RootNode()); n > p.maxNodes {
+		return fmt.Errorf("%w: %d syntax nodes, at most %d allowed", ErrInputTooLarge, n, p.maxNodes)
+	}
+	return nil
+}
+
+// countBlockNodes counts the named nodes of a block tree. The block grammar
+// also marks the delimiters within inline content, which aren't blocks, so an
+// inline node counts as one.
+func countBlockNodes(node *sitter.Node) int {
+	if node.Kind() == "inline" {
+		return 1
+	}
+	n := 0
+	if node.IsNamed() {
+		n = 1
+	}
+	for i := range node.ChildCount() {
+		n += countBlockNodes(node.Child(i))
+	}
+	return n
+}
+
+// aborted reports whether the context of the current translation is done,
+// recording its error as that of the translation.
+func (p *Translator) aborted() bool {
+	if p.ctx == nil || p.ctx.Err() == nil {
+		return false
+	}
+	if p.err == nil {
+		p.err = p.ctx.Err()
+	}
+	return true
+}
@@ -0,0 +1,117 @@
+package md2adf
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTranslationLimits(t *testing.T) {
+	table := "| a | b |\n|---|---|\n" + strings.Repeat("| 1 | 2 |\n", 100)
+
+	tests := []struct {
+		name     string
+		markdown string
+		opts     []TranslatorOption
+		err      error
+	}{
+		{
+			name:     "input within size",
+			markdown: "Short",
+			opts:     []TranslatorOption{WithMaxInputSize(5)},
+		},
+		{
+			name:     "input too large",
+			markdown: "Longer",
+			opts:     []TranslatorOption{WithMaxInputSize(5)},
+			err:      ErrInputTooLarge,
+		},
+		{
+			name:     "nodes within limit",
+			markdown: "Short",
+			opts:     []TranslatorOption{WithMaxNodes(100)},
+		},
+		{
+			// The document, its section, the paragraph and its inline content
+			name:     "nodes at the limit",
+			markdown: "Short **text**",
+			opts:     []TranslatorOption{WithMaxNodes(4)},
+		},
+		{
+			name:     "one node over the limit",
+			markdown: "Short **text**",
+			opts:     []TranslatorOption{WithMaxNodes(3)},
+			err:      ErrInputTooLarge,
+		},
+		{
+			name:     "too many nodes",
+			markdown: table,
+			opts:     []TranslatorOption{WithMaxNodes(100)},
+			err:      ErrInputTooLarge,
+		},
+		{
+			name:     "timeout not reached",
+			markdown: table,
+			opts:     []TranslatorOption{WithTimeout(time.Minute)},
+		},
+		{
+			name:     "timeout",
+			markdown: table,
+			opts:     []TranslatorOption{WithTimeout(time.Nanosecond)},
+			err:      context.DeadlineExceeded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := NewTranslator(tt.opts...).TranslateToADF([]byte(tt.markdown))
+			if tt.err == nil {
+				if err != nil {
+					t.Fatalf("Failed to convert markdown: %v", err)
+				}
+				if len(doc.Content) == 0 {
+					t.Errorf("Expected content")
+				}
+				return
+			}
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Expected %v, got %v", tt.err, err)
+			}
+			if doc != nil {
+				t.Errorf("Expected no document, got %+v", doc)
+			}
+		})
+	}
+}
+
+func TestTranslateToADFContext(t *testing.T) {
+	translator := NewTranslator()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := translator.TranslateToADFContext(ctx, []byte("Text")); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected %v, got %v", context.Canceled, err)
+	}
+
+	// Canceled while the blocks are converted
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	resolving := NewTranslator(WithUserResolver(func(email string) (string, bool) {
+		cancel()
+		return "", false
+	}))
+	if _, err := resolving.TranslateToADFContext(ctx, []byte("Hi @jane@example.com\n\nMore text")); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected %v, got %v", context.Canceled, err)
+	}
+
+	// The translator is usable again afterwards
+	doc, err := translator.TranslateToADFContext(context.Background(), []byte("Text"))
+	if err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+	if len(doc.Content) != 1 {
+		t.Errorf("Expected one paragraph, got %+v", doc.Content)
+	}
+}
@@ -1,7 +1,12 @@
 package md2adf
 
 import (
+	"encoding/json"
+	"slices"
+	"strings"
 	"testing"
+
+	"github.com/jorres/md2adf-translator/adf"
 )
 
 func TestSimpleInlineLink(t *testing.T) {
@@ -136,3 +141,219 @@ func TestMultipleLinksInParagraph(t *testing.T) {
 		t.Errorf("Expected GitHub href, got %v", href)
 	}
 }
+
+func TestFormattedLinkText(t *testing.T) {
+	translator := NewTranslator()
+
+	tests := []struct {
+		name     string
+		markdown string
+		expected []string // text runs as produced by markRuns
+	}{
+		{
+			name:     "bold link text",
+			markdown: "[**bold link**](https://x.com)",
+			expected: []string{"bold link[link+strong]"},
+		},
+		{
+			name:     "italic link text",
+			markdown: "[_italic link_](https://x.com)",
+			expected: []string{"italic link[em+link]"},
+		},
+		{
+			name:     "code link text",
+			markdown: "[`code`](https://x.com)",
+			expected: []string{"code[code+link]"},
+		},
+		{
+			name:     "partially formatted link text",
+			markdown: "See [the **docs** page](https://x.com).",
+			expected: []string{"See []", "the [link]", "docs[link+strong]", " page[link]", ".[]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := translator.TranslateToADF([]byte(tt.markdown))
+			if err != nil {
+				t.Fatalf("Failed to convert markdown: %v", err)
+			}
+			if len(doc.Content) != 1 || doc.Content[0].Type != adf.NodeParagraph {
+				jsonBytes, _ := json.MarshalIndent(doc, "", "  ")
+				t.Fatalf("Expected a single paragraph, got:\n%s", string(jsonBytes))
+			}
+
+			paragraph := doc.Content[0]
+			for _, n := range paragraph.Content {
+				for _, m := range n.Marks {
+					if m.Type == adf.MarkLink && m.Attrs["href"] != "https://x.com" {
+						t.Errorf("Expected href https://x.com, got %v", m.Attrs["href"])
+					}
+				}
+			}
+
+			actual := markRuns(paragraph.Content)
+			if !slices.Equal(tt.expected, actual) {
+				t.Errorf("Markdown %q parsed as %v, expected %v", tt.markdown, actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMentionInLinkText(t *testing.T) {
+	translator := NewTranslator(WithUserEmailMapping(map[string]string{"user@example.com": "user-id"}))
+
+	doc, err := translator.TranslateToADF([]byte("[ask @user@example.com](https://x.com)"))
+	if err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+	if len(doc.Content) != 1 || doc.Content[0].Type != adf.NodeParagraph || len(doc.Content[0].Content) == 0 {
+		jsonBytes, _ := json.MarshalIndent(doc, "", "  ")
+		t.Fatalf("Expected a non-empty paragraph, got:\n%s", string(jsonBytes))
+	}
+
+	// Mentions can't carry a link mark, text around them can
+	for _, n := range doc.Content[0].Content {
+		if n.Type == adf.InlineNodeMention && len(n.Marks) != 0 {
+			t.Errorf("Expected mention without marks, got %v", n.Marks)
+		}
+	}
+}
+
+func TestInlineCardURLNormalization(t *testing.T) {
+	card := &adf.ADFNode{Type: adf.InlineNodeCard, Attrs: map[string]any{"url": "https://example.atlassian.net/browse/PROJ-1"}}
+	paragraph := adf.NewParagraphNode()
+	paragraph.Content = append(paragraph.Content, card)
+
+	translator := NewTranslator()
+	if _, err := translator.TranslateToMarkdown(&adf.ADFDocument{Type: "doc", Content: []*adf.ADFNode{paragraph}}); err != nil {
+		t.Fatalf("Failed to render markdown: %v", err)
+	}
+
+	markdown := "See [link](https://Example.Atlassian.net/browse/PROJ-1/?atlOrigin=abc)"
+	doc, err := translator.TranslateToADF([]byte(markdown))
+	if err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+
+	if !slices.Contains(doc.Content[0].Content, card) {
+		jsonBytes, _ := json.MarshalIndent(doc, "", "  ")
+		t.Errorf("Expected the preserved inline card, got:\n%s", string(jsonBytes))
+	}
+}
+
+func TestReferenceLinks(t *testing.T) {
+	translator := NewTranslator()
+	markdown := "See [the docs][Docs], [docs][] and [docs], not [WIP] or [gone][nowhere].\n\n" +
+		"[docs]: <https://example.com/docs> \"Docs\"\n" +
+		"[DOCS]: https://example.com/ignored\n"
+
+	doc, err := translator.TranslateToADF([]byte(markdown))
+	if err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+
+	if len(doc.Content) != 1 {
+		jsonBytes, _ := json.MarshalIndent(doc, "", "  ")
+		t.Fatalf("Expected the definitions to be omitted, got:\n%s", string(jsonBytes))
+	}
+
+	var linked, literal []string
+	for _, n := range doc.Content[0].Content {
+		if len(n.Marks) == 1 && n.Marks[0].Type == adf.MarkLink {
+			if href := n.Marks[0].Attrs["href"]; href != "https://example.com/docs" {
+				t.Errorf("Expected %q linked to the first definition, got %v", n.Text, href)
+			}
+			linked = append(linked, n.Text)
+		} else {
+			literal = append(literal, n.Text)
+		}
+	}
+	if !slices.Equal(linked, []string{"the docs", "docs", "docs"}) {
+		t.Errorf("Expected the three references linked, got %q", linked)
+	}
+	if !slices.Contains(literal, "[WIP]") || !slices.Contains(literal, "[gone][nowhere]") {
+		t.Errorf("Expected unresolved references kept as text, got %q", literal)
+	}
+
+	warnings := translator.Warnings()
+	if len(warnings) != 1 || warnings[0].Code != WarningUnresolvedReference {
+		t.Fatalf("Expected one unresolved reference warning, got %+v", warnings)
+	}
+	if warnings[0].Pos.Line != 1 {
+		t.Errorf("Expected the warning on line 1, got %s", warnings[0].Pos)
+	}
+}
+
+func TestLinkDestinationsAndTitles(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		href     string
+		title    string
+	}{
+		{"double-quoted title", `[x](https://a.com "My title")`, "https://a.com", "My title"},
+		{"single-quoted title", `[x](https://a.com 'My title')`, "https://a.com", "My title"},
+		{"parenthesized title", `[x](https://a.com (My title))`, "https://a.com", "My title"},
+		{"escaped quote in title", `[x](https://a.com "say \"hi\"")`, "https://a.com", `say "hi"`},
+		{"parentheses in url", "[Go](https://en.wikipedia.org/wiki/Go_(programming_language))", "https://en.wikipedia.org/wiki/Go_(programming_language)", ""},
+		{"angle-bracketed destination", "[x](<https://a.com/a b>)", "https://a.com/a b", ""},
+		{"angle-bracketed destination with title", `[x](<https://a.com/(x)> "T")`, "https://a.com/(x)", "T"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := NewTranslator().TranslateToADF([]byte(tt.markdown))
+			if err != nil {
+				t.Fatalf("Failed to convert markdown: %v", err)
+			}
+			if len(doc.Content) != 1 || len(doc.Content[0].Content) != 1 || len(doc.Content[0].Content[0].Marks) != 1 {
+				jsonBytes, _ := json.MarshalIndent(doc, "", "  ")
+				t.Fatalf("Expected a single linked text node, got:\n%s", string(jsonBytes))
+			}
+
+			link := doc.Content[0].Content[0].Marks[0]
+			if href := link.Attrs["href"]; href != tt.href {
+				t.Errorf("Expected href %q, got %v", tt.href, href)
+			}
+			title, hasTitle := link.Attrs["title"]
+			if tt.title == "" && hasTitle {
+				t.Errorf("Expected no title, got %v", title)
+			} else if tt.title != "" && title != tt.title {
+				t.Errorf("Expected title %q, got %v", tt.title, title)
+			}
+		})
+	}
+}
+
+func TestLinkRoundtripKeepsPunctuation(t *testing.T) {
+	translator := NewTranslator()
+
+	for _, markdown := range []string{
+		"see [docs](https://x.y).",
+		`see [docs](https://x.y "The docs"), then`,
+		"see [Go](https://en.wikipedia.org/wiki/Go_(programming_language)).",
+		"see [spaced](<https://x.y/a b>)!",
+	} {
+		first, err := translator.TranslateToADF([]byte(markdown))
+		if err != nil {
+			t.Fatalf("Failed to convert %q: %v", markdown, err)
+		}
+		rendered, err := translator.TranslateToMarkdown(first)
+		if err != nil {
+			t.Fatalf("Failed to render %q: %v", markdown, err)
+		}
+		if strings.TrimSpace(rendered) != markdown {
+			t.Errorf("Expected %q to render back unchanged, got %q", markdown, rendered)
+		}
+
+		second, err := translator.TranslateToADF([]byte(rendered))
+		if err != nil {
+			t.Fatalf("Failed to convert %q: %v", rendered, err)
+		}
+		if !adf.EqualDocuments(first, second) {
+			jsonBytes, _ := json.MarshalIndent(second, "", "  ")
+			t.Errorf("Expected the same document back from %q, got:\n%s", rendered, string(jsonBytes))
+		}
+	}
+}
@@ -1,6 +1,11 @@
 package md2adf
 
 import (
+	"encoding/json"
+	"github.com/jorres/md2adf-translator/adf"
+	"github.com/jorres/md2adf-translator/adf2md"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -144,3 +149,268 @@ func TestOrderedListStartingNumber(t *testing.T) {
 		t.Errorf("Expected order to be 5, got %v", order)
 	}
 }
+
+func TestMultiParagraphListItem(t *testing.T) {
+	converter := NewTranslator()
+	markdown := `- first paragraph
+
+  second paragraph
+- next item`
+
+	doc, err := converter.TranslateToADF([]byte(markdown))
+	if err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+
+	bulletList := doc.Content[0]
+	if bulletList.Type != "bulletList" || len(bulletList.Content) != 2 {
+		t.Fatalf("Expected bulletList with 2 items, got %s with %d", bulletList.Type, len(bulletList.Content))
+	}
+
+	firstItem := bulletList.Content[0]
+	if len(firstItem.Content) != 2 {
+		t.Fatalf("Expected first item to have 2 paragraphs, got %d", len(firstItem.Content))
+	}
+	for i, expected := range []string{"first paragraph", "second paragraph"} {
+		paragraph := firstItem.Content[i]
+		if paragraph.Type != "paragraph" || len(paragraph.Content) == 0 || paragraph.Content[0].Text != expected {
+			t.Errorf("Expected paragraph %d to be %q, got %+v", i, expected, paragraph)
+		}
+	}
+}
+
+func TestListItemWithTrailingCodeBlock(t *testing.T) {
+	converter := NewTranslator()
+	markdown := "1. Run the build:\n\n   ```bash\n   make build\n   ```\n2. Done"
+
+	doc, err := converter.TranslateToADF([]byte(markdown))
+	if err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+
+	orderedList := doc.Content[0]
+	if orderedList.Type != "orderedList" || len(orderedList.Content) != 2 {
+		t.Fatalf("Expected orderedList with 2 items, got %s with %d", orderedList.Type, len(orderedList.Content))
+	}
+
+	firstItem := orderedList.Content[0]
+	if len(firstItem.Content) != 2 {
+		t.Fatalf("Expected first item to have paragraph and code block, got %d nodes", len(firstItem.Content))
+	}
+	if firstItem.Content[0].Type != "paragraph" || firstItem.Content[1].Type != "codeBlock" {
+		t.Errorf("Expected paragraph followed by codeBlock, got %s, %s", firstItem.Content[0].Type, firstItem.Content[1].Type)
+	}
+}
+
+func TestMultiParagraphListItemRoundtrip(t *testing.T) {
+	converter := NewTranslator()
+	reverse := adf2md.NewTranslator(adf2md.NewMarkdownTranslator())
+	markdown := `- first paragraph
+
+  second paragraph
+- next item`
+
+	doc, err := converter.TranslateToADF([]byte(markdown))
+	if err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+
+	rendered := reverse.MustTranslate(&adf.ADFNode{Type: "doc", Content: doc.Content})
+
+	roundtrip, err := converter.TranslateToADF([]byte(rendered))
+	if err != nil {
+		t.Fatalf("Failed to convert rendered markdown: %v", err)
+	}
+
+	bulletList := roundtrip.Content[0]
+	if len(roundtrip.Content) != 1 || len(bulletList.Content) != 2 {
+		t.Fatalf("Roundtrip changed the list structure, rendered markdown:\n%s", rendered)
+	}
+	if len(bulletList.Content[0].Content) != 2 {
+		t.Fatalf("Roundtrip lost the continuation paragraph, rendered markdown:\n%s", rendered)
+	}
+}
+
+// TestContinuationParagraphRoundtrip checks that continuation paragraphs lose
+// their indentation and are rendered at the content column of the marker, so
+// that they neither grow on every roundtrip nor turn into indented code.
+func TestContinuationParagraphRoundtrip(t *testing.T) {
+	converter := NewTranslator()
+	reverse := adf2md.NewTranslator(adf2md.NewMarkdownTranslator())
+
+	for _, markdown := range []string{"- a\n\n    b", "- a\n\n  b", "1. a\n\n   b"} {
+		doc, err := converter.TranslateToADF([]byte(markdown))
+		if err != nil {
+			t.Fatalf("Failed to convert markdown: %v", err)
+		}
+		if text := adf.PlainText(&adf.ADFDocument{Content: doc.Content[0].Content[0].Content[1:]}); text != "b" {
+			t.Errorf("%q: expected the continuation paragraph text %q, got %q", markdown, "b", text)
+		}
+
+		rendered := reverse.MustTranslate(&adf.ADFNode{Type: "doc", Content: doc.Content})
+		roundtrip, err := converter.TranslateToADF([]byte(rendered))
+		if err != nil {
+			t.Fatalf("Failed to convert rendered markdown: %v", err)
+		}
+		if !adf.Equal(&adf.ADFNode{Type: "doc", Content: doc.Content}, &adf.ADFNode{Type: "doc", Content: roundtrip.Content}) {
+			t.Errorf("%q: roundtrip changed the document, rendered markdown:\n%s", markdown, rendered)
+		}
+	}
+}
+
+func TestFencedCodeBlockInListItem(t *testing.T) {
+	converter := NewTranslator()
+	markdown := "1. Install dependencies:\n\n   ```bash\n   go mod download\n   if [ -n \"$CI\" ]; then\n       make ci\n   fi\n   ```\n\n2. Build the project:\n\n   ```go\n   package main\n   ```\n"
+
+	doc, err := converter.TranslateToADF([]byte(markdown))
+	if err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+
+	if len(doc.Content) != 1 || doc.Content[0].Type != "orderedList" {
+		jsonBytes, _ := json.MarshalIndent(doc, "", "  ")
+		t.Fatalf("Expected a single orderedList, got:\n%s", string(jsonBytes))
+	}
+
+	tests := []struct {
+		item     int
+		language string
+		code     string
+	}{
+		{0, "bash", "go mod download\nif [ -n \"$CI\" ]; then\n    make ci\nfi"},
+		{1, "go", "package main"},
+	}
+
+	for _, tt := range tests {
+		item := doc.Content[0].Content[tt.item]
+		if len(item.Content) != 2 || item.Content[1].Type != "codeBlock" {
+			jsonBytes, _ := json.MarshalIndent(item, "", "  ")
+			t.Fatalf("Expected item %d to end with a codeBlock, got:\n%s", tt.item, string(jsonBytes))
+		}
+
+		codeBlock := item.Content[1]
+		if lang, _ := codeBlock.Attrs["language"].(string); lang != tt.language {
+			t.Errorf("Item %d: expected language %q, got %q", tt.item, tt.language, lang)
+		}
+		if len(codeBlock.Content) != 1 || codeBlock.Content[0].Text != tt.code {
+			jsonBytes, _ := json.MarshalIndent(codeBlock, "", "  ")
+			t.Errorf("Item %d: expected code %q, got:\n%s", tt.item, tt.code, string(jsonBytes))
+		}
+	}
+}
+
+func TestParenthesisListMarkers(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		expected []int // starting number of each ordered list, in order
+	}{
+		{
+			name:     "parenthesis markers",
+			markdown: "3) third\n4) fourth",
+			expected: []int{3},
+		},
+		{
+			// Changing the delimiter starts a new list, as in CommonMark
+			name:     "dot then parenthesis",
+			markdown: "1. one\n2. two\n1) again\n2) and again",
+			expected: []int{1, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := NewTranslator().TranslateToADF([]byte(tt.markdown))
+			if err != nil {
+				t.Fatalf("Failed to convert markdown: %v", err)
+			}
+
+			var starts []int
+			for _, block := range doc.Content {
+				if block.Type != adf.NodeOrderedList {
+					jsonBytes, _ := json.MarshalIndent(doc, "", "  ")
+					t.Fatalf("Expected only ordered lists, got:\n%s", string(jsonBytes))
+				}
+				if len(block.Content) != 2 {
+					t.Errorf("Expected 2 items per list, got %d", len(block.Content))
+				}
+				order := 1 // the attr is left out for lists starting at 1
+				if o, ok := block.Attrs["order"].(int); ok {
+					order = o
+				}
+				starts = append(starts, order)
+			}
+
+			if !slices.Equal(tt.expected, starts) {
+				t.Errorf("Expected lists starting at %v, got %v", tt.expected, starts)
+			}
+		})
+	}
+}
+
+func TestOrderedListStartingNumberRoundtrip(t *testing.T) {
+	markdown := "5. fifth item\n6. sixth item\n"
+
+	doc, err := NewTranslator().TranslateToADF([]byte(markdown))
+	if err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+
+	rendered := adf2md.NewTranslator(adf2md.NewMarkdownTranslator()).MustTranslate(&adf.ADFNode{Type: "doc", Content: doc.Content})
+	if !strings.HasPrefix(rendered, "5. fifth item\n6. sixth item") {
+		t.Errorf("Expected numbering to start at 5, got %q", rendered)
+	}
+
+	// Once more through JSON, where the order attr comes back as float64
+	jsonBytes, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("Failed to encode document: %v", err)
+	}
+	var decoded adf.ADFNode
+	if err := json.Unmarshal(jsonBytes, &decoded); err != nil {
+		t.Fatalf("Failed to decode document: %v", err)
+	}
+	rendered = adf2md.NewTranslator(adf2md.NewMarkdownTranslator()).MustTranslate(&decoded)
+	if !strings.HasPrefix(rendered, "5. fifth item\n6. sixth item") {
+		t.Errorf("Expected numbering to start at 5 after JSON, got %q", rendered)
+	}
+}
+
+func TestMixedNestedListRoundtrip(t *testing.T) {
+	markdown := "1. one\n   - bullet\n     1. inner a\n     2. inner b\n   - second bullet\n2. two\n"
+
+	// listShape describes lists as their type followed by their items' nested lists.
+	var listShape func(n *adf.ADFNode) string
+	listShape = func(n *adf.ADFNode) string {
+		shape := string(n.Type) + "("
+		for _, item := range n.Content {
+			shape += "["
+			for _, block := range item.Content {
+				if block.Type == adf.NodeOrderedList || block.Type == adf.NodeBulletList {
+					shape += listShape(block)
+				}
+			}
+			shape += "]"
+		}
+		return shape + ")"
+	}
+
+	doc, err := NewTranslator().TranslateToADF([]byte(markdown))
+	if err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+	if len(doc.Content) != 1 {
+		jsonBytes, _ := json.MarshalIndent(doc, "", "  ")
+		t.Fatalf("Expected a single list, got:\n%s", string(jsonBytes))
+	}
+
+	expected := "orderedList([bulletList([orderedList([][])][])][])"
+	if actual := listShape(doc.Content[0]); actual != expected {
+		t.Fatalf("Expected %s, got %s", expected, actual)
+	}
+
+	rendered := adf2md.NewTranslator(adf2md.NewMarkdownTranslator()).MustTranslate(&adf.ADFNode{Type: "doc", Content: doc.Content})
+	if rendered != markdown {
+		t.Errorf("Expected the markdown back unchanged, got %q", rendered)
+	}
+}
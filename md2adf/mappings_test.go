@@ -0,0 +1,357 @@
+package md2adf
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jorres/md2adf-translator/adf"
+	"github.com/jorres/md2adf-translator/adf2md"
+)
+
+func issueWithAttachment(collection string) *adf.ADFNode {
+	media := &adf.ADFNode{Type: adf.NodeMedia, Attrs: map[string]any{
+		"id":         "file-1",
+		"type":       "file",
+		"collection": collection,
+	}}
+	mediaSingle := adf.NewMediaSingleNode("center")
+	mediaSingle.Content = append(mediaSingle.Content, media)
+
+	paragraph := adf.NewParagraphNode()
+	paragraph.Content = append(paragraph.Content, adf.NewTextNode("Screenshot:"))
+
+	return &adf.ADFNode{Type: "doc", Content: []*adf.ADFNode{paragraph, mediaSingle}}
+}
+
+func TestPreservedNodeChangedWarning(t *testing.T) {
+	// Fetch the issue and cache its markdown along with the mappings
+	fetch := adf2md.NewTranslator(adf2md.NewJiraMarkdownTranslator())
+	markdown := fetch.MustTranslate(issueWithAttachment("old-collection"))
+	cached := fetch.ExportMappings()
+
+	// Edit the markdown
+	markdown = strings.Replace(markdown, "Screenshot:", "Updated screenshot:", 1)
+
+	// Re-fetch the issue, whose attachment moved to another collection meanwhile
+	refetch := adf2md.NewTranslator(adf2md.NewJiraMarkdownTranslator())
+	refetch.Translate(issueWithAttachment("new-collection"))
+
+	// Push the edited markdown
+	reverse := adf2md.NewTranslator(adf2md.NewJiraMarkdownTranslator())
+	reverse.ImportMappings(cached)
+	reverse.ImportMappings(refetch.ExportMappings())
+
+	translator := NewTranslator(WithAdf2MdTranslator(reverse))
+	doc, err := translator.TranslateToADF([]byte(markdown))
+	if err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+
+	var media *adf.ADFNode
+	for _, n := range doc.Content {
+		if n.Type == adf.NodeMediaSingle {
+			media = n
+		}
+	}
+	if media == nil {
+		t.Fatalf("Expected the attachment in:\n%s", markdown)
+	}
+	if media.Content[0].Attrs["collection"] != "new-collection" {
+		t.Errorf("Expected the node from the newer fetch, got collection %v", media.Content[0].Attrs["collection"])
+	}
+
+	warnings := translator.Warnings()
+	if len(warnings) != 1 || warnings[0].Code != WarningPreservedNodeChanged || !strings.Contains(warnings[0].Message, "file-1") {
+		t.Errorf("Expected one %s warning about file-1, got %+v", WarningPreservedNodeChanged, warnings)
+	}
+
+	// Without a changed resource there is nothing to warn about
+	unchanged := adf2md.NewTranslator(adf2md.NewJiraMarkdownTranslator())
+	unchanged.ImportMappings(cached)
+	translator = NewTranslator(WithAdf2MdTranslator(unchanged))
+	if _, err := translator.TranslateToADF([]byte(markdown)); err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+	if warnings := translator.Warnings(); len(warnings) != 0 {
+		t.Errorf("Expected no warnings, got %+v", warnings)
+	}
+}
+
+func TestTranslateToMarkdownRoundtrip(t *testing.T) {
+	issue := issueWithAttachment("collection")
+	translator := NewTranslator()
+
+	markdown, err := translator.TranslateToMarkdown(&adf.ADFDocument{Version: 1, Type: "doc", Content: issue.Content})
+	if err != nil {
+		t.Fatalf("Failed to render markdown: %v", err)
+	}
+
+	markdown = strings.Replace(markdown, "Screenshot:", "New screenshot:", 1)
+	doc, err := translator.TranslateToADF([]byte(markdown))
+	if err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+
+	if len(doc.Content) != 2 || doc.Content[1] != issue.Content[1] {
+		t.Errorf("Expected the attachment node to be restored from:\n%s", markdown)
+	}
+	if text := adf.PlainText(&adf.ADFDocument{Content: doc.Content[:1]}); text != "New screenshot:" {
+		t.Errorf("Expected the edited text, got %q", text)
+	}
+}
+
+func TestMediaGroupRoundtrip(t *testing.T) {
+	group := &adf.ADFNode{Type: adf.NodeMediaGroup}
+	for _, id := range []string{"file-1", "file-2", "file-3"} {
+		group.Content = append(group.Content, &adf.ADFNode{Type: adf.NodeMedia, Attrs: map[string]any{"id": id, "type": "file", "collection": "c"}})
+	}
+	issue := &adf.ADFDocument{Type: "doc", Content: []*adf.ADFNode{paragraphOf("Attachments:"), group}}
+
+	translator := NewTranslator()
+	markdown, err := translator.TranslateToMarkdown(issue)
+	if err != nil {
+		t.Fatalf("Failed to render markdown: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		markdown string
+		expected [][]string // ids of the media in each group
+	}{
+		{
+			name:     "untouched",
+			markdown: markdown,
+			expected: [][]string{{"file-1", "file-2", "file-3"}},
+		},
+		{
+			name:     "one removed",
+			markdown: strings.Replace(markdown, "{attachment:file-2}\n", "", 1),
+			expected: [][]string{{"file-1", "file-3"}},
+		},
+		{
+			name:     "split by text",
+			markdown: strings.Replace(markdown, "{attachment:file-2}\n", "{attachment:file-2}\n\nBetween\n\n", 1),
+			expected: [][]string{{"file-1", "file-2"}, {"file-3"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := translator.TranslateToADF([]byte(tt.markdown))
+			if err != nil {
+				t.Fatalf("Failed to convert markdown: %v", err)
+			}
+
+			var groups [][]string
+			for _, n := range doc.Content {
+				if n.Type != adf.NodeMediaGroup {
+					continue
+				}
+				var ids []string
+				for _, media := range n.Content {
+					attrs, _ := media.MediaAttrs()
+					ids = append(ids, attrs.ID)
+				}
+				groups = append(groups, ids)
+			}
+			if !slices.EqualFunc(groups, tt.expected, slices.Equal) {
+				t.Fatalf("Expected groups %v, got %v from:\n%s", tt.expected, groups, tt.markdown)
+			}
+			if len(tt.expected[0]) == len(group.Content) && doc.Content[1] != group {
+				t.Errorf("Expected the complete group to be the preserved node")
+			}
+		})
+	}
+}
+
+func TestMissingAttachment(t *testing.T) {
+	markdown := []byte("Before\n\n{attachment:missing}\n\nAfter")
+
+	translator := NewTranslator()
+	doc, err := translator.TranslateToADF(markdown)
+	if err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+	if len(doc.Content) != 3 || doc.Content[1].Type != adf.NodeParagraph || doc.Content[1].Content[0].Text != "{attachment:missing}" {
+		jsonBytes, _ := json.MarshalIndent(doc, "", "  ")
+		t.Fatalf("Expected the reference kept as a paragraph, got:\n%s", string(jsonBytes))
+	}
+	if warnings := translator.Warnings(); len(warnings) != 1 || warnings[0].Code != WarningUnknownAttachment {
+		t.Errorf("Expected one %s warning, got %+v", WarningUnknownAttachment, warnings)
+	}
+
+	var asked []string
+	external := WithMissingAttachmentHandler(func(id string) (*adf.ADFNode, error) {
+		asked = append(asked, id)
+		mediaSingle := adf.NewMediaSingleNode("center")
+		mediaSingle.Content = append(mediaSingle.Content, adf.NewExternalMediaNode("https://example.com/"+id, ""))
+		return mediaSingle, nil
+	})
+	doc, err = NewTranslator(external).TranslateToADF(markdown)
+	if err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+	if len(doc.Content) != 3 || doc.Content[1].Type != adf.NodeMediaSingle || !slices.Equal(asked, []string{"missing"}) {
+		t.Errorf("Expected the handler's node for the missing attachment, asked for %v", asked)
+	}
+
+	// Known attachments never reach the handler
+	asked = nil
+	translator = NewTranslator(external)
+	known, _ := translator.TranslateToMarkdown(&adf.ADFDocument{Type: "doc", Content: issueWithAttachment("c").Content})
+	if _, err := translator.TranslateToADF([]byte(known)); err != nil || len(asked) != 0 {
+		t.Errorf("Expected the mapping to take precedence, asked for %v (err %v)", asked, err)
+	}
+
+	failing := errors.New("no such attachment")
+	_, err = NewTranslator(WithMissingAttachmentHandler(func(id string) (*adf.ADFNode, error) { return nil, failing })).TranslateToADF(markdown)
+	if !errors.Is(err, failing) || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("Expected the handler's error, got %v", err)
+	}
+
+	translator = NewTranslator(WithMissingAttachmentHandler(func(id string) (*adf.ADFNode, error) { return nil, nil }))
+	doc, err = translator.TranslateToADF(markdown)
+	if err != nil || len(doc.Content) != 2 {
+		t.Errorf("Expected the reference dropped, got %d blocks (err %v)", len(doc.Content), err)
+	}
+	if warnings := translator.Warnings(); len(warnings) != 1 || warnings[0].Code != WarningDroppedContent {
+		t.Errorf("Expected one %s warning, got %+v", WarningDroppedContent, warnings)
+	}
+}
+
+func TestAttachmentNamesRoundtrip(t *testing.T) {
+	group := &adf.ADFNode{Type: adf.NodeMediaGroup}
+	for _, name := range []string{"a.png", "b.png"} {
+		group.Content = append(group.Content, &adf.ADFNode{Type: adf.NodeMedia, Attrs: map[string]any{"id": "id-" + name, "type": "file", "alt": name}})
+	}
+	issue := &adf.ADFDocument{Type: "doc", Content: []*adf.ADFNode{paragraphOf("Files:"), group}}
+
+	reverse := adf2md.NewTranslator(adf2md.NewJiraMarkdownTranslator(), adf2md.WithAttachmentNames(nil))
+	translator := NewTranslator(WithAdf2MdTranslator(reverse))
+	markdown, err := translator.TranslateToMarkdown(issue)
+	if err != nil {
+		t.Fatalf("Failed to render markdown: %v", err)
+	}
+	if !strings.Contains(markdown, "{attachment:a.png}\n{attachment:b.png}") {
+		t.Fatalf("Expected attachments referenced by name, got:\n%s", markdown)
+	}
+
+	// Names and ids both resolve
+	edited := strings.Replace(markdown, "{attachment:b.png}", "{attachment:id-b.png}", 1)
+	for _, input := range []string{markdown, edited} {
+		doc, err := translator.TranslateToADF([]byte(input))
+		if err != nil {
+			t.Fatalf("Failed to convert markdown: %v", err)
+		}
+		if len(doc.Content) != 2 || doc.Content[1] != group {
+			jsonBytes, _ := json.MarshalIndent(doc, "", "  ")
+			t.Errorf("Expected the preserved group from %q, got:\n%s", input, string(jsonBytes))
+		}
+	}
+}
+
+func TestAttachmentParameters(t *testing.T) {
+	issue := issueWithAttachment("c")
+	mediaSingle := issue.Content[1]
+	mediaSingle.Attrs["layout"] = "wrap-left"
+	mediaSingle.Content[0].Attrs["width"] = 640.0
+	mediaSingle.Content[0].Attrs["height"] = 480.0
+
+	translator := NewTranslator()
+	markdown, err := translator.TranslateToMarkdown(&adf.ADFDocument{Type: "doc", Content: issue.Content})
+	if err != nil {
+		t.Fatalf("Failed to render markdown: %v", err)
+	}
+	if !strings.Contains(markdown, "{attachment:file-1|width=640|height=480|layout=wrap-left}") {
+		t.Fatalf("Expected the size and layout as parameters, got:\n%s", markdown)
+	}
+
+	// Parameters edited in the markdown apply to a copy of the preserved node
+	edited := strings.Replace(markdown, "|width=640|height=480|layout=wrap-left", "|width=320|layout=center", 1)
+	doc, err := translator.TranslateToADF([]byte(edited))
+	if err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+	resized := doc.Content[1]
+	if resized.Attrs["layout"] != "center" || resized.Content[0].Attrs["width"] != 320 || resized.Content[0].Attrs["height"] != 480.0 {
+		jsonBytes, _ := json.MarshalIndent(resized, "", "  ")
+		t.Errorf("Expected the media resized and centered, got:\n%s", string(jsonBytes))
+	}
+	if mediaSingle.Attrs["layout"] != "wrap-left" || mediaSingle.Content[0].Attrs["width"] != 640.0 {
+		t.Errorf("Expected the preserved node left as it was, got %v and %v", mediaSingle.Attrs, mediaSingle.Content[0].Attrs)
+	}
+
+	// Attachments not in the mapping get the parameters too
+	external := WithMissingAttachmentHandler(func(id string) (*adf.ADFNode, error) {
+		mediaSingle := adf.NewMediaSingleNode("center")
+		mediaSingle.Content = append(mediaSingle.Content, adf.NewExternalMediaNode("https://example.com/"+id, ""))
+		return mediaSingle, nil
+	})
+	translator = NewTranslator(external)
+	doc, err = translator.TranslateToADF([]byte("{attachment:new.png|width=100|layout=wide|size=big|height=tall}"))
+	if err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+	added := doc.Content[0]
+	if added.Attrs["layout"] != "wide" || added.Content[0].Attrs["width"] != 100 || added.Content[0].Attrs["height"] != nil {
+		jsonBytes, _ := json.MarshalIndent(added, "", "  ")
+		t.Errorf("Expected the new attachment sized and laid out, got:\n%s", string(jsonBytes))
+	}
+	warnings := translator.Warnings()
+	if len(warnings) != 2 || warnings[0].Code != WarningInvalidAttachmentParameter || warnings[1].Code != WarningInvalidAttachmentParameter {
+		t.Errorf("Expected two %s warnings, got %+v", WarningInvalidAttachmentParameter, warnings)
+	}
+}
+
+func TestCaptionRoundtrip(t *testing.T) {
+	issue := issueWithAttachment("c")
+	mediaSingle := issue.Content[1]
+	caption := adf.NewCaptionNode()
+	caption.Content = append(caption.Content, adf.NewTextNode("The login page"))
+	mediaSingle.Content = append(mediaSingle.Content, caption)
+
+	translator := NewTranslator()
+	markdown, err := translator.TranslateToMarkdown(&adf.ADFDocument{Type: "doc", Content: issue.Content})
+	if err != nil {
+		t.Fatalf("Failed to render markdown: %v", err)
+	}
+	if !strings.Contains(markdown, "{attachment:file-1}\n{caption}The login page{/caption}") {
+		t.Fatalf("Expected the caption below the attachment, got:\n%s", markdown)
+	}
+
+	captionText := func(doc *adf.ADFDocument) (string, bool) {
+		for _, n := range doc.Content[1].Content {
+			if n.Type == adf.NodeCaption {
+				return adf.PlainText(&adf.ADFDocument{Content: []*adf.ADFNode{n}}), true
+			}
+		}
+		return "", false
+	}
+
+	edited := strings.Replace(markdown, "The login page", "The **new** login page", 1)
+	doc, err := translator.TranslateToADF([]byte(edited))
+	if err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+	if text, _ := captionText(doc); len(doc.Content) != 2 || text != "The new login page" {
+		jsonBytes, _ := json.MarshalIndent(doc, "", "  ")
+		t.Errorf("Expected the edited caption, got:\n%s", string(jsonBytes))
+	}
+	if len(mediaSingle.Content) != 2 || mediaSingle.Content[1] != caption || caption.Content[0].Text != "The login page" {
+		t.Errorf("Expected the preserved node left as it was, got %+v", mediaSingle.Content)
+	}
+
+	// Removing the caption line removes the caption
+	removed := strings.Replace(markdown, "\n{caption}The login page{/caption}", "", 1)
+	doc, err = translator.TranslateToADF([]byte(removed))
+	if err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+	if _, ok := captionText(doc); ok || len(doc.Content[1].Content) != 1 {
+		jsonBytes, _ := json.MarshalIndent(doc, "", "  ")
+		t.Errorf("Expected the caption removed, got:\n%s", string(jsonBytes))
+	}
+}
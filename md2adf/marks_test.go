@@ -0,0 +1,365 @@
+package md2adf
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jorres/md2adf-translator/adf"
+	"github.com/jorres/md2adf-translator/adf2md"
+)
+
+// markRuns describes inline content as text runs keyed by their sorted mark
+// types, merging neighbours with the same marks so node splits don't matter.
+func markRuns(nodes []*adf.ADFNode) []string {
+	var runs []string
+	lastKey := ""
+	for _, n := range nodes {
+		var types []string
+		for _, m := range n.Marks {
+			types = append(types, string(m.Type))
+		}
+		slices.Sort(types)
+		key := strings.Join(types, "+")
+
+		if len(runs) > 0 && key == lastKey {
+			runs[len(runs)-1] = strings.TrimSuffix(runs[len(runs)-1], "["+key+"]") + n.Text + "[" + key + "]"
+			continue
+		}
+		runs = append(runs, n.Text+"["+key+"]")
+		lastKey = key
+	}
+	return runs
+}
+
+func TestAdjacentMarksReparse(t *testing.T) {
+	strong := func() *adf.ADFMark { return adf.NewStrongMark() }
+	em := func() *adf.ADFMark { return adf.NewEmphasisMark() }
+
+	tests := []struct {
+		name  string
+		nodes []*adf.ADFNode
+	}{
+		{
+			name: "adjacent identically marked nodes",
+			nodes: []*adf.ADFNode{
+				adf.NewTextNodeWithMarks("a", []*adf.ADFMark{strong()}),
+				adf.NewTextNodeWithMarks("b", []*adf.ADFMark{strong()}),
+			},
+		},
+		{
+			name: "bold followed by italic",
+			nodes: []*adf.ADFNode{
+				adf.NewTextNodeWithMarks("bold", []*adf.ADFMark{strong()}),
+				adf.NewTextNodeWithMarks("italic", []*adf.ADFMark{em()}),
+			},
+		},
+		{
+			name: "bold followed by bold italic",
+			nodes: []*adf.ADFNode{
+				adf.NewTextNodeWithMarks("a", []*adf.ADFMark{strong()}),
+				adf.NewTextNodeWithMarks("b", []*adf.ADFMark{strong(), em()}),
+			},
+		},
+		{
+			name: "italic bold followed by italic",
+			nodes: []*adf.ADFNode{
+				adf.NewTextNodeWithMarks("a", []*adf.ADFMark{em(), strong()}),
+				adf.NewTextNodeWithMarks("b", []*adf.ADFMark{em()}),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paragraph := adf.NewParagraphNode()
+			paragraph.Content = tt.nodes
+
+			reverse := adf2md.NewTranslator(adf2md.NewMarkdownTranslator())
+			markdown := reverse.MustTranslate(&adf.ADFNode{Type: "doc", Content: []*adf.ADFNode{paragraph}})
+
+			doc, err := NewTranslator().TranslateToADF([]byte(markdown))
+			if err != nil {
+				t.Fatalf("Failed to parse generated markdown %q: %v", markdown, err)
+			}
+			if len(doc.Content) != 1 || doc.Content[0].Type != adf.NodeParagraph {
+				t.Fatalf("Expected a single paragraph from %q, got %+v", markdown, doc.Content)
+			}
+
+			expected := markRuns(tt.nodes)
+			actual := markRuns(doc.Content[0].Content)
+			if !slices.Equal(expected, actual) {
+				t.Errorf("Markdown %q re-parsed as %v, expected %v", markdown, actual, expected)
+			}
+		})
+	}
+}
+
+func TestStrikethroughDelimiters(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		expected []string
+	}{
+		{
+			name:     "single tilde",
+			markdown: "~gone~",
+			expected: []string{"gone[strike]"},
+		},
+		{
+			name:     "double tilde",
+			markdown: "~~gone~~",
+			expected: []string{"gone[strike]"},
+		},
+		{
+			name:     "mixed in one paragraph",
+			markdown: "~~a~~ and ~b~",
+			expected: []string{"a[strike]", " and []", "b[strike]"},
+		},
+		{
+			name:     "double tilde between plain text",
+			markdown: "before ~~struck text~~ after",
+			expected: []string{"before []", "struck text[strike]", " after[]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := NewTranslator().TranslateToADF([]byte(tt.markdown))
+			if err != nil {
+				t.Fatalf("Failed to convert markdown: %v", err)
+			}
+			if len(doc.Content) != 1 || doc.Content[0].Type != adf.NodeParagraph {
+				t.Fatalf("Expected a single paragraph, got %+v", doc.Content)
+			}
+
+			actual := markRuns(doc.Content[0].Content)
+			if !slices.Equal(tt.expected, actual) {
+				t.Errorf("Markdown %q parsed as %v, expected %v", tt.markdown, actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNestedFormattingKeepsSurroundingText(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		expected []string
+	}{
+		{
+			name:     "underline inside bold",
+			markdown: "**prefix <u>mid</u> suffix**",
+			expected: []string{"prefix [strong]", "mid[strong+underline]", " suffix[strong]"},
+		},
+		{
+			name:     "italic inside bold",
+			markdown: "**bold _both_ bold**",
+			expected: []string{"bold [strong]", "both[em+strong]", " bold[strong]"},
+		},
+		{
+			name:     "bold inside strikethrough",
+			markdown: "~gone **very** gone~",
+			expected: []string{"gone [strike]", "very[strike+strong]", " gone[strike]"},
+		},
+		{
+			name:     "code inside bold",
+			markdown: "**run `make` now**",
+			expected: []string{"run [strong]", "make[code+strong]", " now[strong]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := NewTranslator().TranslateToADF([]byte(tt.markdown))
+			if err != nil {
+				t.Fatalf("Failed to convert markdown: %v", err)
+			}
+			if len(doc.Content) != 1 || doc.Content[0].Type != adf.NodeParagraph {
+				t.Fatalf("Expected a single paragraph, got %+v", doc.Content)
+			}
+
+			actual := markRuns(doc.Content[0].Content)
+			if !slices.Equal(tt.expected, actual) {
+				t.Errorf("Markdown %q parsed as %v, expected %v", tt.markdown, actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMarkCombinationsReparse(t *testing.T) {
+	// Listed innermost first, the reverse of the rendering order, so every
+	// combination has to be reordered before it renders correctly.
+	all := []func() *adf.ADFMark{
+		adf.NewCodeMark,
+		adf.NewUnderlineMark,
+		adf.NewStrikethroughMark,
+		adf.NewEmphasisMark,
+		adf.NewStrongMark,
+		func() *adf.ADFMark { return adf.NewLinkMark("https://example.com") },
+	}
+
+	for subset := 1; subset < 1<<len(all); subset++ {
+		var marks []*adf.ADFMark
+		for i, mark := range all {
+			if subset&(1<<i) != 0 {
+				marks = append(marks, mark())
+			}
+		}
+		text := adf.NewTextNodeWithMarks("text", marks)
+		name := strings.TrimSuffix(strings.TrimPrefix(markRuns([]*adf.ADFNode{text})[0], "text["), "]")
+
+		t.Run(name, func(t *testing.T) {
+			// Underline is raw HTML whose content isn't parsed, and code can't
+			// hold HTML, so the two can't be combined in markdown.
+			if subset&0b11 == 0b11 {
+				t.Skip("code and underline have no markdown representation together")
+			}
+
+			paragraph := adf.NewParagraphNode()
+			paragraph.Content = []*adf.ADFNode{adf.NewTextNode("before "), text, adf.NewTextNode(" after")}
+
+			reverse := adf2md.NewTranslator(adf2md.NewMarkdownTranslator())
+			markdown := reverse.MustTranslate(&adf.ADFNode{Type: "doc", Content: []*adf.ADFNode{paragraph}})
+
+			doc, err := NewTranslator().TranslateToADF([]byte(markdown))
+			if err != nil {
+				t.Fatalf("Failed to parse generated markdown %q: %v", markdown, err)
+			}
+			if len(doc.Content) != 1 || doc.Content[0].Type != adf.NodeParagraph {
+				t.Fatalf("Expected a single paragraph from %q, got %+v", markdown, doc.Content)
+			}
+
+			expected := markRuns(paragraph.Content)
+			actual := markRuns(doc.Content[0].Content)
+			for i := range actual {
+				// Links are rendered followed by a space
+				actual[i] = strings.ReplaceAll(actual[i], "  ", " ")
+			}
+			if !slices.Equal(expected, actual) {
+				t.Errorf("Markdown %q re-parsed as %v, expected %v", markdown, actual, expected)
+			}
+		})
+	}
+}
+
+func TestEscapedTextRoundtrip(t *testing.T) {
+	texts := []string{
+		"use *args and **kwargs",
+		"_private and trailing_",
+		"# not a heading",
+		"see [docs](url) and `tick`",
+		"{panel:type=info} stays text",
+		`C:\dir\*`,
+		"~/.bashrc and ~~not struck~~",
+		"1. Some text",
+		"3) not a list either",
+		"- not a list",
+		"+ not a list",
+		"* not a list",
+		"---",
+	}
+
+	for _, text := range texts {
+		t.Run(text, func(t *testing.T) {
+			paragraph := adf.NewParagraphNode()
+			paragraph.Content = []*adf.ADFNode{adf.NewTextNode(text)}
+
+			reverse := adf2md.NewTranslator(adf2md.NewMarkdownTranslator())
+			markdown := reverse.MustTranslate(&adf.ADFNode{Type: "doc", Content: []*adf.ADFNode{paragraph}})
+
+			doc, err := NewTranslator().TranslateToADF([]byte(markdown))
+			if err != nil {
+				t.Fatalf("Failed to parse generated markdown %q: %v", markdown, err)
+			}
+			if len(doc.Content) != 1 || doc.Content[0].Type != adf.NodeParagraph {
+				t.Fatalf("Expected a single paragraph from %q, got %+v", markdown, doc.Content)
+			}
+
+			expected := markRuns(paragraph.Content)
+			actual := markRuns(doc.Content[0].Content)
+			if !slices.Equal(expected, actual) {
+				t.Errorf("Markdown %q re-parsed as %v, expected %v", markdown, actual, expected)
+			}
+		})
+	}
+}
+
+func TestLineStartEscapingRoundtrip(t *testing.T) {
+	texts := []string{
+		"1. Some text",
+		"- not a list",
+		"line one\n# not heading",
+		"line one\n2) not numbered\n+ not a list\n> not quoted",
+	}
+
+	translator := NewTranslator()
+	for _, text := range texts {
+		t.Run(text, func(t *testing.T) {
+			doc := adf.NewDocBuilder().Paragraph(adf.Text(text)).Build()
+			markdown, err := translator.TranslateToMarkdown(doc)
+			if err != nil {
+				t.Fatalf("Failed to render markdown: %v", err)
+			}
+
+			result, err := translator.TranslateToADF([]byte(markdown))
+			if err != nil {
+				t.Fatalf("Failed to convert %q: %v", markdown, err)
+			}
+			if len(result.Content) != 1 || result.Content[0].Type != adf.NodeParagraph {
+				jsonBytes, _ := json.MarshalIndent(result, "", "  ")
+				t.Fatalf("Expected a single paragraph from %q, got:\n%s", markdown, string(jsonBytes))
+			}
+			if back := adf.PlainText(result); back != text {
+				t.Errorf("Expected %q back from %q, got %q", text, markdown, back)
+			}
+		})
+	}
+}
+
+func TestWhitespaceBetweenInlineNodes(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		expected []string
+	}{
+		{
+			name:     "space around code span",
+			markdown: "word `code` word",
+			expected: []string{"word []", "code[code]", " word[]"},
+		},
+		{
+			name:     "space between two spans",
+			markdown: "**bold** _italic_",
+			expected: []string{"bold[strong]", " []", "italic[em]"},
+		},
+		{
+			name:     "line break between spans becomes a space",
+			markdown: "**bold**\n`code`",
+			expected: []string{"bold[strong]", " []", "code[code]"},
+		},
+		{
+			name:     "space inside formatting",
+			markdown: "**`a` `b`**",
+			expected: []string{"a[code+strong]", " [strong]", "b[code+strong]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := NewTranslator().TranslateToADF([]byte(tt.markdown))
+			if err != nil {
+				t.Fatalf("Failed to convert markdown: %v", err)
+			}
+			if len(doc.Content) != 1 || doc.Content[0].Type != adf.NodeParagraph {
+				t.Fatalf("Expected a single paragraph, got %+v", doc.Content)
+			}
+
+			actual := markRuns(doc.Content[0].Content)
+			if !slices.Equal(tt.expected, actual) {
+				t.Errorf("Markdown %q parsed as %v, expected %v", tt.markdown, actual, tt.expected)
+			}
+		})
+	}
+}
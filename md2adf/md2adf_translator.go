@@ -1,40 +1,234 @@
 package md2adf
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"github.com/jorres/md2adf-translator/adf"
 	"github.com/jorres/md2adf-translator/adf2md"
+	"io"
+	"maps"
+	"regexp"
+	"slices"
 	"strings"
+	"time"
+	"unsafe"
 
 	tree_sitter_markdown "github.com/jorres/tree-sitter-jira-markdown/bindings/go"
 	sitter "github.com/tree-sitter/go-tree-sitter"
 )
 
 type Translator struct {
-	markdownParser *tree_sitter_markdown.AdfMarkdownParser
+	// blockParser parses markdown with the block grammar, and inlineParser
+	// the content of its inline nodes and table cells with the inline one.
+	// Both are freed by Close.
+	blockParser  *sitter.Parser
+	inlineParser *sitter.Parser
 
 	userMapping       map[string]string // email -> user ID
+	userResolver      func(email string) (accountID string, ok bool)
+	mentionPolicy     MentionPolicy
+	mentionDisplay    func(email string) string
+	mentionsDisabled  bool
+	mentionDomains    []string
 	reverseTranslator *adf2md.Translator
+	metrics           adf.Collector
+	imagePolicy       func(url, alt string) ImageDecision
+	boldTableHeaders  bool
+	headingOffset     int
+	emptyParagraphs   bool
+	autolinks         bool
+	languageAliases   map[string]string
+	validateLanguages bool
+	recoverPanics     bool
+	v2UnsafeTypes     map[adf.NodeType]bool
+	strict            bool
+	missingAttachment func(id string) (*adf.ADFNode, error)
+	smartLinks        []*regexp.Regexp
+	linkRewriter      func(href string) string
+	maxInputSize      int
+	maxNodes          int
+	timeout           time.Duration
+	// configErr is an invalid option, reported by every translation.
+	configErr error
+
+	// groups maps the media groups rebuilt by the current translation to the
+	// preserved groups they are rebuilt from.
+	groups map[*adf.ADFNode]*adf.ADFNode
+	// reused are the preserved nodes the current translation has put in the
+	// document, with their descendants.
+	reused map[*adf.ADFNode]bool
+	// unresolved are the emails of the mentions no user was found for.
+	unresolved []string
+	// err is the first error raised by a callback during the current translation.
+	err error
+	// ctx is the context of the current translation.
+	ctx context.Context
+
+	// ranges records the source span of every produced node while a
+	// TranslateWithSourceMap call is in progress; nil otherwise.
+	ranges map[*adf.ADFNode]sourceRange
+	// references are the targets of the link reference definitions
+	// of the document being translated, by normalized label.
+	references map[string]linkTarget
+	// inlineBase is the byte offset of the inline node currently being processed.
+	inlineBase uint
+	warnings   []Warning
+
+	// textBreaks are the offsets, relative to inlineBase, at which plain
+	// text being appended is split into separate text nodes, textEscapes
+	// those of the backslashes escaping a character in it. Both are sorted.
+	textBreaks, textEscapes []uint
 }
 
 type TranslatorOption func(*Translator)
 
-// WithUserEmailMapping sets a user email mapping to render emails to user IDs
+// WithUserEmailMapping sets a user email mapping to render emails to user IDs.
+// The emails may be written with or without the leading "@" of a mention.
 func WithUserEmailMapping(mapping map[string]string) TranslatorOption {
 	return func(tr *Translator) {
 		tr.userMapping = mapping
 	}
 }
 
+// WithUserResolver sets a function looking up the account ID of a mentioned
+// user's email, consulted for emails missing from the WithUserEmailMapping
+// mapping
+func WithUserResolver(resolver func(email string) (accountID string, ok bool)) TranslatorOption {
+	return func(tr *Translator) {
+		tr.userResolver = resolver
+	}
+}
+
+// WithMentionDisplayText sets the function producing the display text of a
+// mention from the mentioned email, such as MentionLocalPart (the default),
+// MentionFullEmail or a custom one. The account ID is resolved independently.
+func WithMentionDisplayText(format func(email string) string) TranslatorOption {
+	return func(tr *Translator) {
+		tr.mentionDisplay = format
+	}
+}
+
+// MentionLocalPart displays a mention as the local part of the email, "jane"
+// for jane@example.com.
+func MentionLocalPart(email string) string {
+	local, _, _ := strings.Cut(email, "@")
+	return local
+}
+
+// MentionFullEmail displays a mention as the whole email.
+func MentionFullEmail(email string) string {
+	return email
+}
+
+// MentionPolicy tells how a mention of a user no account ID is found for is
+// converted.
+type MentionPolicy int
+
+const (
+	// MentionFallback converts the mention with the email as its account ID.
+	MentionFallback MentionPolicy = iota
+	// MentionPlainText converts the mention to text.
+	MentionPlainText
+	// MentionError fails the translation with an *UnresolvedMentionsError.
+	MentionError
+)
+
+// UnresolvedMentionsError lists every email mentioned without a known
+// account ID under the MentionError policy.
+type UnresolvedMentionsError struct {
+	Emails []string // in order of first mention
+}
+
+func (e *UnresolvedMentionsError) Error() string {
+	return "unresolved mentions: " + strings.Join(e.Emails, ", ")
+}
+
+// WithUnknownMentionPolicy sets how mentions of unknown users are converted.
+// Without it MentionFallback applies.
+func WithUnknownMentionPolicy(policy MentionPolicy) TranslatorOption {
+	return func(tr *Translator) {
+		tr.mentionPolicy = policy
+	}
+}
+
 func WithAdf2MdTranslator(translator *adf2md.Translator) TranslatorOption {
 	return func(tr *Translator) {
 		tr.reverseTranslator = translator
 	}
 }
 
+// WithMetrics reports conversion metrics to the given collector
+func WithMetrics(collector adf.Collector) TranslatorOption {
+	return func(tr *Translator) {
+		tr.metrics = collector
+	}
+}
+
+// WithBoldTableHeaders makes all table header text bold, whether or not the
+// markdown marks it so
+func WithBoldTableHeaders(bold bool) TranslatorOption {
+	return func(tr *Translator) {
+		tr.boldTableHeaders = bold
+	}
+}
+
+// WithHeadingOffset shifts the level of every heading by offset, such as to
+// nest the document under a heading of the page it is added to. Levels are
+// clamped to 1, and a heading shifted past level 6 becomes a paragraph in
+// bold.
+func WithHeadingOffset(offset int) TranslatorOption {
+	return func(tr *Translator) {
+		tr.headingOffset = offset
+	}
+}
+
+// WithEmptyParagraphs sets whether extra blank lines between paragraphs are
+// kept as empty paragraphs, one for each blank line beyond the first, the way
+// adf2md renders them. Off by default, extra blank lines are dropped.
+func WithEmptyParagraphs(keep bool) TranslatorOption {
+	return func(tr *Translator) {
+		tr.emptyParagraphs = keep
+	}
+}
+
+// WithPanicRecovery sets whether a panic during translation is returned as an
+// *adf.InternalError instead of crashing the caller. It is on by default.
+func WithPanicRecovery(recover bool) TranslatorOption {
+	return func(tr *Translator) {
+		tr.recoverPanics = recover
+	}
+}
+
+// ImageDecision tells how a markdown image is converted.
+type ImageDecision int
+
+const (
+	// ImageMedia converts the image to a block-level mediaSingle node.
+	ImageMedia ImageDecision = iota
+	// ImageLink keeps the image inline as its alt text linked to the image URL,
+	// which suits badges inside sentences and table cells.
+	ImageLink
+	// ImageDrop leaves the image out.
+	ImageDrop
+)
+
+// WithInlineImagePolicy sets the function deciding how each image is converted.
+// Without it every image becomes media.
+func WithInlineImagePolicy(policy func(url, alt string) ImageDecision) TranslatorOption {
+	return func(tr *Translator) {
+		tr.imagePolicy = policy
+	}
+}
+
 func NewTranslator(opts ...TranslatorOption) *Translator {
 	tr := &Translator{
-		markdownParser: tree_sitter_markdown.NewAdfMarkdownParser(),
+		blockParser:     newParser(tree_sitter_markdown.Language()),
+		inlineParser:    newParser(tree_sitter_markdown.InlineLanguage()),
+		recoverPanics:   true,
+		autolinks:       true,
+		v2UnsafeTypes:   maps.Clone(defaultV2UnsafeTypes),
+		languageAliases: maps.Clone(defaultLanguageAliases),
 	}
 
 	for _, opt := range opts {
@@ -51,98 +245,180 @@ func NewTranslator(opts ...TranslatorOption) *Translator {
 	return tr
 }
 
-func (p *Translator) TranslateToADF(content []byte) (*adf.ADFDocument, error) {
-	tree, err := p.markdownParser.Parse(content)
+// newParser creates a parser for a grammar of the markdown bindings.
+func newParser(language unsafe.Pointer) *sitter.Parser {
+	parser := sitter.NewParser()
+	if err := parser.SetLanguage(sitter.NewLanguage(language)); err != nil {
+		panic(err) // the grammar is compiled in, so this is a build problem
+	}
+	return parser
+}
+
+// Close frees the parsers of the Translator, which live in C memory the
+// garbage collector doesn't see. The Translator must not be used afterwards.
+func (p *Translator) Close() {
+	p.blockParser.Close()
+	p.inlineParser.Close()
+}
+
+// inlineTree parses the inline content of an inline node or a table cell,
+// returning nil for other nodes. The caller closes the tree once done with it.
+func (p *Translator) inlineTree(node *sitter.Node, content []byte) *sitter.Tree {
+	switch node.Kind() {
+	case "inline", "pipe_table_cell":
+		return p.inlineParser.Parse(content[node.StartByte():node.EndByte()], nil)
+	}
+	return nil
+}
+
+// parse parses content with the block grammar; the inline content of its
+// nodes is parsed by inlineTree as it is converted. The caller closes the tree
+// once done with it.
+func (p *Translator) parse(content []byte) (*sitter.Tree, error) {
+	tree := p.blockParser.Parse(content, nil)
+	if tree == nil {
+		return nil, fmt.Errorf("failed to parse with block grammar")
+	}
+	return tree, nil
+}
+
+// TranslateToADF translates markdown to an ADF document. Unless recovery is
+// turned off with WithPanicRecovery, a panic is returned as an
+// *adf.InternalError.
+func (p *Translator) TranslateToADF(content []byte) (doc *adf.ADFDocument, err error) {
+	return p.TranslateToADFContext(context.Background(), content)
+}
+
+// TranslateReader is TranslateToADF reading the markdown from r. Nothing is
+// streamed: the parser needs all of the markdown at hand, so r is read to the
+// end first.
+func (p *Translator) TranslateReader(r io.Reader) (*adf.ADFDocument, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading markdown: %w", err)
+	}
+	return p.TranslateToADF(content)
+}
+
+func (p *Translator) translateToADF(ctx context.Context, content []byte) (*adf.ADFDocument, error) {
+	p.warnings = nil
+	p.err = nil
+	p.unresolved = nil
+	p.groups = make(map[*adf.ADFNode]*adf.ADFNode)
+	p.reused = make(map[*adf.ADFNode]bool)
+
+	if p.configErr != nil {
+		return nil, p.configErr
+	}
+	if err := p.checkInputSize(content); err != nil {
+		return nil, err
+	}
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	p.ctx = ctx
+	defer func() { p.ctx = nil }()
+
+	tree, err := p.parse(content)
 	if err != nil {
 		return nil, err
 	}
+	defer tree.Close()
+	if err := p.checkNodeCount(tree); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	p.references = collectReferences(tree.RootNode(), content)
 
 	doc := adf.NewADFDocument()
 	p.processNode(tree.RootNode(), content, doc)
+	doc.Content = p.foldExpands(p.attachCaptions(doc.Content))
+	p.locateWarnings(content)
+
+	if p.err != nil {
+		return nil, p.err
+	}
+	if p.mentionPolicy == MentionError && len(p.unresolved) > 0 {
+		return nil, &UnresolvedMentionsError{Emails: slices.Clone(p.unresolved)}
+	}
+
+	if p.strict && len(p.warnings) > 0 {
+		return nil, &WarningsError{Warnings: p.Warnings()}
+	}
+
 	return doc, nil
 }
 
-// CheckSafeForV2 parses the markdown content into an ADF tree and checks if it contains
-// any node types that are not safe for V2 processing. Returns an error if unsafe nodes are found.
-func (p *Translator) CheckSafeForV2(body string) error {
-	doc, err := p.TranslateToADF([]byte(body))
+// TranslateToMarkdown renders doc to markdown with the reverse translator.
+// The media and inline cards it meets are remembered, so that translating
+// the edited markdown back with TranslateToADF restores them.
+func (p *Translator) TranslateToMarkdown(doc *adf.ADFDocument) (string, error) {
+	return p.reverseTranslator.TranslateDocument(doc)
+}
+
+// observeConversion reports the metrics of a finished conversion, failed
+// with err or not. Only a successful one reports the nodes of doc.
+func (p *Translator) observeConversion(doc *adf.ADFDocument, inputSize int, took time.Duration, err error) {
+	direction := map[string]string{adf.LabelDirection: adf.DirectionMarkdownToADF}
+	p.metrics.Observe(adf.MetricDocumentsConverted, 1, direction)
+	p.metrics.Observe(adf.MetricConversionDuration, took.Seconds(), direction)
+	p.metrics.Observe(adf.MetricInputBytes, float64(inputSize), direction)
 	if err != nil {
-		return fmt.Errorf("failed to parse markdown: %w", err)
+		p.metrics.Observe(adf.MetricConversionErrors, 1, direction)
 	}
 
-	// Define the unsafe node types
-	unsafeTypes := map[adf.NodeType]bool{
-		adf.NodePanel:           true,
-		adf.NodeMedia:           true,
-		adf.NodeMediaGroup:      true,
-		adf.NodeMediaSingle:     true,
-		adf.InlineNodeCard:      true,
-		adf.InlineNodeEmoji:     true,
-		adf.InlineNodeMention:   true,
-		adf.InlineNodeHardBreak: true,
-		adf.MarkUnderline:       true,
+	counts := make(map[adf.NodeType]int)
+	if doc != nil {
+		adf.Walk(doc, func(n *adf.ADFNode, _ int) bool {
+			counts[n.Type]++
+			return true
+		})
 	}
 
-	// Traverse the ADF tree and collect unsafe node types
-	var foundUnsafeTypes []adf.NodeType
-	p.traverseADFTree(doc, unsafeTypes, &foundUnsafeTypes)
-
-	if len(foundUnsafeTypes) > 0 {
-		return fmt.Errorf("unsafe node types found: %v", foundUnsafeTypes)
+	for nodeType, n := range counts {
+		p.metrics.Observe(adf.MetricNodesProduced, float64(n), map[string]string{
+			adf.LabelDirection: adf.DirectionMarkdownToADF,
+			adf.LabelNodeType:  string(nodeType),
+		})
 	}
 
-	return nil
-}
-
-// traverseADFTree recursively traverses the ADF tree and collects unsafe node types
-func (p *Translator) traverseADFTree(doc *adf.ADFDocument, unsafeTypes map[adf.NodeType]bool, foundUnsafeTypes *[]adf.NodeType) {
-	for _, node := range doc.Content {
-		p.traverseADFNode(node, unsafeTypes, foundUnsafeTypes)
+	for _, w := range p.warnings {
+		p.metrics.Observe(adf.MetricWarnings, 1, map[string]string{
+			adf.LabelDirection: adf.DirectionMarkdownToADF,
+			adf.LabelCode:      w.Code,
+		})
 	}
 }
 
-// traverseADFNode recursively traverses an ADF node and its children
-func (p *Translator) traverseADFNode(node *adf.ADFNode, unsafeTypes map[adf.NodeType]bool, foundUnsafeTypes *[]adf.NodeType) {
-	// Check if this node type is unsafe
-	if unsafeTypes[node.Type] {
-		// Add to found list if not already present
-		alreadyFound := false
-		for _, existingType := range *foundUnsafeTypes {
-			if existingType == node.Type {
-				alreadyFound = true
-				break
-			}
-		}
-		if !alreadyFound {
-			*foundUnsafeTypes = append(*foundUnsafeTypes, node.Type)
-		}
-	}
+// TranslateWithSourceMap translates markdown to ADF like TranslateToADF and
+// additionally returns a SourceMap relating byte offsets of the markdown
+// source to the paths of the ADF nodes they produced.
+func (p *Translator) TranslateWithSourceMap(content []byte) (*adf.ADFDocument, *SourceMap, error) {
+	p.ranges = make(map[*adf.ADFNode]sourceRange)
+	defer func() { p.ranges = nil }()
 
-	// Check marks for unsafe types (like underline)
-	for _, mark := range node.Marks {
-		if unsafeTypes[mark.Type] {
-			// Add to found list if not already present
-			alreadyFound := false
-			for _, existingType := range *foundUnsafeTypes {
-				if existingType == mark.Type {
-					alreadyFound = true
-					break
-				}
-			}
-			if !alreadyFound {
-				*foundUnsafeTypes = append(*foundUnsafeTypes, mark.Type)
-			}
-		}
+	doc, err := p.TranslateToADF(content)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	// Recursively traverse child nodes
-	for _, child := range node.Content {
-		p.traverseADFNode(child, unsafeTypes, foundUnsafeTypes)
-	}
+	return doc, newSourceMap(doc, p.ranges), nil
 }
 
 // processNode processes a tree-sitter node and converts it to ADF
 func (p *Translator) processNode(node *sitter.Node, content []byte, doc *adf.ADFDocument) {
+	if p.aborted() {
+		return
+	}
 	nodeType := node.Kind()
 
 	switch nodeType {
@@ -153,25 +429,27 @@ func (p *Translator) processNode(node *sitter.Node, content []byte, doc *adf.ADF
 	case "atx_heading":
 		heading := p.convertHeading(node, content)
 		if heading != nil {
-			doc.Content = append(doc.Content, heading)
+			doc.Content = append(doc.Content, p.hoistBlocks(heading)...)
 		}
 
 	case "attachment":
 		for i := range int(node.ChildCount()) {
 			child := node.Child(uint(i))
 			if child.Kind() == "attachment_path" {
-				attachmentMap := p.reverseTranslator.GetMediaMapping()
 				attachmentId := string(content[child.StartByte():child.EndByte()])
-				if mediaNode, exists := attachmentMap[attachmentId]; exists {
-					doc.Content = append(doc.Content, mediaNode)
-				}
+				p.convertAttachment(doc, attachmentId, node, content)
 			}
 		}
 
 	case "paragraph":
+		if table := p.convertPipelessTable(node, content); table != nil {
+			doc.Content = append(doc.Content, table)
+			break
+		}
+
 		paragraph := p.convertParagraph(node, content)
 		if paragraph != nil {
-			doc.Content = append(doc.Content, paragraph)
+			doc.Content = append(doc.Content, p.hoistBlocks(paragraph)...)
 		}
 
 	case "fenced_code_block":
@@ -186,28 +464,83 @@ func (p *Translator) processNode(node *sitter.Node, content []byte, doc *adf.ADF
 			doc.Content = append(doc.Content, list)
 		}
 
+	case "block_quote":
+		blockquote := p.convertBlockquote(node, content)
+		if blockquote != nil {
+			doc.Content = append(doc.Content, blockquote)
+		}
+
 	case "panel":
 		panel := p.convertPanel(node, content)
 		if panel != nil {
 			doc.Content = append(doc.Content, panel)
 		}
 
+	case "link_reference_definition":
+		// Resolved by the reference links, the definition itself isn't shown
+
 	case "pipe_table":
 		table := p.convertPipeTable(node, content)
 		if table != nil {
 			doc.Content = append(doc.Content, table)
 		}
+
+	default:
+		// Markers of list items and block quotes carry no content of their own
+		if !node.IsNamed() || structuralKinds[nodeType] || strings.HasPrefix(nodeType, "list_marker_") {
+			break
+		}
+		p.warnAt(WarningDroppedContent, node.StartByte(), node.EndByte(), "unsupported %s dropped", strings.ReplaceAll(nodeType, "_", " "))
 	}
 }
 
+// structuralKinds are the block-level node kinds that only delimit content.
+var structuralKinds = map[string]bool{
+	"block_continuation": true,
+	"block_quote_marker": true,
+	"panel_end_mark":     true,
+}
+
 // processChildren processes all children of a node
 func (p *Translator) processChildren(node *sitter.Node, content []byte, doc *adf.ADFDocument) {
+	var previous *sitter.Node
 	childCount := int(node.ChildCount())
 	for i := range childCount {
 		child := node.Child(uint(i))
 		if child != nil {
+			if p.emptyParagraphs && previous != nil && previous.Kind() == "paragraph" && child.Kind() == "paragraph" {
+				p.appendEmptyParagraphs(previous.EndByte(), child.StartByte(), content, doc)
+			}
 			p.processNode(child, content, doc)
+			previous = child
+		}
+	}
+}
+
+// appendEmptyParagraphs appends an empty paragraph for each blank line beyond
+// the first in the gap between two paragraphs.
+func (p *Translator) appendEmptyParagraphs(start, end uint, content []byte, doc *adf.ADFDocument) {
+	gap := string(content[start:end])
+	if strings.TrimSpace(gap) != "" {
+		return
+	}
+
+	lines := strings.SplitAfter(gap, "\n")
+	if start > 0 && content[start-1] != '\n' {
+		// the rest of the line the paragraph ends on isn't blank
+		start += uint(len(lines[0]))
+		lines = lines[1:]
+	}
+	for i, line := range lines {
+		if !strings.HasSuffix(line, "\n") {
+			break
+		}
+		if i > 0 {
+			paragraph := adf.NewParagraphNode()
+			p.track(paragraph, start, start+uint(len(line)))
+			doc.Content = append(doc.Content, paragraph)
 		}
+		start += uint(len(line))
 	}
 }
 
@@ -242,6 +575,17 @@ func (p *Translator) convertHeading(node *sitter.Node, content []byte) *adf.ADFN
 	if inlineNode != nil {
 		p.processInlineContent(inlineNode, content, heading)
 	}
+	if p.headingOffset != 0 {
+		shifted := adf.ShiftHeading(heading, p.headingOffset)
+		// Text made bold is copied, and keeps the source range of the original
+		for i, child := range shifted.Content {
+			if r, ok := p.ranges[heading.Content[i]]; ok {
+				p.ranges[child] = r
+			}
+		}
+		heading = shifted
+	}
+	p.track(heading, node.StartByte(), node.EndByte())
 
 	return heading
 }
@@ -249,6 +593,7 @@ func (p *Translator) convertHeading(node *sitter.Node, content []byte) *adf.ADFN
 // convertParagraph converts a paragraph node to ADF
 func (p *Translator) convertParagraph(node *sitter.Node, content []byte) *adf.ADFNode {
 	paragraph := adf.NewParagraphNode()
+	p.track(paragraph, node.StartByte(), node.EndByte())
 
 	// Find inline content
 	childCount := int(node.ChildCount())
@@ -258,40 +603,69 @@ func (p *Translator) convertParagraph(node *sitter.Node, content []byte) *adf.AD
 			p.processInlineContent(child, content, paragraph)
 		}
 	}
+	trimLineIndents(paragraph)
 
 	return paragraph
 }
 
-// convertCodeBlock converts a fenced code block to ADF
+// convertCodeBlock converts a fenced code block, of backticks or tildes, to ADF
 func (p *Translator) convertCodeBlock(node *sitter.Node, content []byte) *adf.ADFNode {
-	var language string
+	var language, fence string
+	var fenceIndent uint
 	var codeContent string
+	closed, raw := false, false
 
-	// Process children to find language and code content
+	// Process children to find the fences, language and code content
 	childCount := int(node.ChildCount())
 	for i := range childCount {
 		child := node.Child(uint(i))
 		switch child.Kind() {
-		case "info_string":
-			// Extract language from info string
-			languageText := string(content[child.StartByte():child.EndByte()])
-			language = strings.TrimSpace(languageText)
-		case "code_fence_content":
-			// Extract code content
-			rawContent := string(content[child.StartByte():child.EndByte()])
-
-			// Remove any trailing closing fence (``` at the end)
-			if strings.HasSuffix(rawContent, "\n```") {
-				codeContent = strings.TrimSuffix(rawContent, "\n```")
-			} else if strings.HasSuffix(rawContent, "```") {
-				codeContent = strings.TrimSuffix(rawContent, "```")
+		case "fenced_code_block_delimiter":
+			if fence == "" {
+				delimiter := string(content[child.StartByte():child.EndByte()])
+				fence = strings.TrimSpace(delimiter)
+				fenceIndent = uint(len(delimiter) - len(strings.TrimLeft(delimiter, " ")))
 			} else {
-				codeContent = rawContent
+				closed = true
+			}
+		case "info_string":
+			// The language is the first word of the info string, whichever
+			// the fence character
+			languageNode := child
+			for j := range int(child.ChildCount()) {
+				if c := child.Child(uint(j)); c.Kind() == "language" {
+					languageNode = c
+					break
+				}
+			}
+			languageText := string(content[languageNode.StartByte():languageNode.EndByte()])
+			if strings.TrimSpace(languageText) == adf2md.RawADFLanguage {
+				raw = true
+				break
 			}
+			language = p.codeLanguage(strings.TrimSpace(languageText), languageNode.StartByte(), languageNode.EndByte())
+		case "code_fence_content":
+			codeContent = p.codeFenceContentText(child, content, fenceIndent)
 		}
 	}
 
+	// A closing fence on the last line of the input is left in the content
+	// by the parser
+	if !closed {
+		lines := strings.Split(codeContent, "\n")
+		if last := strings.TrimSpace(lines[len(lines)-1]); fence != "" && isClosingFence(last, fence) {
+			codeContent = strings.Join(lines[:len(lines)-1], "\n")
+		}
+	}
+	// The line break before the closing fence is not part of the code
+	codeContent = strings.TrimSuffix(codeContent, "\n")
+
+	if raw {
+		return p.convertRawBlock(codeContent, node, content)
+	}
+
 	codeBlock := adf.NewCodeBlockNode(language)
+	p.track(codeBlock, node.StartByte(), node.EndByte())
 	if codeContent != "" {
 		codeBlock.Content = append(codeBlock.Content, adf.NewTextNode(codeContent))
 	}
@@ -299,19 +673,73 @@ func (p *Translator) convertCodeBlock(node *sitter.Node, content []byte) *adf.AD
 	return codeBlock
 }
 
+// isClosingFence reports whether line closes a code block opened by fence:
+// a run of the fence character at least as long as the fence.
+func isClosingFence(line, fence string) bool {
+	return len(line) >= len(fence) && strings.Trim(line, fence[:1]) == ""
+}
+
+// codeFenceContentText returns the text of a code_fence_content node with the
+// indentation of enclosing containers (list items, quotes) removed from every
+// line. The parser marks that indentation as block_continuation children.
+// Like the fence's own indentation of fenceIndent spaces within them, up to
+// as many leading spaces are stripped from each line; anything beyond is code.
+func (p *Translator) codeFenceContentText(node *sitter.Node, content []byte, fenceIndent uint) string {
+	var text strings.Builder
+	pos := node.StartByte()
+
+	childCount := int(node.ChildCount())
+	for i := range childCount {
+		child := node.Child(uint(i))
+		if child.Kind() != "block_continuation" {
+			continue
+		}
+		if child.StartByte() > pos {
+			text.Write(content[pos:child.StartByte()])
+		}
+		pos = max(pos, child.EndByte())
+	}
+	if pos < node.EndByte() {
+		text.Write(content[pos:node.EndByte()])
+	}
+
+	if fenceIndent == 0 {
+		return text.String()
+	}
+
+	lines := strings.Split(text.String(), "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " ")
+		if strip := len(line) - len(trimmed); strip > int(fenceIndent) {
+			trimmed = line[fenceIndent:]
+		}
+		lines[i] = trimmed
+	}
+	return strings.Join(lines, "\n")
+}
+
 func (p *Translator) processInlineContent(inlineNode *sitter.Node, content []byte, parent *adf.ADFNode) {
-	inlineTree := p.markdownParser.GetInlineTree(inlineNode, content)
+	inlineTree := p.inlineTree(inlineNode, content)
 	if inlineTree == nil {
 		// No inline tree, treat as plain text
 		text := string(content[inlineNode.StartByte():inlineNode.EndByte()])
 		if strings.TrimSpace(text) != "" {
-			parent.Content = append(parent.Content, adf.NewTextNode(text))
+			textNode := adf.NewTextNode(text)
+			p.track(textNode, inlineNode.StartByte(), inlineNode.EndByte())
+			parent.Content = append(parent.Content, textNode)
 		}
 		return
 	}
+	defer inlineTree.Close()
+
+	p.processInlineTree(inlineTree, inlineNode, content, parent)
+}
 
+// processInlineTree processes the inline tree parsed from inlineNode
+func (p *Translator) processInlineTree(inlineTree *sitter.Tree, inlineNode *sitter.Node, content []byte, parent *adf.ADFNode) {
 	// Extract the inline content for correct byte offset calculations
 	inlineContent := content[inlineNode.StartByte():inlineNode.EndByte()]
+	p.inlineBase = inlineNode.StartByte()
 
 	// Process the inline tree with gap filling
 	p.processInlineTreeWithGaps(inlineTree.RootNode(), inlineContent, parent)
@@ -319,168 +747,575 @@ func (p *Translator) processInlineContent(inlineNode *sitter.Node, content []byt
 
 // processInlineTreeWithGaps processes inline tree nodes and fills text gaps
 func (p *Translator) processInlineTreeWithGaps(inlineRoot *sitter.Node, inlineContent []byte, parent *adf.ADFNode) {
+	p.processInlineRange(inlineRoot, 0, uint(len(inlineContent)), inlineContent, parent)
+}
+
+// processInlineRange processes the children of an inline node that lie within
+// [start, end) of the inline content, filling the text gaps between them
+func (p *Translator) processInlineRange(node *sitter.Node, start, end uint, inlineContent []byte, parent *adf.ADFNode) {
 	// Track position for gap filling
-	currentPos := uint(0)
+	currentPos := start
+	// breaks are where the grammar split the plain text since currentPos,
+	// escapes where it has backslash escapes
+	var breaks, escapes []uint
+
+	// Whitespace between siblings is kept, as one space if it spans lines;
+	// at either end of the range it is a layout artifact and dropped. The
+	// text is converted to a string only once it is known to be kept
+	appendGap := func(from, to uint) {
+		text := inlineContent[from:to]
+		if isBlank(text) {
+			if from == start || to == end {
+				return
+			}
+			if bytes.IndexByte(text, '\n') >= 0 {
+				p.appendText(parent, " ", from, to)
+				return
+			}
+		}
+		p.appendText(parent, string(text), from, to)
+	}
+
+	// flushText appends the plain text from currentPos to to as a whole, so
+	// that status lozenges, emoji shortcodes and URLs are recognized across
+	// the punctuation the grammar splits text at; the text nodes left over
+	// still break where the grammar split it
+	flushText := func(to uint) {
+		from := currentPos
+		if len(breaks) > 0 && breaks[0] > from && isBlank(inlineContent[from:breaks[0]]) {
+			if from == start {
+				from = breaks[0]
+			}
+		}
+		if n := len(breaks); n > 0 && breaks[n-1] < to && isBlank(inlineContent[breaks[n-1]:to]) {
+			if to == end {
+				to = breaks[n-1]
+			}
+		}
+		if from >= to {
+			return
+		}
+
+		p.textBreaks, p.textEscapes = breaks, escapes
+		appendGap(from, to)
+		p.textBreaks, p.textEscapes = nil, nil
+	}
+
+	// Most children end up as one node, with at most a text node between them
+	childCount := int(node.ChildCount())
+	parent.Content = slices.Grow(parent.Content, childCount)
 
-	// Process all direct children of the inline root
-	childCount := int(inlineRoot.ChildCount())
+	// Process all direct children of the node within the range
 	for i := range childCount {
-		child := inlineRoot.Child(uint(i))
+		child := node.Child(uint(i))
+		if child.EndByte() <= start || child.StartByte() >= end {
+			continue
+		}
+		kind := child.Kind()
+		var linkText *sitter.Node
+		var target linkTarget
+		isReference := referenceLinkKinds[kind]
+		if isReference {
+			linkText, target, isReference = p.referenceLink(child, inlineContent)
+		}
+		isMention := kind == "people_mention"
+		if isMention {
+			email, _ := splitMention(string(inlineContent[child.StartByte():child.EndByte()]))
+			isMention = p.mentionAllowed(email)
+		}
+		if !inlineElements[kind] || referenceLinkKinds[kind] && !isReference || kind == "people_mention" && !isMention {
+			// Text, punctuation, escapes and mentions kept as written are
+			// plain text
+			breaks = append(breaks, max(child.StartByte(), start), min(child.EndByte(), end))
+			if kind == "backslash_escape" {
+				escapes = append(escapes, child.StartByte())
+			}
+			continue
+		}
 
 		// Add gap before this node
 		if child.StartByte() > currentPos {
-			gapText := string(inlineContent[currentPos:child.StartByte()])
-			parent.Content = append(parent.Content, adf.NewTextNode(gapText))
+			flushText(child.StartByte())
 		}
 
 		// Process this node
-		switch child.Kind() {
+		switch kind {
 		case "people_mention":
-			text := string(inlineContent[child.StartByte():child.EndByte()])
-			email := strings.TrimSpace(text)
-
-			// Look up user ID from mapping
-			userID := email // fallback to email if not found
-			if id, exists := p.userMapping[email]; exists {
-				userID = id
-			}
+			p.processMention(child, inlineContent, parent)
 
-			// Strip company domain from display text and the @ prefix
-			displayText := email
-			if strings.HasPrefix(displayText, "@") {
-				displayText = displayText[1:] // Remove @ prefix
-			}
-			if atIndex := strings.Index(displayText, "@"); atIndex != -1 {
-				displayText = displayText[:atIndex] // Remove domain part
-			}
-
-			mentionNode := adf.NewMentionNode(userID, displayText)
-			parent.Content = append(parent.Content, mentionNode)
+		case "hard_line_break":
+			p.appendInline(parent, adf.NewHardBreakNode(), child.StartByte(), child.EndByte())
 
 		case "code_span":
 			p.processCodeSpan(child, inlineContent, parent)
 
+		case "uri_autolink", "email_autolink":
+			url := strings.Trim(string(inlineContent[child.StartByte():child.EndByte()]), "<>")
+			href := url
+			if kind == "email_autolink" {
+				href = "mailto:" + url
+			}
+			href = p.rewriteLink(href)
+			if card := p.inlineCard(href); card != nil {
+				p.appendInline(parent, card, child.StartByte(), child.EndByte())
+			} else if p.autolinks {
+				p.appendInline(parent, adf.NewTextNodeWithMarks(url, []*adf.ADFMark{adf.NewLinkMark(href)}), child.StartByte(), child.EndByte())
+			} else {
+				appendGap(child.StartByte(), child.EndByte())
+			}
+
 		case "inline_link":
 			p.processLink(child, inlineContent, parent)
 
-		case "strong_emphasis":
-			p.processTextWithMarks(child, inlineContent, parent)
-
-		case "underline":
-			p.processTextWithMarks(child, inlineContent, parent)
+		case "full_reference_link", "collapsed_reference_link", "shortcut_link":
+			p.appendLink(child, linkText, target, inlineContent, parent)
 
-		case "strikethrough":
-			p.processTextWithMarks(child, inlineContent, parent)
+		case "image":
+			p.processImage(child, inlineContent, parent)
 
-		case "emphasis":
-			p.processTextWithMarks(child, inlineContent, parent)
-
-		case "text":
-			text := string(inlineContent[child.StartByte():child.EndByte()])
-			if strings.TrimSpace(text) != "" {
-				parent.Content = append(parent.Content, adf.NewTextNode(text))
-			}
-
-		default:
-			// For other elements (punctuation, etc.), include as plain text
-			text := string(inlineContent[child.StartByte():child.EndByte()])
-			if strings.TrimSpace(text) != "" {
-				parent.Content = append(parent.Content, adf.NewTextNode(text))
-			}
+		case "strong_emphasis", "underline", "strikethrough", "emphasis":
+			p.processTextWithMarks(child, kind, inlineContent, parent)
 		}
 
 		currentPos = child.EndByte()
+		breaks, escapes = breaks[:0], escapes[:0]
 	}
 
 	// Add any remaining text after the last node
-	if currentPos < uint(len(inlineContent)) {
-		remainingText := string(inlineContent[currentPos:])
-		if strings.TrimSpace(remainingText) != "" {
-			parent.Content = append(parent.Content, adf.NewTextNode(remainingText))
-		}
+	if currentPos < end {
+		flushText(end)
 	}
 }
 
-// processCodeSpan processes a code span node (inline code)
-func (p *Translator) processCodeSpan(codeNode *sitter.Node, inlineContent []byte, parent *adf.ADFNode) {
-	// Find the actual code content within the code span
-	// Code spans have structure: code_span -> code_span_delimiter + text + code_span_delimiter
-	var codeText string
-	childCount := int(codeNode.ChildCount())
-	for i := range childCount {
-		child := codeNode.Child(uint(i))
-		if child.Kind() == "text" {
-			codeText = string(inlineContent[child.StartByte():child.EndByte()])
-			break
-		}
-	}
-	// If we didn't find a text child, extract the whole content and strip backticks
-	if codeText == "" {
-		fullText := string(inlineContent[codeNode.StartByte():codeNode.EndByte()])
-		// Remove surrounding backticks
-		codeText = strings.Trim(fullText, "`")
-	}
-	if codeText != "" {
-		codeMark := adf.NewCodeMark()
-		textNode := adf.NewTextNodeWithMarks(codeText, []*adf.ADFMark{codeMark})
-		parent.Content = append(parent.Content, textNode)
-	}
+// isBlank reports whether text is empty or all whitespace.
+func isBlank(text []byte) bool {
+	return len(bytes.TrimSpace(text)) == 0
 }
 
-// processLink processes an inline_link node to create ADF link marks
-func (p *Translator) processLink(linkNode *sitter.Node, inlineContent []byte, parent *adf.ADFNode) {
-	var linkText string
-	var linkURL string
+// inlineElements are the inline node kinds processInlineRange converts;
+// anything else is taken as plain text.
+var inlineElements = map[string]bool{
+	"people_mention":           true,
+	"hard_line_break":          true,
+	"code_span":                true,
+	"uri_autolink":             true,
+	"email_autolink":           true,
+	"inline_link":              true,
+	"full_reference_link":      true,
+	"collapsed_reference_link": true,
+	"shortcut_link":            true,
+	"image":                    true,
+	"strong_emphasis":          true,
+	"underline":                true,
+	"strikethrough":            true,
+	"emphasis":                 true,
+}
 
-	// Process children to find link text and URL
-	childCount := int(linkNode.ChildCount())
-	for i := range childCount {
-		child := linkNode.Child(uint(i))
-		switch child.Kind() {
-		case "link_text":
-			// Extract the text content from inside the brackets
-			linkText = string(inlineContent[child.StartByte():child.EndByte()])
-			// Remove the surrounding brackets
-			if strings.HasPrefix(linkText, "[") && strings.HasSuffix(linkText, "]") {
-				linkText = linkText[1 : len(linkText)-1]
+// appendText appends plain inline text to parent. Status lozenges
+// ({status:color=green}TEXT{/status}) become status nodes, and double-tilde
+// spans the grammar left unparsed (~~text~~) become strike-marked text nodes.
+// A status without text is kept as text, and one of a color Jira doesn't
+// know is made neutral with a warning.
+func (p *Translator) appendText(parent *adf.ADFNode, text string, start, end uint) {
+	prev := 0
+	for _, m := range inlineStatus.FindAllStringSubmatchIndex(text, -1) {
+		if strings.TrimSpace(text[m[4]:m[5]]) == "" {
+			continue
+		}
+		if m[0] > prev {
+			p.appendStruckText(parent, text[prev:m[0]], start+uint(prev), start+uint(m[0]))
+		}
+
+		status := adf.NewStatusNode(text[m[4]:m[5]], adf.StatusNeutral)
+		if m[2] >= 0 {
+			// Pipes come escaped inside table cells
+			params := strings.ReplaceAll(text[m[2]:m[3]], `\|`, "|")
+			for _, param := range strings.Split(params, "|") {
+				key, value, _ := strings.Cut(param, "=")
+				if key == "color" || key == "localId" {
+					status.Attrs[key] = strings.TrimSpace(value)
+				}
 			}
-		case "link_destination":
-			// Extract the URL from inside the parentheses
-			linkURL = string(inlineContent[child.StartByte():child.EndByte()])
-			// Remove the surrounding parentheses
-			if strings.HasPrefix(linkURL, "(") && strings.HasSuffix(linkURL, ")") {
-				linkURL = linkURL[1 : len(linkURL)-1]
+		}
+		if color, _ := status.Attrs["color"].(string); !adf.IsStatusColor(color) {
+			if lower := strings.ToLower(color); adf.IsStatusColor(lower) {
+				status.Attrs["color"] = lower
+			} else {
+				p.warnAt(WarningUnknownStatusColor, p.inlineBase+start+uint(m[0]), p.inlineBase+start+uint(m[1]),
+					"unknown status color %q, using %q", color, adf.StatusNeutral)
+				status.Attrs["color"] = adf.StatusNeutral
 			}
 		}
-	}
+		p.appendInline(parent, status, start+uint(m[0]), start+uint(m[1]))
 
-	if inlineCardNode, exists := p.reverseTranslator.GetInlineCardMapping()[linkURL]; exists {
-		parent.Content = append(parent.Content, inlineCardNode)
-		return
+		prev = m[1]
 	}
 
-	if linkText != "" && linkURL != "" {
-		linkMark := adf.NewLinkMark(linkURL)
-		textNode := adf.NewTextNodeWithMarks(linkText, []*adf.ADFMark{linkMark})
-		parent.Content = append(parent.Content, textNode)
+	if prev < len(text) {
+		p.appendStruckText(parent, text[prev:], start+uint(prev), end)
 	}
 }
 
-// convertList converts a list node to ADF
-func (p *Translator) convertList(node *sitter.Node, content []byte) *adf.ADFNode {
-	// Determine if this is an ordered or unordered list by checking the first list item's marker
-	var isOrdered bool
-	var startingOrder int = 1
+// inlineStatus matches a status lozenge, capturing its parameters and text.
+var inlineStatus = regexp.MustCompile(`\{status(?::([^}]*))?\}(.*?)\{/status\}`)
 
-	childCount := int(node.ChildCount())
-	for i := range childCount {
-		child := node.Child(uint(i))
-		if child.Kind() == "list_item" {
-			// Check the list marker in the first list item
-			markerType := p.getListItemMarkerType(child, content)
-			if markerType == "ordered" {
-				isOrdered = true
-				startingOrder = p.extractOrderFromListItem(child, content)
+// appendStruckText appends plain text to parent, turning ~~text~~ spans into
+// strike-marked text nodes.
+func (p *Translator) appendStruckText(parent *adf.ADFNode, text string, start, end uint) {
+	for {
+		open := strings.Index(text, "~~")
+		if open == -1 {
+			break
+		}
+		length := strings.Index(text[open+2:], "~~")
+		if length <= 0 {
+			break
+		}
+		inner := text[open+2 : open+2+length]
+		if strings.TrimSpace(inner) != inner {
+			// Delimiters must hug the struck text, as in GFM
+			break
+		}
+
+		if open > 0 {
+			p.appendEmojiText(parent, text[:open], start, start+uint(open))
+		}
+		consumed := uint(open + 2 + length + 2)
+		struck := adf.NewTextNodeWithMarks(inner, []*adf.ADFMark{adf.NewStrikethroughMark()})
+		p.appendInline(parent, struck, start+uint(open), start+consumed)
+
+		text = text[consumed:]
+		start += consumed
+	}
+
+	if text != "" {
+		p.appendEmojiText(parent, text, start, end)
+	}
+}
+
+// emojiShortcode matches an emoji shortcode such as ":thumbsup:".
+var emojiShortcode = regexp.MustCompile(`:[a-z0-9_+-]+:`)
+
+// appendEmojiText appends plain text to parent, turning known emoji
+// shortcodes into emoji nodes. Unknown ones stay text.
+func (p *Translator) appendEmojiText(parent *adf.ADFNode, text string, start, end uint) {
+	prev := 0
+	for _, m := range emojiShortcode.FindAllStringIndex(text, -1) {
+		emoji, ok := adf.EmojiText(text[m[0]:m[1]])
+		if !ok {
+			continue
+		}
+		if m[0] > prev {
+			p.appendLinkedText(parent, text[prev:m[0]], start+uint(prev), start+uint(m[0]))
+		}
+		p.appendInline(parent, adf.NewEmojiNode(text[m[0]:m[1]], emoji), start+uint(m[0]), start+uint(m[1]))
+		prev = m[1]
+	}
+
+	if prev < len(text) {
+		p.appendLinkedText(parent, text[prev:], start+uint(prev), end)
+	}
+}
+
+// resolveUser returns the account ID of the user with the given email from
+// the user mapping or, failing that, the user resolver.
+func (p *Translator) resolveUser(email string) (string, bool) {
+	if id, exists := p.userMapping[email]; exists {
+		return id, true
+	}
+	if id, exists := p.userMapping["@"+email]; exists {
+		return id, true
+	}
+	if p.userResolver != nil {
+		return p.userResolver(email)
+	}
+	return "", false
+}
+
+// processCodeSpan processes a code span node (inline code)
+func (p *Translator) processCodeSpan(codeNode *sitter.Node, inlineContent []byte, parent *adf.ADFNode) {
+	// Code spans have structure: code_span_delimiter, content, code_span_delimiter;
+	// the content is what lies between the delimiters, backticks included
+	var opening, closing *sitter.Node
+	childCount := int(codeNode.ChildCount())
+	for i := range childCount {
+		child := codeNode.Child(uint(i))
+		if child.Kind() == "code_span_delimiter" {
+			if opening == nil {
+				opening = child
+			} else {
+				closing = child
+			}
+		}
+	}
+
+	var codeText string
+	if opening != nil && closing != nil {
+		codeText = string(inlineContent[opening.EndByte():closing.StartByte()])
+		// One space on both ends lets the code start or end with a backtick
+		if strings.HasPrefix(codeText, " ") && strings.HasSuffix(codeText, " ") && strings.TrimSpace(codeText) != "" {
+			codeText = codeText[1 : len(codeText)-1]
+		}
+	} else {
+		// Without both delimiters, extract the whole content and strip backticks
+		fullText := string(inlineContent[codeNode.StartByte():codeNode.EndByte()])
+		codeText = strings.Trim(fullText, "`")
+	}
+	if codeText != "" {
+		codeMark := adf.NewCodeMark()
+		textNode := adf.NewTextNodeWithMarks(codeText, []*adf.ADFMark{codeMark})
+		p.appendInline(parent, textNode, codeNode.StartByte(), codeNode.EndByte())
+	}
+}
+
+// linkTarget is where a link points: its destination URL and optional title.
+type linkTarget struct {
+	url, title string
+}
+
+// parseLinkTarget reads the link_destination and link_title children of an
+// inline link or link reference definition. A destination may be wrapped in
+// angle brackets, a title in double or single quotes or parentheses.
+func parseLinkTarget(node *sitter.Node, content []byte) linkTarget {
+	var target linkTarget
+	for i := range int(node.ChildCount()) {
+		child := node.Child(uint(i))
+		text := string(content[child.StartByte():child.EndByte()])
+		switch child.Kind() {
+		case "link_destination":
+			if strings.HasPrefix(text, "<") && strings.HasSuffix(text, ">") {
+				text = text[1 : len(text)-1]
+			}
+			target.url = unescapePunctuation(text)
+		case "link_title":
+			if len(text) >= 2 {
+				text = text[1 : len(text)-1]
+			}
+			target.title = unescapePunctuation(text)
+		}
+	}
+	return target
+}
+
+// unescapePunctuation removes the backslashes escaping ASCII punctuation.
+func unescapePunctuation(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", s[i+1]) >= 0 {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// processLink processes an inline_link node to create ADF link marks
+func (p *Translator) processLink(linkNode *sitter.Node, inlineContent []byte, parent *adf.ADFNode) {
+	var linkTextNode *sitter.Node
+	for i := range int(linkNode.ChildCount()) {
+		if child := linkNode.Child(uint(i)); child.Kind() == "link_text" {
+			linkTextNode = child
+		}
+	}
+
+	p.appendLink(linkNode, linkTextNode, parseLinkTarget(linkNode, inlineContent), inlineContent, parent)
+}
+
+// appendLink appends the text of a link node, linked to target, or the
+// inline card the target URL stands for.
+func (p *Translator) appendLink(linkNode, linkTextNode *sitter.Node, target linkTarget, inlineContent []byte, parent *adf.ADFNode) {
+	linkURL := p.rewriteLink(target.url)
+	if inlineCardNode, exists := p.reverseTranslator.InlineCard(linkURL); exists {
+		if p.reverseTranslator.InlineCardChanged(linkURL) {
+			p.warn(WarningPreservedNodeChanged, "inline card %s changed since the markdown was generated", linkURL)
+		}
+		p.appendInline(parent, p.reuse(inlineCardNode), linkNode.StartByte(), linkNode.EndByte())
+		return
+	}
+
+	if linkTextNode == nil || linkURL == "" {
+		return
+	}
+
+	// Process the text inside the brackets like any other inline content, so
+	// formatting is kept, and link every resulting text node
+	start, end := linkTextNode.StartByte(), linkTextNode.EndByte()
+	if inlineContent[start] == '[' && inlineContent[end-1] == ']' {
+		start, end = start+1, end-1
+	}
+
+	// A link showing its own URL is a smart link, like a bare URL
+	if string(inlineContent[start:end]) == target.url && p.isSmartLink(linkURL) {
+		p.appendInline(parent, adf.NewInlineCardNode(linkURL), linkNode.StartByte(), linkNode.EndByte())
+		return
+	}
+
+	linked := &adf.ADFNode{}
+	p.processInlineRange(linkTextNode, start, end, inlineContent, linked)
+	for _, n := range linked.Content {
+		if n.Type == adf.ChildNodeText {
+			// The link replaces those of URLs in its text
+			n.Marks = slices.DeleteFunc(n.Marks, func(m *adf.ADFMark) bool { return m.Type == adf.MarkLink })
+			link := adf.NewLinkMark(linkURL)
+			if target.title != "" {
+				link.Attrs["title"] = target.title
+			}
+			n.Marks = append(n.Marks, link)
+		}
+	}
+	parent.Content = append(parent.Content, linked.Content...)
+}
+
+// processImage processes an image node into a mediaSingle with external media.
+// Media has no title, so that of an image is only kept when the image policy
+// makes a link of it.
+func (p *Translator) processImage(imageNode *sitter.Node, inlineContent []byte, parent *adf.ADFNode) {
+	var altText string
+	for i := range int(imageNode.ChildCount()) {
+		if child := imageNode.Child(uint(i)); child.Kind() == "image_description" {
+			altText = string(inlineContent[child.StartByte():child.EndByte()])
+			if strings.HasPrefix(altText, "[") && strings.HasSuffix(altText, "]") {
+				altText = altText[1 : len(altText)-1]
+			}
+		}
+	}
+	target := parseLinkTarget(imageNode, inlineContent)
+	start, end := p.inlineBase+imageNode.StartByte(), p.inlineBase+imageNode.EndByte()
+
+	if target.url == "" {
+		text := string(inlineContent[imageNode.StartByte():imageNode.EndByte()])
+		p.appendInline(parent, adf.NewTextNode(text), imageNode.StartByte(), imageNode.EndByte())
+		return
+	}
+
+	node := p.convertImage(target.url, altText)
+	if node == nil {
+		p.warnAt(WarningDroppedContent, start, end, "image %s dropped by the image policy", target.url)
+		return
+	}
+	if target.title != "" {
+		if i := slices.IndexFunc(node.Marks, func(m *adf.ADFMark) bool { return m.Type == adf.MarkLink }); i >= 0 {
+			node.Marks[i].Attrs["title"] = target.title
+		} else {
+			p.warnAt(WarningDroppedContent, start, end, "title %q of image %s dropped", target.title, target.url)
+		}
+	}
+	p.appendInline(parent, node, imageNode.StartByte(), imageNode.EndByte())
+}
+
+// convertImage converts an image according to the image policy: to a
+// mediaSingle node, to linked alt text, or to nothing (nil).
+func (p *Translator) convertImage(imageURL, altText string) *adf.ADFNode {
+	decision := ImageMedia
+	if p.imagePolicy != nil {
+		decision = p.imagePolicy(imageURL, altText)
+	}
+
+	switch decision {
+	case ImageDrop:
+		return nil
+	case ImageLink:
+		text := altText
+		if text == "" {
+			text = imageURL
+		}
+		return adf.NewTextNodeWithMarks(text, []*adf.ADFMark{adf.NewLinkMark(p.rewriteLink(imageURL))})
+	}
+
+	// Images that came from the document we're editing keep their original node
+	if mediaNode, exists := p.reverseTranslator.GetMediaMapping()[imageURL]; exists {
+		if p.reverseTranslator.MediaChanged(imageURL) {
+			p.warn(WarningPreservedNodeChanged, "image %s changed since the markdown was generated", imageURL)
+		}
+		return p.reuse(mediaNode)
+	}
+
+	mediaSingle := adf.NewMediaSingleNode("center")
+	mediaSingle.Content = append(mediaSingle.Content, adf.NewExternalMediaNode(imageURL, altText))
+	return mediaSingle
+}
+
+// hoistBlocks moves block-level nodes produced by inline processing (images)
+// out of a paragraph or heading, since ADF doesn't allow media inline. A paragraph
+// is split around each hoisted node; a heading is kept whole and followed by them.
+func (p *Translator) hoistBlocks(block *adf.ADFNode) []*adf.ADFNode {
+	if !slices.ContainsFunc(block.Content, isHoistedNode) {
+		return []*adf.ADFNode{block}
+	}
+
+	inline := block.Content
+	block.Content = []*adf.ADFNode{}
+
+	if block.Type != adf.NodeParagraph {
+		var hoisted []*adf.ADFNode
+		for _, n := range inline {
+			if isHoistedNode(n) {
+				hoisted = append(hoisted, n)
+			} else {
+				block.Content = append(block.Content, n)
+			}
+		}
+		return append([]*adf.ADFNode{block}, hoisted...)
+	}
+
+	var blocks []*adf.ADFNode
+	current := block
+	flush := func() {
+		if hasVisibleContent(current) {
+			blocks = append(blocks, current)
+		}
+		current = adf.NewParagraphNode()
+		if r, ok := p.ranges[block]; ok {
+			p.ranges[current] = r
+		}
+	}
+
+	for _, n := range inline {
+		if isHoistedNode(n) {
+			flush()
+			blocks = append(blocks, n)
+		} else {
+			current.Content = append(current.Content, n)
+		}
+	}
+	flush()
+
+	return blocks
+}
+
+// isHoistedNode reports whether an inline-produced node must live at block level.
+func isHoistedNode(n *adf.ADFNode) bool {
+	return n.Type == adf.NodeMediaSingle || n.Type == adf.NodeMediaGroup
+}
+
+// hasVisibleContent reports whether a paragraph has anything besides whitespace.
+func hasVisibleContent(paragraph *adf.ADFNode) bool {
+	for _, n := range paragraph.Content {
+		if n.Type != adf.ChildNodeText || strings.TrimSpace(n.Text) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// convertList converts a list node to ADF
+func (p *Translator) convertList(node *sitter.Node, content []byte) *adf.ADFNode {
+	// Determine if this is an ordered or unordered list by checking the first list item's marker
+	var isOrdered bool
+	var startingOrder int = 1
+
+	childCount := int(node.ChildCount())
+	for i := range childCount {
+		child := node.Child(uint(i))
+		if child.Kind() == "list_item" {
+			// Check the list marker in the first list item
+			markerType := p.getListItemMarkerType(child, content)
+			if markerType == "ordered" {
+				isOrdered = true
+				startingOrder = p.extractOrderFromListItem(child, content)
 				break
 			} else if markerType == "unordered" {
 				isOrdered = false
@@ -489,6 +1324,10 @@ func (p *Translator) convertList(node *sitter.Node, content []byte) *adf.ADFNode
 		}
 	}
 
+	if !isOrdered && isDecisionList(node, content) {
+		return p.convertDecisionList(node, content)
+	}
+
 	// Create the appropriate list node
 	var listNode *adf.ADFNode
 	if isOrdered {
@@ -496,6 +1335,7 @@ func (p *Translator) convertList(node *sitter.Node, content []byte) *adf.ADFNode
 	} else {
 		listNode = adf.NewBulletListNode()
 	}
+	p.track(listNode, node.StartByte(), node.EndByte())
 
 	// Convert all list items
 	for i := range childCount {
@@ -514,37 +1354,36 @@ func (p *Translator) convertList(node *sitter.Node, content []byte) *adf.ADFNode
 // convertListItem converts a list_item node to ADF
 func (p *Translator) convertListItem(node *sitter.Node, content []byte) *adf.ADFNode {
 	listItem := adf.NewListItemNode()
+	p.track(listItem, node.StartByte(), node.EndByte())
 
-	childCount := int(node.ChildCount())
-	for i := range childCount {
-		child := node.Child(uint(i))
-		switch child.Kind() {
-		case "paragraph":
-			// Convert the paragraph content of the list item
-			paragraph := p.convertParagraph(child, content)
-			if paragraph != nil {
-				listItem.Content = append(listItem.Content, paragraph)
-			}
-		case "list":
-			// Handle nested lists
-			nestedList := p.convertList(child, content)
-			if nestedList != nil {
-				listItem.Content = append(listItem.Content, nestedList)
-			}
-		}
-		// Ignore list markers and other elements
-	}
+	// Every block child (paragraphs, code blocks, blockquotes, nested lists)
+	// becomes listItem content in order; list markers are ignored by processNode
+	tempDoc := adf.NewADFDocument()
+	p.processChildren(node, content, tempDoc)
+	listItem.Content = append(listItem.Content, tempDoc.Content...)
 
 	return listItem
 }
 
+// convertBlockquote converts a block_quote node to ADF
+func (p *Translator) convertBlockquote(node *sitter.Node, content []byte) *adf.ADFNode {
+	blockquote := adf.NewBlockquoteNode()
+	p.track(blockquote, node.StartByte(), node.EndByte())
+
+	tempDoc := adf.NewADFDocument()
+	p.processChildren(node, content, tempDoc)
+	blockquote.Content = append(blockquote.Content, tempDoc.Content...)
+
+	return blockquote
+}
+
 // getListItemMarkerType determines if a list item has an ordered or unordered marker
 func (p *Translator) getListItemMarkerType(listItemNode *sitter.Node, content []byte) string {
 	childCount := int(listItemNode.ChildCount())
 	for i := range childCount {
 		child := listItemNode.Child(uint(i))
 		switch child.Kind() {
-		case "list_marker_dot":
+		case "list_marker_dot", "list_marker_parenthesis":
 			return "ordered"
 		case "list_marker_minus", "list_marker_plus", "list_marker_star":
 			return "unordered"
@@ -558,10 +1397,10 @@ func (p *Translator) extractOrderFromListItem(listItemNode *sitter.Node, content
 	childCount := int(listItemNode.ChildCount())
 	for i := range childCount {
 		child := listItemNode.Child(uint(i))
-		if child.Kind() == "list_marker_dot" {
+		if child.Kind() == "list_marker_dot" || child.Kind() == "list_marker_parenthesis" {
 			markerText := string(content[child.StartByte():child.EndByte()])
-			// Extract number from marker like "1. " or "42. "
-			numberStr := strings.TrimSuffix(strings.TrimSpace(markerText), ".")
+			// Extract number from marker like "1. ", "42. " or "3) "
+			numberStr := strings.TrimRight(strings.TrimSpace(markerText), ".)")
 			var num int
 			if n, err := fmt.Sscanf(numberStr, "%d", &num); n == 1 && err == nil {
 				return num
@@ -571,166 +1410,93 @@ func (p *Translator) extractOrderFromListItem(listItemNode *sitter.Node, content
 	return 1 // Default to 1 if we can't parse
 }
 
-// processTextWithMarks processes nodes with text formatting marks (strong, underline, strikethrough, emphasis)
-func (p *Translator) processTextWithMarks(node *sitter.Node, inlineContent []byte, parent *adf.ADFNode) {
-	text, marks := p.extractTextContentWithMarks(node, inlineContent)
-
-	if strings.TrimSpace(text) != "" {
-		textNode := adf.NewTextNodeWithMarks(text, marks)
-		parent.Content = append(parent.Content, textNode)
-	}
-}
-
-// extractTextContentWithMarks recursively extracts text content and collects marks
-func (p *Translator) extractTextContentWithMarks(node *sitter.Node, inlineContent []byte) (string, []*adf.ADFMark) {
-	nodeType := node.Kind()
-	marks := []*adf.ADFMark{}
-
-	// Add mark based on node type
-	switch nodeType {
+// processTextWithMarks processes nodes with text formatting marks (strong, underline,
+// strikethrough, emphasis) of the given kind. Everything between the delimiters is
+// processed like any other inline content, so plain text next to nested formatting
+// is kept, and the node's mark is added to every resulting text node.
+func (p *Translator) processTextWithMarks(node *sitter.Node, kind string, inlineContent []byte, parent *adf.ADFNode) {
+	var mark *adf.ADFMark
+	switch kind {
 	case "strong_emphasis":
-		marks = append(marks, adf.NewStrongMark())
+		mark = adf.NewStrongMark()
 	case "underline":
-		marks = append(marks, adf.NewUnderlineMark())
+		mark = adf.NewUnderlineMark()
 	case "strikethrough":
-		marks = append(marks, adf.NewStrikethroughMark())
+		mark = adf.NewStrikethroughMark()
 	case "emphasis":
-		marks = append(marks, adf.NewEmphasisMark())
+		mark = adf.NewEmphasisMark()
 	}
 
 	childCount := int(node.ChildCount())
 
-	// Handle different formatting node types
-	switch nodeType {
-	case "strong_emphasis":
-		// Find first and last delimiter positions for **text**
-		var firstDelimiterEnd, lastDelimiterStart uint
-		delimiterCount := 0
-
-		for i := range childCount {
-			child := node.Child(uint(i))
-			if child.Kind() == "emphasis_delimiter" {
-				delimiterCount++
-				if delimiterCount == 2 { // After second delimiter (opening pair)
-					firstDelimiterEnd = child.EndByte()
-				}
-				if delimiterCount == 3 { // Third delimiter (start of closing pair)
-					lastDelimiterStart = child.StartByte()
-				}
-			}
-		}
-
-		// Extract text between the delimiters or process nested formatting
-		if delimiterCount >= 4 && lastDelimiterStart > firstDelimiterEnd {
-			// Check for nested formatting within this content first
-			for i := range childCount {
-				child := node.Child(uint(i))
-				childType := child.Kind()
-
-				if childType == "underline" || childType == "strikethrough" || childType == "emphasis" {
-					nestedText, nestedMarks := p.extractTextContentWithMarks(child, inlineContent)
-					// Combine marks: current marks + nested marks
-					allMarks := append(marks, nestedMarks...)
-					return nestedText, allMarks
-				}
-			}
-
-			// No nested formatting, return text between delimiters
-			return string(inlineContent[firstDelimiterEnd:lastDelimiterStart]), marks
-		}
-
-	case "strikethrough", "emphasis":
-		// Find first and last delimiter positions for ~text~ or _text_
-		var firstDelimiterEnd, lastDelimiterStart uint
-		delimiterCount := 0
-
-		for i := range childCount {
-			child := node.Child(uint(i))
-			if child.Kind() == "emphasis_delimiter" {
-				delimiterCount++
-				if delimiterCount == 1 { // After first delimiter
-					firstDelimiterEnd = child.EndByte()
-				}
-				if delimiterCount == 2 { // Second delimiter
-					lastDelimiterStart = child.StartByte()
-				}
-			}
-		}
-
-		// Extract text between the delimiters or process nested formatting
-		if delimiterCount >= 2 && lastDelimiterStart > firstDelimiterEnd {
-			// Check for nested formatting within this content first
-			for i := range childCount {
-				child := node.Child(uint(i))
-				childType := child.Kind()
-
-				if childType == "strong_emphasis" || childType == "underline" || childType == "emphasis" || childType == "strikethrough" {
-					// Skip self-reference to avoid infinite recursion
-					if childType != nodeType {
-						nestedText, nestedMarks := p.extractTextContentWithMarks(child, inlineContent)
-						// Combine marks: current marks + nested marks
-						allMarks := append(marks, nestedMarks...)
-						return nestedText, allMarks
-					}
-				}
-			}
-
-			// No nested formatting, return text between delimiters
-			return string(inlineContent[firstDelimiterEnd:lastDelimiterStart]), marks
-		}
-
-	case "underline":
-		// For underline, look for underline_content directly
+	if kind == "underline" {
+		// Underline content is taken as is
 		for i := range childCount {
 			child := node.Child(uint(i))
 			if child.Kind() == "underline_content" {
-				return string(inlineContent[child.StartByte():child.EndByte()]), marks
+				text := inlineContent[child.StartByte():child.EndByte()]
+				if !isBlank(text) {
+					textNode := adf.NewTextNodeWithMarks(string(text), []*adf.ADFMark{mark})
+					p.appendInline(parent, textNode, node.StartByte(), node.EndByte())
+				}
 			}
 		}
+		return
 	}
 
-	// Look for text content in children (fallback for other node types)
-	var textContent strings.Builder
+	// Find the text between the delimiters: **text**, ~text~, ~~text~~ or _text_.
+	// Each delimiter character is its own node, so the opening run is the
+	// first half of them and the closing run the second half.
+	var buf [4]*sitter.Node
+	delimiters := buf[:0]
 	for i := range childCount {
 		child := node.Child(uint(i))
-		childType := child.Kind()
-
-		switch childType {
-		case "underline_content":
-			// Direct text content from underline
-			text := string(inlineContent[child.StartByte():child.EndByte()])
-			textContent.WriteString(text)
+		if child.Kind() == "emphasis_delimiter" {
+			delimiters = append(delimiters, child)
+		}
+	}
 
-		case "strong_emphasis", "underline", "strikethrough", "emphasis":
-			// Nested formatting - recurse
-			nestedText, nestedMarks := p.extractTextContentWithMarks(child, inlineContent)
-			marks = append(marks, nestedMarks...)
-			textContent.WriteString(nestedText)
+	start, end := node.StartByte(), node.EndByte()
+	if n := len(delimiters); n >= 2 {
+		start, end = delimiters[n/2-1].EndByte(), delimiters[n/2].StartByte()
+	}
+	if end <= start {
+		return
+	}
 
-		case "emphasis_delimiter", "underline_open", "underline_close":
-			// Skip delimiters and markup
-			continue
+	if p.aborted() {
+		return
+	}
+	marked := &adf.ADFNode{}
+	p.processInlineRange(node, start, end, inlineContent, marked)
+	if !hasVisibleContent(marked) {
+		return
+	}
 
-		default:
-			// For text content that's not a delimiter, include it
-			if !strings.Contains(childType, "delimiter") &&
-				!strings.Contains(childType, "_open") &&
-				!strings.Contains(childType, "_close") {
-				text := string(inlineContent[child.StartByte():child.EndByte()])
-				textContent.WriteString(text)
-			}
+	// The mark slices of the text nodes share one backing array, each capped
+	// so that adding a mark to one node later copies it rather than
+	// overwriting its neighbour's
+	size := 0
+	for _, n := range marked.Content {
+		size += len(n.Marks) + 1
+	}
+	marks := make([]*adf.ADFMark, 0, size)
+	for _, n := range marked.Content {
+		// ~~text~~ parses as a strikethrough inside a strikethrough
+		if n.Type == adf.ChildNodeText && !slices.ContainsFunc(n.Marks, func(m *adf.ADFMark) bool { return m.Type == mark.Type }) {
+			from := len(marks)
+			marks = append(append(marks, mark), n.Marks...)
+			n.Marks = marks[from:len(marks):len(marks)]
 		}
 	}
-
-	return textContent.String(), marks
+	parent.Content = append(parent.Content, marked.Content...)
 }
 
 // convertPanel converts a panel node to ADF
 func (p *Translator) convertPanel(node *sitter.Node, content []byte) *adf.ADFNode {
-	var panelType string = "info" // default panel type
-
 	// Create the panel node
-	panel := adf.NewPanelNode(panelType)
+	panel := adf.NewPanelNode(adf.PanelInfo)
+	p.track(panel, node.StartByte(), node.EndByte())
 
 	// Process children to find panel_start and content
 	childCount := int(node.ChildCount())
@@ -738,29 +1504,51 @@ func (p *Translator) convertPanel(node *sitter.Node, content []byte) *adf.ADFNod
 		child := node.Child(uint(i))
 		switch child.Kind() {
 		case "panel_start":
-			// Extract panel type from panel_start
-			panelType = p.extractPanelType(child, content)
-			// Update the panel type attribute
-			panel.Attrs["panelType"] = panelType
+			p.setPanelAttrs(panel, child, content)
 		case "section":
 			// This is a content section within the panel
 			tempDoc := adf.NewADFDocument()
 			p.processChildren(child, content, tempDoc)
 			panel.Content = append(panel.Content, tempDoc.Content...)
-		case "paragraph", "atx_heading", "fenced_code_block", "list":
-			// Direct content nodes within the panel
-			tempDoc := adf.NewADFDocument()
-			p.processNode(child, content, tempDoc)
-			panel.Content = append(panel.Content, tempDoc.Content...)
 		case "panel_end_mark":
 			// Ignore panel end mark
 			continue
+		default:
+			// Direct content nodes within the panel: paragraphs, headings,
+			// code blocks, lists, tables
+			tempDoc := adf.NewADFDocument()
+			p.processNode(child, content, tempDoc)
+			panel.Content = append(panel.Content, tempDoc.Content...)
 		}
 	}
 
+	panel.Content = p.foldExpands(panel.Content)
 	return panel
 }
 
+// setPanelAttrs sets the type and the custom panel attrs of panel from its
+// panel_start node, as in "{panel:type=custom|panelIcon=:star:}". An unknown
+// type falls back to info with a warning.
+func (p *Translator) setPanelAttrs(panel *adf.ADFNode, panelStartNode *sitter.Node, content []byte) {
+	// The grammar takes every parameter after "type=" for the type
+	params := strings.Split(p.extractPanelType(panelStartNode, content), "|")
+
+	panelType := strings.TrimSpace(params[0])
+	if !adf.IsPanelType(panelType) {
+		p.warnAt(WarningUnknownPanelType, panelStartNode.StartByte(), panelStartNode.EndByte(),
+			"unknown panel type %q, using %q", panelType, adf.PanelInfo)
+		panelType = adf.PanelInfo
+	}
+	panel.Attrs["panelType"] = panelType
+
+	for _, param := range params[1:] {
+		name, value, ok := strings.Cut(param, "=")
+		if name = strings.TrimSpace(name); ok && slices.Contains(adf.PanelAttrs, name) {
+			panel.Attrs[name] = strings.TrimSpace(value)
+		}
+	}
+}
+
 // extractPanelType extracts the panel type from a panel_start node
 func (p *Translator) extractPanelType(panelStartNode *sitter.Node, content []byte) string {
 	childCount := int(panelStartNode.ChildCount())
@@ -782,13 +1570,15 @@ func (p *Translator) extractPanelType(panelStartNode *sitter.Node, content []byt
 			}
 		}
 	}
-	return "info" // default fallback
+	return adf.PanelInfo // default fallback
 }
 
 // convertPipeTable converts a pipe table to ADF table
 func (p *Translator) convertPipeTable(node *sitter.Node, content []byte) *adf.ADFNode {
 	table := adf.NewTableNode()
+	p.track(table, node.StartByte(), node.EndByte())
 
+	var alignments []string
 	childCount := int(node.ChildCount())
 	for i := range childCount {
 		child := node.Child(uint(i))
@@ -804,66 +1594,471 @@ func (p *Translator) convertPipeTable(node *sitter.Node, content []byte) *adf.AD
 				table.Content = append(table.Content, dataRow)
 			}
 		case "pipe_table_delimiter_row":
-			// Skip delimiter rows - they're just formatting
-			continue
+			for _, d := range splitTableRow(string(content[child.StartByte():child.EndByte()])) {
+				alignments = append(alignments, columnAlignment(d.text))
+			}
 		}
 	}
 
+	alignColumns(table, alignments)
 	return table
 }
 
+// columnAlignment returns the ADF alignment of a delimiter row cell: "center"
+// for ":-:", "end" for "-:", and "" for the default start alignment.
+func columnAlignment(delimiter string) string {
+	delimiter = strings.TrimSpace(delimiter)
+	switch {
+	case len(delimiter) > 1 && strings.HasPrefix(delimiter, ":") && strings.HasSuffix(delimiter, ":"):
+		return "center"
+	case strings.HasSuffix(delimiter, ":"):
+		return "end"
+	}
+	return ""
+}
+
+// alignColumns marks the paragraphs and headings of every cell with the
+// alignment of its column.
+func alignColumns(table *adf.ADFNode, alignments []string) {
+	for _, row := range table.Content {
+		for col, cell := range row.Content {
+			if col >= len(alignments) || alignments[col] == "" {
+				continue
+			}
+			for _, block := range cell.Content {
+				if block.Type == adf.NodeParagraph || block.Type == adf.NodeHeading {
+					block.Marks = append(block.Marks, adf.NewAlignmentMark(alignments[col]))
+				}
+			}
+		}
+	}
+}
+
 // convertPipeTableRow converts a pipe table row to ADF table row
 func (p *Translator) convertPipeTableRow(node *sitter.Node, content []byte, isHeader bool) *adf.ADFNode {
 	row := adf.NewTableRowNode()
+	p.track(row, node.StartByte(), node.EndByte())
 
 	childCount := int(node.ChildCount())
 	for i := range childCount {
 		child := node.Child(uint(i))
 		if child.Kind() == "pipe_table_cell" {
-			var cell *adf.ADFNode
-			if isHeader {
-				cell = adf.NewTableHeaderNode()
-			} else {
-				cell = adf.NewTableCellNode()
+			row.Content = append(row.Content, p.convertTableCell(content, child.StartByte(), child.EndByte(), isHeader))
+		}
+	}
+
+	return row
+}
+
+// convertTableCell converts the table cell content[cellStart:cellEnd], of a
+// pipe table or one written without outer pipes, to an ADF table cell or
+// header, processing its content like paragraph content
+func (p *Translator) convertTableCell(content []byte, cellStart, cellEnd uint, isHeader bool) *adf.ADFNode {
+	var cell *adf.ADFNode
+	if isHeader {
+		cell = adf.NewTableHeaderNode()
+	} else {
+		cell = adf.NewTableCellNode()
+	}
+	p.track(cell, cellStart, cellEnd)
+
+	inlineContent := content[cellStart:cellEnd]
+	inlineTree := p.inlineParser.Parse(inlineContent, nil)
+	if inlineTree == nil {
+		// No inline tree, treat as plain text
+		paragraph := adf.NewParagraphNode()
+		if text := strings.TrimSpace(string(inlineContent)); text != "" {
+			paragraph.Content = append(paragraph.Content, adf.NewTextNode(text))
+		}
+		cell.Content = append(cell.Content, paragraph)
+		return cell
+	}
+	defer inlineTree.Close()
+
+	// Paragraphs are separated by <br> tags, as a cell can't span lines, and
+	// those of bulleted lines make a list
+	var list *adf.ADFNode
+	p.inlineBase = cellStart
+	for _, span := range cellParagraphSpans(inlineTree.RootNode(), inlineContent) {
+		start, end := cellStart+span[0], cellStart+span[1]
+		paragraph := adf.NewParagraphNode()
+		p.track(paragraph, start, end)
+		p.processInlineRange(inlineTree.RootNode(), span[0], span[1], inlineContent, paragraph)
+		p.joinText(paragraph)
+		trimInlineWhitespace(paragraph)
+		unescapeCellPipes(paragraph)
+		bullet := trimCellBullet(paragraph)
+
+		if isHeader && p.boldTableHeaders {
+			for _, n := range paragraph.Content {
+				if n.Type == adf.ChildNodeText && !slices.ContainsFunc(n.Marks, func(m *adf.ADFMark) bool { return m.Type == adf.MarkStrong }) {
+					n.Marks = append(n.Marks, adf.NewStrongMark())
+				}
 			}
+		}
 
-			// Get cell content and convert it
-			cellText := strings.TrimSpace(string(content[child.StartByte():child.EndByte()]))
-			if cellText != "" {
-				paragraph := adf.NewParagraphNode()
+		// Media can't be inline, so it is moved out of the paragraph
+		blocks := p.hoistBlocks(paragraph)
+		if !bullet {
+			list = nil
+			cell.Content = append(cell.Content, blocks...)
+			continue
+		}
 
-				// Parse formatting within the cell
-				p.parseCellContent(cellText, paragraph, isHeader)
+		if list == nil {
+			list = adf.NewBulletListNode()
+			p.track(list, start, end)
+			cell.Content = append(cell.Content, list)
+		} else if r, ok := p.ranges[list]; ok {
+			r.end = int(end)
+			p.ranges[list] = r
+		}
+		item := adf.NewListItemNode()
+		item.Content = blocks
+		p.track(item, start, end)
+		list.Content = append(list.Content, item)
+	}
+	if len(cell.Content) == 0 {
+		// Empty cell gets empty paragraph
+		cell.Content = append(cell.Content, adf.NewParagraphNode())
+	}
+
+	return cell
+}
 
-				cell.Content = append(cell.Content, paragraph)
+// cellParagraphSpans returns the spans of the inline content of a table cell
+// between the <br> tags separating its paragraphs.
+func cellParagraphSpans(root *sitter.Node, inlineContent []byte) [][2]uint {
+	var spans [][2]uint
+	start := uint(0)
+	for i := range root.ChildCount() {
+		child := root.Child(i)
+		if child.Kind() == "html_tag" && cellBreak.Match(inlineContent[child.StartByte():child.EndByte()]) {
+			spans = append(spans, [2]uint{start, child.StartByte()})
+			start = child.EndByte()
+		}
+	}
+	return append(spans, [2]uint{start, uint(len(inlineContent))})
+}
+
+// cellBullet starts the lines adf2md renders the items of a bullet list in a
+// table cell as.
+const cellBullet = "• "
+
+// trimCellBullet removes the cellBullet starting a paragraph of a table cell
+// and reports whether there was one.
+func trimCellBullet(paragraph *adf.ADFNode) bool {
+	if len(paragraph.Content) == 0 {
+		return false
+	}
+	first := paragraph.Content[0]
+	// An empty item is trimmed down to the bullet
+	if first.Type != adf.ChildNodeText || len(first.Marks) > 0 ||
+		!strings.HasPrefix(first.Text, cellBullet) && first.Text != strings.TrimSpace(cellBullet) {
+		return false
+	}
+
+	first.Text = strings.TrimLeft(strings.TrimPrefix(first.Text, strings.TrimSpace(cellBullet)), " ")
+	if first.Text == "" {
+		paragraph.Content = paragraph.Content[1:]
+	}
+	return true
+}
+
+// cellBreak matches the <br> tag adf2md separates the paragraphs of a table
+// cell with.
+var cellBreak = regexp.MustCompile(`^(?i)<br\s*/?>$`)
+
+// joinText joins neighbouring text nodes with the same marks, which cell
+// content is kept as rather than split at punctuation like paragraphs.
+func (p *Translator) joinText(paragraph *adf.ADFNode) {
+	var joined []*adf.ADFNode
+	for _, n := range paragraph.Content {
+		if last := len(joined) - 1; last >= 0 && n.Type == adf.ChildNodeText && joined[last].Type == adf.ChildNodeText && sameMarks(joined[last].Marks, n.Marks) {
+			if p.ranges != nil {
+				p.ranges[joined[last]] = sourceRange{start: p.ranges[joined[last]].start, end: p.ranges[n].end}
+			}
+			joined[last].Text += n.Text
+			continue
+		}
+		joined = append(joined, n)
+	}
+	paragraph.Content = joined
+}
+
+// sameMarks reports whether two mark lists are equal.
+func sameMarks(a, b []*adf.ADFMark) bool {
+	return slices.EqualFunc(a, b, func(x, y *adf.ADFMark) bool {
+		return x.Type == y.Type && maps.Equal(x.Attrs, y.Attrs)
+	})
+}
+
+// unescapeCellPipes turns escaped pipes left in cell text, such as those in
+// code spans, back into literal pipes, as table cells require escaping them
+func unescapeCellPipes(paragraph *adf.ADFNode) {
+	for _, n := range paragraph.Content {
+		if n.Type == adf.ChildNodeText {
+			n.Text = strings.ReplaceAll(n.Text, `\|`, "|")
+		}
+	}
+}
+
+// trimInlineWhitespace removes the whitespace around the inline content of a
+// paragraph, dropping text nodes left empty.
+func trimInlineWhitespace(paragraph *adf.ADFNode) {
+	if n := len(paragraph.Content); n > 0 && paragraph.Content[0].Type == adf.ChildNodeText {
+		paragraph.Content[0].Text = strings.TrimLeft(paragraph.Content[0].Text, " \t")
+	}
+	if n := len(paragraph.Content); n > 0 && paragraph.Content[n-1].Type == adf.ChildNodeText {
+		paragraph.Content[n-1].Text = strings.TrimRight(paragraph.Content[n-1].Text, " \t")
+	}
+
+	paragraph.Content = slices.DeleteFunc(paragraph.Content, func(n *adf.ADFNode) bool {
+		return n.Type == adf.ChildNodeText && n.Text == ""
+	})
+}
+
+// lineIndent matches the indentation of a line following a line break.
+var lineIndent = regexp.MustCompile(`\n[ \t]+`)
+
+// trimLineIndents removes the indentation of the lines of a paragraph, which
+// isn't part of its content: that of its first line beyond the content column
+// of the list item it is in, as in "- a\n\n    b", and that of the lines
+// after. Code spans are left as they are.
+func trimLineIndents(paragraph *adf.ADFNode) {
+	lineStart := true
+	for _, n := range paragraph.Content {
+		if n.Type == adf.InlineNodeHardBreak {
+			lineStart = true
+			continue
+		}
+		if n.Type != adf.ChildNodeText || slices.ContainsFunc(n.Marks, func(m *adf.ADFMark) bool { return m.Type == adf.MarkCode }) {
+			lineStart = false
+			continue
+		}
+		if lineStart {
+			n.Text = strings.TrimLeft(n.Text, " \t")
+		}
+		n.Text = lineIndent.ReplaceAllString(n.Text, "\n")
+		if n.Text != "" {
+			lineStart = strings.HasSuffix(n.Text, "\n")
+		}
+	}
+
+	paragraph.Content = slices.DeleteFunc(paragraph.Content, func(n *adf.ADFNode) bool {
+		return n.Type == adf.ChildNodeText && n.Text == ""
+	})
+}
+
+// cutTextPrefix returns inline content without prefix at its start, which
+// may be split across text nodes. The nodes are left as they are: a text
+// node cut short is copied. ok is false when the content doesn't start with
+// prefix.
+func cutTextPrefix(nodes []*adf.ADFNode, prefix string) (rest []*adf.ADFNode, ok bool) {
+	rest = slices.Clone(nodes)
+	for prefix != "" {
+		if len(rest) == 0 || rest[0].Type != adf.ChildNodeText {
+			return nodes, false
+		}
+		text := rest[0].Text
+		cut := min(len(prefix), len(text))
+		if text[:cut] != prefix[:cut] {
+			return nodes, false
+		}
+		if cut == len(text) {
+			rest = rest[1:]
+		} else {
+			n := *rest[0]
+			n.Text = text[cut:]
+			rest[0] = &n
+		}
+		prefix = prefix[cut:]
+	}
+	return rest, true
+}
+
+// cutTextSuffix is cutTextPrefix for the end of inline content.
+func cutTextSuffix(nodes []*adf.ADFNode, suffix string) (rest []*adf.ADFNode, ok bool) {
+	rest = slices.Clone(nodes)
+	for suffix != "" {
+		last := len(rest) - 1
+		if last < 0 || rest[last].Type != adf.ChildNodeText {
+			return nodes, false
+		}
+		text := rest[last].Text
+		cut := min(len(suffix), len(text))
+		if text[len(text)-cut:] != suffix[len(suffix)-cut:] {
+			return nodes, false
+		}
+		if cut == len(text) {
+			rest = rest[:last]
+		} else {
+			n := *rest[last]
+			n.Text = text[:len(text)-cut]
+			rest[last] = &n
+		}
+		suffix = suffix[:len(suffix)-cut]
+	}
+	return rest, true
+}
+
+// convertPipelessTable recognizes a paragraph that is really a GFM table
+// written without leading and trailing pipes ("a | b" / "--- | ---" / "1 | 2"),
+// which the grammar leaves as plain text. It returns nil for anything else.
+func (p *Translator) convertPipelessTable(node *sitter.Node, content []byte) *adf.ADFNode {
+	text := string(content[node.StartByte():node.EndByte()])
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	if len(lines) < 2 || !strings.Contains(lines[0], "|") {
+		return nil
+	}
+
+	header := splitTableRow(lines[0])
+	delimiters := splitTableRow(lines[1])
+	if len(header) != len(delimiters) {
+		return nil
+	}
+	alignments := make([]string, len(delimiters))
+	for i, d := range delimiters {
+		if !tableDelimiterCell.MatchString(strings.TrimSpace(d.text)) {
+			return nil
+		}
+		alignments[i] = columnAlignment(d.text)
+	}
+
+	table := adf.NewTableNode()
+	p.track(table, node.StartByte(), node.EndByte())
+
+	lineStart := node.StartByte()
+	for i, line := range lines {
+		lineEnd := lineStart + uint(len(line))
+		if i == 1 {
+			// The delimiter row only carries the alignments
+			lineStart = lineEnd + 1
+			continue
+		}
+
+		row := adf.NewTableRowNode()
+		p.track(row, lineStart, lineEnd)
+
+		// Rows are padded or truncated to the header's column count, as in GFM
+		cells := splitTableRow(line)
+		for col := range header {
+			if col < len(cells) {
+				cellStart := lineStart + uint(cells[col].offset)
+				row.Content = append(row.Content, p.convertTableCell(content, cellStart, cellStart+uint(len(cells[col].text)), i == 0))
 			} else {
-				// Empty cell gets empty paragraph
-				cell.Content = append(cell.Content, adf.NewParagraphNode())
+				row.Content = append(row.Content, p.convertTableCell(content, lineEnd, lineEnd, i == 0))
 			}
+		}
+
+		table.Content = append(table.Content, row)
+		lineStart = lineEnd + 1
+	}
+
+	alignColumns(table, alignments)
+	return table
+}
+
+// tableDelimiterCell matches a cell of a table delimiter row, e.g. "---" or ":-:".
+var tableDelimiterCell = regexp.MustCompile(`^:?-+:?$`)
+
+// tableRowCell is the raw text of a table cell and its byte offset in the row.
+type tableRowCell struct {
+	text   string
+	offset int
+}
 
-			row.Content = append(row.Content, cell)
+// splitTableRow splits a table row on unescaped pipes, dropping the optional
+// leading and trailing pipe.
+func splitTableRow(line string) []tableRowCell {
+	var cells []tableRowCell
+
+	start := 0
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++ // skip the escaped character
+		case '|':
+			cells = append(cells, tableRowCell{text: line[start:i], offset: start})
+			start = i + 1
 		}
 	}
+	cells = append(cells, tableRowCell{text: line[start:], offset: start})
 
-	return row
+	if len(cells) > 1 && strings.TrimSpace(cells[0].text) == "" {
+		cells = cells[1:]
+	}
+	if len(cells) > 1 && strings.TrimSpace(cells[len(cells)-1].text) == "" {
+		cells = cells[:len(cells)-1]
+	}
+
+	return cells
 }
 
-// parseCellContent parses the content of a table cell and handles formatting
-func (p *Translator) parseCellContent(cellText string, paragraph *adf.ADFNode, isHeader bool) {
-	// Simple parsing for bold text marked with **text**
-	if strings.HasPrefix(cellText, "**") && strings.HasSuffix(cellText, "**") && len(cellText) > 4 {
-		// Bold text
-		innerText := cellText[2 : len(cellText)-2]
-		textNode := adf.NewTextNode(innerText)
-		textNode.Marks = append(textNode.Marks, &adf.ADFMark{Type: adf.MarkStrong})
-		paragraph.Content = append(paragraph.Content, textNode)
-	} else {
-		// Plain text
-		textNode := adf.NewTextNode(cellText)
-		// Headers are automatically bold in ADF, but we can add explicit bold mark if needed
-		if isHeader {
-			textNode.Marks = append(textNode.Marks, &adf.ADFMark{Type: adf.MarkStrong})
+// appendInline appends an inline node to parent, recording its source span
+// given as offsets relative to the inline content being processed.
+func (p *Translator) appendInline(parent, node *adf.ADFNode, start, end uint) {
+	p.track(node, p.inlineBase+start, p.inlineBase+end)
+	parent.Content = append(parent.Content, node)
+}
+
+// appendPlainText appends text to parent as text nodes, split at the
+// textBreaks within it and with the textEscapes backslashes removed. A
+// split-off piece of whitespace spanning lines becomes a single space.
+func (p *Translator) appendPlainText(parent *adf.ADFNode, text string, start, end uint) {
+	breaks, escapes := p.textBreaks, p.textEscapes
+	// Pieces are substrings of text; only one with an escape in it is copied
+	// to drop the backslashes
+	from, pieceStart := 0, start
+	var unescaped []byte
+	escaped := false
+
+	appendPiece := func(to int, toOffset uint) {
+		piece := text[from:to]
+		if escaped {
+			piece = string(unescaped)
+		}
+		from, escaped = to, false
+		if piece == "" {
+			pieceStart = toOffset
+			return
+		}
+		if strings.TrimSpace(piece) == "" && strings.Contains(piece, "\n") {
+			piece = " "
+		}
+		p.appendInline(parent, adf.NewTextNode(piece), pieceStart, toOffset)
+		pieceStart = toOffset
+	}
+
+	for i := range len(text) {
+		offset := start + uint(i)
+		for len(breaks) > 0 && breaks[0] < offset {
+			breaks = breaks[1:]
+		}
+		if i > 0 && len(breaks) > 0 && breaks[0] == offset {
+			appendPiece(i, offset)
+		}
+		for len(escapes) > 0 && escapes[0] < offset {
+			escapes = escapes[1:]
+		}
+		if len(escapes) > 0 && escapes[0] == offset && text[i] == '\\' {
+			if !escaped {
+				unescaped = append(unescaped[:0], text[from:i]...)
+				escaped = true
+			}
+			continue
 		}
-		paragraph.Content = append(paragraph.Content, textNode)
+		if escaped {
+			unescaped = append(unescaped, text[i])
+		}
+	}
+	appendPiece(len(text), end)
+}
+
+// track records the source span of node while a source map is being built.
+func (p *Translator) track(node *adf.ADFNode, start, end uint) {
+	if p.ranges == nil || node == nil {
+		return
 	}
+	p.ranges[node] = sourceRange{start: int(start), end: int(end)}
 }
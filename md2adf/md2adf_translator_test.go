@@ -2,8 +2,14 @@ package md2adf
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/jorres/md2adf-translator/adf"
+	"github.com/jorres/md2adf-translator/adf2md"
+	"slices"
+	"strings"
 	"testing"
+	"testing/iotest"
 
 	tree_sitter_markdown "github.com/jorres/tree-sitter-jira-markdown/bindings/go"
 )
@@ -282,3 +288,316 @@ func TestTextMarksProcessing(t *testing.T) {
 		})
 	}
 }
+
+func TestEmptyParagraphs(t *testing.T) {
+	markdown := []byte("First\n\n\n\nSecond\nline\n\nThird\n")
+
+	doc, err := NewTranslator().TranslateToADF(markdown)
+	if err != nil {
+		t.Fatalf("Failed to translate: %v", err)
+	}
+	if len(doc.Content) != 3 {
+		t.Errorf("Expected extra blank lines to be dropped by default, got %d blocks", len(doc.Content))
+	}
+
+	doc, err = NewTranslator(WithEmptyParagraphs(true)).TranslateToADF(markdown)
+	if err != nil {
+		t.Fatalf("Failed to translate: %v", err)
+	}
+	expected := adf.NewDocBuilder().
+		Paragraph(adf.Text("First")).
+		Paragraph().
+		Paragraph().
+		Paragraph(adf.Text("Second\nline")).
+		Paragraph(adf.Text("Third")).
+		Build()
+	if !adf.EqualDocuments(expected, doc) {
+		jsonBytes, _ := json.MarshalIndent(doc, "", "  ")
+		t.Errorf("Expected two empty paragraphs after the first, got:\n%s", string(jsonBytes))
+	}
+}
+
+func TestEmptyParagraphsRoundtrip(t *testing.T) {
+	spaced := adf.NewDocBuilder().
+		Paragraph(adf.Text("First")).
+		Paragraph().
+		Paragraph(adf.Text("Second")).
+		Paragraph().
+		Paragraph().
+		Paragraph(adf.Text("Third")).
+		Build()
+
+	markdown, err := adf2md.NewTranslator(adf2md.NewMarkdownTranslator()).TranslateDocument(spaced)
+	if err != nil {
+		t.Fatalf("Failed to render the document: %v", err)
+	}
+
+	doc, err := NewTranslator(WithEmptyParagraphs(true)).TranslateToADF([]byte(markdown))
+	if err != nil {
+		t.Fatalf("Failed to translate %q: %v", markdown, err)
+	}
+	if !adf.EqualDocuments(spaced, doc) {
+		jsonBytes, _ := json.MarshalIndent(doc, "", "  ")
+		t.Errorf("Expected the empty paragraphs back from %q, got:\n%s", markdown, string(jsonBytes))
+	}
+}
+
+func TestBlockquoteRoundtrip(t *testing.T) {
+	quote := adf.NewDocBuilder().
+		Blockquote(
+			adf.Paragraph(adf.Text("First")),
+			adf.Paragraph(adf.Text("Second")),
+			adf.BulletList(adf.Item(adf.Text("a")), adf.Item(adf.Text("b"))),
+			adf.CodeBlock("go", "x := 1\ny := 2"),
+		).
+		Paragraph(adf.Text("After")).
+		Build()
+
+	markdown, err := adf2md.NewTranslator(adf2md.NewMarkdownTranslator()).TranslateDocument(quote)
+	if err != nil {
+		t.Fatalf("Failed to render the quote: %v", err)
+	}
+
+	doc, err := NewTranslator().TranslateToADF([]byte(markdown))
+	if err != nil {
+		t.Fatalf("Failed to translate %q: %v", markdown, err)
+	}
+	if !adf.EqualDocuments(quote, doc) {
+		jsonBytes, _ := json.MarshalIndent(doc, "", "  ")
+		t.Errorf("Expected the quote back from %q, got:\n%s", markdown, string(jsonBytes))
+	}
+}
+
+// TestBlockquoteSiblingBlocksRoundtrip checks that a paragraph after a list or
+// a code block in a quote is read back as a block of its own rather than as a
+// lazy continuation of the one before.
+func TestBlockquoteSiblingBlocksRoundtrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		block adf.Block
+	}{
+		{"list", adf.BulletList(adf.Item(adf.Text("item")))},
+		{"code block", adf.CodeBlock("go", "x := 1")},
+		{"heading", adf.Heading(2, adf.Text("Title"))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quote := adf.NewDocBuilder().
+				Blockquote(tt.block, adf.Paragraph(adf.Text("para"))).
+				Build()
+
+			markdown, err := adf2md.NewTranslator(adf2md.NewMarkdownTranslator()).TranslateDocument(quote)
+			if err != nil {
+				t.Fatalf("Failed to render the quote: %v", err)
+			}
+
+			doc, err := NewTranslator().TranslateToADF([]byte(markdown))
+			if err != nil {
+				t.Fatalf("Failed to translate %q: %v", markdown, err)
+			}
+			if !adf.EqualDocuments(quote, doc) {
+				jsonBytes, _ := json.MarshalIndent(doc, "", "  ")
+				t.Errorf("Expected the quote back from %q, got:\n%s", markdown, string(jsonBytes))
+			}
+		})
+	}
+}
+
+func TestFlattenedLayoutRoundtrip(t *testing.T) {
+	column := func(blocks ...adf.Block) *adf.ADFNode {
+		n := &adf.ADFNode{Type: adf.ChildNodeLayoutColumn, Attrs: map[string]any{"width": 50}}
+		for _, b := range blocks {
+			n.Content = append(n.Content, b.Node())
+		}
+		return n
+	}
+	layout := &adf.ADFNode{Type: adf.NodeLayoutSection, Content: []*adf.ADFNode{
+		column(adf.BulletList(adf.Item(adf.Text("Left")))),
+		column(adf.Heading(2, adf.Text("Right")), adf.Paragraph(adf.Text("Text"))),
+	}}
+	doc := adf.NewDocBuilder().Paragraph(adf.Text("Intro")).AppendNodes(layout).Build()
+
+	translator := NewTranslator()
+	markdown, err := translator.TranslateToMarkdown(doc)
+	if err != nil {
+		t.Fatalf("Failed to render the layout: %v", err)
+	}
+
+	result, err := translator.TranslateToADF([]byte(markdown))
+	if err != nil {
+		t.Fatalf("Failed to translate %q: %v", markdown, err)
+	}
+	// The columns are read back one after the other
+	flattened := adf.NewDocBuilder().
+		Paragraph(adf.Text("Intro")).
+		AppendNodes(layout.Content[0].Content...).
+		AppendNodes(layout.Content[1].Content...).
+		Build()
+	if !adf.EqualDocuments(flattened, result) {
+		jsonBytes, _ := json.MarshalIndent(result, "", "  ")
+		t.Errorf("Expected the columns back from %q, got:\n%s", markdown, string(jsonBytes))
+	}
+}
+
+func TestHeadingInlineContentRoundtrip(t *testing.T) {
+	reverse := adf2md.NewTranslator(adf2md.NewJiraMarkdownTranslator(adf2md.WithUserEmailResolver(func(id string) string {
+		if id == "jane-id" {
+			return "jane@example.com"
+		}
+		return ""
+	})))
+	translator := NewTranslator(WithUserEmailMapping(map[string]string{"jane@example.com": "jane-id"}), WithAdf2MdTranslator(reverse))
+
+	// shape describes the inline nodes of a heading by type, marks and
+	// mentioned account, leaving out the text
+	shape := func(heading *adf.ADFNode) string {
+		var parts []string
+		for _, n := range heading.Content {
+			part := string(n.Type)
+			for _, m := range n.Marks {
+				part += "+" + string(m.Type)
+			}
+			if id, ok := n.Attrs["id"].(string); ok {
+				part += "(" + id + ")"
+			}
+			parts = append(parts, part)
+		}
+		return fmt.Sprintf("h%v %s", heading.Attrs["level"], strings.Join(parts, " "))
+	}
+
+	tests := []struct {
+		name     string
+		markdown string
+		expected string
+	}{
+		{"bold", "## A **bold** word", "h2 text text+strong text"},
+		{"code", "### Run `make` first", "h3 text text+code text"},
+		{"code in bold", "## **Bold `code` inside**", "h2 text+strong text+strong+code text+strong"},
+		{"link", "## See [the docs](https://example.com/docs) now", "h2 text text+link text"},
+		{"bold link", "# [**Bold** link](https://example.com)", "h1 text+strong+link text+link"},
+		{"mention", "## Ask @jane@example.com first", "h2 text mention(jane-id) text"},
+		{"underline", "## <u>Under</u> line", "h2 text+underline text"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := translator.TranslateToADF([]byte(tt.markdown))
+			if err != nil {
+				t.Fatalf("Failed to convert markdown: %v", err)
+			}
+			if len(doc.Content) != 1 || doc.Content[0].Type != adf.NodeHeading {
+				t.Fatalf("Expected a heading from %q, got %+v", tt.markdown, doc.Content)
+			}
+			if got := shape(doc.Content[0]); got != tt.expected {
+				t.Fatalf("Expected %s from %q, got %s", tt.expected, tt.markdown, got)
+			}
+
+			markdown, err := translator.TranslateToMarkdown(doc)
+			if err != nil {
+				t.Fatalf("Failed to render markdown: %v", err)
+			}
+			result, err := translator.TranslateToADF([]byte(markdown))
+			if err != nil {
+				t.Fatalf("Failed to convert %q: %v", markdown, err)
+			}
+			if len(result.Content) != 1 || result.Content[0].Type != adf.NodeHeading {
+				t.Fatalf("Expected a heading back from %q, got %+v", markdown, result.Content)
+			}
+			if got := shape(result.Content[0]); got != tt.expected {
+				t.Errorf("Expected %s back from %q, got %s", tt.expected, markdown, got)
+			}
+			text := strings.Fields(adf.PlainText(doc))
+			if back := strings.Fields(adf.PlainText(result)); !slices.Equal(text, back) {
+				t.Errorf("Expected the words %q back from %q, got %q", text, markdown, back)
+			}
+		})
+	}
+}
+
+func TestHeadingMarkerLength(t *testing.T) {
+	doc, err := NewTranslator().TranslateToADF([]byte("###### Six\n\n####### Seven\n"))
+	if err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+	if len(doc.Content) != 2 {
+		t.Fatalf("Expected two blocks, got %+v", doc.Content)
+	}
+	if heading := doc.Content[0]; heading.Type != adf.NodeHeading || adf.HeadingLevel(heading.Attrs) != 6 {
+		t.Errorf("Expected a level 6 heading, got %+v", heading)
+	}
+	// Like in CommonMark, more than six hashes don't start a heading
+	paragraph := doc.Content[1]
+	if text := adf.PlainText(&adf.ADFDocument{Content: []*adf.ADFNode{paragraph}}); paragraph.Type != adf.NodeParagraph || text != "####### Seven" {
+		t.Errorf("Expected the paragraph \"####### Seven\", got %s %q", paragraph.Type, text)
+	}
+}
+
+func TestHeadingOffset(t *testing.T) {
+	markdown := "# Title\n\n## Section\n\n##### Deep with `code`\n\n{panel:type=note}\n## Panel heading\n\n###### Panel **detail**\n{/panel}\n"
+	doc, err := NewTranslator(WithHeadingOffset(1)).TranslateToADF([]byte(markdown))
+	if err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+	if len(doc.Content) != 4 {
+		t.Fatalf("Expected four blocks, got %+v", doc.Content)
+	}
+
+	for i, level := range []int{2, 3, 6} {
+		if heading := doc.Content[i]; heading.Type != adf.NodeHeading || adf.HeadingLevel(heading.Attrs) != level {
+			t.Errorf("Expected block %d to be a level %d heading, got %s %+v", i, level, heading.Type, heading.Attrs)
+		}
+	}
+
+	panel := doc.Content[3]
+	if panel.Type != adf.NodePanel || len(panel.Content) != 2 {
+		t.Fatalf("Expected a panel of two blocks, got %+v", panel)
+	}
+	if heading := panel.Content[0]; heading.Type != adf.NodeHeading || adf.HeadingLevel(heading.Attrs) != 3 {
+		t.Errorf("Expected a level 3 heading in the panel, got %s %+v", heading.Type, heading.Attrs)
+	}
+	// Past level 6, the heading becomes a paragraph in bold
+	paragraph := panel.Content[1]
+	if paragraph.Type != adf.NodeParagraph || paragraph.Attrs != nil {
+		t.Fatalf("Expected a paragraph in the panel, got %s %+v", paragraph.Type, paragraph.Attrs)
+	}
+	if runs := markRuns(paragraph.Content); !slices.Equal(runs, []string{"Panel detail[strong]"}) {
+		t.Errorf("Expected the paragraph in bold, got %q", runs)
+	}
+
+	// A negative offset stops at level 1
+	doc, err = NewTranslator(WithHeadingOffset(-2)).TranslateToADF([]byte("# Title\n\n### Section\n"))
+	if err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+	for i, heading := range doc.Content {
+		if adf.HeadingLevel(heading.Attrs) != 1 {
+			t.Errorf("Expected heading %d at level 1, got %+v", i, heading.Attrs)
+		}
+	}
+}
+
+func TestTranslateReader(t *testing.T) {
+	markdown := "# Title\n\nSome **bold** text\n\n- one\n- two\n"
+
+	translator := NewTranslator()
+	expected, err := translator.TranslateToADF([]byte(markdown))
+	if err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+
+	// Short reads are put together
+	doc, err := translator.TranslateReader(iotest.OneByteReader(strings.NewReader(markdown)))
+	if err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+	if !adf.EqualDocuments(expected, doc) {
+		jsonBytes, _ := json.MarshalIndent(doc, "", "  ")
+		t.Errorf("Expected the document of TranslateToADF, got:\n%s", string(jsonBytes))
+	}
+
+	failing := errors.New("connection reset")
+	if _, err := translator.TranslateReader(iotest.ErrReader(failing)); !errors.Is(err, failing) {
+		t.Errorf("Expected the read error, got %v", err)
+	}
+}
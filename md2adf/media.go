@@ -0,0 +1,335 @@
+package md2adf
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jorres/md2adf-translator/adf"
+	sitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+// WithMissingAttachmentHandler sets the function converting an
+// {attachment:id} reference whose id the reverse translator's media mapping
+// doesn't know; known ids always resolve to their preserved node. The
+// returned node is added as a block, nil drops the reference, and an error
+// fails the translation. Without a handler the reference is kept as a
+// paragraph with its literal text.
+func WithMissingAttachmentHandler(handler func(id string) (*adf.ADFNode, error)) TranslatorOption {
+	return func(tr *Translator) {
+		tr.missingAttachment = handler
+	}
+}
+
+// mediaLayouts are the layouts Jira accepts for a mediaSingle.
+var mediaLayouts = []string{"center", "wrap-left", "wrap-right", "wide", "full-width", "align-start", "align-end"}
+
+// attachmentParams are the parameters of an {attachment:id|name=value|...}
+// reference: the size of the media in pixels and the layout of the
+// mediaSingle holding it. Zero values leave the attrs as they are.
+type attachmentParams struct {
+	width, height int
+	layout        string
+}
+
+// parseAttachmentRef splits the path of an attachment reference into the id
+// and the parameters. Unknown parameters and invalid values are dropped with
+// a warning.
+func (p *Translator) parseAttachmentRef(path string, node *sitter.Node) (string, attachmentParams) {
+	id, rest, _ := strings.Cut(path, "|")
+
+	var params attachmentParams
+	for _, param := range strings.Split(rest, "|") {
+		if param == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(param, "=")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+
+		valid := false
+		switch name {
+		case "width", "height":
+			size, err := strconv.Atoi(value)
+			valid = err == nil && size > 0
+			switch {
+			case valid && name == "width":
+				params.width = size
+			case valid:
+				params.height = size
+			}
+		case "layout":
+			if valid = slices.Contains(mediaLayouts, value); valid {
+				params.layout = value
+			}
+		}
+		if !valid {
+			p.warnAt(WarningInvalidAttachmentParameter, node.StartByte(), node.EndByte(),
+				"attachment %s: parameter %q dropped", id, param)
+		}
+	}
+	return id, params
+}
+
+// apply sets the parameters on the attrs of a media node, or of a mediaSingle
+// and its media. The layout only applies to a mediaSingle.
+func (params attachmentParams) apply(block *adf.ADFNode) {
+	if params.layout != "" && block.Type == adf.NodeMediaSingle {
+		block.Attrs = setAttr(block.Attrs, "layout", params.layout)
+	}
+
+	media := []*adf.ADFNode{block}
+	if block.Type != adf.NodeMedia {
+		media = block.Content
+	}
+	for _, n := range media {
+		if n == nil || n.Type != adf.NodeMedia {
+			continue
+		}
+		if params.width > 0 {
+			n.Attrs = setAttr(n.Attrs, "width", params.width)
+		}
+		if params.height > 0 {
+			n.Attrs = setAttr(n.Attrs, "height", params.height)
+		}
+	}
+}
+
+// isZero reports whether no parameter is set.
+func (params attachmentParams) isZero() bool {
+	return params == attachmentParams{}
+}
+
+// setAttr sets an attr, creating the attrs if needed.
+func setAttr(attrs map[string]any, name string, value any) map[string]any {
+	if attrs == nil {
+		attrs = make(map[string]any)
+	}
+	attrs[name] = value
+	return attrs
+}
+
+// convertAttachment appends the block an {attachment:id|...} reference stands
+// for to doc. The id may also be the filename the attachment was rendered by.
+// Parameters of the reference are set on a copy of the block.
+func (p *Translator) convertAttachment(doc *adf.ADFDocument, path string, node *sitter.Node, content []byte) {
+	id, params := p.parseAttachmentRef(path, node)
+
+	if mediaNode, exists := p.reverseTranslator.GetMediaMapping()[id]; exists {
+		if p.reverseTranslator.MediaChanged(id) {
+			p.warn(WarningPreservedNodeChanged, "attachment %s changed since the markdown was generated", id)
+		}
+		p.appendAttachment(doc, p.reverseTranslator.AttachmentID(id), mediaNode, params, node)
+		return
+	}
+
+	if p.missingAttachment == nil {
+		p.warnAt(WarningUnknownAttachment, node.StartByte(), node.EndByte(), "attachment %s is unknown, kept as text", id)
+		text := adf.NewTextNode(strings.TrimSpace(string(content[node.StartByte():node.EndByte()])))
+		p.track(text, node.StartByte(), node.EndByte())
+		paragraph := adf.NewParagraphNode()
+		paragraph.Content = append(paragraph.Content, text)
+		p.track(paragraph, node.StartByte(), node.EndByte())
+		doc.Content = append(doc.Content, paragraph)
+		return
+	}
+
+	block, err := p.missingAttachment(id)
+	switch {
+	case err != nil:
+		if p.err == nil {
+			p.err = fmt.Errorf("attachment %s: %w", id, err)
+		}
+	case block == nil:
+		p.warnAt(WarningDroppedContent, node.StartByte(), node.EndByte(), "attachment %s is unknown, dropped", id)
+	default:
+		if params.isZero() {
+			block = p.reuse(block)
+		} else {
+			block = block.Clone()
+			params.apply(block)
+		}
+		p.track(block, node.StartByte(), node.EndByte())
+		doc.Content = append(doc.Content, block)
+	}
+}
+
+// appendAttachment appends the preserved container of the attachment id to
+// doc. A media group is preserved under the id of each of its media, so
+// consecutive references to the same group are gathered into one group again;
+// if all of its media are referenced in their order without parameters, that
+// is the preserved group itself.
+func (p *Translator) appendAttachment(doc *adf.ADFDocument, id string, container *adf.ADFNode, params attachmentParams, node *sitter.Node) {
+	if container.Type != adf.NodeMediaGroup {
+		if params.isZero() {
+			container = p.reuse(container)
+		} else {
+			container = container.Clone()
+			params.apply(container)
+		}
+		p.track(container, node.StartByte(), node.EndByte())
+		doc.Content = append(doc.Content, container)
+		return
+	}
+
+	media := groupMedia(container, id)
+	if media == nil {
+		return
+	}
+	if params.isZero() {
+		media = p.reuse(media)
+	} else {
+		media = media.Clone()
+		params.apply(media)
+	}
+
+	group := p.openGroup(doc, container)
+	if group == nil {
+		group = &adf.ADFNode{Type: adf.NodeMediaGroup, Attrs: maps.Clone(container.Attrs)}
+		p.groups[group] = container
+		p.track(group, node.StartByte(), node.EndByte())
+		doc.Content = append(doc.Content, group)
+	} else if r, ok := p.ranges[group]; ok {
+		p.ranges[group] = sourceRange{start: r.start, end: int(node.EndByte())}
+	}
+	group.Content = append(group.Content, media)
+
+	if !slices.Equal(group.Content, container.Content) {
+		return
+	}
+
+	// The group is complete, so its preserved original replaces it. Its media
+	// are all used here for the first time, so the group is too
+	delete(p.groups, group)
+	p.reused[container] = true
+	if r, ok := p.ranges[group]; ok {
+		p.ranges[container] = r
+	}
+	doc.Content[len(doc.Content)-1] = container
+}
+
+// openGroup returns the group rebuilt from container that ends doc, if any.
+func (p *Translator) openGroup(doc *adf.ADFDocument, container *adf.ADFNode) *adf.ADFNode {
+	if len(doc.Content) == 0 {
+		return nil
+	}
+
+	last := doc.Content[len(doc.Content)-1]
+	if p.groups[last] != container {
+		return nil
+	}
+	return last
+}
+
+// reuse returns a preserved node to put in the document: the node itself the
+// first time the translation uses it, a copy after that. The document then
+// holds no node twice, and every use has a source range of its own.
+func (p *Translator) reuse(n *adf.ADFNode) *adf.ADFNode {
+	subtree := &adf.ADFDocument{Content: []*adf.ADFNode{n}}
+
+	used := false
+	adf.Walk(subtree, func(d *adf.ADFNode, _ int) bool {
+		used = used || p.reused[d]
+		return !used
+	})
+	if used {
+		return n.Clone()
+	}
+
+	adf.Walk(subtree, func(d *adf.ADFNode, _ int) bool {
+		p.reused[d] = true
+		return true
+	})
+	return n
+}
+
+// groupMedia returns the media node of group with the given id or URL.
+func groupMedia(group *adf.ADFNode, id string) *adf.ADFNode {
+	for _, media := range group.Content {
+		attrs, _ := media.MediaAttrs()
+		if attrs.ID == id || attrs.ID == "" && attrs.URL == id {
+			return media
+		}
+	}
+	return nil
+}
+
+// captionStart and captionEnd enclose the caption of an image or attachment,
+// written on the line below it.
+const captionStart, captionEnd = "{caption}", "{/caption}"
+
+// attachCaptions moves "{caption}...{/caption}" paragraphs into the media
+// single right before them. The markdown has the final say: a caption of a
+// preserved media single is replaced, or dropped when no paragraph follows.
+// Media singles are copied rather than changed, as they may be preserved.
+func (p *Translator) attachCaptions(blocks []*adf.ADFNode) []*adf.ADFNode {
+	out := make([]*adf.ADFNode, 0, len(blocks))
+	for i := 0; i < len(blocks); i++ {
+		block := blocks[i]
+		if block.Type != adf.NodeMediaSingle {
+			out = append(out, block)
+			continue
+		}
+
+		var caption *adf.ADFNode
+		if i+1 < len(blocks) {
+			caption = captionOf(blocks[i+1])
+		}
+		hadCaption := slices.ContainsFunc(block.Content, isCaption)
+		if caption == nil && !hadCaption {
+			out = append(out, block)
+			continue
+		}
+
+		captioned := *block
+		captioned.Content = slices.DeleteFunc(slices.Clone(block.Content), isCaption)
+		if r, ok := p.ranges[block]; ok {
+			p.ranges[&captioned] = r
+		}
+		if caption != nil {
+			captioned.Content = append(captioned.Content, caption)
+			if r, ok := p.ranges[blocks[i+1]]; ok {
+				p.ranges[caption] = r
+				p.ranges[&captioned] = sourceRange{start: p.ranges[&captioned].start, end: r.end}
+			}
+			i++
+		}
+		out = append(out, &captioned)
+	}
+	return out
+}
+
+// captionOf returns the caption a paragraph stands for, nil if it isn't one.
+func captionOf(block *adf.ADFNode) *adf.ADFNode {
+	if block.Type != adf.NodeParagraph {
+		return nil
+	}
+
+	// A caption below an image continues the image's paragraph, so it
+	// starts with the line break turned space
+	content := slices.Clone(block.Content)
+	if len(content) > 0 && content[0].Type == adf.ChildNodeText {
+		first := *content[0]
+		first.Text = strings.TrimLeft(first.Text, " \t\n")
+		content[0] = &first
+	}
+
+	content, ok := cutTextPrefix(content, captionStart)
+	if !ok {
+		return nil
+	}
+	content, ok = cutTextSuffix(content, captionEnd)
+	if !ok {
+		return nil
+	}
+
+	caption := adf.NewCaptionNode()
+	caption.Content = append(caption.Content, content...)
+	return caption
+}
+
+// isCaption reports whether n is a caption node.
+func isCaption(n *adf.ADFNode) bool {
+	return n != nil && n.Type == adf.NodeCaption
+}
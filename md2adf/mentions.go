@@ -0,0 +1,153 @@
+package md2adf
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/jorres/md2adf-translator/adf"
+	sitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+// WithMentionsDisabled keeps "@" mentions as the text they are written as,
+// for content where email-like tokens, such as Python decorators or social
+// media handles, aren't meant to mention anyone.
+func WithMentionsDisabled() TranslatorOption {
+	return func(tr *Translator) {
+		tr.mentionsDisabled = true
+	}
+}
+
+// WithMentionDomainAllowlist converts only the mentions of emails in one of
+// domains or their subdomains, matched regardless of case; the others stay
+// text. A nil list allows every domain again.
+func WithMentionDomainAllowlist(domains []string) TranslatorOption {
+	return func(tr *Translator) {
+		tr.mentionDomains = domains
+	}
+}
+
+// mentionAllowed reports whether a mention of email is converted to a
+// mention rather than kept as text.
+func (p *Translator) mentionAllowed(email string) bool {
+	if p.mentionsDisabled {
+		return false
+	}
+	if p.mentionDomains == nil {
+		return true
+	}
+	_, domain, _ := strings.Cut(email, "@")
+	domain = strings.ToLower(domain)
+	for _, allowed := range p.mentionDomains {
+		allowed = strings.ToLower(allowed)
+		if domain == allowed || strings.HasSuffix(domain, "."+allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// Mention is a user mention found in markdown.
+type Mention struct {
+	Email string
+	// AccountID is the account ID of the user from the user mapping or
+	// resolver, empty if neither knows the email.
+	AccountID string
+	// Start and End are the byte offsets [Start, End) of the mention in the
+	// markdown.
+	Start, End int
+}
+
+// ExtractMentions returns the mentions in markdown content in document order,
+// without converting it to ADF. Like in a translation, emails inside code
+// spans and code blocks, and those the mention options keep as text, are not
+// mentions.
+func (p *Translator) ExtractMentions(content []byte) ([]Mention, error) {
+	tree, err := p.parse(content)
+	if err != nil {
+		return nil, err
+	}
+	defer tree.Close()
+
+	var mentions []Mention
+
+	// collectInline collects the mentions of an inline tree, which are
+	// relative to base
+	var collectInline func(node *sitter.Node, base uint)
+	collectInline = func(node *sitter.Node, base uint) {
+		switch node.Kind() {
+		case "code_span":
+			return
+		case "people_mention":
+			start, end := base+node.StartByte(), base+node.EndByte()
+			email, rest := splitMention(string(content[start:end]))
+			if !p.mentionAllowed(email) {
+				return
+			}
+			end -= uint(len(rest))
+			accountID, _ := p.resolveUser(email)
+			mentions = append(mentions, Mention{Email: email, AccountID: accountID, Start: int(start), End: int(end)})
+			return
+		}
+		for i := range int(node.ChildCount()) {
+			collectInline(node.Child(uint(i)), base)
+		}
+	}
+
+	var collect func(node *sitter.Node)
+	collect = func(node *sitter.Node) {
+		switch node.Kind() {
+		case "fenced_code_block", "indented_code_block":
+			return
+		case "inline", "pipe_table_cell":
+			if inlineTree := p.inlineTree(node, content); inlineTree != nil {
+				collectInline(inlineTree.RootNode(), node.StartByte())
+				inlineTree.Close()
+			}
+			return
+		}
+		for i := range int(node.ChildCount()) {
+			collect(node.Child(uint(i)))
+		}
+	}
+	collect(tree.RootNode())
+
+	return mentions, nil
+}
+
+// splitMention splits the text of a people_mention node into the email and
+// the sentence punctuation the grammar takes in after it, as in
+// "@jane@example.com,".
+func splitMention(text string) (email, rest string) {
+	email = strings.TrimRight(strings.TrimPrefix(text, "@"), ".,;:!?)]}'\"")
+	return email, text[1+len(email):]
+}
+
+// processMention converts a people_mention node to a mention of the user's
+// account, handling users without one by the mention policy.
+func (p *Translator) processMention(node *sitter.Node, inlineContent []byte, parent *adf.ADFNode) {
+	email, rest := splitMention(string(inlineContent[node.StartByte():node.EndByte()]))
+	start, end := node.StartByte(), node.EndByte()-uint(len(rest))
+
+	userID, ok := p.resolveUser(email)
+	if !ok {
+		userID = "@" + email // fallback to the mention text if not found
+		p.warnAt(WarningUnresolvedMention, p.inlineBase+start, p.inlineBase+end, "mention of %s has no account id", email)
+		if !slices.Contains(p.unresolved, email) {
+			p.unresolved = append(p.unresolved, email)
+		}
+	}
+
+	if !ok && p.mentionPolicy == MentionPlainText {
+		p.appendInline(parent, adf.NewTextNode("@"+email), start, end)
+	} else {
+		displayText := MentionLocalPart
+		if p.mentionDisplay != nil {
+			displayText = p.mentionDisplay
+		}
+		p.appendInline(parent, adf.NewMentionNode(userID, displayText(email)), start, end)
+	}
+
+	if rest != "" {
+		p.appendText(parent, rest, end, node.EndByte())
+	}
+}
@@ -0,0 +1,298 @@
+package md2adf
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jorres/md2adf-translator/adf"
+	"github.com/jorres/md2adf-translator/adf2md"
+)
+
+// mentionIDs returns the ids of the mentions in the first paragraph of doc.
+func mentionIDs(doc *adf.ADFDocument) []string {
+	var ids []string
+	for _, n := range doc.Content[0].Content {
+		if n.Type == adf.InlineNodeMention {
+			ids = append(ids, n.Attrs["id"].(string))
+		}
+	}
+	return ids
+}
+
+func TestUserResolver(t *testing.T) {
+	var looked []string
+	resolver := WithUserResolver(func(email string) (string, bool) {
+		looked = append(looked, email)
+		if email == "lazy@example.com" {
+			return "lazy-id", true
+		}
+		return "", false
+	})
+	mapping := WithUserEmailMapping(map[string]string{"mapped@example.com": "mapped-id", "lazy@example.com": "static-id"})
+
+	translator := NewTranslator(resolver)
+	doc, err := translator.TranslateToADF([]byte("Hi @lazy@example.com and @nobody@example.com"))
+	if err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+	if ids := mentionIDs(doc); !slices.Equal(ids, []string{"lazy-id", "@nobody@example.com"}) {
+		t.Errorf("Expected the resolved id and the email fallback, got %v", ids)
+	}
+	warnings := translator.Warnings()
+	if len(warnings) != 1 || warnings[0].Code != WarningUnresolvedMention || warnings[0].Pos.String() != "1:26" {
+		t.Errorf("Expected one %s warning at 1:26, got %+v", WarningUnresolvedMention, warnings)
+	}
+
+	// The static mapping takes precedence and isn't looked up again
+	looked = nil
+	doc, err = NewTranslator(mapping, resolver).TranslateToADF([]byte("Hi @lazy@example.com and @mapped@example.com"))
+	if err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+	if ids := mentionIDs(doc); !slices.Equal(ids, []string{"static-id", "mapped-id"}) {
+		t.Errorf("Expected the mapped ids, got %v", ids)
+	}
+	if len(looked) != 0 {
+		t.Errorf("Expected no resolver lookups, got %v", looked)
+	}
+}
+
+func TestUnknownMentionPolicy(t *testing.T) {
+	markdown := []byte("Hi @known@example.com, @a@example.com and @b@example.com, again @a@example.com")
+	mapping := WithUserEmailMapping(map[string]string{"known@example.com": "known-id"})
+
+	doc, err := NewTranslator(mapping).TranslateToADF(markdown)
+	if err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+	if ids := mentionIDs(doc); !slices.Equal(ids, []string{"known-id", "@a@example.com", "@b@example.com", "@a@example.com"}) {
+		t.Errorf("Expected mention texts as ids by default, got %v", ids)
+	}
+
+	doc, err = NewTranslator(mapping, WithUnknownMentionPolicy(MentionPlainText)).TranslateToADF(markdown)
+	if err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+	if ids := mentionIDs(doc); !slices.Equal(ids, []string{"known-id"}) {
+		t.Errorf("Expected only the known mention, got %v", ids)
+	}
+	var texts string
+	for _, n := range doc.Content[0].Content {
+		texts += n.Text
+	}
+	if texts != "Hi , @a@example.com and @b@example.com, again @a@example.com" {
+		t.Errorf("Expected unknown mentions as text, got %q", texts)
+	}
+
+	doc, err = NewTranslator(mapping, WithUnknownMentionPolicy(MentionError)).TranslateToADF(markdown)
+	if doc != nil {
+		t.Errorf("Expected no document, got %+v", doc)
+	}
+	unresolved, ok := err.(*UnresolvedMentionsError)
+	if !ok || !slices.Equal(unresolved.Emails, []string{"a@example.com", "b@example.com"}) {
+		t.Fatalf("Expected both unresolved emails in one error, got %v", err)
+	}
+
+	if _, err := NewTranslator(mapping, WithUnknownMentionPolicy(MentionError)).TranslateToADF([]byte("Hi @known@example.com")); err != nil {
+		t.Errorf("Expected known mentions to pass, got %v", err)
+	}
+}
+
+func TestMentionDisplayText(t *testing.T) {
+	markdown := []byte("Hi @jane@example.com")
+	mapping := WithUserEmailMapping(map[string]string{"jane@example.com": "jane-id"})
+
+	tests := []struct {
+		name     string
+		opts     []TranslatorOption
+		expected string
+	}{
+		{name: "default", expected: "jane"},
+		{name: "local part", opts: []TranslatorOption{WithMentionDisplayText(MentionLocalPart)}, expected: "jane"},
+		{name: "full email", opts: []TranslatorOption{WithMentionDisplayText(MentionFullEmail)}, expected: "jane@example.com"},
+		{
+			name:     "custom",
+			opts:     []TranslatorOption{WithMentionDisplayText(func(email string) string { return "user " + MentionLocalPart(email) })},
+			expected: "user jane",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := NewTranslator(append(tt.opts, mapping)...).TranslateToADF(markdown)
+			if err != nil {
+				t.Fatalf("Failed to convert markdown: %v", err)
+			}
+			if ids := mentionIDs(doc); !slices.Equal(ids, []string{"jane-id"}) {
+				t.Errorf("Expected the mapped id, got %v", ids)
+			}
+			mention := doc.Content[0].Content[len(doc.Content[0].Content)-1]
+			if mention.Attrs["text"] != tt.expected {
+				t.Errorf("Expected display text %q, got %v", tt.expected, mention.Attrs["text"])
+			}
+		})
+	}
+}
+
+func TestExtractMentions(t *testing.T) {
+	markdown := "Hi @jane@example.com, see `@code@example.com`\n\n" +
+		"```\n@block@example.com\n```\n\n" +
+		"- ask @joe@example.com\n\n" +
+		"| who |\n|---|\n| @cell@example.com |\n"
+
+	translator := NewTranslator(WithUserEmailMapping(map[string]string{"jane@example.com": "jane-id"}))
+	mentions, err := translator.ExtractMentions([]byte(markdown))
+	if err != nil {
+		t.Fatalf("Failed to extract mentions: %v", err)
+	}
+
+	expected := []struct{ email, accountID string }{
+		{"jane@example.com", "jane-id"},
+		{"joe@example.com", ""},
+		{"cell@example.com", ""},
+	}
+	if len(mentions) != len(expected) {
+		t.Fatalf("Expected %d mentions, got %+v", len(expected), mentions)
+	}
+	for i, e := range expected {
+		m := mentions[i]
+		if m.Email != e.email || m.AccountID != e.accountID {
+			t.Errorf("Mention %d: expected %s (%q), got %+v", i, e.email, e.accountID, m)
+		}
+		if !strings.Contains(markdown[m.Start:m.End], e.email) {
+			t.Errorf("Mention %d: range [%d, %d) doesn't cover %s", i, m.Start, m.End, e.email)
+		}
+	}
+
+	// The same mentions as a full translation finds
+	doc, err := translator.TranslateToADF([]byte(markdown))
+	if err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+	var translated int
+	var count func(nodes []*adf.ADFNode)
+	count = func(nodes []*adf.ADFNode) {
+		for _, n := range nodes {
+			if n.Type == adf.InlineNodeMention {
+				translated++
+			}
+			count(n.Content)
+		}
+	}
+	count(doc.Content)
+	if translated != len(mentions) {
+		t.Errorf("Expected %d mentions like the translation, got %d", translated, len(mentions))
+	}
+}
+
+func TestMentionSpacingRoundtrip(t *testing.T) {
+	reverse := adf2md.NewTranslator(adf2md.NewJiraMarkdownTranslator(adf2md.WithUserEmailResolver(func(id string) string {
+		if id == "jane-id" {
+			return "jane@example.com"
+		}
+		return ""
+	})))
+	translator := NewTranslator(WithUserEmailMapping(map[string]string{"jane@example.com": "jane-id"}), WithAdf2MdTranslator(reverse))
+
+	tests := []struct {
+		name     string
+		markdown string
+	}{
+		{"start of line", "@jane@example.com will review"},
+		{"before a comma", "Hello @jane@example.com, thanks"},
+		{"in parentheses", "Reviewed (by @jane@example.com)."},
+		{"end of sentence", "Ask @jane@example.com."},
+		{"end of paragraph", "Ask @jane@example.com"},
+		{"before formatting", "Ask @jane@example.com **now**"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := translator.TranslateToADF([]byte(tt.markdown))
+			if err != nil {
+				t.Fatalf("Failed to convert markdown: %v", err)
+			}
+			markdown, err := translator.TranslateToMarkdown(doc)
+			if err != nil {
+				t.Fatalf("Failed to render markdown: %v", err)
+			}
+			if markdown != tt.markdown+"\n\n" {
+				t.Errorf("Expected %q back, got %q", tt.markdown+"\n\n", markdown)
+			}
+
+			result, err := translator.TranslateToADF([]byte(markdown))
+			if err != nil {
+				t.Fatalf("Failed to convert %q: %v", markdown, err)
+			}
+			if !adf.EqualDocuments(doc, result) {
+				jsonBytes, _ := json.MarshalIndent(result, "", "  ")
+				t.Errorf("Expected the document back from %q, got:\n%s", markdown, string(jsonBytes))
+			}
+		})
+	}
+}
+
+func TestMentionsKeptAsText(t *testing.T) {
+	markdown := "Ping @jane@example.com and @handle@twitter.example, or @ops@eu.Example.com"
+
+	tests := []struct {
+		name     string
+		opts     []TranslatorOption
+		mentions []string
+		text     string
+	}{
+		{
+			name:     "all mentions",
+			mentions: []string{"@jane@example.com", "@handle@twitter.example", "@ops@eu.Example.com"},
+			text:     "Ping  and , or ",
+		},
+		{
+			name: "disabled",
+			opts: []TranslatorOption{WithMentionsDisabled()},
+			text: markdown,
+		},
+		{
+			name:     "domain allowlist",
+			opts:     []TranslatorOption{WithMentionDomainAllowlist([]string{"EXAMPLE.com"})},
+			mentions: []string{"@jane@example.com", "@ops@eu.Example.com"},
+			text:     "Ping  and @handle@twitter.example, or ",
+		},
+		{
+			name: "empty allowlist",
+			opts: []TranslatorOption{WithMentionDomainAllowlist([]string{})},
+			text: markdown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			translator := NewTranslator(tt.opts...)
+			doc, err := translator.TranslateToADF([]byte(markdown))
+			if err != nil {
+				t.Fatalf("Failed to convert markdown: %v", err)
+			}
+
+			if ids := mentionIDs(doc); !slices.Equal(ids, tt.mentions) {
+				t.Errorf("Expected mentions %q, got %q", tt.mentions, ids)
+			}
+			var text strings.Builder
+			for _, n := range doc.Content[0].Content {
+				text.WriteString(n.Text)
+			}
+			if text.String() != tt.text {
+				jsonBytes, _ := json.MarshalIndent(doc, "", "  ")
+				t.Errorf("Expected text %q, got:\n%s", tt.text, string(jsonBytes))
+			}
+
+			extracted, err := translator.ExtractMentions([]byte(markdown))
+			if err != nil {
+				t.Fatalf("Failed to extract mentions: %v", err)
+			}
+			if len(extracted) != len(tt.mentions) {
+				t.Errorf("Expected %d extracted mentions, got %+v", len(tt.mentions), extracted)
+			}
+		})
+	}
+}
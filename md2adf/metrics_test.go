@@ -0,0 +1,118 @@
+package md2adf
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jorres/md2adf-translator/adf"
+)
+
+type recordingCollector struct {
+	observations map[string][]float64
+	nodeCounts   map[string]float64
+}
+
+func (c *recordingCollector) Observe(name string, value float64, labels map[string]string) {
+	if labels[adf.LabelDirection] != adf.DirectionMarkdownToADF {
+		return
+	}
+	c.observations[name] = append(c.observations[name], value)
+	if name == adf.MetricNodesProduced {
+		c.nodeCounts[labels[adf.LabelNodeType]] = value
+	}
+}
+
+func TestTranslateMetrics(t *testing.T) {
+	collector := &recordingCollector{
+		observations: make(map[string][]float64),
+		nodeCounts:   make(map[string]float64),
+	}
+	translator := NewTranslator(WithMetrics(collector))
+
+	markdown := "# Title\n\nSome **bold** text.\n\n- one\n- two"
+	if _, err := translator.TranslateToADF([]byte(markdown)); err != nil {
+		t.Fatalf("Translation failed: %v", err)
+	}
+
+	if got := collector.observations[adf.MetricDocumentsConverted]; len(got) != 1 || got[0] != 1 {
+		t.Errorf("Expected one converted document, got %v", got)
+	}
+	if got := collector.observations[adf.MetricInputBytes]; len(got) != 1 || got[0] != float64(len(markdown)) {
+		t.Errorf("Expected input size %d, got %v", len(markdown), got)
+	}
+	if got := collector.observations[adf.MetricConversionDuration]; len(got) != 1 || got[0] < 0 {
+		t.Errorf("Expected one non-negative duration, got %v", got)
+	}
+
+	expectedNodes := map[string]float64{
+		"heading":    1,
+		"paragraph":  3,
+		"bulletList": 1,
+		"listItem":   2,
+	}
+	for nodeType, expected := range expectedNodes {
+		if got := collector.nodeCounts[nodeType]; got != expected {
+			t.Errorf("Expected %v %s nodes, got %v", expected, nodeType, got)
+		}
+	}
+}
+
+func TestTranslateMetricsOnError(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []TranslatorOption
+		markdown string
+	}{
+		{
+			name:     "input too large",
+			opts:     []TranslatorOption{WithMaxInputSize(5)},
+			markdown: "Longer than five bytes",
+		},
+		{
+			name:     "warnings in strict mode",
+			opts:     []TranslatorOption{WithStrictMode()},
+			markdown: "Before\n\n***\n\nAfter",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collector := &recordingCollector{
+				observations: make(map[string][]float64),
+				nodeCounts:   make(map[string]float64),
+			}
+			translator := NewTranslator(append(tt.opts, WithMetrics(collector))...)
+			defer translator.Close()
+
+			if _, err := translator.TranslateToADF([]byte(tt.markdown)); err == nil {
+				t.Fatal("Expected the translation to fail")
+			}
+			if got := collector.observations[adf.MetricDocumentsConverted]; len(got) != 1 {
+				t.Errorf("Expected one converted document, got %v", got)
+			}
+			if got := collector.observations[adf.MetricConversionDuration]; len(got) != 1 {
+				t.Errorf("Expected one duration, got %v", got)
+			}
+			if got := collector.observations[adf.MetricConversionErrors]; len(got) != 1 || got[0] != 1 {
+				t.Errorf("Expected one conversion error, got %v", got)
+			}
+		})
+	}
+
+	// An aborted translation is observed too
+	collector := &recordingCollector{
+		observations: make(map[string][]float64),
+		nodeCounts:   make(map[string]float64),
+	}
+	translator := NewTranslator(WithMetrics(collector))
+	defer translator.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := translator.TranslateToADFContext(ctx, []byte("Text")); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if got := collector.observations[adf.MetricConversionErrors]; len(got) != 1 {
+		t.Errorf("Expected one conversion error, got %v", got)
+	}
+}
@@ -3,6 +3,7 @@ package md2adf
 import (
 	"encoding/json"
 	"github.com/jorres/md2adf-translator/adf"
+	"github.com/jorres/md2adf-translator/adf2md"
 	"testing"
 )
 
@@ -86,3 +87,123 @@ TODO
 	}
 }
 
+
+// TestPanelWithOnlyCodeBlockRoundtrip makes sure the closing fence and the
+// panel end marker stay apart through repeated roundtrips.
+func TestPanelWithOnlyCodeBlockRoundtrip(t *testing.T) {
+	markdown := "{panel:type=error}\n```\nstack trace\n  at main.go:12\n```\n{/panel}"
+
+	for cycle := 1; cycle <= 2; cycle++ {
+		doc, err := NewTranslator().TranslateToADF([]byte(markdown))
+		if err != nil {
+			t.Fatalf("Cycle %d: failed to convert markdown: %v", cycle, err)
+		}
+
+		if len(doc.Content) != 1 || doc.Content[0].Type != adf.NodePanel {
+			jsonBytes, _ := json.MarshalIndent(doc, "", "  ")
+			t.Fatalf("Cycle %d: expected a single panel from %q, got:\n%s", cycle, markdown, string(jsonBytes))
+		}
+		panel := doc.Content[0]
+		if panel.Attrs["panelType"] != "error" || len(panel.Content) != 1 || panel.Content[0].Type != adf.NodeCodeBlock {
+			jsonBytes, _ := json.MarshalIndent(panel, "", "  ")
+			t.Fatalf("Cycle %d: expected an error panel holding one code block, got:\n%s", cycle, string(jsonBytes))
+		}
+		code := panel.Content[0].Content
+		if len(code) != 1 || code[0].Text != "stack trace\n  at main.go:12" {
+			jsonBytes, _ := json.MarshalIndent(panel.Content[0], "", "  ")
+			t.Fatalf("Cycle %d: unexpected code block content:\n%s", cycle, string(jsonBytes))
+		}
+
+		reverse := adf2md.NewTranslator(adf2md.NewJiraMarkdownTranslator())
+		markdown = reverse.MustTranslate(&adf.ADFNode{Type: "doc", Content: doc.Content})
+	}
+}
+
+func TestCustomPanelRoundtrip(t *testing.T) {
+	panel := adf.NewPanelNode(adf.PanelCustom)
+	panel.Attrs["panelIcon"] = ":star:"
+	panel.Attrs["panelColor"] = "#ff0000"
+	paragraph := adf.NewParagraphNode()
+	paragraph.Content = append(paragraph.Content, adf.NewTextNode("Starred"))
+	panel.Content = append(panel.Content, paragraph)
+
+	markdown := adf2md.NewTranslator(adf2md.NewJiraMarkdownTranslator()).MustTranslate(&adf.ADFNode{Type: "doc", Content: []*adf.ADFNode{panel}})
+
+	doc, err := NewTranslator().TranslateToADF([]byte(markdown))
+	if err != nil {
+		t.Fatalf("Failed to translate %q: %v", markdown, err)
+	}
+	if len(doc.Content) != 1 || !adf.Equal(doc.Content[0], panel) {
+		jsonBytes, _ := json.MarshalIndent(doc, "", "  ")
+		t.Fatalf("Expected the custom panel back from %q, got:\n%s", markdown, string(jsonBytes))
+	}
+}
+
+func TestUnknownPanelType(t *testing.T) {
+	translator := NewTranslator()
+	doc, err := translator.TranslateToADF([]byte("{panel:type=tip}\nHint\n\n{/panel}"))
+	if err != nil {
+		t.Fatalf("Failed to translate: %v", err)
+	}
+
+	if len(doc.Content) != 1 || doc.Content[0].Attrs["panelType"] != adf.PanelInfo {
+		jsonBytes, _ := json.MarshalIndent(doc, "", "  ")
+		t.Fatalf("Expected an info panel, got:\n%s", string(jsonBytes))
+	}
+	warnings := translator.Warnings()
+	if len(warnings) != 1 || warnings[0].Code != WarningUnknownPanelType {
+		t.Errorf("Expected an unknown panel type warning, got %v", warnings)
+	}
+}
+
+func TestPanelWithTableRoundtrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+	}{
+		{
+			name:     "table only",
+			markdown: "{panel:type=warning}\n| A | B | C |\n|---|---|---|\n| 1 | 2 | 3 |\n\n{/panel}\n",
+		},
+		{
+			name:     "table between paragraphs",
+			markdown: "{panel:type=warning}\nBefore\n\n| A | B | C |\n|---|---|---|\n| 1 | 2 | 3 |\n\nAfter\n\n{/panel}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			markdown := tt.markdown
+			var first *adf.ADFDocument
+			for cycle := range 2 {
+				doc, err := NewTranslator().TranslateToADF([]byte(markdown))
+				if err != nil {
+					t.Fatalf("Cycle %d: failed to translate: %v", cycle, err)
+				}
+
+				if len(doc.Content) != 1 || doc.Content[0].Type != adf.NodePanel || doc.Content[0].Attrs["panelType"] != "warning" {
+					jsonBytes, _ := json.MarshalIndent(doc, "", "  ")
+					t.Fatalf("Cycle %d: expected a single warning panel from %q, got:\n%s", cycle, markdown, string(jsonBytes))
+				}
+				var table *adf.ADFNode
+				for _, n := range doc.Content[0].Content {
+					if n.Type == adf.NodeTable {
+						table = n
+					}
+				}
+				if table == nil || len(table.Content) != 2 || len(table.Content[1].Content) != 3 {
+					jsonBytes, _ := json.MarshalIndent(doc.Content[0], "", "  ")
+					t.Fatalf("Cycle %d: expected a 2x3 table in the panel, got:\n%s", cycle, string(jsonBytes))
+				}
+
+				if first == nil {
+					first = doc
+				} else if !adf.EqualDocuments(first, doc) {
+					t.Errorf("Cycle %d: document changed through the roundtrip of %q", cycle, markdown)
+				}
+
+				markdown = adf2md.NewTranslator(adf2md.NewJiraMarkdownTranslator()).MustTranslate(&adf.ADFNode{Type: "doc", Content: doc.Content})
+			}
+		})
+	}
+}
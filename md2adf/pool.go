@@ -0,0 +1,98 @@
+package md2adf
+
+import (
+	"context"
+	"runtime"
+	"sync"
+
+	"github.com/jorres/md2adf-translator/adf"
+)
+
+// TranslatorPool hands out Translators built with the same options to
+// goroutines translating concurrently. A Translator isn't safe for
+// concurrent use, and building one builds its parsers, which costs more than
+// translating a small document; the pool reuses them instead.
+//
+// The options are applied to every Translator of the pool, so what they
+// share, such as a user resolver, a metrics collector or the translator of
+// WithAdf2MdTranslator, is used from several goroutines at once. The latter
+// is only looked up in and must not translate while the pool is in use.
+//
+// The pool keeps at most GOMAXPROCS free Translators; those given back to a
+// full pool are closed, and so are those kept once the pool is closed.
+type TranslatorPool struct {
+	opts []TranslatorOption
+	free chan *Translator
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// NewTranslatorPool returns a pool of Translators built with opts.
+func NewTranslatorPool(opts ...TranslatorOption) *TranslatorPool {
+	return &TranslatorPool{
+		opts: opts,
+		free: make(chan *Translator, runtime.GOMAXPROCS(0)),
+	}
+}
+
+// Get returns a Translator of the pool for the caller's use alone, building
+// one if none is free. Give it back with Put once done with it and its
+// Warnings.
+func (tp *TranslatorPool) Get() *Translator {
+	select {
+	case tr := <-tp.free:
+		return tr
+	default:
+		return NewTranslator(tp.opts...)
+	}
+}
+
+// Put gives back a Translator got from Get. The caller must not use it
+// afterwards.
+func (tp *TranslatorPool) Put(tr *Translator) {
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+	if tp.closed {
+		tr.Close()
+		return
+	}
+	select {
+	case tp.free <- tr:
+	default:
+		tr.Close()
+	}
+}
+
+// Close closes the free Translators of the pool. Translators still in use
+// are closed as they are given back; Get keeps building new ones, which the
+// pool no longer keeps.
+func (tp *TranslatorPool) Close() {
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+	tp.closed = true
+	for {
+		select {
+		case tr := <-tp.free:
+			tr.Close()
+		default:
+			return
+		}
+	}
+}
+
+// TranslateToADF translates markdown to an ADF document with a Translator of
+// the pool. It is safe for concurrent use.
+func (tp *TranslatorPool) TranslateToADF(content []byte) (*adf.ADFDocument, error) {
+	tr := tp.Get()
+	defer tp.Put(tr)
+	return tr.TranslateToADF(content)
+}
+
+// TranslateToADFContext is TranslateToADF aborting with the error of ctx once
+// it is done, as Translator.TranslateToADFContext does.
+func (tp *TranslatorPool) TranslateToADFContext(ctx context.Context, content []byte) (*adf.ADFDocument, error) {
+	tr := tp.Get()
+	defer tp.Put(tr)
+	return tr.TranslateToADFContext(ctx, content)
+}
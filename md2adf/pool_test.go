@@ -0,0 +1,137 @@
+package md2adf
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// TestTranslatorPoolConcurrent translates documents from many goroutines at
+// once; run with -race to catch state shared between the Translators.
+func TestTranslatorPoolConcurrent(t *testing.T) {
+	inputs := []string{
+		"# Title\n\nSome **bold** and *emphasis* with a [link](https://example.com).",
+		"- one\n- two\n  - nested\n\n```go\nfmt.Println(1)\n```",
+		"| a | b |\n|---|---|\n| `x` | @user@example.com |",
+		"Text with a [reference][ref].\n\n[ref]: https://example.com/ref",
+	}
+
+	expected := make([]string, len(inputs))
+	for i, input := range inputs {
+		doc, err := NewTranslator().TranslateToADF([]byte(input))
+		if err != nil {
+			t.Fatalf("Failed to convert markdown: %v", err)
+		}
+		out, _ := json.Marshal(doc)
+		expected[i] = string(out)
+	}
+
+	pool := NewTranslatorPool()
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 50 {
+				n := (g + i) % len(inputs)
+				doc, err := pool.TranslateToADF([]byte(inputs[n]))
+				if err != nil {
+					errs <- err
+					return
+				}
+				if out, _ := json.Marshal(doc); string(out) != expected[n] {
+					errs <- fmt.Errorf("input %d: expected %s, got %s", n, expected[n], out)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestTranslatorPoolGetPut(t *testing.T) {
+	pool := NewTranslatorPool(WithBoldTableHeaders(true))
+
+	translator := pool.Get()
+	if !translator.boldTableHeaders {
+		t.Errorf("Expected the pool's options to be applied")
+	}
+	if _, err := translator.TranslateToADF([]byte("Before\n\n***\n\nAfter")); err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+	if len(translator.Warnings()) != 1 {
+		t.Fatalf("Expected one warning, got %+v", translator.Warnings())
+	}
+	pool.Put(translator)
+
+	// A Translator given back starts the next translation afresh
+	translator = pool.Get()
+	defer pool.Put(translator)
+	if _, err := translator.TranslateToADF([]byte("Plain")); err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+	if warnings := translator.Warnings(); len(warnings) != 0 {
+		t.Errorf("Expected no warnings, got %+v", warnings)
+	}
+}
+
+// TestTranslatorPoolRepeated translates the same document over and over
+// through a pool, more at once than it keeps, so that Translators are both
+// reused and closed on the way back.
+func TestTranslatorPoolRepeated(t *testing.T) {
+	markdown := []byte("Hi @jane@example.com\n\n| a | b |\n|---|---|\n| *x* | @joe@example.com |\n\n- one\n- two")
+	pool := NewTranslatorPool(WithUserEmailMapping(map[string]string{"jane@example.com": "jane-id"}))
+
+	doc, err := pool.TranslateToADF(markdown)
+	if err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+	expected, _ := json.Marshal(doc)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*cap(pool.free))
+	for range 2 * cap(pool.free) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 20 {
+				translator := pool.Get()
+				doc, err := translator.TranslateToADF(markdown)
+				if err == nil {
+					if out, _ := json.Marshal(doc); string(out) != string(expected) {
+						err = fmt.Errorf("expected %s, got %s", expected, out)
+					}
+				}
+				if mentions, _ := translator.ExtractMentions(markdown); err == nil && len(mentions) != 2 {
+					err = fmt.Errorf("expected 2 mentions, got %+v", mentions)
+				}
+				pool.Put(translator)
+				if err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	if n := len(pool.free); n == 0 || n > cap(pool.free) {
+		t.Errorf("Expected the pool to keep between 1 and %d Translators, got %d", cap(pool.free), n)
+	}
+	pool.Close()
+	pool.Put(pool.Get())
+	if n := len(pool.free); n != 0 {
+		t.Errorf("Expected a closed pool to keep no Translators, got %d", n)
+	}
+}
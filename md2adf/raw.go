@@ -0,0 +1,38 @@
+package md2adf
+
+import (
+	"fmt"
+
+	sitter "github.com/tree-sitter/go-tree-sitter"
+
+	"github.com/jorres/md2adf-translator/adf"
+)
+
+// RawBlockError reports an adf-raw code block whose JSON is not an ADF node.
+type RawBlockError struct {
+	Pos adf.Position // of the opening fence
+	Err error
+}
+
+func (e *RawBlockError) Error() string {
+	return fmt.Sprintf("adf-raw block at %s: %v", e.Pos, e.Err)
+}
+
+func (e *RawBlockError) Unwrap() error {
+	return e.Err
+}
+
+// convertRawBlock splices in the ADF node embedded as JSON in an adf-raw code
+// block, as adf2md writes the nodes it can't render in lossless mode. JSON
+// that is not a node fails the translation with a *RawBlockError.
+func (p *Translator) convertRawBlock(raw string, node *sitter.Node, content []byte) *adf.ADFNode {
+	block, err := adf.NodeFromJSON([]byte(raw))
+	if err != nil {
+		if p.err == nil {
+			p.err = &RawBlockError{Pos: adf.PositionAt(content, int(node.StartByte())), Err: err}
+		}
+		return nil
+	}
+	p.track(block, node.StartByte(), node.EndByte())
+	return block
+}
@@ -0,0 +1,67 @@
+package md2adf
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/jorres/md2adf-translator/adf"
+	"github.com/jorres/md2adf-translator/adf2md"
+)
+
+func TestRawBlockRoundtrip(t *testing.T) {
+	extension := &adf.ADFNode{
+		Type:    "bodiedExtension",
+		Attrs:   map[string]any{"extensionKey": "toc", "parameters": map[string]any{"depth": 2}},
+		Content: []*adf.ADFNode{adf.Paragraph(adf.Text("Inside")).Node()},
+	}
+	merged := adf.Table(
+		adf.Row(adf.HeaderCell(adf.Text("A")), adf.HeaderCell(adf.Text("B"))),
+		adf.Row(adf.Cell(adf.Text("Both"))),
+	).Node()
+	merged.Content[1].Content[0].Attrs = map[string]any{"colspan": 2}
+	doc := adf.NewDocBuilder().
+		Paragraph(adf.Text("Before")).
+		AppendNodes(extension, merged).
+		Paragraph(adf.Text("After")).
+		Build()
+
+	translator := NewTranslator(WithAdf2MdTranslator(adf2md.NewTranslator(adf2md.NewJiraMarkdownTranslator(), adf2md.WithLossless())))
+	markdown, err := translator.TranslateToMarkdown(doc)
+	if err != nil {
+		t.Fatalf("Failed to render markdown: %v", err)
+	}
+
+	result, err := translator.TranslateToADF([]byte(markdown))
+	if err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+	if !adf.EqualDocuments(doc, result) {
+		expected, _ := json.MarshalIndent(doc, "", "  ")
+		actual, _ := json.MarshalIndent(result, "", "  ")
+		t.Errorf("Expected the document back from:\n%s\nExpected:\n%s\nGot:\n%s", markdown, string(expected), string(actual))
+	}
+}
+
+func TestRawBlockError(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+	}{
+		{name: "corrupted JSON", markdown: "Intro\n\n```adf-raw\n{\"type\": \"extension\",\n```\n"},
+		{name: "not a node", markdown: "Intro\n\n```adf-raw\n{\"attrs\": {}}\n```\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewTranslator().TranslateToADF([]byte(tt.markdown))
+			var rawErr *RawBlockError
+			if !errors.As(err, &rawErr) {
+				t.Fatalf("Expected a *RawBlockError, got %v", err)
+			}
+			if rawErr.Pos.Line != 3 {
+				t.Errorf("Expected the error at line 3, got %v", rawErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package md2adf
+
+import (
+	"bytes"
+	"strings"
+
+	sitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+// referenceLinkKinds are the inline node kinds of links resolved against a
+// link reference definition: [text][label], [label][] and [label].
+var referenceLinkKinds = map[string]bool{
+	"full_reference_link":      true,
+	"collapsed_reference_link": true,
+	"shortcut_link":            true,
+}
+
+// collectReferences returns the targets of the link reference
+// definitions under node by their normalized labels. The first definition of
+// a label wins.
+func collectReferences(node *sitter.Node, content []byte) map[string]linkTarget {
+	references := make(map[string]linkTarget)
+	// Every definition has a "]:" after its label; without one there is no
+	// need to walk the tree
+	if !bytes.Contains(content[node.StartByte():node.EndByte()], []byte("]:")) {
+		return references
+	}
+
+	var walk func(n *sitter.Node)
+	walk = func(n *sitter.Node) {
+		if n.Kind() == "link_reference_definition" {
+			var label string
+			for i := range int(n.ChildCount()) {
+				if child := n.Child(uint(i)); child.Kind() == "link_label" {
+					label = normalizeLabel(string(content[child.StartByte():child.EndByte()]))
+				}
+			}
+			target := parseLinkTarget(n, content)
+			if _, exists := references[label]; !exists && label != "" && target.url != "" {
+				references[label] = target
+			}
+			return
+		}
+		for i := range int(n.ChildCount()) {
+			walk(n.Child(uint(i)))
+		}
+	}
+	walk(node)
+
+	return references
+}
+
+// normalizeLabel returns the form link labels are matched by: without the
+// brackets, case-folded and with runs of whitespace collapsed.
+func normalizeLabel(label string) string {
+	label = strings.TrimSuffix(strings.TrimPrefix(label, "["), "]")
+	return strings.ToLower(strings.Join(strings.Fields(label), " "))
+}
+
+// referenceLink returns the link text and target of a reference link
+// node. An undefined label of an explicit reference, [text][label] or
+// [label][], is warned about; a lone [label] is just bracketed text.
+func (p *Translator) referenceLink(node *sitter.Node, inlineContent []byte) (text *sitter.Node, target linkTarget, ok bool) {
+	var label string
+	for i := range int(node.ChildCount()) {
+		child := node.Child(uint(i))
+		switch child.Kind() {
+		case "link_text":
+			text = child
+			if label == "" {
+				label = string(inlineContent[child.StartByte():child.EndByte()])
+			}
+		case "link_label":
+			label = string(inlineContent[child.StartByte():child.EndByte()])
+		}
+	}
+	if text == nil {
+		return nil, linkTarget{}, false
+	}
+
+	target, ok = p.references[normalizeLabel(label)]
+	if !ok && node.Kind() != "shortcut_link" {
+		p.warnAt(WarningUnresolvedReference, p.inlineBase+node.StartByte(), p.inlineBase+node.EndByte(),
+			"link reference %s is not defined, kept as text", strings.TrimSpace(label))
+	}
+	return text, target, ok
+}
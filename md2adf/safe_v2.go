@@ -0,0 +1,170 @@
+package md2adf
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/jorres/md2adf-translator/adf"
+)
+
+// defaultV2UnsafeTypes are the node and mark types the v2 API can't represent.
+var defaultV2UnsafeTypes = map[adf.NodeType]bool{
+	adf.NodePanel:             true,
+	adf.NodeExpand:            true,
+	adf.NodeMedia:             true,
+	adf.NodeMediaGroup:        true,
+	adf.NodeMediaSingle:       true,
+	adf.NodeDecisionList:      true,
+	adf.ChildNodeDecisionItem: true,
+	adf.InlineNodeCard:        true,
+	adf.InlineNodeEmoji:       true,
+	adf.InlineNodeMention:     true,
+	adf.InlineNodeHardBreak:   true,
+	adf.InlineNodeStatus:      true,
+	adf.MarkUnderline:         true,
+}
+
+// WithV2UnsafeTypes replaces the node and mark types CheckSafeForV2 and
+// AnalyzeV2Safety report as unsafe.
+func WithV2UnsafeTypes(types ...adf.NodeType) TranslatorOption {
+	return func(tr *Translator) {
+		tr.v2UnsafeTypes = make(map[adf.NodeType]bool, len(types))
+		for _, t := range types {
+			tr.v2UnsafeTypes[t] = true
+		}
+	}
+}
+
+// WithExtraV2UnsafeTypes reports types as unsafe for V2 in addition to the
+// ones already considered unsafe.
+func WithExtraV2UnsafeTypes(types ...adf.NodeType) TranslatorOption {
+	return func(tr *Translator) {
+		for _, t := range types {
+			tr.v2UnsafeTypes[t] = true
+		}
+	}
+}
+
+// WithV2SafeTypes stops reporting types as unsafe for V2, e.g. mentions on
+// instances whose v2 API accepts them.
+func WithV2SafeTypes(types ...adf.NodeType) TranslatorOption {
+	return func(tr *Translator) {
+		for _, t := range types {
+			delete(tr.v2UnsafeTypes, t)
+		}
+	}
+}
+
+// V2UnsafeTypes returns the node and mark types considered unsafe for V2.
+func (p *Translator) V2UnsafeTypes() []adf.NodeType {
+	return slices.Sorted(maps.Keys(p.v2UnsafeTypes))
+}
+
+// maxSnippetLength is the number of runes a V2SafetyIssue snippet is cut to.
+const maxSnippetLength = 60
+
+// V2SafetyIssue is a node or mark in the markdown that can't be posted
+// through the v2 API.
+type V2SafetyIssue struct {
+	Type adf.NodeType
+	// Snippet is the markdown the node was produced from, shortened if long.
+	Snippet string
+	// Start and End are the byte offsets [Start, End) of the markdown the node
+	// was produced from. Where the parser doesn't expose the node's own span,
+	// the span of the closest enclosing node is used.
+	Start, End int
+	// Pos is the position of Start.
+	Pos adf.Position
+}
+
+// V2SafetyReport is the result of AnalyzeV2Safety.
+type V2SafetyReport struct {
+	Safe   bool
+	Issues []V2SafetyIssue // in document order
+}
+
+// UnsafeTypes returns the distinct types of the issues in the order they were
+// first found.
+func (r *V2SafetyReport) UnsafeTypes() []adf.NodeType {
+	var types []adf.NodeType
+	for _, issue := range r.Issues {
+		if !slices.Contains(types, issue.Type) {
+			types = append(types, issue.Type)
+		}
+	}
+	return types
+}
+
+// CheckSafeForV2 parses the markdown content into an ADF tree and checks if it contains
+// any node types that are not safe for V2 processing. Returns an error if unsafe nodes are found.
+func (p *Translator) CheckSafeForV2(body string) error {
+	report, err := p.AnalyzeV2Safety(body)
+	if err != nil {
+		return err
+	}
+
+	if !report.Safe {
+		return fmt.Errorf("unsafe node types found: %v", report.UnsafeTypes())
+	}
+
+	return nil
+}
+
+// AnalyzeV2Safety parses the markdown content into an ADF tree and reports
+// every node and mark in it that is not safe for V2 processing, along with
+// the part of the markdown it comes from.
+func (p *Translator) AnalyzeV2Safety(body string) (*V2SafetyReport, error) {
+	p.ranges = make(map[*adf.ADFNode]sourceRange)
+	defer func() { p.ranges = nil }()
+
+	doc, err := p.TranslateToADF([]byte(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse markdown: %w", err)
+	}
+
+	report := &V2SafetyReport{}
+
+	// spans[d] is the source span of the node last visited at depth d-1, the
+	// closest approximation for nodes at depth d without a span of their own
+	spans := []sourceRange{{start: 0, end: len(body)}}
+	adf.WalkMarks(doc, func(n *adf.ADFNode, depth int) bool {
+		spans = spans[:depth+1]
+		r, ok := p.ranges[n]
+		if !ok {
+			r = spans[depth]
+		}
+		spans = append(spans, r)
+
+		if p.v2UnsafeTypes[n.Type] {
+			report.Issues = append(report.Issues, newV2SafetyIssue(n.Type, body, r))
+		}
+		return true
+	}, func(m *adf.ADFMark, n *adf.ADFNode) {
+		if p.v2UnsafeTypes[m.Type] {
+			report.Issues = append(report.Issues, newV2SafetyIssue(m.Type, body, spans[len(spans)-1]))
+		}
+	})
+
+	report.Safe = len(report.Issues) == 0
+	return report, nil
+}
+
+// newV2SafetyIssue describes an unsafe node produced from the r span of body.
+func newV2SafetyIssue(nodeType adf.NodeType, body string, r sourceRange) V2SafetyIssue {
+	start, end := min(r.start, len(body)), min(r.end, len(body))
+
+	snippet := strings.TrimSpace(body[start:end])
+	if runes := []rune(snippet); len(runes) > maxSnippetLength {
+		snippet = string(runes[:maxSnippetLength]) + "…"
+	}
+
+	return V2SafetyIssue{
+		Type:    nodeType,
+		Snippet: snippet,
+		Start:   start,
+		End:     end,
+		Pos:     adf.PositionAt([]byte(body), start),
+	}
+}
@@ -1,8 +1,12 @@
 package md2adf
 
 import (
+	"slices"
 	"strings"
 	"testing"
+
+	"github.com/jorres/md2adf-translator/adf"
+	"github.com/jorres/md2adf-translator/adf2md"
 )
 
 func TestCheckSafeForV2(t *testing.T) {
@@ -30,12 +34,35 @@ func TestCheckSafeForV2(t *testing.T) {
 			expectError:         true,
 			expectedUnsafeTypes: []string{"underline"},
 		},
+		{
+			name:                "unsafe markdown - emoji",
+			markdown:            "Ship it :rocket:",
+			expectError:         true,
+			expectedUnsafeTypes: []string{"emoji"},
+		},
+		{
+			name:        "safe markdown - unknown shortcode",
+			markdown:    "Meet at 10:30:00 :notanemoji:",
+			expectError: false,
+		},
+		{
+			name:                "unsafe markdown - status",
+			markdown:            "Currently {status:color=blue}IN PROGRESS{/status}",
+			expectError:         true,
+			expectedUnsafeTypes: []string{"status"},
+		},
 		{
 			name:                "unsafe markdown - mention",
 			markdown:            "Hello @user@example.com",
 			expectError:         true,
 			expectedUnsafeTypes: []string{"mention"},
 		},
+		{
+			name:                "unsafe markdown - hard break",
+			markdown:            "First line\\\nsecond line",
+			expectError:         true,
+			expectedUnsafeTypes: []string{"hardBreak"},
+		},
 		{
 			name:                "unsafe markdown - panel",
 			markdown:            "{panel}\nThis is an info panel\n\n{/panel}",
@@ -85,3 +112,103 @@ func TestCheckSafeForV2(t *testing.T) {
 		})
 	}
 }
+
+func TestAnalyzeV2Safety(t *testing.T) {
+	markdown := "Intro paragraph\n\nHello @user@example.com and <u>you</u>\n\n{panel}\nInside\n\n{/panel}"
+
+	report, err := NewTranslator().AnalyzeV2Safety(markdown)
+	if err != nil {
+		t.Fatalf("Failed to analyze markdown: %v", err)
+	}
+	if report.Safe {
+		t.Fatalf("Expected the report to be unsafe")
+	}
+
+	expected := []struct {
+		nodeType adf.NodeType
+		line     int
+		contains string
+	}{
+		{adf.InlineNodeMention, 3, "@user@example.com"},
+		{adf.MarkUnderline, 3, "you"},
+		{adf.NodePanel, 5, "Inside"},
+	}
+	if len(report.Issues) != len(expected) {
+		t.Fatalf("Expected %d issues, got %+v", len(expected), report.Issues)
+	}
+	for i, e := range expected {
+		issue := report.Issues[i]
+		if issue.Type != e.nodeType || issue.Pos.Line != e.line || !strings.Contains(issue.Snippet, e.contains) {
+			t.Errorf("Issue %d: expected %s on line %d containing %q, got %+v", i, e.nodeType, e.line, e.contains, issue)
+		}
+		if !strings.Contains(markdown[issue.Start:issue.End], e.contains) {
+			t.Errorf("Issue %d: range [%d, %d) doesn't cover %q", i, issue.Start, issue.End, e.contains)
+		}
+	}
+
+	report, err = NewTranslator().AnalyzeV2Safety("Just **text**")
+	if err != nil {
+		t.Fatalf("Failed to analyze markdown: %v", err)
+	}
+	if !report.Safe || len(report.Issues) != 0 {
+		t.Errorf("Expected a safe report, got %+v", report)
+	}
+}
+
+func TestV2UnsafeTypeOptions(t *testing.T) {
+	markdown := "Hello @user@example.com\n\n{panel}\nInside\n\n{/panel}"
+
+	tests := []struct {
+		name     string
+		opts     []TranslatorOption
+		expected []adf.NodeType
+	}{
+		{
+			name:     "default set",
+			expected: []adf.NodeType{adf.InlineNodeMention, adf.NodePanel},
+		},
+		{
+			name:     "mentions accepted",
+			opts:     []TranslatorOption{WithV2SafeTypes(adf.InlineNodeMention)},
+			expected: []adf.NodeType{adf.NodePanel},
+		},
+		{
+			name:     "replaced set",
+			opts:     []TranslatorOption{WithV2UnsafeTypes(adf.InlineNodeMention)},
+			expected: []adf.NodeType{adf.InlineNodeMention},
+		},
+		{
+			name:     "extra type",
+			opts:     []TranslatorOption{WithV2UnsafeTypes(), WithExtraV2UnsafeTypes(adf.NodeParagraph)},
+			expected: []adf.NodeType{adf.NodeParagraph},
+		},
+		{
+			name: "nothing unsafe",
+			opts: []TranslatorOption{WithV2SafeTypes(adf.InlineNodeMention, adf.NodePanel)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := NewTranslator(tt.opts...).AnalyzeV2Safety(markdown)
+			if err != nil {
+				t.Fatalf("Failed to analyze markdown: %v", err)
+			}
+			if actual := report.UnsafeTypes(); !slices.Equal(actual, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, actual)
+			}
+			if report.Safe != (len(tt.expected) == 0) {
+				t.Errorf("Expected Safe to be %v", len(tt.expected) == 0)
+			}
+		})
+	}
+}
+
+func TestWikiMarkupRefusesV2UnsafeTypes(t *testing.T) {
+	wiki := adf2md.NewWikiMarkupTranslator()
+	for _, nodeType := range NewTranslator().V2UnsafeTypes() {
+		if err := wiki.Accept(&adf.ADFNode{Type: nodeType}); err == nil {
+			t.Errorf("Expected the wiki markup translator to refuse %s", nodeType)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package md2adf
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jorres/md2adf-translator/adf"
+)
+
+// bareURL matches a URL written as plain text.
+var bareURL = regexp.MustCompile(`https?://[^\s<>]+`)
+
+// WithSmartLinks converts links to URLs matching one of the regular
+// expression patterns, such as `^https://[a-z]+\.atlassian\.net/`, to inline
+// cards, the way Jira's editor turns pasted links into smart links. This
+// applies to bare URLs, autolinks and [text](url) links whose text is the
+// URL. Preserved inline cards still take precedence. An invalid pattern makes
+// every translation fail.
+func WithSmartLinks(patterns []string) TranslatorOption {
+	return func(tr *Translator) {
+		for _, pattern := range patterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				tr.configErr = fmt.Errorf("invalid smart link pattern %q: %w", pattern, err)
+				return
+			}
+			tr.smartLinks = append(tr.smartLinks, re)
+		}
+	}
+}
+
+// WithAutolinks sets whether bare URLs and <url> autolinks become links to
+// themselves, as Jira's editor links them. It is on by default; off, they are
+// kept as text.
+func WithAutolinks(autolink bool) TranslatorOption {
+	return func(tr *Translator) {
+		tr.autolinks = autolink
+	}
+}
+
+// WithLinkRewriter sets a function rewriting the URL of every link, such as
+// to make relative links absolute. It applies to [text](url) and reference
+// links, autolinks, bare URLs and images kept as links, before they are
+// looked up among the preserved inline cards or matched as smart links, so
+// a URL rewritten to that of a card still becomes the card.
+func WithLinkRewriter(rewrite func(href string) string) TranslatorOption {
+	return func(tr *Translator) {
+		tr.linkRewriter = rewrite
+	}
+}
+
+// rewriteLink returns href as the link rewriter rewrites it.
+func (p *Translator) rewriteLink(href string) string {
+	if p.linkRewriter == nil {
+		return href
+	}
+	return p.linkRewriter(href)
+}
+
+// isSmartLink reports whether a link to url becomes an inline card.
+func (p *Translator) isSmartLink(url string) bool {
+	for _, re := range p.smartLinks {
+		if re.MatchString(url) {
+			return true
+		}
+	}
+	return false
+}
+
+// inlineCard returns the inline card a link to url stands for: the preserved
+// one if the URL is known, a new one if it is a smart link, nil otherwise.
+func (p *Translator) inlineCard(url string) *adf.ADFNode {
+	if card, exists := p.reverseTranslator.InlineCard(url); exists {
+		if p.reverseTranslator.InlineCardChanged(url) {
+			p.warn(WarningPreservedNodeChanged, "inline card %s changed since the markdown was generated", url)
+		}
+		return p.reuse(card)
+	}
+	if p.isSmartLink(url) {
+		return adf.NewInlineCardNode(url)
+	}
+	return nil
+}
+
+// appendLinkedText appends plain text to parent, turning bare smart link
+// URLs in it into inline cards and, with autolinks on, other bare URLs into
+// links.
+func (p *Translator) appendLinkedText(parent *adf.ADFNode, text string, start, end uint) {
+	prev := 0
+	if len(p.smartLinks) > 0 || p.autolinks {
+		for _, m := range bareURL.FindAllStringIndex(text, -1) {
+			// Sentence punctuation after a URL isn't part of it
+			url := strings.TrimRight(text[m[0]:m[1]], ".,;:!?)'\"")
+			href := p.rewriteLink(url)
+			var node *adf.ADFNode
+			switch {
+			case p.isSmartLink(href):
+				node = p.inlineCard(href)
+			case p.autolinks:
+				node = adf.NewTextNodeWithMarks(url, []*adf.ADFMark{adf.NewLinkMark(href)})
+			default:
+				continue
+			}
+			if m[0] > prev {
+				p.appendPlainText(parent, text[prev:m[0]], start+uint(prev), start+uint(m[0]))
+			}
+			urlEnd := m[0] + len(url)
+			p.appendInline(parent, node, start+uint(m[0]), start+uint(urlEnd))
+			prev = urlEnd
+		}
+	}
+
+	if prev < len(text) {
+		p.appendPlainText(parent, text[prev:], start+uint(prev), end)
+	}
+}
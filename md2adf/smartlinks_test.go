@@ -0,0 +1,243 @@
+package md2adf
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/jorres/md2adf-translator/adf"
+)
+
+// inlineShape describes the inline content of a paragraph as the texts of its
+// text nodes and the URLs of its inline cards.
+func inlineShape(paragraph *adf.ADFNode) []string {
+	var shape []string
+	for _, n := range paragraph.Content {
+		switch n.Type {
+		case adf.InlineNodeCard:
+			shape = append(shape, "card:"+n.Attrs["url"].(string))
+		case adf.ChildNodeText:
+			if len(n.Marks) > 0 && n.Marks[0].Type == adf.MarkLink {
+				shape = append(shape, "link:"+n.Text)
+			} else {
+				shape = append(shape, n.Text)
+			}
+		default:
+			shape = append(shape, string(n.Type))
+		}
+	}
+	return shape
+}
+
+func TestSmartLinks(t *testing.T) {
+	smart := WithSmartLinks([]string{`^https://[a-z]+\.atlassian\.net/`})
+
+	tests := []struct {
+		name     string
+		markdown string
+		expected string
+	}{
+		{
+			name:     "bare url",
+			markdown: "See https://acme.atlassian.net/browse/PROJ-1 for details",
+			expected: `["See ","card:https://acme.atlassian.net/browse/PROJ-1"," for details"]`,
+		},
+		{
+			name:     "bare url before punctuation",
+			markdown: "See https://acme.atlassian.net/browse/PROJ-1.",
+			expected: `["See ","card:https://acme.atlassian.net/browse/PROJ-1","."]`,
+		},
+		{
+			name:     "other bare url stays a link",
+			markdown: "See https://example.com/page",
+			expected: `["See ","link:https://example.com/page"]`,
+		},
+		{
+			name:     "autolink",
+			markdown: "See <https://acme.atlassian.net/wiki/spaces/X>",
+			expected: `["See ","card:https://acme.atlassian.net/wiki/spaces/X"]`,
+		},
+		{
+			name:     "link showing its url",
+			markdown: "[https://acme.atlassian.net/browse/PROJ-2](https://acme.atlassian.net/browse/PROJ-2)",
+			expected: `["card:https://acme.atlassian.net/browse/PROJ-2"]`,
+		},
+		{
+			name:     "link with own text stays a link",
+			markdown: "[the ticket](https://acme.atlassian.net/browse/PROJ-2)",
+			expected: `["link:the ticket"]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := NewTranslator(smart).TranslateToADF([]byte(tt.markdown))
+			if err != nil {
+				t.Fatalf("Failed to convert markdown: %v", err)
+			}
+			actual, _ := json.Marshal(inlineShape(doc.Content[0]))
+			if string(actual) != tt.expected {
+				jsonBytes, _ := json.MarshalIndent(doc, "", "  ")
+				t.Errorf("Expected %s, got %s from:\n%s", tt.expected, actual, string(jsonBytes))
+			}
+		})
+	}
+
+	// Without the option bare URLs are links, or text without autolinks
+	doc, err := NewTranslator().TranslateToADF([]byte(tests[0].markdown))
+	if err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+	if actual, _ := json.Marshal(inlineShape(doc.Content[0])); string(actual) != `["See ","link:https://acme.atlassian.net/browse/PROJ-1"," for details"]` {
+		t.Errorf("Expected a link without smart links, got %s", actual)
+	}
+	doc, err = NewTranslator(WithAutolinks(false)).TranslateToADF([]byte(tests[0].markdown))
+	if err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+	if actual, _ := json.Marshal(inlineShape(doc.Content[0])); !strings.HasPrefix(string(actual), `["See https",":","/","/","acme"`) {
+		t.Errorf("Expected plain text without smart links and autolinks, got %s", actual)
+	}
+}
+
+func TestSmartLinkPreservedCardPrecedence(t *testing.T) {
+	card := &adf.ADFNode{Type: adf.InlineNodeCard, Attrs: map[string]any{"url": "https://acme.atlassian.net/browse/PROJ-1", "localId": "kept"}}
+	paragraph := adf.NewParagraphNode()
+	paragraph.Content = append(paragraph.Content, card)
+
+	translator := NewTranslator(WithSmartLinks([]string{`atlassian\.net`}))
+	if _, err := translator.TranslateToMarkdown(&adf.ADFDocument{Type: "doc", Content: []*adf.ADFNode{paragraph}}); err != nil {
+		t.Fatalf("Failed to render markdown: %v", err)
+	}
+
+	doc, err := translator.TranslateToADF([]byte("See https://acme.atlassian.net/browse/PROJ-1"))
+	if err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+	if content := doc.Content[0].Content; len(content) != 2 || content[1] != card {
+		t.Errorf("Expected the preserved card, got %v", inlineShape(doc.Content[0]))
+	}
+}
+
+func TestLinkRewriter(t *testing.T) {
+	rewrite := func(href string) string {
+		if path, ok := strings.CutPrefix(href, "./"); ok {
+			return "https://github.com/acme/repo/blob/main/" + path
+		}
+		if key, ok := strings.CutPrefix(href, "https://go.acme/"); ok {
+			return "https://acme.atlassian.net/browse/" + key
+		}
+		return href
+	}
+
+	card := &adf.ADFNode{Type: adf.InlineNodeCard, Attrs: map[string]any{"url": "https://acme.atlassian.net/browse/PROJ-1", "localId": "kept"}}
+	paragraph := adf.NewParagraphNode()
+	paragraph.Content = append(paragraph.Content, card)
+
+	translator := NewTranslator(
+		WithLinkRewriter(rewrite),
+		WithSmartLinks([]string{`atlassian\.net`}),
+		WithInlineImagePolicy(func(url, alt string) ImageDecision { return ImageLink }),
+	)
+	if _, err := translator.TranslateToMarkdown(&adf.ADFDocument{Type: "doc", Content: []*adf.ADFNode{paragraph}}); err != nil {
+		t.Fatalf("Failed to render markdown: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		markdown string
+		expected []string
+		href     string
+	}{
+		{"relative link", "[design](./design.md)", []string{"link:design"}, "https://github.com/acme/repo/blob/main/design.md"},
+		{"reference link", "[design][d]\n\n[d]: ./design.md", []string{"link:design"}, "https://github.com/acme/repo/blob/main/design.md"},
+		{"image as link", "![design](./design.png)", []string{"link:design"}, "https://github.com/acme/repo/blob/main/design.png"},
+		{"untouched link", "[site](https://example.com)", []string{"link:site"}, "https://example.com"},
+		{"bare short link", "See https://go.acme/PROJ-2", []string{"See ", "card:https://acme.atlassian.net/browse/PROJ-2"}, ""},
+		{"autolinked short link", "<https://go.acme/PROJ-3>", []string{"card:https://acme.atlassian.net/browse/PROJ-3"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := translator.TranslateToADF([]byte(tt.markdown))
+			if err != nil {
+				t.Fatalf("Failed to convert markdown: %v", err)
+			}
+
+			shape := inlineShape(doc.Content[0])
+			if strings.Join(shape, "|") != strings.Join(tt.expected, "|") {
+				jsonBytes, _ := json.MarshalIndent(doc, "", "  ")
+				t.Fatalf("Expected %q, got %q:\n%s", tt.expected, shape, string(jsonBytes))
+			}
+			if tt.href != "" {
+				if href := doc.Content[0].Content[0].Marks[0].Attrs["href"]; href != tt.href {
+					t.Errorf("Expected href %q, got %v", tt.href, href)
+				}
+			}
+		})
+	}
+
+	// The card a short link is rewritten to the URL of is restored
+	doc, err := translator.TranslateToADF([]byte("[ticket](https://go.acme/PROJ-1)"))
+	if err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+	if doc.Content[0].Content[0] != card {
+		t.Errorf("Expected the preserved card, got %v", inlineShape(doc.Content[0]))
+	}
+}
+
+func TestInvalidSmartLinkPattern(t *testing.T) {
+	_, err := NewTranslator(WithSmartLinks([]string{"("})).TranslateToADF([]byte("text"))
+	if err == nil || !strings.Contains(err.Error(), "invalid smart link pattern") {
+		t.Errorf("Expected an invalid pattern error, got %v", err)
+	}
+}
+
+func TestAutolinks(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		expected string
+	}{
+		{"bare url", "See https://example.com/path, then", `["See ","link:https://example.com/path",","," then"]`},
+		{"angle autolink", "See <https://example.com/a>", `["See ","link:https://example.com/a"]`},
+		{"email autolink", "Mail <me@example.com>", `["Mail ","link:me@example.com"]`},
+		{"url in link text", "[see https://example.com](https://example.org)", `["link:see ","link:https://example.com"]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := NewTranslator().TranslateToADF([]byte(tt.markdown))
+			if err != nil {
+				t.Fatalf("Failed to convert markdown: %v", err)
+			}
+			actual, _ := json.Marshal(inlineShape(doc.Content[0]))
+			if string(actual) != tt.expected {
+				jsonBytes, _ := json.MarshalIndent(doc, "", "  ")
+				t.Errorf("Expected %s, got %s from:\n%s", tt.expected, actual, string(jsonBytes))
+			}
+			for _, n := range doc.Content[0].Content {
+				if len(n.Marks) > 1 {
+					t.Errorf("Expected a single link mark on %q, got %+v", n.Text, n.Marks)
+				}
+			}
+		})
+	}
+
+	doc, err := NewTranslator().TranslateToADF([]byte("Mail <me@example.com>"))
+	if err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+	if href := doc.Content[0].Content[1].Marks[0].Attrs["href"]; href != "mailto:me@example.com" {
+		t.Errorf("Expected a mailto link, got %v", href)
+	}
+
+	doc, err = NewTranslator(WithAutolinks(false)).TranslateToADF([]byte("See <https://example.com/a>"))
+	if err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+	if actual := inlineShape(doc.Content[0]); strings.Join(actual, "") != "See <https://example.com/a>" || len(actual) != 2 {
+		t.Errorf("Expected the autolink kept as text, got %q", actual)
+	}
+}
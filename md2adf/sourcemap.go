@@ -0,0 +1,83 @@
+package md2adf
+
+import (
+	"slices"
+
+	"github.com/jorres/md2adf-translator/adf"
+)
+
+// sourceRange is a half-open byte range [start, end) of the markdown source.
+type sourceRange struct {
+	start, end int
+}
+
+// sourceMapEntry relates an ADF node path to the source range it was produced from.
+type sourceMapEntry struct {
+	path []int
+	sourceRange
+}
+
+// SourceMap relates byte ranges of a markdown source to the ADF nodes they
+// produced. Paths are sequences of indexes into the Content slices, starting
+// at the document: path {1, 0} is the first child of the second top-level node.
+//
+// Every block-level node is covered, including table cells and panel children.
+// Inline nodes are covered where the parser exposes their exact span; text
+// inside table cells shares the span of the trimmed cell content.
+type SourceMap struct {
+	entries []sourceMapEntry // in document order
+}
+
+// newSourceMap builds a source map by walking doc and looking up the ranges
+// recorded for its nodes during translation.
+func newSourceMap(doc *adf.ADFDocument, ranges map[*adf.ADFNode]sourceRange) *SourceMap {
+	sm := &SourceMap{}
+
+	var walk func(nodes []*adf.ADFNode, prefix []int)
+	walk = func(nodes []*adf.ADFNode, prefix []int) {
+		for i, n := range nodes {
+			path := append(slices.Clone(prefix), i)
+			if r, ok := ranges[n]; ok {
+				sm.entries = append(sm.entries, sourceMapEntry{path: path, sourceRange: r})
+			}
+			walk(n.Content, path)
+		}
+	}
+	walk(doc.Content, nil)
+
+	return sm
+}
+
+// NodeAt returns the path of the most deeply nested node whose source range
+// contains the given byte offset.
+func (m *SourceMap) NodeAt(offset int) (path []int, ok bool) {
+	if m == nil {
+		return nil, false
+	}
+
+	for _, e := range m.entries {
+		if offset < e.start || offset >= e.end {
+			continue
+		}
+		if !ok || len(e.path) > len(path) {
+			path, ok = e.path, true
+		}
+	}
+
+	return slices.Clone(path), ok
+}
+
+// RangeOf returns the source byte range [start, end) of the node at path.
+func (m *SourceMap) RangeOf(path []int) (start, end int, ok bool) {
+	if m == nil {
+		return 0, 0, false
+	}
+
+	for _, e := range m.entries {
+		if slices.Equal(e.path, path) {
+			return e.start, e.end, true
+		}
+	}
+
+	return 0, 0, false
+}
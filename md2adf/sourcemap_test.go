@@ -0,0 +1,128 @@
+package md2adf
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jorres/md2adf-translator/adf"
+)
+
+// nodeAtPath resolves a source map path against a translated document.
+func nodeAtPath(doc *adf.ADFDocument, path []int) *adf.ADFNode {
+	nodes := doc.Content
+	var node *adf.ADFNode
+	for _, idx := range path {
+		if idx >= len(nodes) {
+			return nil
+		}
+		node = nodes[idx]
+		nodes = node.Content
+	}
+	return node
+}
+
+func TestTranslateWithSourceMap(t *testing.T) {
+	translator := NewTranslator()
+
+	markdown := `- first item
+- second item
+
+| **a** | **b** |
+| ----- | ----- |
+| c     | dcell |
+
+{panel:type=info}
+Panel text
+
+{/panel}`
+
+	doc, sm, err := translator.TranslateWithSourceMap([]byte(markdown))
+	if err != nil {
+		t.Fatalf("Translation failed: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		needle     string
+		pathPrefix []int
+		nodeType   adf.NodeType
+	}{
+		{name: "list item", needle: "second", pathPrefix: []int{0, 1, 0}, nodeType: adf.ChildNodeText},
+		{name: "table cell", needle: "dcell", pathPrefix: []int{1, 1, 1, 0}, nodeType: adf.ChildNodeText},
+		{name: "panel content", needle: "Panel text", pathPrefix: []int{2, 0}, nodeType: adf.ChildNodeText},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			offset := strings.Index(markdown, tt.needle)
+
+			path, ok := sm.NodeAt(offset)
+			if !ok {
+				t.Fatalf("No node found at offset %d", offset)
+			}
+			if len(path) < len(tt.pathPrefix) || !slices.Equal(path[:len(tt.pathPrefix)], tt.pathPrefix) {
+				t.Fatalf("Expected path with prefix %v, got %v", tt.pathPrefix, path)
+			}
+
+			node := nodeAtPath(doc, path)
+			if node == nil || node.Type != tt.nodeType {
+				t.Fatalf("Expected %s node at %v, got %+v", tt.nodeType, path, node)
+			}
+			if !strings.HasPrefix(node.Text, tt.needle) {
+				t.Errorf("Expected node text to start with %q, got %q", tt.needle, node.Text)
+			}
+
+			start, end, ok := sm.RangeOf(path)
+			if !ok || offset < start || offset >= end {
+				t.Errorf("Expected range of %v to contain %d, got [%d, %d)", path, offset, start, end)
+			}
+		})
+	}
+
+	// Block-level containers are mapped too
+	start, end, ok := sm.RangeOf([]int{2})
+	if !ok || !strings.HasPrefix(markdown[start:end], "{panel") {
+		t.Errorf("Expected panel range to start at the panel marker, got [%d, %d)", start, end)
+	}
+
+	if _, ok := sm.NodeAt(len(markdown) + 10); ok {
+		t.Error("Expected no node past the end of the source")
+	}
+}
+
+func TestSourceMapRepeatedAttachment(t *testing.T) {
+	issue := issueWithAttachment("collection")
+	group := &adf.ADFNode{Type: adf.NodeMediaGroup}
+	for _, id := range []string{"file-2", "file-3"} {
+		group.Content = append(group.Content, &adf.ADFNode{Type: adf.NodeMedia, Attrs: map[string]any{"id": id, "type": "file", "collection": "c"}})
+	}
+	translator := NewTranslator()
+	if _, err := translator.TranslateToMarkdown(&adf.ADFDocument{Version: 1, Type: "doc", Content: append(issue.Content, group)}); err != nil {
+		t.Fatalf("Failed to render markdown: %v", err)
+	}
+
+	markdown := "Intro\n\n{attachment:file-1}\n\nAgain:\n\n{attachment:file-1}\n\n{attachment:file-2}\n{attachment:file-3}\n\nAnd:\n\n{attachment:file-2}\n{attachment:file-3}\n"
+	doc, sm, err := translator.TranslateWithSourceMap([]byte(markdown))
+	if err != nil {
+		t.Fatalf("Translation failed: %v", err)
+	}
+	if err := adf.Validate(doc); err != nil {
+		t.Fatalf("Expected a valid document, got %v", err)
+	}
+
+	tests := []struct {
+		path   int
+		offset int
+	}{
+		{path: 1, offset: strings.Index(markdown, "{attachment:file-1}")},
+		{path: 3, offset: strings.LastIndex(markdown, "{attachment:file-1}")},
+		{path: 4, offset: strings.Index(markdown, "{attachment:file-2}")},
+		{path: 6, offset: strings.LastIndex(markdown, "{attachment:file-2}")},
+	}
+	for _, tt := range tests {
+		if start, _, ok := sm.RangeOf([]int{tt.path}); !ok || start != tt.offset {
+			t.Errorf("Expected node %d to start at %d, got %d (%v)", tt.path, tt.offset, start, ok)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package md2adf
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jorres/md2adf-translator/adf"
+	"github.com/jorres/md2adf-translator/adf2md"
+)
+
+func TestStatusTranslation(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		expected []*adf.ADFNode
+	}{
+		{
+			name:     "status between text",
+			markdown: "State {status:color=green}DONE{/status} today",
+			expected: []*adf.ADFNode{
+				adf.NewTextNode("State "),
+				{Type: adf.InlineNodeStatus, Attrs: map[string]any{"text": "DONE", "color": "green"}},
+				adf.NewTextNode(" today"),
+			},
+		},
+		{
+			name:     "local id is kept",
+			markdown: "{status:color=blue|localId=abc-123}IN PROGRESS{/status}",
+			expected: []*adf.ADFNode{
+				{Type: adf.InlineNodeStatus, Attrs: map[string]any{"text": "IN PROGRESS", "color": "blue", "localId": "abc-123"}},
+			},
+		},
+		{
+			name:     "color defaults to neutral",
+			markdown: "{status}TODO{/status}",
+			expected: []*adf.ADFNode{
+				{Type: adf.InlineNodeStatus, Attrs: map[string]any{"text": "TODO", "color": "neutral"}},
+			},
+		},
+		{
+			name:     "color is lowercased",
+			markdown: "{status:color=Green}DONE{/status}",
+			expected: []*adf.ADFNode{
+				{Type: adf.InlineNodeStatus, Attrs: map[string]any{"text": "DONE", "color": "green"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := NewTranslator().TranslateToADF([]byte(tt.markdown))
+			if err != nil {
+				t.Fatalf("Failed to convert markdown: %v", err)
+			}
+			if len(doc.Content) != 1 || doc.Content[0].Type != adf.NodeParagraph {
+				t.Fatalf("Expected a single paragraph, got %+v", doc.Content)
+			}
+
+			actual, _ := json.Marshal(doc.Content[0].Content)
+			expected, _ := json.Marshal(tt.expected)
+			if string(actual) != string(expected) {
+				t.Errorf("Expected %s, got %s", expected, actual)
+			}
+		})
+	}
+}
+
+func TestEmptyStatus(t *testing.T) {
+	markdown := "Empty {status:color=green}{/status} here"
+	doc, err := NewTranslator().TranslateToADF([]byte(markdown))
+	if err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+
+	if len(adf.FindAll(doc, adf.InlineNodeStatus)) != 0 {
+		t.Errorf("Expected no status node, got %+v", doc.Content)
+	}
+	if text := adf.PlainText(doc); text != markdown {
+		t.Errorf("Expected the status kept as text, got %q", text)
+	}
+}
+
+func TestUnknownStatusColor(t *testing.T) {
+	translator := NewTranslator()
+	doc, err := translator.TranslateToADF([]byte("State {status:color=orange}LATE{/status}"))
+	if err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+
+	status := doc.Content[0].Content[1]
+	if status.Type != adf.InlineNodeStatus || status.Attrs["color"] != adf.StatusNeutral {
+		t.Errorf("Expected a neutral status, got %+v", status)
+	}
+	warnings := translator.Warnings()
+	if len(warnings) != 1 || warnings[0].Code != WarningUnknownStatusColor {
+		t.Fatalf("Expected an unknown status color warning, got %+v", warnings)
+	}
+	if warnings[0].Start != len("State ") {
+		t.Errorf("Expected the warning at the status, got %+v", warnings[0])
+	}
+}
+
+func TestStatusRoundtrip(t *testing.T) {
+	status := adf.NewStatusNode("IN REVIEW", "purple")
+	status.Attrs["localId"] = "f00d"
+	paragraph := adf.NewParagraphNode()
+	paragraph.Content = append(paragraph.Content, adf.NewTextNode("See "), status)
+
+	for _, inCell := range []bool{false, true} {
+		block := paragraph
+		if inCell {
+			cell := &adf.ADFNode{Type: adf.ChildNodeTableHeader, Content: []*adf.ADFNode{paragraph}}
+			row := adf.NewTableRowNode()
+			row.Content = append(row.Content, cell)
+			block = adf.NewTableNode()
+			block.Content = append(block.Content, row)
+		}
+
+		markdown := adf2md.NewTranslator(adf2md.NewMarkdownTranslator()).MustTranslate(&adf.ADFNode{Type: "doc", Content: []*adf.ADFNode{block}})
+		doc, err := NewTranslator().TranslateToADF([]byte(markdown))
+		if err != nil {
+			t.Fatalf("Failed to convert %q: %v", markdown, err)
+		}
+
+		var found *adf.ADFNode
+		var find func(nodes []*adf.ADFNode)
+		find = func(nodes []*adf.ADFNode) {
+			for _, n := range nodes {
+				if n.Type == adf.InlineNodeStatus {
+					found = n
+				}
+				find(n.Content)
+			}
+		}
+		find(doc.Content)
+
+		if found == nil {
+			t.Fatalf("No status node parsed from %q", markdown)
+		}
+		actual, _ := json.Marshal(found.Attrs)
+		expected, _ := json.Marshal(status.Attrs)
+		if string(actual) != string(expected) {
+			t.Errorf("Status from %q: expected %s, got %s", markdown, expected, actual)
+		}
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"encoding/json"
 	"github.com/jorres/md2adf-translator/adf"
 	"github.com/jorres/md2adf-translator/adf2md"
+	"os"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -383,9 +386,12 @@ func TestTableRoundtrip(t *testing.T) {
 		Type:    "doc",
 		Content: adfDoc.Content,
 	}
-	resultMarkdown := adf2mdTranslator.Translate(docWrapper)
+	resultMarkdown := adf2mdTranslator.MustTranslate(docWrapper)
 
 	// The result should be a properly formatted table with boundaries
+	if strings.Contains(resultMarkdown, "****") {
+		t.Fatalf("Header bold markers doubled up in:\n%s", resultMarkdown)
+	}
 	if resultMarkdown == "" {
 		// Debug: print the ADF structure
 		jsonBytes, _ := json.MarshalIndent(adfDoc, "", "  ")
@@ -447,3 +453,442 @@ func TestTableRoundtrip(t *testing.T) {
 	t.Logf("Roundtrip test passed. Generated markdown:\n%s", resultMarkdown)
 }
 
+
+// TestPipelessTableRoundtrip feeds the tables adf2md renders for its golden
+// fixture back through the translator, both as rendered and with the outer
+// pipes removed.
+func TestPipelessTableRoundtrip(t *testing.T) {
+	data, err := os.ReadFile("../adf2md/testdata/md.json")
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+	var fixture adf.ADFNode
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		t.Fatalf("Failed to parse fixture: %v", err)
+	}
+	rendered := adf2md.NewTranslator(adf2md.NewMarkdownTranslator()).MustTranslate(&fixture)
+
+	// Collect every run of table lines from the rendered markdown
+	var tables [][]string
+	var current []string
+	for _, line := range strings.Split(rendered, "\n") {
+		if strings.HasPrefix(line, "|") {
+			current = append(current, line)
+			continue
+		}
+		if len(current) > 0 {
+			tables = append(tables, current)
+			current = nil
+		}
+	}
+	if len(tables) == 0 {
+		t.Fatalf("Expected tables in rendered fixture, got:\n%s", rendered)
+	}
+
+	for i, lines := range tables {
+		columns := strings.Count(lines[0], "|") - 1
+
+		var pipeless []string
+		for _, line := range lines {
+			line = strings.TrimSuffix(strings.TrimPrefix(line, "|"), "|")
+			pipeless = append(pipeless, strings.TrimSpace(line))
+		}
+
+		for _, markdown := range []string{strings.Join(lines, "\n"), strings.Join(pipeless, "\n")} {
+			doc, err := NewTranslator().TranslateToADF([]byte(markdown))
+			if err != nil {
+				t.Fatalf("Table %d: failed to convert markdown: %v", i, err)
+			}
+			if len(doc.Content) != 1 || doc.Content[0].Type != adf.NodeTable {
+				jsonBytes, _ := json.MarshalIndent(doc, "", "  ")
+				t.Fatalf("Table %d: expected a single table from:\n%s\ngot:\n%s", i, markdown, string(jsonBytes))
+			}
+
+			table := doc.Content[0]
+			if len(table.Content) != len(lines)-1 {
+				t.Errorf("Table %d: expected %d rows, got %d", i, len(lines)-1, len(table.Content))
+			}
+			for r, row := range table.Content {
+				if len(row.Content) != columns {
+					t.Errorf("Table %d row %d: expected %d columns, got %d", i, r, columns, len(row.Content))
+				}
+			}
+
+			header := table.Content[0].Content[0]
+			if header.Type != adf.ChildNodeTableHeader || header.Content[0].Content[0].Text != "Table Header 1" {
+				jsonBytes, _ := json.MarshalIndent(header, "", "  ")
+				t.Errorf("Table %d: unexpected first header cell:\n%s", i, string(jsonBytes))
+			}
+		}
+	}
+}
+
+func TestTableCellInlineFormatting(t *testing.T) {
+	translator := NewTranslator(WithUserEmailMapping(map[string]string{"user@example.com": "user-id"}))
+
+	markdown := `| **Field** | **Notes** |
+| --------- | --------- |
+| a ` + "`code`" + ` cell | [docs](https://example.com) |
+| **Name:** value | ask @user@example.com |
+| _italic_ |  |`
+
+	doc, err := translator.TranslateToADF([]byte(markdown))
+	if err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+	if len(doc.Content) != 1 || doc.Content[0].Type != adf.NodeTable || len(doc.Content[0].Content) != 4 {
+		jsonBytes, _ := json.MarshalIndent(doc, "", "  ")
+		t.Fatalf("Expected a table with 4 rows, got:\n%s", string(jsonBytes))
+	}
+	rows := doc.Content[0].Content
+
+	cellRuns := func(row, col int) []string {
+		cell := rows[row].Content[col]
+		if len(cell.Content) != 1 || cell.Content[0].Type != adf.NodeParagraph {
+			jsonBytes, _ := json.MarshalIndent(cell, "", "  ")
+			t.Fatalf("Expected cell %d/%d to hold one paragraph, got:\n%s", row, col, string(jsonBytes))
+		}
+		return markRuns(cell.Content[0].Content)
+	}
+
+	tests := []struct {
+		row, col int
+		expected []string
+	}{
+		{1, 0, []string{"a []", "code[code]", " cell[]"}},
+		{1, 1, []string{"docs[link]"}},
+		{2, 0, []string{"Name:[strong]", " value[]"}},
+		{3, 0, []string{"italic[em]"}},
+		{3, 1, nil},
+	}
+	for _, tt := range tests {
+		if actual := cellRuns(tt.row, tt.col); !slices.Equal(tt.expected, actual) {
+			t.Errorf("Cell %d/%d parsed as %v, expected %v", tt.row, tt.col, actual, tt.expected)
+		}
+	}
+
+	// Mentions become mention nodes like in paragraphs
+	mentionCell := rows[2].Content[1].Content[0]
+	if !slices.ContainsFunc(mentionCell.Content, func(n *adf.ADFNode) bool {
+		return n.Type == adf.InlineNodeMention && n.Attrs["id"] == "user-id"
+	}) {
+		jsonBytes, _ := json.MarshalIndent(mentionCell, "", "  ")
+		t.Errorf("Expected a mention node in the cell, got:\n%s", string(jsonBytes))
+	}
+}
+
+func TestTableHeaderBold(t *testing.T) {
+	markdown := `| Name | **Age** |
+| ---- | ------- |
+| Bob  | 30      |`
+
+	tests := []struct {
+		name       string
+		translator *Translator
+		expected   []string // header runs as produced by markRuns
+	}{
+		{
+			name:       "bold only where marked",
+			translator: NewTranslator(),
+			expected:   []string{"Name[]", "Age[strong]"},
+		},
+		{
+			name:       "all headers bold",
+			translator: NewTranslator(WithBoldTableHeaders(true)),
+			expected:   []string{"Name[strong]", "Age[strong]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := tt.translator.TranslateToADF([]byte(markdown))
+			if err != nil {
+				t.Fatalf("Failed to convert markdown: %v", err)
+			}
+			if len(doc.Content) != 1 || doc.Content[0].Type != adf.NodeTable {
+				jsonBytes, _ := json.MarshalIndent(doc, "", "  ")
+				t.Fatalf("Expected a single table, got:\n%s", string(jsonBytes))
+			}
+
+			var actual []string
+			for _, cell := range doc.Content[0].Content[0].Content {
+				if cell.Type != adf.ChildNodeTableHeader {
+					t.Fatalf("Expected header cells in the first row, got %s", cell.Type)
+				}
+				actual = append(actual, markRuns(cell.Content[0].Content)...)
+			}
+			if !slices.Equal(tt.expected, actual) {
+				t.Errorf("Header parsed as %v, expected %v", actual, tt.expected)
+			}
+
+			// Data cells are never forced bold
+			for _, n := range doc.Content[0].Content[1].Content[0].Content[0].Content {
+				if len(n.Marks) != 0 {
+					t.Errorf("Expected unmarked data cell, got %v", n.Marks)
+				}
+			}
+		})
+	}
+}
+
+func TestTableCellFormatting(t *testing.T) {
+	translator := NewTranslator()
+
+	tests := []struct {
+		name     string
+		cellText string
+		isHeader bool
+		expected []string // runs as produced by markRuns
+	}{
+		{"bold prefix", "**Name:** value", false, []string{"Name:[strong]", " value[]"}},
+		{"bold prefix in header", "**Name:** value", true, []string{"Name:[strong]", " value[]"}},
+		{"italic cell", "*italic*", false, []string{"italic[em]"}},
+		{"italic header", "_italic_", true, []string{"italic[em]"}},
+		{"code cell", "`make build`", false, []string{"make build[code]"}},
+		{"code infix", "run `make` now", true, []string{"run []", "make[code]", " now[]"}},
+		{"italic inside bold", "**very _much_ so**", false, []string{"very [strong]", "much[em+strong]", " so[strong]"}},
+		{"snake_case stays literal", "some_snake_case", false, []string{"some_snake_case[]"}},
+		{"unmatched delimiters stay literal", "5 * 3 ** 2", false, []string{"5 * 3 ** 2[]"}},
+		{"escaped delimiter", `\*not italic\*`, false, []string{"*not italic*[]"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			markdown := "| Header |\n|---|\n| " + tt.cellText + " |"
+			if tt.isHeader {
+				markdown = "| " + tt.cellText + " |\n|---|\n| value |"
+			}
+			doc, err := translator.TranslateToADF([]byte(markdown))
+			if err != nil {
+				t.Fatalf("Failed to convert markdown: %v", err)
+			}
+			if len(doc.Content) != 1 || doc.Content[0].Type != adf.NodeTable {
+				jsonBytes, _ := json.MarshalIndent(doc, "", "  ")
+				t.Fatalf("Expected a single table, got:\n%s", string(jsonBytes))
+			}
+
+			row := doc.Content[0].Content[1]
+			if tt.isHeader {
+				row = doc.Content[0].Content[0]
+			}
+			actual := markRuns(row.Content[0].Content[0].Content)
+			if !slices.Equal(tt.expected, actual) {
+				t.Errorf("Cell %q parsed as %v, expected %v", tt.cellText, actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPipelessTableCellFormatting(t *testing.T) {
+	markdown := "**Field:** name | *Notes*\n--- | ---\n`id` | **Required:** yes\n[docs](https://example.com) | ~~old~~ new"
+
+	doc, err := NewTranslator().TranslateToADF([]byte(markdown))
+	if err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+	if len(doc.Content) != 1 || doc.Content[0].Type != adf.NodeTable {
+		jsonBytes, _ := json.MarshalIndent(doc, "", "  ")
+		t.Fatalf("Expected a single table, got:\n%s", string(jsonBytes))
+	}
+
+	expected := [][]string{
+		{"Field:[strong]", " name[]"}, {"Notes[em]"},
+		{"id[code]"}, {"Required:[strong]", " yes[]"},
+		{"docs[link]"}, {"old[strike]", " new[]"},
+	}
+	var i int
+	for _, row := range doc.Content[0].Content {
+		for _, cell := range row.Content {
+			if actual := markRuns(cell.Content[0].Content); !slices.Equal(expected[i], actual) {
+				t.Errorf("Cell %d parsed as %v, expected %v", i, actual, expected[i])
+			}
+			i++
+		}
+	}
+}
+
+func TestTableCellPipeRoundtrip(t *testing.T) {
+	cell := func(nt adf.NodeType, text string, marks ...*adf.ADFMark) *adf.ADFNode {
+		paragraph := adf.NewParagraphNode()
+		paragraph.Content = append(paragraph.Content, adf.NewTextNodeWithMarks(text, marks))
+		return &adf.ADFNode{Type: nt, Content: []*adf.ADFNode{paragraph}}
+	}
+	header := adf.NewTableRowNode()
+	header.Content = append(header.Content, cell(adf.ChildNodeTableHeader, "Text"), cell(adf.ChildNodeTableHeader, "Code"))
+	row := adf.NewTableRowNode()
+	row.Content = append(row.Content, cell(adf.ChildNodeTableCell, "a|b"), cell(adf.ChildNodeTableCell, "grep 'x|y'", adf.NewCodeMark()))
+	table := adf.NewTableNode()
+	table.Content = append(table.Content, header, row)
+
+	reverse := adf2md.NewTranslator(adf2md.NewMarkdownTranslator())
+	markdown := reverse.MustTranslate(&adf.ADFNode{Type: "doc", Content: []*adf.ADFNode{table}})
+
+	doc, err := NewTranslator().TranslateToADF([]byte(markdown))
+	if err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+	if len(doc.Content) != 1 || doc.Content[0].Type != adf.NodeTable {
+		jsonBytes, _ := json.MarshalIndent(doc, "", "  ")
+		t.Fatalf("Expected a single table from:\n%s\ngot:\n%s", markdown, string(jsonBytes))
+	}
+
+	dataRow := doc.Content[0].Content[1]
+	if len(dataRow.Content) != 2 {
+		t.Fatalf("Expected 2 columns from:\n%s\ngot %d", markdown, len(dataRow.Content))
+	}
+	if actual := markRuns(dataRow.Content[0].Content[0].Content); !slices.Equal([]string{"a|b[]"}, actual) {
+		t.Errorf("Expected a|b, got %v from:\n%s", actual, markdown)
+	}
+	if actual := markRuns(dataRow.Content[1].Content[0].Content); !slices.Equal([]string{"grep 'x|y'[code]"}, actual) {
+		t.Errorf("Expected code grep 'x|y', got %v from:\n%s", actual, markdown)
+	}
+}
+
+func TestTableColumnAlignment(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+	}{
+		{
+			name:     "pipe table",
+			markdown: "| Name | Status | Count |\n| --- | :---: | ---: |\n| a | ok | 7 |",
+		},
+		{
+			name:     "pipeless table",
+			markdown: "Name | Status | Count\n--- | :---: | ---:\na | ok | 7",
+		},
+	}
+
+	alignments := []string{"", "center", "end"}
+	alignmentOf := func(cell *adf.ADFNode) string {
+		for _, m := range cell.Content[0].Marks {
+			if m.Type == adf.MarkAlignment {
+				align, _ := m.Attrs["align"].(string)
+				return align
+			}
+		}
+		return ""
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := NewTranslator().TranslateToADF([]byte(tt.markdown))
+			if err != nil {
+				t.Fatalf("Failed to convert markdown: %v", err)
+			}
+			if len(doc.Content) != 1 || doc.Content[0].Type != adf.NodeTable {
+				jsonBytes, _ := json.MarshalIndent(doc, "", "  ")
+				t.Fatalf("Expected a single table, got:\n%s", string(jsonBytes))
+			}
+
+			for r, row := range doc.Content[0].Content {
+				for c, cell := range row.Content {
+					if actual := alignmentOf(cell); actual != alignments[c] {
+						t.Errorf("Row %d column %d: expected alignment %q, got %q", r, c, alignments[c], actual)
+					}
+				}
+			}
+
+			// The alignment survives rendering back to markdown
+			markdown := adf2md.NewTranslator(adf2md.NewMarkdownTranslator()).MustTranslate(&adf.ADFNode{Type: "doc", Content: doc.Content})
+			lines := strings.Split(strings.TrimSpace(markdown), "\n")
+			if len(lines) != 3 || lines[1] != "|-------|:------:|------:|" {
+				t.Errorf("Unexpected rendered table:\n%s", markdown)
+			}
+		})
+	}
+}
+
+func TestTableCellParagraphsRoundtrip(t *testing.T) {
+	cell := &adf.ADFNode{Type: adf.ChildNodeTableCell}
+	for _, text := range []*adf.ADFNode{
+		adf.NewTextNode("First"),
+		adf.NewTextNodeWithMarks("Second", []*adf.ADFMark{adf.NewStrongMark()}),
+		nil,
+		adf.NewTextNode("literal <br> tag"),
+	} {
+		paragraph := adf.NewParagraphNode()
+		if text != nil {
+			paragraph.Content = append(paragraph.Content, text)
+		}
+		cell.Content = append(cell.Content, paragraph)
+	}
+	header := adf.NewTableRowNode()
+	header.Content = append(header.Content, &adf.ADFNode{Type: adf.ChildNodeTableHeader, Content: []*adf.ADFNode{paragraphOf("Notes")}})
+	row := adf.NewTableRowNode()
+	row.Content = append(row.Content, cell)
+	table := adf.NewTableNode()
+	table.Content = append(table.Content, header, row)
+
+	reverse := adf2md.NewTranslator(adf2md.NewMarkdownTranslator())
+	markdown := reverse.MustTranslate(&adf.ADFNode{Type: "doc", Content: []*adf.ADFNode{table}})
+
+	doc, err := NewTranslator().TranslateToADF([]byte(markdown))
+	if err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+	if len(doc.Content) != 1 || doc.Content[0].Type != adf.NodeTable {
+		jsonBytes, _ := json.MarshalIndent(doc, "", "  ")
+		t.Fatalf("Expected a single table from:\n%s\ngot:\n%s", markdown, string(jsonBytes))
+	}
+
+	var actual [][]string
+	for _, paragraph := range doc.Content[0].Content[1].Content[0].Content {
+		actual = append(actual, markRuns(paragraph.Content))
+	}
+	expected := [][]string{{"First[]"}, {"Second[strong]"}, nil, {"literal <br> tag[]"}}
+	if !slices.EqualFunc(expected, actual, slices.Equal) {
+		t.Errorf("Expected paragraphs %v, got %v from:\n%s", expected, actual, markdown)
+	}
+}
+
+func TestTableCellBlocksRoundtrip(t *testing.T) {
+	list := adf.NewBulletListNode()
+	for _, text := range []string{"one", "two"} {
+		item := adf.NewListItemNode()
+		item.Content = append(item.Content, paragraphOf(text))
+		list.Content = append(list.Content, item)
+	}
+	code := adf.NewCodeBlockNode("sh")
+	code.Content = append(code.Content, adf.NewTextNode("make build\nmake test"))
+	cell := &adf.ADFNode{Type: adf.ChildNodeTableCell, Content: []*adf.ADFNode{paragraphOf("Steps:"), list, code}}
+
+	header := adf.NewTableRowNode()
+	header.Content = append(header.Content, &adf.ADFNode{Type: adf.ChildNodeTableHeader, Content: []*adf.ADFNode{paragraphOf("Release")}})
+	row := adf.NewTableRowNode()
+	row.Content = append(row.Content, cell)
+	table := adf.NewTableNode()
+	table.Content = append(table.Content, header, row)
+
+	reverse := adf2md.NewTranslator(adf2md.NewMarkdownTranslator())
+	markdown := reverse.MustTranslate(&adf.ADFNode{Type: "doc", Content: []*adf.ADFNode{table}})
+
+	doc, err := NewTranslator().TranslateToADF([]byte(markdown))
+	if err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+	if len(doc.Content) != 1 || doc.Content[0].Type != adf.NodeTable {
+		jsonBytes, _ := json.MarshalIndent(doc, "", "  ")
+		t.Fatalf("Expected a single table from:\n%s\ngot:\n%s", markdown, string(jsonBytes))
+	}
+
+	// The list reads back as a list, the code as a code span
+	blocks := doc.Content[0].Content[1].Content[0].Content
+	var actual []string
+	for _, block := range blocks {
+		switch block.Type {
+		case adf.NodeBulletList:
+			for _, item := range block.Content {
+				actual = append(actual, "• "+strings.Join(markRuns(item.Content[0].Content), ""))
+			}
+		case adf.NodeParagraph:
+			actual = append(actual, strings.Join(markRuns(block.Content), ""))
+		default:
+			actual = append(actual, string(block.Type))
+		}
+	}
+	expected := []string{"Steps:[]", "• one[]", "• two[]", "make build make test[code]"}
+	if !slices.Equal(expected, actual) || len(blocks) != 3 {
+		t.Errorf("Expected %v, got %v from:\n%s", expected, actual, markdown)
+	}
+}
@@ -0,0 +1,112 @@
+package md2adf
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jorres/md2adf-translator/adf"
+)
+
+// Warning is a problem found during translation that didn't stop it.
+type Warning struct {
+	Code    string
+	Message string
+	// Start and End are the byte offsets [Start, End) of the markdown the
+	// warning is about, both zero when it isn't about a particular span.
+	Start, End int
+	// Pos is the position of Start, zero when Start and End are.
+	Pos adf.Position
+}
+
+// String formats the warning as its message, prefixed with "line:column: "
+// when it is about a span of the markdown.
+func (w Warning) String() string {
+	if w.Pos.Line == 0 {
+		return w.Message
+	}
+	return w.Pos.String() + ": " + w.Message
+}
+
+// WarningsError is returned by a translation in strict mode instead of a
+// document when the translation produced warnings.
+type WarningsError struct {
+	Warnings []Warning
+}
+
+func (e *WarningsError) Error() string {
+	messages := make([]string, len(e.Warnings))
+	for i, w := range e.Warnings {
+		messages[i] = w.String()
+	}
+	return "translation produced warnings: " + strings.Join(messages, "; ")
+}
+
+// Warning codes.
+const (
+	// WarningPreservedNodeChanged reports a preserved media or inline card node
+	// that was substituted although the resource changed since the markdown
+	// referencing it was generated.
+	WarningPreservedNodeChanged = "preserved_node_changed"
+	// WarningDroppedContent reports markdown that has no ADF equivalent and
+	// was left out of the document.
+	WarningDroppedContent = "dropped_content"
+	// WarningUnknownAttachment reports an attachment reference to media the
+	// reverse translator doesn't know, kept as text.
+	WarningUnknownAttachment = "unknown_attachment"
+	// WarningInvalidAttachmentParameter reports a parameter of an attachment
+	// reference that is unknown or has an invalid value, dropped.
+	WarningInvalidAttachmentParameter = "invalid_attachment_parameter"
+	// WarningUnresolvedMention reports a mention whose email no user mapping
+	// or resolver knows, so the email stands in for the account ID.
+	WarningUnresolvedMention = "unresolved_mention"
+	// WarningUnknownPanelType reports a panel of a type Jira doesn't know,
+	// turned into an info panel.
+	WarningUnknownPanelType = "unknown_panel_type"
+	// WarningUnknownStatusColor reports a status lozenge of a color Jira
+	// doesn't know, made neutral.
+	WarningUnknownStatusColor = "unknown_status_color"
+	// WarningUnknownLanguage reports a code block language Jira doesn't
+	// highlight, dropped under WithLanguageValidation.
+	WarningUnknownLanguage = "unknown_language"
+	// WarningUnresolvedReference reports a reference link to a label no
+	// link reference definition defines, kept as text.
+	WarningUnresolvedReference = "unresolved_reference"
+)
+
+// WithStrictMode makes a translation that produced warnings fail with a
+// *WarningsError.
+func WithStrictMode() TranslatorOption {
+	return func(tr *Translator) {
+		tr.strict = true
+	}
+}
+
+// Warnings returns the warnings emitted by the last translation.
+func (p *Translator) Warnings() []Warning {
+	return slices.Clone(p.warnings)
+}
+
+// warn records a warning for the current translation.
+func (p *Translator) warn(code, format string, args ...any) {
+	p.warnings = append(p.warnings, Warning{Code: code, Message: fmt.Sprintf(format, args...)})
+}
+
+// warnAt records a warning about the [start, end) span of the markdown.
+func (p *Translator) warnAt(code string, start, end uint, format string, args ...any) {
+	p.warnings = append(p.warnings, Warning{
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+		Start:   int(start),
+		End:     int(end),
+	})
+}
+
+// locateWarnings sets the positions of the warnings about spans of content.
+func (p *Translator) locateWarnings(content []byte) {
+	for i, w := range p.warnings {
+		if w.End > 0 {
+			p.warnings[i].Pos = adf.PositionAt(content, w.Start)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package md2adf
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDroppedContentWarnings(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		opts     []TranslatorOption
+		dropped  string
+		reason   string
+		pos      string
+	}{
+		{
+			name:     "thematic break",
+			markdown: "Before\n\n***\n\nAfter",
+			dropped:  "***",
+			reason:   "thematic break",
+			pos:      "3:1",
+		},
+		{
+			name:     "html block",
+			markdown: "Before\n\n<div>\nraw\n</div>\n\nAfter",
+			dropped:  "<div>",
+			reason:   "html block",
+			pos:      "3:1",
+		},
+		{
+			name:     "image dropped by policy",
+			markdown: "Before ![alt](https://example.com/i.png) after",
+			opts:     []TranslatorOption{WithInlineImagePolicy(func(url, alt string) ImageDecision { return ImageDrop })},
+			dropped:  "![alt](https://example.com/i.png)",
+			reason:   "https://example.com/i.png",
+			pos:      "1:8",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			translator := NewTranslator(tt.opts...)
+			if _, err := translator.TranslateToADF([]byte(tt.markdown)); err != nil {
+				t.Fatalf("Failed to convert markdown: %v", err)
+			}
+
+			warnings := translator.Warnings()
+			if len(warnings) != 1 || warnings[0].Code != WarningDroppedContent {
+				t.Fatalf("Expected one %s warning, got %+v", WarningDroppedContent, warnings)
+			}
+			w := warnings[0]
+			if !strings.Contains(w.Message, tt.reason) {
+				t.Errorf("Expected the message to mention %q, got %q", tt.reason, w.Message)
+			}
+			if w.Pos.String() != tt.pos {
+				t.Errorf("Expected the warning at %s, got %s", tt.pos, w.Pos)
+			}
+			if !strings.HasPrefix(tt.markdown[w.Start:w.End], tt.dropped) {
+				t.Errorf("Expected the range to cover %q, got %q", tt.dropped, tt.markdown[w.Start:w.End])
+			}
+		})
+	}
+}
+
+func TestNoWarningsForSupportedContent(t *testing.T) {
+	translator := NewTranslator()
+	markdown := "# Title\n\n- a\n  - b\n1. c\n\n> quoted\n> text\n\n```go\ncode\n```\n\n| a | b |\n|---|---|\n| c | d |\n"
+	if _, err := translator.TranslateToADF([]byte(markdown)); err != nil {
+		t.Fatalf("Failed to convert markdown: %v", err)
+	}
+	if warnings := translator.Warnings(); len(warnings) != 0 {
+		t.Errorf("Expected no warnings, got %+v", warnings)
+	}
+}
+
+func TestStrictMode(t *testing.T) {
+	markdown := []byte("Before\n\n***\n\nAfter")
+
+	doc, err := NewTranslator(WithStrictMode()).TranslateToADF(markdown)
+	if doc != nil {
+		t.Errorf("Expected no document in strict mode, got %+v", doc)
+	}
+	var warningsErr *WarningsError
+	if !errors.As(err, &warningsErr) || len(warningsErr.Warnings) != 1 {
+		t.Fatalf("Expected a *WarningsError with one warning, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "3:1: unsupported thematic break dropped") {
+		t.Errorf("Expected the error to locate the dropped break, got %q", err)
+	}
+
+	if _, err := NewTranslator(WithStrictMode()).TranslateToADF([]byte("Just text")); err != nil {
+		t.Errorf("Expected clean markdown to pass in strict mode, got %v", err)
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jorres/md2adf-translator/adf"
+	"github.com/jorres/md2adf-translator/md2adf"
+)
+
+// diffContext is the number of unchanged lines shown around each change.
+const diffContext = 3
+
+// verifyRoundtrip translates input to ADF, back to markdown and to ADF again,
+// prints a unified diff of the two documents when they differ and returns
+// the exit code of --verify-roundtrip. Differences in how text is split into
+// nodes and the localIds generated for decisions are ignored.
+func verifyRoundtrip(translator *md2adf.Translator, input []byte) int {
+	first, err := translator.TranslateToADF(input)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing markdown: %v\n", err)
+		return 1
+	}
+
+	markdown, err := translator.TranslateToMarkdown(first)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error translating ADF document: %v\n", err)
+		return 1
+	}
+
+	second, err := translator.TranslateToADF([]byte(markdown))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing translated markdown: %v\n", err)
+		return 1
+	}
+
+	adf.MergeText(first)
+	adf.MergeText(second)
+	clearDecisionIDs(first)
+	clearDecisionIDs(second)
+	if adf.EqualDocuments(first, second) {
+		return 0
+	}
+
+	before, err := first.ToJSON()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error converting to JSON: %v\n", err)
+		return 1
+	}
+	after, err := second.ToJSON()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error converting to JSON: %v\n", err)
+		return 1
+	}
+
+	fmt.Print(unifiedDiff("markdown", "roundtrip", string(before), string(after)))
+	return exitCheckFailed
+}
+
+// clearDecisionIDs removes the localIds of the decision lists and items of
+// doc, which md2adf generates anew on every translation.
+func clearDecisionIDs(doc *adf.ADFDocument) {
+	adf.Walk(doc, func(n *adf.ADFNode, _ int) bool {
+		if n.Type == adf.NodeDecisionList || n.Type == adf.ChildNodeDecisionItem {
+			delete(n.Attrs, "localId")
+		}
+		return true
+	})
+}
+
+// unifiedDiff returns the unified diff of the lines of a and b, empty if they
+// are equal.
+func unifiedDiff(nameA, nameB, a, b string) string {
+	linesA, linesB := strings.Split(a, "\n"), strings.Split(b, "\n")
+
+	// The lines before the first and after the last difference are common,
+	// so only those in between need comparing, which for the few changes of
+	// a roundtrip keeps the table below small
+	prefix := 0
+	for prefix < len(linesA) && prefix < len(linesB) && linesA[prefix] == linesB[prefix] {
+		prefix++
+	}
+	endA, endB := len(linesA), len(linesB)
+	for endA > prefix && endB > prefix && linesA[endA-1] == linesB[endB-1] {
+		endA--
+		endB--
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of
+	// linesA[prefix+i:endA] and linesB[prefix+j:endB]
+	lcs := make([][]int, endA-prefix+1)
+	for i := range lcs {
+		lcs[i] = make([]int, endB-prefix+1)
+	}
+	for i := endA - prefix - 1; i >= 0; i-- {
+		for j := endB - prefix - 1; j >= 0; j-- {
+			if linesA[prefix+i] == linesB[prefix+j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	// edits turns a into b line by line, keeping (' '), removing ('-') or
+	// adding ('+') a line
+	type edit struct {
+		op   byte
+		line string
+		i, j int // line numbers in a and b before the edit
+	}
+	var edits []edit
+	for k := range prefix {
+		edits = append(edits, edit{' ', linesA[k], k, k})
+	}
+	i, j := prefix, prefix
+	for i < endA || j < endB {
+		switch {
+		case i < endA && j < endB && linesA[i] == linesB[j]:
+			edits = append(edits, edit{' ', linesA[i], i, j})
+			i++
+			j++
+		case i < endA && (j == endB || lcs[i+1-prefix][j-prefix] >= lcs[i-prefix][j+1-prefix]):
+			edits = append(edits, edit{'-', linesA[i], i, j})
+			i++
+		default:
+			edits = append(edits, edit{'+', linesB[j], i, j})
+			j++
+		}
+	}
+	for ; i < len(linesA); i, j = i+1, j+1 {
+		edits = append(edits, edit{' ', linesA[i], i, j})
+	}
+
+	var sb strings.Builder
+	for start := 0; start < len(edits); {
+		if edits[start].op == ' ' {
+			start++
+			continue
+		}
+
+		// a hunk spans the changes less than 2*diffContext lines apart,
+		// with diffContext lines of context on both ends
+		end := start
+		for k := start; k < len(edits) && k-end <= 2*diffContext; k++ {
+			if edits[k].op != ' ' {
+				end = k + 1
+			}
+		}
+		from, to := max(start-diffContext, 0), min(end+diffContext, len(edits))
+
+		if sb.Len() == 0 {
+			fmt.Fprintf(&sb, "--- %s\n+++ %s\n", nameA, nameB)
+		}
+		var countA, countB int
+		for _, e := range edits[from:to] {
+			if e.op != '+' {
+				countA++
+			}
+			if e.op != '-' {
+				countB++
+			}
+		}
+		fmt.Fprintf(&sb, "@@ -%d,%d +%d,%d @@\n", edits[from].i+1, countA, edits[from].j+1, countB)
+		for _, e := range edits[from:to] {
+			fmt.Fprintf(&sb, "%c%s\n", e.op, e.line)
+		}
+
+		start = to
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jorres/md2adf-translator/md2adf"
+)
+
+// numbered returns the lines "1" to "n", with the given lines replaced.
+func numbered(n int, replaced map[int]string) string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = fmt.Sprint(i + 1)
+		if r, ok := replaced[i+1]; ok {
+			lines[i] = r
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     string
+		expected string
+	}{
+		{
+			name: "equal",
+			a:    numbered(5, nil),
+			b:    numbered(5, nil),
+		},
+		{
+			name:     "one change",
+			a:        numbered(10, nil),
+			b:        numbered(10, map[int]string{5: "five"}),
+			expected: "--- a\n+++ b\n@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+		},
+		{
+			name:     "line added at the end",
+			a:        "a\nb",
+			b:        "a\nb\nc",
+			expected: "--- a\n+++ b\n@@ -1,2 +1,3 @@\n a\n b\n+c\n",
+		},
+		{
+			name:     "changes close together share a hunk",
+			a:        numbered(12, nil),
+			b:        numbered(12, map[int]string{3: "three", 10: "ten"}),
+			expected: "--- a\n+++ b\n@@ -1,12 +1,12 @@\n 1\n 2\n-3\n+three\n 4\n 5\n 6\n 7\n 8\n 9\n-10\n+ten\n 11\n 12\n",
+		},
+		{
+			name:     "changes further apart get a hunk each",
+			a:        numbered(16, nil),
+			b:        numbered(16, map[int]string{3: "three", 11: "eleven"}),
+			expected: "--- a\n+++ b\n@@ -1,6 +1,6 @@\n 1\n 2\n-3\n+three\n 4\n 5\n 6\n@@ -8,7 +8,7 @@\n 8\n 9\n 10\n-11\n+eleven\n 12\n 13\n 14\n",
+		},
+		{
+			name:     "lines removed and added",
+			a:        "a\nb\nc\nd",
+			b:        "a\nx\ny\nd\ne",
+			expected: "--- a\n+++ b\n@@ -1,4 +1,5 @@\n a\n-b\n-c\n+x\n+y\n d\n+e\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unifiedDiff("a", "b", tt.a, tt.b); got != tt.expected {
+				t.Errorf("Expected diff:\n%s\ngot:\n%s", tt.expected, got)
+			}
+		})
+	}
+}
+
+// captureStdout returns what fn writes to os.Stdout.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	return capture(t, &os.Stdout, fn)
+}
+
+// capture returns what fn writes to *file, one of os.Stdout and os.Stderr.
+func capture(t *testing.T, file **os.File, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create a pipe: %v", err)
+	}
+	saved := *file
+	*file = w
+	defer func() { *file = saved }()
+
+	fn()
+	w.Close()
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("Failed to read the output: %v", err)
+	}
+	return string(out)
+}
+
+func TestVerifyRoundtrip(t *testing.T) {
+	var code int
+	out := captureStdout(t, func() {
+		code = verifyRoundtrip(md2adf.NewTranslator(), []byte("# Title\n\nSome **bold** text.\n\n- one\n- two\n"))
+	})
+	if code != 0 || out != "" {
+		t.Errorf("Expected a clean roundtrip to pass silently, got exit code %d and:\n%s", code, out)
+	}
+
+	// Two lists with different markers render as one
+	out = captureStdout(t, func() {
+		code = verifyRoundtrip(md2adf.NewTranslator(), []byte("* a\n+ b\n"))
+	})
+	if code != exitCheckFailed {
+		t.Errorf("Expected exit code %d, got %d", exitCheckFailed, code)
+	}
+	if !strings.HasPrefix(out, "--- markdown\n+++ roundtrip\n@@ ") || !strings.Contains(out, "-      \"type\": \"bulletList\",\n") {
+		t.Errorf("Expected a diff losing the second list, got:\n%s", out)
+	}
+}
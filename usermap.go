@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// accountIDPattern matches Atlassian account IDs: a UUID or 24 hex digits,
+// optionally prefixed with a numeric site ID as in "557058:f58131cb-...".
+var accountIDPattern = regexp.MustCompile(`(?i)^(\d+:)?([0-9a-f]{24}|[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12})$`)
+
+// jiraUser is an entry of a Jira user search export
+// (GET /rest/api/3/user/search).
+type jiraUser struct {
+	AccountID    string `json:"accountId"`
+	EmailAddress string `json:"emailAddress"`
+}
+
+// loadUserMap reads an email to account ID mapping from the JSON file at path.
+// The file holds either a flat {"email": "accountId"} object or the array of
+// users returned by the Jira user search API.
+func loadUserMap(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	mapping := make(map[string]string)
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		var users []jiraUser
+		if err := json.Unmarshal(data, &users); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		for _, user := range users {
+			if user.EmailAddress == "" {
+				continue // users hiding their email can't be mentioned by it
+			}
+			mapping[user.EmailAddress] = user.AccountID
+		}
+	} else if err := json.Unmarshal(data, &mapping); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	var invalid []string
+	for email, accountID := range mapping {
+		if !accountIDPattern.MatchString(accountID) {
+			invalid = append(invalid, fmt.Sprintf("%s (%q)", email, accountID))
+		}
+	}
+	if len(invalid) > 0 {
+		return nil, fmt.Errorf("%s: invalid account ids for %s", path, strings.Join(invalid, ", "))
+	}
+
+	return mapping, nil
+}
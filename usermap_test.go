@@ -0,0 +1,105 @@
+package main
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFile writes content to name in a temporary directory and returns its
+// path.
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestLoadUserMap(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected map[string]string
+		err      string
+	}{
+		{
+			name:    "flat object",
+			content: `{"ann@example.com": "5b10ac8d82e05b22cc7d4ef5", "bob@example.com": "557058:f58131cb-b67d-43c7-b30d-6b58d40bd077"}`,
+			expected: map[string]string{
+				"ann@example.com": "5b10ac8d82e05b22cc7d4ef5",
+				"bob@example.com": "557058:f58131cb-b67d-43c7-b30d-6b58d40bd077",
+			},
+		},
+		{
+			name: "user search export",
+			content: `
+[
+  {"accountId": "5b10ac8d82e05b22cc7d4ef5", "emailAddress": "ann@example.com", "displayName": "Ann"},
+  {"accountId": "F58131CB-B67D-43C7-B30D-6B58D40BD077", "emailAddress": "bob@example.com"}
+]`,
+			expected: map[string]string{
+				"ann@example.com": "5b10ac8d82e05b22cc7d4ef5",
+				"bob@example.com": "F58131CB-B67D-43C7-B30D-6B58D40BD077",
+			},
+		},
+		{
+			name:     "users without an email are skipped",
+			content:  `[{"accountId": "5b10ac8d82e05b22cc7d4ef5", "emailAddress": "ann@example.com"}, {"accountId": "not an id"}]`,
+			expected: map[string]string{"ann@example.com": "5b10ac8d82e05b22cc7d4ef5"},
+		},
+		{
+			name:     "empty",
+			content:  `{}`,
+			expected: map[string]string{},
+		},
+		{
+			name:    "invalid account id",
+			content: `{"ann@example.com": "5b10ac8d82e05b22cc7d4ef5", "bob@example.com": "bob"}`,
+			err:     `invalid account ids for bob@example.com ("bob")`,
+		},
+		{
+			name:    "invalid account id in an export",
+			content: `[{"accountId": "557058:", "emailAddress": "ann@example.com"}]`,
+			err:     `invalid account ids for ann@example.com ("557058:")`,
+		},
+		{
+			name:    "malformed",
+			content: `{"ann@example.com": }`,
+			err:     "invalid character",
+		},
+		{
+			name:    "wrong shape",
+			content: `["ann@example.com"]`,
+			err:     "cannot unmarshal",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeFile(t, "users.json", tt.content)
+			mapping, err := loadUserMap(path)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) || !strings.HasPrefix(err.Error(), path+": ") {
+					t.Fatalf("Expected an error for %s containing %q, got %v", path, tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to load the user map: %v", err)
+			}
+			if !maps.Equal(mapping, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, mapping)
+			}
+		})
+	}
+}
+
+func TestLoadUserMapMissingFile(t *testing.T) {
+	if _, err := loadUserMap(filepath.Join(t.TempDir(), "users.json")); !os.IsNotExist(err) {
+		t.Errorf("Expected a not-exist error, got %v", err)
+	}
+}